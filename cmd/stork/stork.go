@@ -22,6 +22,7 @@ import (
 	"github.com/libopenstorage/stork/pkg/migration"
 	"github.com/libopenstorage/stork/pkg/monitor"
 	"github.com/libopenstorage/stork/pkg/pvcwatcher"
+	"github.com/libopenstorage/stork/pkg/queue"
 	"github.com/libopenstorage/stork/pkg/resourcecollector"
 	"github.com/libopenstorage/stork/pkg/rule"
 	"github.com/libopenstorage/stork/pkg/schedule"
@@ -191,8 +192,9 @@ func run(c *cli.Context) {
 		}
 	}
 	webhook = &webhookadmission.Controller{
-		Driver:   d,
-		Recorder: recorder,
+		Driver:         d,
+		Recorder:       recorder,
+		AdminNamespace: getAdminNamespace(c),
 	}
 	if err := webhook.Start(); err != nil {
 		log.Fatalf("error starting webhook controller: %v", err)
@@ -252,6 +254,14 @@ func run(c *cli.Context) {
 	}
 }
 
+func getAdminNamespace(c *cli.Context) string {
+	adminNamespace := c.String("admin-namespace")
+	if adminNamespace == "" {
+		adminNamespace = c.String("migration-admin-namespace")
+	}
+	return adminNamespace
+}
+
 func runStork(d volume.Driver, recorder record.EventRecorder, c *cli.Context) {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
@@ -263,16 +273,14 @@ func runStork(d volume.Driver, recorder record.EventRecorder, c *cli.Context) {
 		log.Fatalf("Error initializing rule: %v", err)
 	}
 
+	adminNamespace := getAdminNamespace(c)
 	resourceCollector := resourcecollector.ResourceCollector{
-		Driver: d,
+		Driver:         d,
+		AdminNamespace: adminNamespace,
 	}
 	if err := resourceCollector.Init(nil); err != nil {
 		log.Fatalf("Error initializing ResourceCollector: %v", err)
 	}
-	adminNamespace := c.String("admin-namespace")
-	if adminNamespace == "" {
-		adminNamespace = c.String("migration-admin-namespace")
-	}
 
 	monitor := &monitor.Monitor{
 		Driver:      d,
@@ -285,6 +293,9 @@ func runStork(d volume.Driver, recorder record.EventRecorder, c *cli.Context) {
 	if err := schedule.Init(); err != nil {
 		log.Fatalf("Error initializing schedule: %v", err)
 	}
+	if err := queue.Init(adminNamespace); err != nil {
+		log.Fatalf("Error initializing queue: %v", err)
+	}
 	if d != nil {
 		if c.Bool("health-monitor") {
 			if err := monitor.Start(); err != nil {
@@ -56,6 +56,8 @@ type aws struct {
 	storkvolume.ClusterDomainsNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.BackupCopyNotRequired
+	storkvolume.BackupLocationCredentialsNotRequired
 }
 
 func (a *aws) Init(_ interface{}) error {
@@ -54,6 +54,8 @@ type azure struct {
 	storkvolume.ClusterDomainsNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.BackupCopyNotRequired
+	storkvolume.BackupLocationCredentialsNotRequired
 }
 
 func (a *azure) Init(_ interface{}) error {
@@ -48,6 +48,8 @@ type gcp struct {
 	storkvolume.ClusterDomainsNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.BackupCopyNotRequired
+	storkvolume.BackupLocationCredentialsNotRequired
 }
 
 func (g *gcp) Init(_ interface{}) error {
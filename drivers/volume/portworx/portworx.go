@@ -43,7 +43,9 @@ import (
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 	v1 "k8s.io/api/core/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -1947,6 +1949,10 @@ func (p *portworx) DeletePair(pair *storkapi.ClusterPair) error {
 	return p.clusterManager.DeletePair(pair.Status.RemoteStorageID)
 }
 
+func (p *portworx) ValidatePair(pair *storkapi.ClusterPair) error {
+	return p.clusterManager.ValidatePair(pair.Status.RemoteStorageID)
+}
+
 func (p *portworx) StartMigration(migration *storkapi.Migration) ([]*storkapi.MigrationVolumeInfo, error) {
 	volDriver, err := p.getUserVolDriver(migration.Annotations)
 	if err != nil {
@@ -1987,6 +1993,12 @@ func (p *portworx) StartMigration(migration *storkapi.Migration) ([]*storkapi.Mi
 				PersistentVolumeClaim: pvc.Name,
 				Namespace:             pvc.Namespace,
 			}
+			// The volume is bound to the PVC in the mapped namespace on the
+			// destination since the claim of the PV is updated when the
+			// resources are migrated
+			if destNamespace := migration.Spec.GetDestinationNamespace(pvc.Namespace); destNamespace != pvc.Namespace {
+				volumeInfo.DestinationNamespace = destNamespace
+			}
 			volumeInfos = append(volumeInfos, volumeInfo)
 
 			volume, err := core.Instance().GetVolumeForPersistentVolumeClaim(&pvc)
@@ -1994,38 +2006,203 @@ func (p *portworx) StartMigration(migration *storkapi.Migration) ([]*storkapi.Mi
 				return nil, fmt.Errorf("error getting volume for PVC: %v", err)
 			}
 			volumeInfo.Volume = volume
-			taskID := p.getMigrationTaskID(migration, volumeInfo)
-			_, err = volDriver.CloudMigrateStart(&api.CloudMigrateStartRequest{
-				TaskId:    taskID,
-				Operation: api.CloudMigrate_MigrateVolume,
-				ClusterId: clusterPair.Status.RemoteStorageID,
-				TargetId:  volume,
-			})
-			if err != nil {
-				if _, ok := err.(*ost_errors.ErrExists); !ok {
-					return nil, fmt.Errorf("error starting migration for volume: %v", err)
-				}
+			if err := p.startVolumeMigration(volDriver, migration, clusterPair, volumeInfo); err != nil {
+				return nil, err
 			}
-			volumeInfo.Status = storkapi.MigrationStatusInProgress
-			volumeInfo.Reason = "Volume migration has started. Backup in progress."
 		}
 	}
 
 	return volumeInfos, nil
 }
 
+// RetryMigration starts the migration again for the volumes that failed
+func (p *portworx) RetryMigration(
+	migration *storkapi.Migration,
+	volumeInfos []*storkapi.MigrationVolumeInfo,
+) ([]*storkapi.MigrationVolumeInfo, error) {
+	volDriver, err := p.getUserVolDriver(migration.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	clusterPair, err := storkops.Instance().GetClusterPair(migration.Spec.ClusterPair, migration.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error getting clusterpair: %v", err)
+	}
+	for _, volumeInfo := range volumeInfos {
+		volumeInfo.Retries = migration.Status.Retries
+		if err := p.startVolumeMigration(volDriver, migration, clusterPair, volumeInfo); err != nil {
+			return nil, err
+		}
+	}
+	return volumeInfos, nil
+}
+
+func (p *portworx) startVolumeMigration(
+	volDriver volume.VolumeDriver,
+	migration *storkapi.Migration,
+	clusterPair *storkapi.ClusterPair,
+	volumeInfo *storkapi.MigrationVolumeInfo,
+) error {
+	taskID := p.getMigrationTaskID(migration, volumeInfo)
+	_, err := volDriver.CloudMigrateStart(&api.CloudMigrateStartRequest{
+		TaskId:    taskID,
+		Operation: api.CloudMigrate_MigrateVolume,
+		ClusterId: clusterPair.Status.RemoteStorageID,
+		TargetId:  volumeInfo.Volume,
+	})
+	if err != nil {
+		if _, ok := err.(*ost_errors.ErrExists); !ok {
+			return fmt.Errorf("error starting migration for volume: %v", err)
+		}
+	}
+	volumeInfo.Status = storkapi.MigrationStatusInProgress
+	volumeInfo.Reason = "Volume migration has started. Backup in progress."
+	return nil
+}
+
 func (p *portworx) getMigrationTaskID(migration *storkapi.Migration, volumeInfo *storkapi.MigrationVolumeInfo) string {
-	return string(migration.UID) + "-" + volumeInfo.Namespace + "-" + volumeInfo.PersistentVolumeClaim
+	return getRetryTaskID(string(migration.UID)+"-"+volumeInfo.Namespace+"-"+volumeInfo.PersistentVolumeClaim, volumeInfo.Retries)
+}
+
+func (p *portworx) getBackupRestoreTaskID(operationUID types.UID, namespace string, pvc string, retries int) string {
+	return getRetryTaskID(string(operationUID)+"-"+namespace+"-"+pvc, retries)
 }
 
-func (p *portworx) getBackupRestoreTaskID(operationUID types.UID, namespace string, pvc string) string {
-	return string(operationUID) + "-" + namespace + "-" + pvc
+// getRetryTaskID returns a different task ID for each retry since the task
+// from the failed attempt could still exist
+func getRetryTaskID(taskID string, retries int) string {
+	if retries == 0 {
+		return taskID
+	}
+	return fmt.Sprintf("%v-retry%v", taskID, retries)
 }
 
+// getCredID returns the ID of the credentials that stork created for the
+// backup location. If they weren't created by stork, credentials named
+// k8s/<namespace>/<backupLocation> are expected to have been created
 func (p *portworx) getCredID(backupLocation string, namespace string) string {
+	location, err := storkops.Instance().GetBackupLocation(backupLocation, namespace)
+	if err == nil &&
+		location.Status.CredentialStatus.DriverName == driverName &&
+		location.Status.CredentialStatus.CredentialID != "" {
+		return location.Status.CredentialStatus.CredentialID
+	}
 	return "k8s/" + namespace + "/" + backupLocation
 }
 
+func (p *portworx) getCredentialsClient() (api.OpenStorageCredentialsClient, error) {
+	conn, err := p.sdkConn.getGrpcConn()
+	if err != nil {
+		return nil, err
+	}
+	return api.NewOpenStorageCredentialsClient(conn), nil
+}
+
+// getCredentialCreateRequest returns the request to create credentials for
+// the backup location. A new name is used each time so that the existing
+// credentials can still be used until the location refers to the new ones
+func getCredentialCreateRequest(location *storkapi.BackupLocation) (*api.SdkCredentialCreateRequest, error) {
+	request := &api.SdkCredentialCreateRequest{
+		Name:   fmt.Sprintf("stork-%v-%v-%v", location.Namespace, location.Name, time.Now().Unix()),
+		Bucket: location.Location.Path,
+	}
+	switch location.Location.Type {
+	case storkapi.BackupLocationS3:
+		config := location.Location.S3Config
+		if config == nil {
+			return nil, fmt.Errorf("s3Config is required for backup location type %v", location.Location.Type)
+		}
+		if config.AuthType != storkapi.S3AuthStatic {
+			return nil, &errors.ErrNotSupported{
+				Feature: "Portworx credentials",
+				Reason:  fmt.Sprintf("authType %v doesn't use static keys", config.AuthType),
+			}
+		}
+		request.CredentialType = &api.SdkCredentialCreateRequest_AwsCredential{
+			AwsCredential: &api.SdkAwsCredentialRequest{
+				AccessKey:        config.AccessKeyID,
+				SecretKey:        config.SecretAccessKey,
+				Endpoint:         config.Endpoint,
+				Region:           config.Region,
+				DisableSsl:       config.DisableSSL,
+				DisablePathStyle: config.VirtualHostedStyle,
+			},
+		}
+	case storkapi.BackupLocationAzure:
+		config := location.Location.AzureConfig
+		if config == nil {
+			return nil, fmt.Errorf("azureConfig is required for backup location type %v", location.Location.Type)
+		}
+		request.CredentialType = &api.SdkCredentialCreateRequest_AzureCredential{
+			AzureCredential: &api.SdkAzureCredentialRequest{
+				AccountName: config.StorageAccountName,
+				AccountKey:  config.StorageAccountKey,
+			},
+		}
+	case storkapi.BackupLocationGoogle:
+		config := location.Location.GoogleConfig
+		if config == nil {
+			return nil, fmt.Errorf("googleConfig is required for backup location type %v", location.Location.Type)
+		}
+		request.CredentialType = &api.SdkCredentialCreateRequest_GoogleCredential{
+			GoogleCredential: &api.SdkGoogleCredentialRequest{
+				ProjectId: config.ProjectID,
+				JsonKey:   config.AccountKey,
+			},
+		}
+	default:
+		return nil, &errors.ErrNotSupported{
+			Feature: "Portworx credentials",
+			Reason:  fmt.Sprintf("backup location type %v is not supported by Portworx", location.Location.Type),
+		}
+	}
+	return request, nil
+}
+
+func (p *portworx) UpdateBackupLocationCredentials(
+	location *storkapi.BackupLocation,
+	credentialID string,
+) (string, error) {
+	request, err := getCredentialCreateRequest(location)
+	if err != nil {
+		return "", err
+	}
+	credentialsClient, err := p.getCredentialsClient()
+	if err != nil {
+		return "", err
+	}
+	ctx, err := p.getUserContext(context.Background(), location.Annotations)
+	if err != nil {
+		return "", err
+	}
+	response, err := credentialsClient.Create(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("error creating credentials: %v", err)
+	}
+	return response.CredentialId, nil
+}
+
+func (p *portworx) DeleteBackupLocationCredentials(
+	location *storkapi.BackupLocation,
+	credentialID string,
+) error {
+	credentialsClient, err := p.getCredentialsClient()
+	if err != nil {
+		return err
+	}
+	ctx, err := p.getUserContext(context.Background(), location.Annotations)
+	if err != nil {
+		return err
+	}
+	_, err = credentialsClient.Delete(ctx, &api.SdkCredentialDeleteRequest{
+		CredentialId: credentialID,
+	})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return fmt.Errorf("error deleting credentials: %v", err)
+	}
+	return nil
+}
+
 func (p *portworx) GetMigrationStatus(migration *storkapi.Migration) ([]*storkapi.MigrationVolumeInfo, error) {
 	volDriver, err := p.getUserVolDriver(migration.Annotations)
 	if err != nil {
@@ -2464,6 +2641,7 @@ func (p *portworx) StartBackup(backup *storkapi.ApplicationBackup,
 	if err != nil {
 		return nil, err
 	}
+	credID := p.getCredID(backup.Spec.BackupLocation, backup.Namespace)
 	volumeInfos := make([]*storkapi.ApplicationBackupVolumeInfo, 0)
 	for _, pvc := range pvcs {
 		log.ApplicationBackupLog(backup).Infof("PVC %v in portworx", pvc.Name)
@@ -2475,6 +2653,7 @@ func (p *portworx) StartBackup(backup *storkapi.ApplicationBackup,
 		volumeInfo.PersistentVolumeClaim = pvc.Name
 		volumeInfo.Namespace = pvc.Namespace
 		volumeInfo.DriverName = driverName
+		volumeInfo.Retries = backup.Status.Retries
 		volumeInfos = append(volumeInfos, volumeInfo)
 
 		volume, err := core.Instance().GetVolumeForPersistentVolumeClaim(&pvc)
@@ -2482,8 +2661,7 @@ func (p *portworx) StartBackup(backup *storkapi.ApplicationBackup,
 			return nil, fmt.Errorf("Error getting volume for PVC: %v", err)
 		}
 		volumeInfo.Volume = volume
-		taskID := p.getBackupRestoreTaskID(backup.UID, volumeInfo.Namespace, volumeInfo.PersistentVolumeClaim)
-		credID := p.getCredID(backup.Spec.BackupLocation, backup.Namespace)
+		taskID := p.getBackupRestoreTaskID(backup.UID, volumeInfo.Namespace, volumeInfo.PersistentVolumeClaim, backup.Status.Retries)
 		request := &api.CloudBackupCreateRequest{
 			VolumeID:       volume,
 			CredentialUUID: credID,
@@ -2513,7 +2691,7 @@ func (p *portworx) GetBackupStatus(backup *storkapi.ApplicationBackup) ([]*stork
 		if vInfo.DriverName != driverName {
 			continue
 		}
-		taskID := p.getBackupRestoreTaskID(backup.UID, vInfo.Namespace, vInfo.PersistentVolumeClaim)
+		taskID := p.getBackupRestoreTaskID(backup.UID, vInfo.Namespace, vInfo.PersistentVolumeClaim, vInfo.Retries)
 		csStatus := p.getCloudSnapStatus(volDriver, api.CloudBackupOp, taskID)
 		if isCloudsnapStatusActive(csStatus.status) {
 			vInfo.Status = storkapi.ApplicationBackupStatusInProgress
@@ -2537,11 +2715,12 @@ func (p *portworx) DeleteBackup(backup *storkapi.ApplicationBackup) error {
 	if err != nil {
 		return err
 	}
+	credID := p.getCredID(backup.Spec.BackupLocation, backup.Namespace)
 	for _, vInfo := range backup.Status.Volumes {
 		if vInfo.BackupID != "" {
 			input := &api.CloudBackupDeleteRequest{
 				ID:             vInfo.BackupID,
-				CredentialUUID: p.getCredID(backup.Spec.BackupLocation, backup.Namespace),
+				CredentialUUID: credID,
 			}
 			if err := volDriver.CloudBackupDelete(input); err != nil {
 				return err
@@ -2557,7 +2736,7 @@ func (p *portworx) CancelBackup(backup *storkapi.ApplicationBackup) error {
 		return err
 	}
 	for _, vInfo := range backup.Status.Volumes {
-		taskID := p.getBackupRestoreTaskID(backup.UID, vInfo.Namespace, vInfo.PersistentVolumeClaim)
+		taskID := p.getBackupRestoreTaskID(backup.UID, vInfo.Namespace, vInfo.PersistentVolumeClaim, vInfo.Retries)
 		if err := p.stopCloudBackupTask(volDriver, taskID); err != nil {
 			return err
 		}
@@ -2565,6 +2744,45 @@ func (p *portworx) CancelBackup(backup *storkapi.ApplicationBackup) error {
 	return nil
 }
 
+// ValidateBackupCopy returns ErrNotSupported. Cloudsnaps can't be copied
+// between credentials, and taking a new cloudsnap of the volume for a replica
+// would store data from a different point in time than the resources in the
+// backup without running the rules for the backup
+func (p *portworx) ValidateBackupCopy() error {
+	return &errors.ErrNotSupported{
+		Feature: "Copying backups to replica locations",
+		Reason:  "Cloudsnaps can't be copied between credentials",
+	}
+}
+
+// StartBackupCopy returns ErrNotSupported
+func (p *portworx) StartBackupCopy(
+	*storkapi.ApplicationBackup,
+	*storkapi.ApplicationBackupReplicaInfo,
+	[]*storkapi.ApplicationBackupVolumeInfo,
+) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
+	return nil, p.ValidateBackupCopy()
+}
+
+// GetBackupCopyStatus returns ErrNotSupported
+func (p *portworx) GetBackupCopyStatus(
+	*storkapi.ApplicationBackup,
+	*storkapi.ApplicationBackupReplicaInfo,
+	[]*storkapi.ApplicationBackupVolumeInfo,
+) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
+	return nil, p.ValidateBackupCopy()
+}
+
+// DeleteBackupCopy doesn't do anything since backups are never copied for
+// Portworx volumes
+func (p *portworx) DeleteBackupCopy(
+	*storkapi.ApplicationBackup,
+	*storkapi.ApplicationBackupReplicaInfo,
+	[]*storkapi.ApplicationBackupVolumeInfo,
+) error {
+	return nil
+}
+
 func (p *portworx) stopCloudBackupTask(volDriver volume.VolumeDriver, taskID string) error {
 	input := &api.CloudBackupStateChangeRequest{
 		Name:           taskID,
@@ -2605,6 +2823,7 @@ func (p *portworx) StartRestore(
 	if err != nil {
 		return nil, err
 	}
+	credID := p.getCredID(restore.Spec.BackupLocation, restore.Namespace)
 	volumeInfos := make([]*storkapi.ApplicationRestoreVolumeInfo, 0)
 	for _, backupVolumeInfo := range volumeBackupInfos {
 		volumeInfo := &storkapi.ApplicationRestoreVolumeInfo{}
@@ -2613,10 +2832,10 @@ func (p *portworx) StartRestore(
 		volumeInfo.SourceVolume = backupVolumeInfo.Volume
 		volumeInfo.RestoreVolume = p.generatePVName()
 		volumeInfo.DriverName = driverName
+		volumeInfo.Retries = restore.Status.Retries
 		volumeInfos = append(volumeInfos, volumeInfo)
 
-		taskID := p.getBackupRestoreTaskID(restore.UID, volumeInfo.SourceNamespace, volumeInfo.PersistentVolumeClaim)
-		credID := p.getCredID(restore.Spec.BackupLocation, restore.Namespace)
+		taskID := p.getBackupRestoreTaskID(restore.UID, volumeInfo.SourceNamespace, volumeInfo.PersistentVolumeClaim, restore.Status.Retries)
 		request := &api.CloudBackupRestoreRequest{
 			ID:                backupVolumeInfo.BackupID,
 			RestoreVolumeName: volumeInfo.RestoreVolume,
@@ -2645,7 +2864,7 @@ func (p *portworx) GetRestoreStatus(restore *storkapi.ApplicationRestore) ([]*st
 		if vInfo.DriverName != driverName {
 			continue
 		}
-		taskID := p.getBackupRestoreTaskID(restore.UID, vInfo.SourceNamespace, vInfo.PersistentVolumeClaim)
+		taskID := p.getBackupRestoreTaskID(restore.UID, vInfo.SourceNamespace, vInfo.PersistentVolumeClaim, vInfo.Retries)
 		csStatus := p.getCloudSnapStatus(volDriver, api.CloudRestoreOp, taskID)
 		if isCloudsnapStatusActive(csStatus.status) {
 			vInfo.Status = storkapi.ApplicationRestoreStatusInProgress
@@ -2669,7 +2888,7 @@ func (p *portworx) CancelRestore(restore *storkapi.ApplicationRestore) error {
 		return err
 	}
 	for _, vInfo := range restore.Status.Volumes {
-		taskID := p.getBackupRestoreTaskID(restore.UID, vInfo.SourceNamespace, vInfo.PersistentVolumeClaim)
+		taskID := p.getBackupRestoreTaskID(restore.UID, vInfo.SourceNamespace, vInfo.PersistentVolumeClaim, vInfo.Retries)
 		if err := p.stopCloudBackupTask(volDriver, taskID); err != nil {
 			return err
 		}
@@ -16,6 +16,12 @@ const (
 	// Default snapshot type if drivers don't support different types or can't
 	// find driver
 	defaultSnapType = "Local"
+
+	// BackupDataPathOption is set in the options of a volume backup by
+	// drivers that store the data for the volume backup in the backup
+	// location. It is the path under which all the objects for the volume
+	// backup are stored, so that they can be exported along with the backup
+	BackupDataPathOption = "backupDataPath"
 )
 
 // Driver defines an external volume driver interface.
@@ -97,6 +103,8 @@ type ClusterPairPluginInterface interface {
 	CreatePair(*storkapi.ClusterPair) (string, error)
 	// Deletes a paring with a remote cluster
 	DeletePair(*storkapi.ClusterPair) error
+	// Validates that an existing pair with a remote cluster is healthy
+	ValidatePair(*storkapi.ClusterPair) error
 }
 
 // MigratePluginInterface Interface to migrate data between clusters
@@ -109,6 +117,9 @@ type MigratePluginInterface interface {
 	GetMigrationStatus(*storkapi.Migration) ([]*storkapi.MigrationVolumeInfo, error)
 	// Cancel the migration of volumes specified in the status
 	CancelMigration(*storkapi.Migration) error
+	// Retry the migration of the volumes that failed. Returns the updated
+	// info for the volumes
+	RetryMigration(*storkapi.Migration, []*storkapi.MigrationVolumeInfo) ([]*storkapi.MigrationVolumeInfo, error)
 	// Update the PVC spec to point to the migrated volume on the destination
 	// cluster
 	UpdateMigratedPersistentVolumeSpec(*v1.PersistentVolume) (*v1.PersistentVolume, error)
@@ -144,6 +155,25 @@ type BackupRestorePluginInterface interface {
 	GetRestoreStatus(*storkapi.ApplicationRestore) ([]*storkapi.ApplicationRestoreVolumeInfo, error)
 	// Cancel the restore of volumes specified in the status
 	CancelRestore(*storkapi.ApplicationRestore) error
+	// Returns an error if the volume backups taken by the driver can't be
+	// copied to replica backup locations
+	ValidateBackupCopy() error
+	// Start copying the given volume backups to the replica backup location
+	StartBackupCopy(*storkapi.ApplicationBackup, *storkapi.ApplicationBackupReplicaInfo, []*storkapi.ApplicationBackupVolumeInfo) ([]*storkapi.ApplicationBackupVolumeInfo, error)
+	// Get the status of the copy of the given volume backups to the replica
+	// backup location
+	GetBackupCopyStatus(*storkapi.ApplicationBackup, *storkapi.ApplicationBackupReplicaInfo, []*storkapi.ApplicationBackupVolumeInfo) ([]*storkapi.ApplicationBackupVolumeInfo, error)
+	// Delete the copies of the given volume backups from the replica backup
+	// location
+	DeleteBackupCopy(*storkapi.ApplicationBackup, *storkapi.ApplicationBackupReplicaInfo, []*storkapi.ApplicationBackupVolumeInfo) error
+	// Create the credentials used by the driver to access the backup
+	// location, replacing the credentials with the given ID if any. The
+	// replaced credentials are deleted by the caller once the location refers
+	// to the new ones. Returns the ID of the new credentials
+	UpdateBackupLocationCredentials(*storkapi.BackupLocation, string) (string, error)
+	// Delete the credentials with the given ID that were created for the
+	// backup location
+	DeleteBackupLocationCredentials(*storkapi.BackupLocation, string) error
 }
 
 // SnapshotRestorePluginInterface Interface to perform in place restore of volume
@@ -283,6 +313,11 @@ func (c *ClusterPairNotSupported) DeletePair(*storkapi.ClusterPair) error {
 	return &errors.ErrNotSupported{}
 }
 
+// ValidatePair Returns ErrNotSupported
+func (c *ClusterPairNotSupported) ValidatePair(*storkapi.ClusterPair) error {
+	return &errors.ErrNotSupported{}
+}
+
 // MigrationNotSupported to be used by drivers that don't support migration
 type MigrationNotSupported struct{}
 
@@ -301,6 +336,14 @@ func (m *MigrationNotSupported) CancelMigration(*storkapi.Migration) error {
 	return &errors.ErrNotSupported{}
 }
 
+// RetryMigration returns ErrNotSupported
+func (m *MigrationNotSupported) RetryMigration(
+	*storkapi.Migration,
+	[]*storkapi.MigrationVolumeInfo,
+) ([]*storkapi.MigrationVolumeInfo, error) {
+	return nil, &errors.ErrNotSupported{}
+}
+
 // UpdateMigratedPersistentVolumeSpec returns ErrNotSupported
 func (m *MigrationNotSupported) UpdateMigratedPersistentVolumeSpec(
 	*v1.PersistentVolume,
@@ -388,6 +431,113 @@ func (b *BackupRestoreNotSupported) CancelRestore(*storkapi.ApplicationRestore)
 	return &errors.ErrNotSupported{}
 }
 
+// ValidateBackupCopy returns ErrNotSupported
+func (b *BackupRestoreNotSupported) ValidateBackupCopy() error {
+	return &errors.ErrNotSupported{}
+}
+
+// StartBackupCopy returns ErrNotSupported
+func (b *BackupRestoreNotSupported) StartBackupCopy(
+	*storkapi.ApplicationBackup,
+	*storkapi.ApplicationBackupReplicaInfo,
+	[]*storkapi.ApplicationBackupVolumeInfo,
+) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
+	return nil, &errors.ErrNotSupported{}
+}
+
+// GetBackupCopyStatus returns ErrNotSupported
+func (b *BackupRestoreNotSupported) GetBackupCopyStatus(
+	*storkapi.ApplicationBackup,
+	*storkapi.ApplicationBackupReplicaInfo,
+	[]*storkapi.ApplicationBackupVolumeInfo,
+) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
+	return nil, &errors.ErrNotSupported{}
+}
+
+// DeleteBackupCopy returns ErrNotSupported
+func (b *BackupRestoreNotSupported) DeleteBackupCopy(
+	*storkapi.ApplicationBackup,
+	*storkapi.ApplicationBackupReplicaInfo,
+	[]*storkapi.ApplicationBackupVolumeInfo,
+) error {
+	return &errors.ErrNotSupported{}
+}
+
+// UpdateBackupLocationCredentials returns ErrNotSupported
+func (b *BackupRestoreNotSupported) UpdateBackupLocationCredentials(*storkapi.BackupLocation, string) (string, error) {
+	return "", &errors.ErrNotSupported{}
+}
+
+// DeleteBackupLocationCredentials returns ErrNotSupported
+func (b *BackupRestoreNotSupported) DeleteBackupLocationCredentials(*storkapi.BackupLocation, string) error {
+	return &errors.ErrNotSupported{}
+}
+
+// BackupCopyNotRequired to be used by drivers whose backups aren't stored in
+// the backup location. The same backups can be restored when using a replica
+// backup location, so nothing needs to be copied
+type BackupCopyNotRequired struct{}
+
+// BackupCopySharedReason is the reason set for volume backups that are shared
+// with the primary backup location instead of being copied to the replica
+const BackupCopySharedReason = "Volume backup is shared with the primary backup location"
+
+// ValidateBackupCopy returns nil since nothing needs to be copied
+func (b *BackupCopyNotRequired) ValidateBackupCopy() error {
+	return nil
+}
+
+// StartBackupCopy returns the volume backups as they are, marked as shared
+// with the primary backup location
+func (b *BackupCopyNotRequired) StartBackupCopy(
+	_ *storkapi.ApplicationBackup,
+	_ *storkapi.ApplicationBackupReplicaInfo,
+	volumeBackupInfos []*storkapi.ApplicationBackupVolumeInfo,
+) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
+	volumeInfos := make([]*storkapi.ApplicationBackupVolumeInfo, 0)
+	for _, vInfo := range volumeBackupInfos {
+		vInfo = vInfo.DeepCopy()
+		vInfo.Reason = BackupCopySharedReason
+		volumeInfos = append(volumeInfos, vInfo)
+	}
+	return volumeInfos, nil
+}
+
+// GetBackupCopyStatus returns the volume backups as they are
+func (b *BackupCopyNotRequired) GetBackupCopyStatus(
+	_ *storkapi.ApplicationBackup,
+	_ *storkapi.ApplicationBackupReplicaInfo,
+	volumeBackupInfos []*storkapi.ApplicationBackupVolumeInfo,
+) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
+	return volumeBackupInfos, nil
+}
+
+// DeleteBackupCopy doesn't do anything since the backups are deleted along
+// with the original backup
+func (b *BackupCopyNotRequired) DeleteBackupCopy(
+	*storkapi.ApplicationBackup,
+	*storkapi.ApplicationBackupReplicaInfo,
+	[]*storkapi.ApplicationBackupVolumeInfo,
+) error {
+	return nil
+}
+
+// BackupLocationCredentialsNotRequired to be used by drivers that don't need
+// credentials to access the backup location
+type BackupLocationCredentialsNotRequired struct{}
+
+// UpdateBackupLocationCredentials returns ErrNotSupported since no
+// credentials need to be created
+func (b *BackupLocationCredentialsNotRequired) UpdateBackupLocationCredentials(*storkapi.BackupLocation, string) (string, error) {
+	return "", &errors.ErrNotSupported{}
+}
+
+// DeleteBackupLocationCredentials returns ErrNotSupported since no
+// credentials need to be deleted
+func (b *BackupLocationCredentialsNotRequired) DeleteBackupLocationCredentials(*storkapi.BackupLocation, string) error {
+	return &errors.ErrNotSupported{}
+}
+
 // CloneNotSupported to be used by drivers that don't support volume clone
 type CloneNotSupported struct{}
 
@@ -3,4 +3,8 @@ package stork
 const (
 	// GroupName is the group name of the CRD
 	GroupName = "stork.libopenstorage.org"
+	// RetryAnnotation is added to a failed or partially successful
+	// ApplicationBackup, ApplicationRestore, Migration, MigrationFailover or
+	// DRPlan to retry it from where it failed
+	RetryAnnotation = GroupName + "/retry"
 )
@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,8 +31,39 @@ type ApplicationBackupSpec struct {
 	PreExecRule    string                             `json:"preExecRule"`
 	PostExecRule   string                             `json:"postExecRule"`
 	ReclaimPolicy  ApplicationBackupReclaimPolicyType `json:"reclaimPolicy"`
+	// ReplicaBackupLocations are the backup locations the backup should be
+	// copied to after it completes successfully
+	ReplicaBackupLocations []string `json:"replicaBackupLocations"`
+	// Priority of the operation when it is queued because the limits for
+	// concurrent operations have been reached. Operations with a higher
+	// priority are started first
+	Priority int `json:"priority"`
+	// SecretsMode is how the Secrets in the namespaces are backed up.
+	// Defaults to Include
+	SecretsMode ApplicationBackupSecretsModeType `json:"secretsMode"`
+	// SecretsEncryptionKey is the key in a Secret in the namespace of the
+	// backup that is used to encrypt the Secrets when SecretsMode is set to
+	// Encrypt
+	SecretsEncryptionKey *corev1.SecretKeySelector `json:"secretsEncryptionKey"`
 }
 
+// ApplicationBackupSecretsModeType is how Secrets are handled by the
+// application backup
+type ApplicationBackupSecretsModeType string
+
+const (
+	// ApplicationBackupSecretsModeInclude is to specify that Secrets should be
+	// backed up with the rest of the resources
+	ApplicationBackupSecretsModeInclude ApplicationBackupSecretsModeType = "Include"
+	// ApplicationBackupSecretsModeExclude is to specify that Secrets should
+	// not be backed up
+	ApplicationBackupSecretsModeExclude ApplicationBackupSecretsModeType = "Exclude"
+	// ApplicationBackupSecretsModeEncrypt is to specify that Secrets should be
+	// stored separately from the rest of the resources and encrypted with the
+	// SecretsEncryptionKey. They can only be restored with the same key
+	ApplicationBackupSecretsModeEncrypt ApplicationBackupSecretsModeType = "Encrypt"
+)
+
 // ApplicationBackupReclaimPolicyType is the reclaim policy for the application backup
 type ApplicationBackupReclaimPolicyType string
 
@@ -53,6 +85,26 @@ type ApplicationBackupStatus struct {
 	BackupPath       string                           `json:"backupPath"`
 	TriggerTimestamp metav1.Time                      `json:"triggerTimestamp"`
 	FinishTimestamp  metav1.Time                      `json:"finishTimestamp"`
+	// Replicas is the status of the copy of the backup to each of the
+	// replica backup locations
+	Replicas []*ApplicationBackupReplicaInfo `json:"replicas"`
+	// Retries is the number of times the failed volumes of the backup have
+	// been retried
+	Retries int `json:"retries"`
+	// QueuePosition is the position of the operation in the queue when its
+	// status is Queued, or when a retry of the operation is waiting to start
+	QueuePosition int `json:"queuePosition"`
+}
+
+// ApplicationBackupReplicaInfo is the info for the copy of a backup to a
+// replica backup location
+type ApplicationBackupReplicaInfo struct {
+	BackupLocation  string                         `json:"backupLocation"`
+	Status          ApplicationBackupStatusType    `json:"status"`
+	Reason          string                         `json:"reason"`
+	Volumes         []*ApplicationBackupVolumeInfo `json:"volumes"`
+	BackupPath      string                         `json:"backupPath"`
+	FinishTimestamp metav1.Time                    `json:"finishTimestamp"`
 }
 
 // ApplicationBackupResourceInfo is the info for the backup of a resource
@@ -73,6 +125,8 @@ type ApplicationBackupVolumeInfo struct {
 	Status                ApplicationBackupStatusType `json:"status"`
 	Reason                string                      `json:"reason"`
 	Options               map[string]string           `jons:"options"`
+	// Retries is the retry of the backup in which the volume was backed up
+	Retries int `json:"retries"`
 }
 
 // ApplicationBackupStatusType is the status of the application backup
@@ -83,6 +137,9 @@ const (
 	ApplicationBackupStatusInitial ApplicationBackupStatusType = ""
 	// ApplicationBackupStatusPending for when backup is still pending
 	ApplicationBackupStatusPending ApplicationBackupStatusType = "Pending"
+	// ApplicationBackupStatusQueued for when backup is waiting for other
+	// operations to complete before it can start
+	ApplicationBackupStatusQueued ApplicationBackupStatusType = "Queued"
 	// ApplicationBackupStatusInProgress for when backup is in progress
 	ApplicationBackupStatusInProgress ApplicationBackupStatusType = "InProgress"
 	// ApplicationBackupStatusFailed for when backup has failed
@@ -17,6 +17,11 @@ type ApplicationBackupScheduleSpec struct {
 	SchedulePolicyName string                        `json:"schedulePolicyName"`
 	Suspend            *bool                         `json:"suspend"`
 	ReclaimPolicy      ReclaimPolicyType             `json:"reclaimPolicy"`
+	// RPOTarget is the maximum time allowed since the last successful
+	// backup was started. An event is raised and the RPOBreached condition
+	// is set when it is exceeded
+	// +optional
+	RPOTarget *meta.Duration `json:"rpoTarget,omitempty"`
 }
 
 // ApplicationBackupTemplateSpec describes the data a ApplicationBackup should have when created
@@ -28,6 +33,17 @@ type ApplicationBackupTemplateSpec struct {
 // ApplicationBackupScheduleStatus is the status of a applicationbackup schedule
 type ApplicationBackupScheduleStatus struct {
 	Items map[SchedulePolicyType][]*ScheduledApplicationBackupStatus `json:"items"`
+	// LastSuccessfulTimestamp is the time the last successful backup was
+	// started. The RPO is measured from this time
+	// +optional
+	LastSuccessfulTimestamp meta.Time `json:"lastSuccessfulTimestamp"`
+	// LastSuccessfulFinishTimestamp is the time the last successful backup
+	// completed
+	// +optional
+	LastSuccessfulFinishTimestamp meta.Time `json:"lastSuccessfulFinishTimestamp"`
+	// Conditions is the list of conditions of the schedule
+	// +optional
+	Conditions []ScheduleCondition `json:"conditions"`
 }
 
 // ScheduledApplicationBackupStatus keeps track of the applicationbackup that was triggered by a
@@ -37,6 +37,10 @@ type ApplicationCloneSpec struct {
 	PostExecRule string            `json:"postExecRule"`
 	// ReplacePolicy to decide how to react when a object conflict occurs in the cloning process
 	ReplacePolicy ApplicationCloneReplacePolicyType `json:"replacePolicy"`
+	// Priority of the operation when it is queued because the limits for
+	// concurrent operations have been reached. Operations with a higher
+	// priority are started first
+	Priority int `json:"priority"`
 }
 
 // ApplicationCloneStatus defines the status of the clone
@@ -48,6 +52,9 @@ type ApplicationCloneStatus struct {
 	Resources       []*ApplicationCloneResourceInfo `json:"resources"`
 	Volumes         []*ApplicationCloneVolumeInfo   `json:"volumes"`
 	FinishTimestamp meta.Time                       `json:"finishTimestamp"`
+	// QueuePosition is the position of the operation in the queue when its
+	// status is Queued
+	QueuePosition int `json:"queuePosition"`
 }
 
 // ApplicationCloneResourceInfo is the info for the cloning of a resource
@@ -75,6 +82,9 @@ const (
 	ApplicationCloneStatusInitial ApplicationCloneStatusType = ""
 	// ApplicationCloneStatusPending when cloning is still pending
 	ApplicationCloneStatusPending ApplicationCloneStatusType = "Pending"
+	// ApplicationCloneStatusQueued when cloning is waiting for other
+	// operations to complete before it can start
+	ApplicationCloneStatusQueued ApplicationCloneStatusType = "Queued"
 	// ApplicationCloneStatusInProgress cloning in progress
 	ApplicationCloneStatusInProgress ApplicationCloneStatusType = "InProgress"
 	// ApplicationCloneStatusFailed when cloning has failed
@@ -115,6 +125,11 @@ const (
 	ApplicationCloneReplacePolicyDelete ApplicationCloneReplacePolicyType = "Delete"
 	// ApplicationCloneReplacePolicyRetain will retain any conflicts and not change/clone
 	ApplicationCloneReplacePolicyRetain ApplicationCloneReplacePolicyType = "Retain"
+	// ApplicationCloneReplacePolicyUpdate will patch any conflicts in the destination
+	// namespace, keeping fields that aren't being cloned, like the clusterIP and
+	// nodePorts for services. Existing PVCs are replaced to bind them to the cloned volumes,
+	// unless they are being used by pods, in which case they fail to be cloned
+	ApplicationCloneReplacePolicyUpdate ApplicationCloneReplacePolicyType = "Update"
 	// ApplicationCloneReplacePolicyFail will trigger a clone failure on conflicts
 	ApplicationCloneReplacePolicyFail ApplicationCloneReplacePolicyType = "Fail"
 )
@@ -25,12 +25,34 @@ type ApplicationRestore struct {
 
 // ApplicationRestoreSpec is the spec used to restore applications
 type ApplicationRestoreSpec struct {
-	BackupName       string                              `json:"backupName"`
-	BackupLocation   string                              `json:"backupLocation"`
+	BackupName     string `json:"backupName"`
+	BackupLocation string `json:"backupLocation"`
+	// BackupPath is the path of the backup in the BackupLocation. If set,
+	// the backup is loaded from its metadata in the BackupLocation instead
+	// of from the ApplicationBackup with BackupName, so backups can be
+	// restored without having to sync them to the cluster first. It must be
+	// under the directory for the namespace of the BackupLocation
+	BackupPath       string                              `json:"backupPath"`
 	NamespaceMapping map[string]string                   `json:"namespaceMapping"`
 	Selectors        map[string]string                   `json:"selectors"`
 	EncryptionKey    *corev1.EnvVarSource                `json:"encryptionKey"`
 	ReplacePolicy    ApplicationRestoreReplacePolicyType `json:"replacePolicy"`
+	// PostExecRule is the rule to run in the restored namespaces once the
+	// volumes and resources have been restored and the pods selected by
+	// the rule are ready
+	PostExecRule string `json:"postExecRule"`
+	// ReadinessGates are used to wait for the resources applied in a stage
+	// to be ready before applying the next stage
+	ReadinessGates []ReadinessGate `json:"readinessGates"`
+	// Priority of the operation when it is queued because the limits for
+	// concurrent operations have been reached. Operations with a higher
+	// priority are started first
+	Priority int `json:"priority"`
+	// SecretsEncryptionKey is the key in a Secret in the namespace of the
+	// restore used to decrypt the Secrets of a backup taken with SecretsMode
+	// set to Encrypt. Defaults to the SecretsEncryptionKey of the backup.
+	// Secrets are skipped if they can't be decrypted
+	SecretsEncryptionKey *corev1.SecretKeySelector `json:"secretsEncryptionKey"`
 }
 
 // ApplicationRestoreReplacePolicyType is the replace policy for the application restore
@@ -46,6 +68,14 @@ const (
 	// should retain existing resources that conflict with resources being
 	// restored
 	ApplicationRestoreReplacePolicyRetain ApplicationRestoreReplacePolicyType = "Retain"
+	// ApplicationRestoreReplacePolicyUpdate is to specify that the restore
+	// should update existing resources that conflict with resources being
+	// restored. The existing resources are patched, so fields that aren't
+	// in the backup, like labels added on the cluster and the clusterIP and
+	// nodePorts of services, are kept. Existing PVCs that are bound to other
+	// volumes are replaced to bind them to the restored volumes, unless they
+	// are being used by pods, in which case they fail to be restored
+	ApplicationRestoreReplacePolicyUpdate ApplicationRestoreReplacePolicyType = "Update"
 )
 
 // ApplicationRestoreStatus is the status of a application restore operation
@@ -55,6 +85,28 @@ type ApplicationRestoreStatus struct {
 	Resources       []*ApplicationRestoreResourceInfo `json:"resources"`
 	Volumes         []*ApplicationRestoreVolumeInfo   `json:"volumes"`
 	FinishTimestamp metav1.Time                       `json:"finishTimestamp"`
+	// BackupLocation is the location the backup is being restored from. This
+	// is one of the replica locations of the backup if the primary location
+	// wasn't reachable
+	BackupLocation string `json:"backupLocation"`
+	// ApplyStages is the progress of each stage in which the resources are
+	// applied
+	ApplyStages []*ApplyStageInfo `json:"applyStages"`
+	// Retries is the number of times the failed volumes and resources of the
+	// restore have been retried
+	Retries int `json:"retries"`
+	// QueuePosition is the position of the operation in the queue when its
+	// status is Queued, or when a retry of the operation is waiting to start
+	QueuePosition int `json:"queuePosition"`
+	// PostExecRuleStartTimestamp is when the restore started waiting for the
+	// pods selected by the PostExecRule to be ready
+	PostExecRuleStartTimestamp metav1.Time `json:"postExecRuleStartTimestamp"`
+	// PostExecRuleInProgress is set before the PostExecRule is executed so
+	// that it isn't executed again if the restore is interrupted
+	PostExecRuleInProgress bool `json:"postExecRuleInProgress"`
+	// PostExecRuleExecuted is set once the PostExecRule has been executed
+	// successfully in all the restored namespaces
+	PostExecRuleExecuted bool `json:"postExecRuleExecuted"`
 }
 
 // ApplicationRestoreResourceInfo is the info for the restore of a resource
@@ -76,6 +128,8 @@ type ApplicationRestoreVolumeInfo struct {
 	Zones                 []string                     `json:"zones"`
 	Status                ApplicationRestoreStatusType `json:"status"`
 	Reason                string                       `json:"reason"`
+	// Retries is the retry of the restore in which the volume was restored
+	Retries int `json:"retries"`
 }
 
 // ApplicationRestoreStatusType is the status of the application restore
@@ -86,6 +140,9 @@ const (
 	ApplicationRestoreStatusInitial ApplicationRestoreStatusType = ""
 	// ApplicationRestoreStatusPending for when restore is still pending
 	ApplicationRestoreStatusPending ApplicationRestoreStatusType = "Pending"
+	// ApplicationRestoreStatusQueued for when restore is waiting for other
+	// operations to complete before it can start
+	ApplicationRestoreStatusQueued ApplicationRestoreStatusType = "Queued"
 	// ApplicationRestoreStatusInProgress for when restore is in progress
 	ApplicationRestoreStatusInProgress ApplicationRestoreStatusType = "InProgress"
 	// ApplicationRestoreStatusFailed for when restore has failed
@@ -94,6 +151,10 @@ const (
 	ApplicationRestoreStatusPartialSuccess ApplicationRestoreStatusType = "PartialSuccess"
 	// ApplicationRestoreStatusRetained for when restore was skipped to retain an already existing resource
 	ApplicationRestoreStatusRetained ApplicationRestoreStatusType = "Retained"
+	// ApplicationRestoreStatusSkipped for when a resource was skipped because
+	// it couldn't be restored from the backup, like Secrets that couldn't be
+	// decrypted
+	ApplicationRestoreStatusSkipped ApplicationRestoreStatusType = "Skipped"
 	// ApplicationRestoreStatusSuccessful for when restore has completed successfully
 	ApplicationRestoreStatusSuccessful ApplicationRestoreStatusType = "Successful"
 )
@@ -109,6 +170,9 @@ const (
 	// ApplicationRestoreStageApplications for when applications are being
 	// restored
 	ApplicationRestoreStageApplications ApplicationRestoreStageType = "Applications"
+	// ApplicationRestoreStagePostExecRule for when the PostExecRule is being
+	// executed
+	ApplicationRestoreStagePostExecRule ApplicationRestoreStageType = "PostExecRule"
 	// ApplicationRestoreStageFinal is the final stage for restore
 	ApplicationRestoreStageFinal ApplicationRestoreStageType = "Final"
 )
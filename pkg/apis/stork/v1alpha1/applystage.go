@@ -0,0 +1,73 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApplyStageType is a stage in which resources are applied during a restore
+// or migration. The stages are applied in the order listed below so that
+// resources are created before the resources that depend on them
+type ApplyStageType string
+
+const (
+	// ApplyStageNamespaces for namespaces
+	ApplyStageNamespaces ApplyStageType = "Namespaces"
+	// ApplyStageCRDs for CustomResourceDefinitions
+	ApplyStageCRDs ApplyStageType = "CustomResourceDefinitions"
+	// ApplyStageRBAC for ServiceAccounts, Roles, ClusterRoles and their
+	// bindings
+	ApplyStageRBAC ApplyStageType = "RBAC"
+	// ApplyStageConfig for ConfigMaps, Secrets and other configuration used
+	// by workloads
+	ApplyStageConfig ApplyStageType = "Config"
+	// ApplyStageStorage for PersistentVolumes and PersistentVolumeClaims
+	ApplyStageStorage ApplyStageType = "Storage"
+	// ApplyStageWorkloads for applications and all other resources
+	ApplyStageWorkloads ApplyStageType = "Workloads"
+)
+
+// ReadinessGate is used to wait for the resources applied in a stage to be
+// ready before applying the next stage. Resources are considered ready when
+// PersistentVolumeClaims are Bound, Deployments, StatefulSets and DaemonSets
+// have all their replicas available, and CustomResourceDefinitions are
+// established. Other resources are ready once they are created
+type ReadinessGate struct {
+	Stage ApplyStageType `json:"stage"`
+	// TimeoutSeconds is the time to wait for the resources to be ready,
+	// starting from when the stage is applied. Readiness is checked each
+	// time the object is resynced, so the timeout is only as precise as the
+	// resync period. Defaults to 300 seconds
+	TimeoutSeconds int64 `json:"timeoutSeconds"`
+}
+
+// ApplyStageInfo is the progress of a stage in which resources are applied
+type ApplyStageInfo struct {
+	Stage  ApplyStageType       `json:"stage"`
+	Status ApplyStageStatusType `json:"status"`
+	Reason string               `json:"reason"`
+	// Resources is the number of resources to apply in the stage
+	Resources int `json:"resources"`
+	// AppliedResources is the number of resources that have been applied,
+	// including the ones that failed
+	AppliedResources int         `json:"appliedResources"`
+	StartTimestamp   metav1.Time `json:"startTimestamp"`
+	FinishTimestamp  metav1.Time `json:"finishTimestamp"`
+}
+
+// ApplyStageStatusType is the status of a stage in which resources are
+// applied
+type ApplyStageStatusType string
+
+const (
+	// ApplyStageStatusPending for when the stage hasn't started yet
+	ApplyStageStatusPending ApplyStageStatusType = "Pending"
+	// ApplyStageStatusInProgress for when the resources are being applied
+	ApplyStageStatusInProgress ApplyStageStatusType = "InProgress"
+	// ApplyStageStatusWaiting for when waiting for the resources to be ready
+	ApplyStageStatusWaiting ApplyStageStatusType = "Waiting"
+	// ApplyStageStatusSuccessful for when the stage has completed
+	ApplyStageStatusSuccessful ApplyStageStatusType = "Successful"
+	// ApplyStageStatusFailed for when the resources weren't ready before
+	// the readiness gate timed out
+	ApplyStageStatusFailed ApplyStageStatusType = "Failed"
+)
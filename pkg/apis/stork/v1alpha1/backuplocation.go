@@ -23,16 +23,19 @@ const (
 type BackupLocation struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
-	Location          BackupLocationItem `json:"location"`
+	Location          BackupLocationItem   `json:"location"`
+	Status            BackupLocationStatus `json:"status"`
 }
 
 // BackupLocationItem is the spec used to store a backup location
 // Only one of S3Config, AzureConfig or GoogleConfig should be specified and
 // should match the Type field. Members of the config can be specified inline or
-// through the SecretConfig
+// through the SecretConfig. The filesystem type doesn't require any config.
 type BackupLocationItem struct {
 	Type BackupLocationType `json:"type"`
-	// Path is either the bucket or any other path for the backup location
+	// Path is either the bucket or any other path for the backup location.
+	// For the filesystem type it is the directory in the stork pod where
+	// the backups should be stored
 	Path          string        `json:"path"`
 	EncryptionKey string        `json:"encryptionKey"`
 	S3Config      *S3Config     `json:"s3Config,omitempty"`
@@ -40,8 +43,111 @@ type BackupLocationItem struct {
 	GoogleConfig  *GoogleConfig `json:"googleConfig,omitempty"`
 	SecretConfig  string        `json:"secretConfig"`
 	Sync          bool          `json:"sync"`
+	// SyncNamespaces limits the backups that are synced from the location to
+	// the ones that backed up at least one of these namespaces. All backups
+	// are synced if empty
+	SyncNamespaces []string `json:"syncNamespaces"`
+	// StaleBackupPolicy is the action taken during sync for backups whose
+	// data has been removed from the location
+	StaleBackupPolicy StaleBackupPolicyType `json:"staleBackupPolicy"`
+	// CreateBucket creates the bucket specified in Path when the location is
+	// validated if it doesn't exist
+	CreateBucket bool `json:"createBucket"`
 }
 
+// BackupLocationStatus is the status of the backup location, updated when
+// the location is validated
+type BackupLocationStatus struct {
+	Status               BackupLocationStatusType `json:"status"`
+	Reason               string                   `json:"reason"`
+	LastCheckedTimestamp metav1.Time              `json:"lastCheckedTimestamp"`
+	// SyncStatus is the status of the last sync of backups from the
+	// location, only updated if sync is enabled
+	SyncStatus BackupLocationSyncStatus `json:"syncStatus"`
+	// CredentialStatus is the status of the credentials created for the
+	// location in the volume driver, only updated for drivers that need
+	// credentials to access the location
+	CredentialStatus BackupLocationCredentialStatus `json:"credentialStatus"`
+}
+
+// BackupLocationCredentialStatus is the status of the credentials created for
+// a backup location in a volume driver
+type BackupLocationCredentialStatus struct {
+	Status     BackupLocationStatusType `json:"status"`
+	Reason     string                   `json:"reason"`
+	DriverName string                   `json:"driverName"`
+	// CredentialID is the ID of the credentials in the driver
+	CredentialID string `json:"credentialID"`
+	// ConfigHash is the hash of the config the credentials were created
+	// from, used to update the credentials when the config changes
+	ConfigHash          string      `json:"configHash"`
+	LastUpdateTimestamp metav1.Time `json:"lastUpdateTimestamp"`
+}
+
+// BackupLocationSyncStatus is the status of the last sync of backups from a
+// backup location
+type BackupLocationSyncStatus struct {
+	Status            BackupLocationSyncStatusType `json:"status"`
+	Reason            string                       `json:"reason"`
+	LastSyncTimestamp metav1.Time                  `json:"lastSyncTimestamp"`
+	// TotalBackups is the number of backups found in the location
+	TotalBackups int `json:"totalBackups"`
+	// SyncedBackups is the number of backups imported in the last sync
+	SyncedBackups int `json:"syncedBackups"`
+	// StaleBackups is the number of backups whose data was not found in the
+	// location
+	StaleBackups int `json:"staleBackups"`
+	// Path is the path of the location that was synced
+	Path string `json:"path"`
+	// PathChangedTimestamp is when the path of the location was changed.
+	// Backups that finished before then were stored in the previous path and
+	// aren't checked for missing data
+	PathChangedTimestamp metav1.Time `json:"pathChangedTimestamp"`
+}
+
+// BackupLocationSyncStatusType is the status of a sync from a backup location
+type BackupLocationSyncStatusType string
+
+const (
+	// BackupLocationSyncStatusInitial is the initial state when backups
+	// haven't been synced from the location yet
+	BackupLocationSyncStatusInitial BackupLocationSyncStatusType = ""
+	// BackupLocationSyncStatusSuccessful for when the last sync was
+	// successful
+	BackupLocationSyncStatusSuccessful BackupLocationSyncStatusType = "Successful"
+	// BackupLocationSyncStatusFailed for when the last sync failed
+	BackupLocationSyncStatusFailed BackupLocationSyncStatusType = "Failed"
+)
+
+// StaleBackupPolicyType is the action taken for backups whose data has been
+// removed from the backup location
+type StaleBackupPolicyType string
+
+const (
+	// StaleBackupPolicyMark annotates the ApplicationBackup and raises an
+	// event. This is the default
+	StaleBackupPolicyMark StaleBackupPolicyType = "Mark"
+	// StaleBackupPolicyDelete deletes the ApplicationBackup without deleting
+	// the volume backups. The ApplicationBackup is only marked until its data
+	// hasn't been found for several syncs in a row
+	StaleBackupPolicyDelete StaleBackupPolicyType = "Delete"
+)
+
+// BackupLocationStatusType is the status of the backup location
+type BackupLocationStatusType string
+
+const (
+	// BackupLocationStatusInitial is the initial state when the backup
+	// location hasn't been validated yet
+	BackupLocationStatusInitial BackupLocationStatusType = ""
+	// BackupLocationStatusReady for when the backup location was validated
+	// successfully
+	BackupLocationStatusReady BackupLocationStatusType = "Ready"
+	// BackupLocationStatusError for when the backup location failed
+	// validation
+	BackupLocationStatusError BackupLocationStatusType = "Error"
+)
+
 // BackupLocationType is the type of the backup location
 type BackupLocationType string
 
@@ -52,6 +158,10 @@ const (
 	BackupLocationAzure BackupLocationType = "azure"
 	// BackupLocationGoogle stores the backup in Google Cloud Storage
 	BackupLocationGoogle BackupLocationType = "google"
+	// BackupLocationFilesystem stores the backup in a directory on the
+	// filesystem of the stork pod. This can be a local path or an NFS export
+	// mounted into the pod
+	BackupLocationFilesystem BackupLocationType = "filesystem"
 )
 
 // S3Config speficies the config required to connect to an S3-compliant
@@ -66,8 +176,63 @@ type S3Config struct {
 	// Disable SSL option if using with a non-AWS S3 objectstore which doesn't
 	// have SSL enabled
 	DisableSSL bool `json:"disableSSL"`
+	// CACert is a PEM encoded CA bundle used to verify the certificate of the
+	// objectstore. Required for objectstores using a private PKI
+	CACert string `json:"caCert"`
+	// VirtualHostedStyle uses virtual-hosted-style addressing
+	// (bucket.endpoint) instead of path-style addressing (endpoint/bucket)
+	VirtualHostedStyle bool `json:"virtualHostedStyle"`
+	// ServerSideEncryption is the server-side encryption to be used for
+	// objects uploaded to the objectstore
+	ServerSideEncryption S3ServerSideEncryptionType `json:"serverSideEncryption"`
+	// SSEKMSKeyID is the ID of the KMS key to be used when
+	// ServerSideEncryption is set to aws:kms. The default KMS key will be used
+	// if not provided
+	SSEKMSKeyID string `json:"sseKMSKeyID"`
+	// StorageClass is the storage class to be used for objects uploaded to
+	// the objectstore, for example STANDARD_IA. The default storage class of
+	// the bucket will be used if not provided
+	StorageClass string `json:"storageClass"`
+	// AuthType is the type of authentication to be used. Defaults to static
+	// credentials using AccessKeyID and SecretAccessKey
+	AuthType S3AuthType `json:"authType"`
+	// RoleARN is the ARN of the role to be assumed. Required when AuthType is
+	// set to webIdentity, optional for iam
+	RoleARN string `json:"roleARN"`
+	// WebIdentityTokenFile is the path to the web identity token to be used
+	// when AuthType is set to webIdentity. Defaults to the path in
+	// the AWS_WEB_IDENTITY_TOKEN_FILE environment variable
+	WebIdentityTokenFile string `json:"webIdentityTokenFile"`
 }
 
+// S3ServerSideEncryptionType is the type of server-side encryption to be used
+// for objects in an S3-compliant objectstore
+type S3ServerSideEncryptionType string
+
+const (
+	// S3ServerSideEncryptionNone is used to not request server-side encryption
+	S3ServerSideEncryptionNone S3ServerSideEncryptionType = ""
+	// S3ServerSideEncryptionAES256 is used for SSE-S3
+	S3ServerSideEncryptionAES256 S3ServerSideEncryptionType = "AES256"
+	// S3ServerSideEncryptionKMS is used for SSE-KMS
+	S3ServerSideEncryptionKMS S3ServerSideEncryptionType = "aws:kms"
+)
+
+// S3AuthType is the type of authentication used for an S3-compliant
+// objectstore
+type S3AuthType string
+
+const (
+	// S3AuthStatic uses AccessKeyID and SecretAccessKey for authentication
+	S3AuthStatic S3AuthType = ""
+	// S3AuthIAM uses the default credential chain, which includes the IAM
+	// role of the instance. RoleARN will be assumed if provided
+	S3AuthIAM S3AuthType = "iam"
+	// S3AuthWebIdentity assumes RoleARN using a web identity token, for
+	// example a projected service account token
+	S3AuthWebIdentity S3AuthType = "webIdentity"
+)
+
 // AzureConfig specifies the config required to connect to Azure Blob Storage
 type AzureConfig struct {
 	StorageAccountName string `json:"storageAccountName"`
@@ -111,6 +276,9 @@ func (bl *BackupLocation) UpdateFromSecret(client kubernetes.Interface) error {
 		return bl.getMergedAzureConfig(client)
 	case BackupLocationGoogle:
 		return bl.getMergedGoogleConfig(client)
+	case BackupLocationFilesystem:
+		// Only the path is required, which has already been merged above
+		return nil
 	default:
 		return fmt.Errorf("Invalid BackupLocation type %v", bl.Location.Type)
 	}
@@ -146,6 +314,56 @@ func (bl *BackupLocation) getMergedS3Config(client kubernetes.Interface) error {
 				return fmt.Errorf("error parding disableSSL from Secret: %v", err)
 			}
 		}
+		if val, ok := secretConfig.Data["caCert"]; ok && val != nil {
+			bl.Location.S3Config.CACert = string(val)
+		}
+		if val, ok := secretConfig.Data["virtualHostedStyle"]; ok && val != nil {
+			bl.Location.S3Config.VirtualHostedStyle, err = strconv.ParseBool(strings.TrimSuffix(string(val), "\n"))
+			if err != nil {
+				return fmt.Errorf("error parsing virtualHostedStyle from Secret: %v", err)
+			}
+		}
+		if val, ok := secretConfig.Data["serverSideEncryption"]; ok && val != nil {
+			bl.Location.S3Config.ServerSideEncryption = S3ServerSideEncryptionType(strings.TrimSuffix(string(val), "\n"))
+		}
+		if val, ok := secretConfig.Data["sseKMSKeyID"]; ok && val != nil {
+			bl.Location.S3Config.SSEKMSKeyID = strings.TrimSuffix(string(val), "\n")
+		}
+		if val, ok := secretConfig.Data["storageClass"]; ok && val != nil {
+			bl.Location.S3Config.StorageClass = strings.TrimSuffix(string(val), "\n")
+		}
+		if val, ok := secretConfig.Data["authType"]; ok && val != nil {
+			bl.Location.S3Config.AuthType = S3AuthType(strings.TrimSuffix(string(val), "\n"))
+		}
+		if val, ok := secretConfig.Data["roleARN"]; ok && val != nil {
+			bl.Location.S3Config.RoleARN = strings.TrimSuffix(string(val), "\n")
+		}
+		if val, ok := secretConfig.Data["webIdentityTokenFile"]; ok && val != nil {
+			bl.Location.S3Config.WebIdentityTokenFile = strings.TrimSuffix(string(val), "\n")
+		}
+	}
+	return bl.Location.S3Config.validate()
+}
+
+func (s *S3Config) validate() error {
+	switch s.ServerSideEncryption {
+	case S3ServerSideEncryptionNone, S3ServerSideEncryptionAES256:
+		if s.SSEKMSKeyID != "" {
+			return fmt.Errorf("sseKMSKeyID can only be used with serverSideEncryption set to %v", S3ServerSideEncryptionKMS)
+		}
+	case S3ServerSideEncryptionKMS:
+	default:
+		return fmt.Errorf("invalid serverSideEncryption %v", s.ServerSideEncryption)
+	}
+
+	switch s.AuthType {
+	case S3AuthStatic, S3AuthIAM:
+	case S3AuthWebIdentity:
+		if s.RoleARN == "" {
+			return fmt.Errorf("roleARN is required for authType %v", S3AuthWebIdentity)
+		}
+	default:
+		return fmt.Errorf("invalid authType %v", s.AuthType)
 	}
 	return nil
 }
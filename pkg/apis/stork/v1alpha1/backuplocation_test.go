@@ -0,0 +1,119 @@
+// +build unittest
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestS3ConfigValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   S3Config
+		errorMsg string
+	}{
+		{
+			name:   "defaults",
+			config: S3Config{},
+		},
+		{
+			name:   "sse-s3",
+			config: S3Config{ServerSideEncryption: S3ServerSideEncryptionAES256},
+		},
+		{
+			name:     "kms key without sse-kms",
+			config:   S3Config{ServerSideEncryption: S3ServerSideEncryptionAES256, SSEKMSKeyID: "key"},
+			errorMsg: "sseKMSKeyID can only be used with serverSideEncryption set to aws:kms",
+		},
+		{
+			name:   "sse-kms with key",
+			config: S3Config{ServerSideEncryption: S3ServerSideEncryptionKMS, SSEKMSKeyID: "key"},
+		},
+		{
+			name:     "invalid sse",
+			config:   S3Config{ServerSideEncryption: "invalid"},
+			errorMsg: "invalid serverSideEncryption invalid",
+		},
+		{
+			name:   "iam without role",
+			config: S3Config{AuthType: S3AuthIAM},
+		},
+		{
+			name:     "web identity without role",
+			config:   S3Config{AuthType: S3AuthWebIdentity},
+			errorMsg: "roleARN is required for authType webIdentity",
+		},
+		{
+			name:   "web identity with role",
+			config: S3Config{AuthType: S3AuthWebIdentity, RoleARN: "arn:aws:iam::123456789012:role/stork"},
+		},
+		{
+			name:     "invalid auth type",
+			config:   S3Config{AuthType: "invalid"},
+			errorMsg: "invalid authType invalid",
+		},
+	}
+	for _, test := range tests {
+		err := test.config.validate()
+		if test.errorMsg == "" {
+			require.NoError(t, err, "Unexpected error for %v", test.name)
+		} else {
+			require.EqualError(t, err, test.errorMsg, "Unexpected error for %v", test.name)
+		}
+	}
+}
+
+func TestS3ConfigFromSecret(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "s3secret",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{
+			"caCert":               []byte("-----BEGIN CERTIFICATE-----\n"),
+			"serverSideEncryption": []byte("aws:kms\n"),
+			"sseKMSKeyID":          []byte("key\n"),
+			"storageClass":         []byte("STANDARD_IA\n"),
+			"authType":             []byte("webIdentity\n"),
+			"roleARN":              []byte("arn:aws:iam::123456789012:role/stork\n"),
+			"webIdentityTokenFile": []byte("/var/run/token\n"),
+		},
+	})
+	location := &BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "s3location",
+			Namespace: "test",
+		},
+		Location: BackupLocationItem{
+			Type:         BackupLocationS3,
+			SecretConfig: "s3secret",
+		},
+	}
+	require.NoError(t, location.UpdateFromSecret(client), "Error updating location from secret")
+	s3Config := location.Location.S3Config
+	require.Equal(t, "-----BEGIN CERTIFICATE-----\n", s3Config.CACert, "CA bundle should be used as is")
+	require.Equal(t, S3ServerSideEncryptionKMS, s3Config.ServerSideEncryption)
+	require.Equal(t, "key", s3Config.SSEKMSKeyID)
+	require.Equal(t, "STANDARD_IA", s3Config.StorageClass)
+	require.Equal(t, S3AuthWebIdentity, s3Config.AuthType)
+	require.Equal(t, "arn:aws:iam::123456789012:role/stork", s3Config.RoleARN)
+	require.Equal(t, "/var/run/token", s3Config.WebIdentityTokenFile)
+
+	location.Location.S3Config = nil
+	_, err := client.CoreV1().Secrets("test").Update(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "s3secret",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{
+			"authType": []byte("invalid"),
+		},
+	})
+	require.NoError(t, err, "Error updating secret")
+	require.EqualError(t, location.UpdateFromSecret(client), "invalid authType invalid")
+}
@@ -0,0 +1,105 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BackupVerificationScheduleResourceName is name for "backupverificationschedule" resource
+	BackupVerificationScheduleResourceName = "backupverificationschedule"
+	// BackupVerificationScheduleResourcePlural is plural for "backupverificationschedule" resource
+	BackupVerificationScheduleResourcePlural = "backupverificationschedules"
+)
+
+// BackupVerificationScheduleSpec is the spec used to schedule verification of
+// the backups taken by an ApplicationBackupSchedule. On each trigger the
+// latest successful backup of the schedule is restored into new namespaces,
+// checked and then the namespaces are deleted. Since new namespaces are
+// created, it can only be created in the admin namespace and can only verify
+// backup schedules in the admin namespace
+type BackupVerificationScheduleSpec struct {
+	// BackupScheduleName is the name of the ApplicationBackupSchedule whose
+	// backups should be verified
+	BackupScheduleName string `json:"backupScheduleName"`
+	SchedulePolicyName string `json:"schedulePolicyName"`
+	Suspend            *bool  `json:"suspend"`
+	// Rule to run in the restored namespaces once the pods selected by the
+	// rule are ready. The verification fails if the rule fails
+	Rule string `json:"rule"`
+	// ReadinessGates are used to check that the restored resources are
+	// ready. The verification fails if any of the gates times out. Defaults
+	// to gates for the Storage and Workloads stages
+	ReadinessGates []ReadinessGate `json:"readinessGates"`
+	// TimeoutSeconds is the time to wait for the restore and the checks to
+	// complete before the verification fails. Defaults to 3600 seconds
+	TimeoutSeconds int64 `json:"timeoutSeconds"`
+}
+
+// BackupVerificationScheduleStatus is the status of a backup verification
+// schedule
+type BackupVerificationScheduleStatus struct {
+	Items map[SchedulePolicyType][]*ScheduledBackupVerificationStatus `json:"items"`
+}
+
+// ScheduledBackupVerificationStatus keeps track of a verification that was
+// triggered by a scheduled policy
+type ScheduledBackupVerificationStatus struct {
+	// Name of the ApplicationRestore used to restore the backup
+	Name string `json:"name"`
+	// BackupName is the name of the ApplicationBackup that was verified
+	BackupName string `json:"backupName"`
+	// Namespaces are the namespaces the backup was restored into
+	Namespaces        []string                     `json:"namespaces"`
+	CreationTimestamp meta.Time                    `json:"creationTimestamp"`
+	FinishTimestamp   meta.Time                    `json:"finishTimestamp"`
+	Status            BackupVerificationStatusType `json:"status"`
+	Reason            string                       `json:"reason"`
+	// Duration is the time taken to restore and check the backup
+	Duration meta.Duration `json:"duration"`
+	// Volumes are the PersistentVolumes that were restored. Their reclaim
+	// policy is set to Delete when the namespaces are deleted so that the
+	// restored volumes are deleted too
+	Volumes []string `json:"volumes"`
+	// Cleaned is set once the namespaces the backup was restored into have
+	// been deleted
+	Cleaned bool `json:"cleaned"`
+}
+
+// BackupVerificationStatusType is the status of a backup verification
+type BackupVerificationStatusType string
+
+const (
+	// BackupVerificationStatusPending for when the verification has been
+	// triggered
+	BackupVerificationStatusPending BackupVerificationStatusType = "Pending"
+	// BackupVerificationStatusInProgress for when the backup is being
+	// restored and checked
+	BackupVerificationStatusInProgress BackupVerificationStatusType = "InProgress"
+	// BackupVerificationStatusFailed for when the backup couldn't be
+	// restored or the checks failed
+	BackupVerificationStatusFailed BackupVerificationStatusType = "Failed"
+	// BackupVerificationStatusSuccessful for when the backup was restored
+	// and the checks passed
+	BackupVerificationStatusSuccessful BackupVerificationStatusType = "Successful"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BackupVerificationSchedule represents a scheduled verification of backups
+type BackupVerificationSchedule struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+	Spec            BackupVerificationScheduleSpec   `json:"spec"`
+	Status          BackupVerificationScheduleStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BackupVerificationScheduleList is a list of BackupVerificationSchedules
+type BackupVerificationScheduleList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+
+	Items []BackupVerificationSchedule `json:"items"`
+}
@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/clientcmd/api"
 )
@@ -27,8 +28,28 @@ type ClusterPair struct {
 type ClusterPairSpec struct {
 	Config  api.Config        `json:"config"`
 	Options map[string]string `json:"options"`
+	// CredentialsSecret is the name of a Secret in the namespace of the pair
+	// with the credentials for the remote cluster. The kubeconfig key
+	// replaces Config, the token key replaces the credentials of the current
+	// context and keys with the options. prefix are added to Options. The
+	// Secret is read every time the pair is used, so credentials can be
+	// rotated by updating it
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret"`
 }
 
+const (
+	// ClusterPairSecretKubeconfigKey is the key in the credentials Secret
+	// with the kubeconfig for the remote cluster
+	ClusterPairSecretKubeconfigKey = "kubeconfig"
+	// ClusterPairSecretTokenKey is the key in the credentials Secret with the
+	// bearer token to use for the remote cluster
+	ClusterPairSecretTokenKey = "token"
+	// ClusterPairSecretOptionsPrefix is the prefix of the keys in the
+	// credentials Secret with storage options
+	ClusterPairSecretOptionsPrefix = "options."
+)
+
 // ClusterPairStatusType is the status of the pair
 type ClusterPairStatusType string
 
@@ -61,6 +82,43 @@ type ClusterPairStatus struct {
 	// ID of the remote storage which is paired
 	// +optional
 	RemoteStorageID string `json:"remoteStorageId"`
+	// OptionsHash is the hash of the storage options the pair was last
+	// created with. The pair is created again when the options change
+	// +optional
+	OptionsHash string `json:"optionsHash"`
+	// Conditions from the last time the pair was checked
+	// +optional
+	Conditions []ClusterPairCondition `json:"conditions"`
+	// LastCheckedTimestamp is the time the pair was last checked
+	// +optional
+	LastCheckedTimestamp meta.Time `json:"lastCheckedTimestamp"`
+	// SchedulerLatency is the time the remote scheduler took to respond
+	// the last time the pair was checked
+	// +optional
+	SchedulerLatency meta.Duration `json:"schedulerLatency"`
+}
+
+// ClusterPairConditionType is the type of a condition of the pair
+type ClusterPairConditionType string
+
+const (
+	// ClusterPairConditionSchedulerReachable is set to True if the remote
+	// scheduler could be reached
+	ClusterPairConditionSchedulerReachable ClusterPairConditionType = "SchedulerReachable"
+	// ClusterPairConditionStorageHealthy is set to True if the storage driver
+	// reports that the pair is healthy
+	ClusterPairConditionStorageHealthy ClusterPairConditionType = "StorageHealthy"
+)
+
+// ClusterPairCondition is the state of the pair for one of the checks
+type ClusterPairCondition struct {
+	Type   ClusterPairConditionType `json:"type"`
+	Status corev1.ConditionStatus   `json:"status"`
+	// LastTransitionTime is the last time the status of the condition
+	// changed
+	LastTransitionTime meta.Time `json:"lastTransitionTime"`
+	Reason             string    `json:"reason"`
+	Message            string    `json:"message"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,166 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DRPlanResourceName is name for "drplan" resource
+	DRPlanResourceName = "drplan"
+	// DRPlanResourcePlural is plural for "drplan" resource
+	DRPlanResourcePlural = "drplans"
+)
+
+// DRPlanSpec is the spec of a plan to move applications that have been
+// migrated to this cluster in ordered waves. It is created on the cluster the
+// applications are moved to. Each wave is started once the previous one has
+// completed and its applications are ready
+type DRPlanSpec struct {
+	// ClusterPair on this cluster that points to the cluster the
+	// applications are being moved from
+	ClusterPair string `json:"clusterPair"`
+	// Waves of applications in the order they are moved
+	Waves []DRPlanWave `json:"waves"`
+	// SkipFinalSync skips the final migration from the other cluster for
+	// all the waves
+	SkipFinalSync bool `json:"skipFinalSync"`
+}
+
+// DRPlanWave is a group of applications that are moved together
+type DRPlanWave struct {
+	// Name of the wave, unique in the plan
+	Name string `json:"name"`
+	// MigrationSchedules on the other cluster that have been migrating the
+	// applications of the wave to this cluster. A MigrationFailover is
+	// started for each of them. They are expected to be in the same
+	// namespace as the plan
+	MigrationSchedules []string `json:"migrationSchedules"`
+	// Namespaces on this cluster that belong to the wave. The namespaces of
+	// the migration schedules are added to them. They are used for the
+	// rules and the health check, and are passed to the failovers in case
+	// the other cluster isn't reachable
+	Namespaces []string `json:"namespaces"`
+	// PreExecRule is the name of a Rule in the namespace of the plan that
+	// is run in the namespaces of the wave before it is started
+	PreExecRule string `json:"preExecRule"`
+	// PostExecRule is the name of a Rule in the namespace of the plan that
+	// is run in the namespaces of the wave once its applications are ready
+	PostExecRule string `json:"postExecRule"`
+	// HealthCheckTimeout is how long to wait for the deployments and
+	// statefulsets in the namespaces of the wave to be ready before the
+	// wave is failed. Defaults to 10 minutes
+	HealthCheckTimeout *meta.Duration `json:"healthCheckTimeout,omitempty"`
+	// SkipHealthCheck starts the next wave without waiting for the
+	// applications of this wave to be ready
+	SkipHealthCheck bool `json:"skipHealthCheck"`
+}
+
+// DRPlanActionType is the action a plan is executed for
+type DRPlanActionType string
+
+const (
+	// DRPlanActionFailover to move the applications to this cluster
+	DRPlanActionFailover DRPlanActionType = "Failover"
+	// DRPlanActionFailback to move the applications back to this cluster
+	// after they were failed over from it
+	DRPlanActionFailback DRPlanActionType = "Failback"
+	// DRPlanActionTest to bring up a copy of the applications on this
+	// cluster without deactivating them on the other cluster
+	DRPlanActionTest DRPlanActionType = "Test"
+)
+
+// DRPlanStatus is the status of the last execution of a plan
+type DRPlanStatus struct {
+	Status DRPlanStatusType `json:"status"`
+	// Action the plan was last executed for
+	Action DRPlanActionType `json:"action"`
+	// Waves is the status of each wave. An execution that is retried
+	// continues from the first wave that hasn't completed
+	Waves           []*DRPlanWaveInfo `json:"waves"`
+	StartTimestamp  meta.Time         `json:"startTimestamp"`
+	FinishTimestamp meta.Time         `json:"finishTimestamp"`
+}
+
+// DRPlanWaveInfo is the status of a wave of a plan
+type DRPlanWaveInfo struct {
+	Name   string           `json:"name"`
+	Status DRPlanStatusType `json:"status"`
+	Reason string           `json:"reason"`
+	// Stages is the status of each stage of the wave
+	Stages []*DRPlanWaveStageInfo `json:"stages"`
+	// Namespaces on this cluster that belong to the wave
+	Namespaces []string `json:"namespaces"`
+	// MigrationFailovers started for the wave
+	MigrationFailovers []string  `json:"migrationFailovers"`
+	StartTimestamp     meta.Time `json:"startTimestamp"`
+	FinishTimestamp    meta.Time `json:"finishTimestamp"`
+}
+
+// DRPlanWaveStageInfo is the status of a stage of a wave
+type DRPlanWaveStageInfo struct {
+	Stage           DRPlanWaveStageType `json:"stage"`
+	Status          DRPlanStatusType    `json:"status"`
+	Reason          string              `json:"reason"`
+	StartTimestamp  meta.Time           `json:"startTimestamp"`
+	FinishTimestamp meta.Time           `json:"finishTimestamp"`
+}
+
+// DRPlanWaveStageType is a stage of a wave
+type DRPlanWaveStageType string
+
+const (
+	// DRPlanWaveStagePreExecRule runs the PreExecRule of the wave
+	DRPlanWaveStagePreExecRule DRPlanWaveStageType = "PreExecRule"
+	// DRPlanWaveStageFailover runs the failovers for the migration
+	// schedules of the wave
+	DRPlanWaveStageFailover DRPlanWaveStageType = "Failover"
+	// DRPlanWaveStageHealthCheck waits for the applications of the wave to
+	// be ready
+	DRPlanWaveStageHealthCheck DRPlanWaveStageType = "HealthCheck"
+	// DRPlanWaveStagePostExecRule runs the PostExecRule of the wave
+	DRPlanWaveStagePostExecRule DRPlanWaveStageType = "PostExecRule"
+)
+
+// DRPlanStatusType is the status of a plan, wave or stage
+type DRPlanStatusType string
+
+const (
+	// DRPlanStatusInitial is the initial state when the plan is created or
+	// hasn't been executed
+	DRPlanStatusInitial DRPlanStatusType = ""
+	// DRPlanStatusPending for when a wave or stage hasn't started
+	DRPlanStatusPending DRPlanStatusType = "Pending"
+	// DRPlanStatusInProgress for when the plan, wave or stage is in
+	// progress
+	DRPlanStatusInProgress DRPlanStatusType = "InProgress"
+	// DRPlanStatusSkipped for when a stage was skipped because it isn't
+	// configured for the wave
+	DRPlanStatusSkipped DRPlanStatusType = "Skipped"
+	// DRPlanStatusFailed for when the plan, wave or stage failed
+	DRPlanStatusFailed DRPlanStatusType = "Failed"
+	// DRPlanStatusSuccessful for when the plan, wave or stage completed
+	// successfully
+	DRPlanStatusSuccessful DRPlanStatusType = "Successful"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DRPlan represents a plan to fail over or fail back migrated applications
+// between paired clusters in ordered waves
+type DRPlan struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+	Spec            DRPlanSpec   `json:"spec"`
+	Status          DRPlanStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DRPlanList is a list of DRPlans
+type DRPlanList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+
+	Items []DRPlan `json:"items"`
+}
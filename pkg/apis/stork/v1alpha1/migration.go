@@ -23,6 +23,35 @@ type MigrationSpec struct {
 	Selectors             map[string]string `json:"selectors"`
 	PreExecRule           string            `json:"preExecRule"`
 	PostExecRule          string            `json:"postExecRule"`
+	// ReadinessGates are used to wait for the resources applied in a stage
+	// to be ready before applying the next stage
+	ReadinessGates []ReadinessGate `json:"readinessGates"`
+	// Priority of the operation when it is queued because the limits for
+	// concurrent operations have been reached. Operations with a higher
+	// priority are started first
+	Priority int `json:"priority"`
+	// CheckDrift compares the resources on the source and destination
+	// clusters once the resources have been migrated and saves the
+	// differences in the status
+	CheckDrift *bool `json:"checkDrift"`
+	// NamespaceMapping maps the namespaces being migrated to the namespaces
+	// they should be migrated to on the destination cluster. Namespaces
+	// that aren't in the mapping are migrated to the same namespace
+	NamespaceMapping map[string]string `json:"namespaceMapping"`
+	// SkipUnchangedResources skips applying resources that haven't changed
+	// since they were last migrated to the destination cluster. Applications
+	// that have been activated or scaled on the destination cluster are
+	// always applied again. Disabled by default
+	SkipUnchangedResources *bool `json:"skipUnchangedResources"`
+}
+
+// GetDestinationNamespace returns the namespace on the destination cluster
+// for a namespace being migrated
+func (m *MigrationSpec) GetDestinationNamespace(namespace string) string {
+	if destNamespace, ok := m.NamespaceMapping[namespace]; ok && destNamespace != "" {
+		return destNamespace
+	}
+	return namespace
 }
 
 // MigrationStatus is the status of a migration operation
@@ -32,8 +61,56 @@ type MigrationStatus struct {
 	Resources       []*MigrationResourceInfo `json:"resources"`
 	Volumes         []*MigrationVolumeInfo   `json:"volumes"`
 	FinishTimestamp meta.Time                `json:"finishTimestamp"`
+	// ApplyStages is the progress of each stage in which the resources are
+	// applied
+	ApplyStages []*ApplyStageInfo `json:"applyStages"`
+	// Retries is the number of times the failed volumes and resources of the
+	// migration have been retried
+	Retries int `json:"retries"`
+	// QueuePosition is the position of the operation in the queue when its
+	// status is Queued, or when a retry of the operation is waiting to start
+	QueuePosition int `json:"queuePosition"`
+	// Drift is the result of comparing the resources on the source and
+	// destination clusters, only set if CheckDrift is enabled
+	Drift *MigrationDriftStatus `json:"drift"`
+}
+
+// MigrationDriftStatus is the result of comparing the resources on the source
+// and destination clusters of a migration
+type MigrationDriftStatus struct {
+	CheckTimestamp meta.Time `json:"checkTimestamp"`
+	// Reason is set if the resources couldn't be compared
+	Reason string `json:"reason"`
+	// Resources that are missing, extra or different on the destination
+	Resources []*MigrationDriftInfo `json:"resources"`
+}
+
+// MigrationDriftInfo is the drift of a resource on the destination cluster
+type MigrationDriftInfo struct {
+	Name                  string `json:"name"`
+	Namespace             string `json:"namespace"`
+	meta.GroupVersionKind `json:",inline"`
+	Type                  MigrationDriftType `json:"type"`
+	// Fields that are different on the destination, only set when the type
+	// is Different
+	Fields []string `json:"fields"`
 }
 
+// MigrationDriftType is the type of drift of a resource
+type MigrationDriftType string
+
+const (
+	// MigrationDriftMissing for when a resource on the source is missing on
+	// the destination
+	MigrationDriftMissing MigrationDriftType = "Missing"
+	// MigrationDriftExtra for when a resource on the destination doesn't
+	// exist on the source
+	MigrationDriftExtra MigrationDriftType = "Extra"
+	// MigrationDriftDifferent for when a resource is different on the
+	// source and destination
+	MigrationDriftDifferent MigrationDriftType = "Different"
+)
+
 // MigrationResourceInfo is the info for the migration of a resource
 type MigrationResourceInfo struct {
 	Name                  string `json:"name"`
@@ -45,11 +122,16 @@ type MigrationResourceInfo struct {
 
 // MigrationVolumeInfo is the info for the migration of a volume
 type MigrationVolumeInfo struct {
-	PersistentVolumeClaim string              `json:"persistentVolumeClaim"`
-	Namespace             string              `json:"namespace"`
-	Volume                string              `json:"volume"`
-	Status                MigrationStatusType `json:"status"`
-	Reason                string              `json:"reason"`
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+	Namespace             string `json:"namespace"`
+	// DestinationNamespace is the namespace the volume is migrated to if
+	// it is different from Namespace
+	DestinationNamespace string              `json:"destinationNamespace"`
+	Volume               string              `json:"volume"`
+	Status               MigrationStatusType `json:"status"`
+	Reason               string              `json:"reason"`
+	// Retries is the retry of the migration in which the volume was migrated
+	Retries int `json:"retries"`
 }
 
 // +genclient
@@ -71,6 +153,9 @@ const (
 	MigrationStatusInitial MigrationStatusType = ""
 	// MigrationStatusPending for when migration is still pending
 	MigrationStatusPending MigrationStatusType = "Pending"
+	// MigrationStatusQueued for when migration is waiting for other
+	// operations to complete before it can start
+	MigrationStatusQueued MigrationStatusType = "Queued"
 	// MigrationStatusInProgress for when migration is in progress
 	MigrationStatusInProgress MigrationStatusType = "InProgress"
 	// MigrationStatusFailed for when migration has failed
@@ -81,6 +166,9 @@ const (
 	MigrationStatusSuccessful MigrationStatusType = "Successful"
 	// MigrationStatusPurged for when migration objects has been deleted
 	MigrationStatusPurged MigrationStatusType = "Purged"
+	// MigrationStatusUnchanged for when a resource wasn't applied since it
+	// hasn't changed since it was last migrated
+	MigrationStatusUnchanged MigrationStatusType = "Unchanged"
 )
 
 // MigrationStageType is the stage of the migration
@@ -0,0 +1,162 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MigrationFailoverResourceName is name for "migrationfailover" resource
+	MigrationFailoverResourceName = "migrationfailover"
+	// MigrationFailoverResourcePlural is plural for "migrationfailover" resource
+	MigrationFailoverResourcePlural = "migrationfailovers"
+)
+
+// MigrationFailoverSpec is the spec used to move applications that have been
+// migrated to this cluster by a MigrationSchedule. It is created on the
+// cluster the applications are moved to, so that it can be completed even if
+// the cluster they are moved from isn't reachable
+type MigrationFailoverSpec struct {
+	// Type of the operation, Failover or Failback
+	Type MigrationFailoverType `json:"type"`
+	// ClusterPair on this cluster that points to the cluster the
+	// applications are being moved from. It is used to reach that cluster
+	// and to migrate the applications back to it
+	ClusterPair string `json:"clusterPair"`
+	// MigrationSchedule on the other cluster that has been migrating the
+	// applications to this cluster. It is expected to be in the same
+	// namespace as the failover
+	MigrationSchedule string `json:"migrationSchedule"`
+	// ReverseMigrationSchedule is the MigrationSchedule on this cluster used
+	// to migrate the applications back. It is resumed if it already exists,
+	// otherwise it is created from the template of MigrationSchedule.
+	// Defaults to <migrationSchedule>-reverse for a failover and to the name
+	// of MigrationSchedule without the -reverse suffix for a failback
+	ReverseMigrationSchedule string `json:"reverseMigrationSchedule"`
+	// SchedulePolicyName to use when creating the reverse migration
+	// schedule. Defaults to the policy of MigrationSchedule
+	SchedulePolicyName string `json:"schedulePolicyName"`
+	// Namespaces to activate on this cluster. Only required if the other
+	// cluster isn't reachable, otherwise the namespaces of MigrationSchedule
+	// are used
+	Namespaces []string `json:"namespaces"`
+	// NamespaceMapping from the namespaces on the other cluster to the
+	// namespaces on this cluster. Only required if the other cluster isn't
+	// reachable, otherwise the mapping of MigrationSchedule is used
+	NamespaceMapping map[string]string `json:"namespaceMapping"`
+	// SkipFinalSync skips the final migration from the other cluster even
+	// if it is reachable
+	SkipFinalSync bool `json:"skipFinalSync"`
+	// TestMode activates a copy of the applications on this cluster without
+	// suspending the migration schedule, deactivating the applications on the
+	// other cluster or starting the reverse migration, so that the
+	// applications stay protected while the copy is being tested
+	TestMode bool `json:"testMode"`
+}
+
+// MigrationFailoverType is the type of the failover
+type MigrationFailoverType string
+
+const (
+	// MigrationFailoverTypeFailover to move applications to the cluster they
+	// have been migrated to
+	MigrationFailoverTypeFailover MigrationFailoverType = "Failover"
+	// MigrationFailoverTypeFailback to move applications back to the cluster
+	// they were failed over from
+	MigrationFailoverTypeFailback MigrationFailoverType = "Failback"
+)
+
+// MigrationFailoverStatus is the status of a failover
+type MigrationFailoverStatus struct {
+	Status MigrationFailoverStatusType `json:"status"`
+	// Steps is the status of each step of the failover. A failover that is
+	// interrupted or retried continues from the first step that hasn't
+	// completed
+	Steps []*MigrationFailoverStepInfo `json:"steps"`
+	// Namespaces that are being failed over, as they are named on the
+	// other cluster
+	Namespaces []string `json:"namespaces"`
+	// NamespaceMapping from the namespaces on the other cluster to the
+	// namespaces on this cluster
+	NamespaceMapping map[string]string `json:"namespaceMapping"`
+	// RemoteReachable is set if the other cluster could be reached when the
+	// failover was started
+	RemoteReachable bool      `json:"remoteReachable"`
+	FinishTimestamp meta.Time `json:"finishTimestamp"`
+}
+
+// MigrationFailoverStepInfo is the status of a step of a failover
+type MigrationFailoverStepInfo struct {
+	Step   MigrationFailoverStepType   `json:"step"`
+	Status MigrationFailoverStatusType `json:"status"`
+	Reason string                      `json:"reason"`
+	// Name of the object created for the step, if any
+	Name            string    `json:"name"`
+	StartTimestamp  meta.Time `json:"startTimestamp"`
+	FinishTimestamp meta.Time `json:"finishTimestamp"`
+}
+
+// MigrationFailoverStepType is a step of a failover
+type MigrationFailoverStepType string
+
+const (
+	// MigrationFailoverStepSuspendSchedule suspends the MigrationSchedule on
+	// the other cluster
+	MigrationFailoverStepSuspendSchedule MigrationFailoverStepType = "SuspendSchedule"
+	// MigrationFailoverStepDeactivateSource scales down the applications on
+	// the other cluster
+	MigrationFailoverStepDeactivateSource MigrationFailoverStepType = "DeactivateSource"
+	// MigrationFailoverStepFinalSync runs a final migration from the other
+	// cluster once its applications have been scaled down
+	MigrationFailoverStepFinalSync MigrationFailoverStepType = "FinalSync"
+	// MigrationFailoverStepActivateDestination scales up the applications on
+	// this cluster
+	MigrationFailoverStepActivateDestination MigrationFailoverStepType = "ActivateDestination"
+	// MigrationFailoverStepReverseMigration resumes or creates the schedule
+	// to migrate the applications back to the other cluster
+	MigrationFailoverStepReverseMigration MigrationFailoverStepType = "ReverseMigration"
+)
+
+// MigrationFailoverStatusType is the status of a failover or of one of its
+// steps
+type MigrationFailoverStatusType string
+
+const (
+	// MigrationFailoverStatusInitial is the initial state when the failover
+	// is created
+	MigrationFailoverStatusInitial MigrationFailoverStatusType = ""
+	// MigrationFailoverStatusPending for when a step hasn't started
+	MigrationFailoverStatusPending MigrationFailoverStatusType = "Pending"
+	// MigrationFailoverStatusInProgress for when the failover or step is in
+	// progress
+	MigrationFailoverStatusInProgress MigrationFailoverStatusType = "InProgress"
+	// MigrationFailoverStatusSkipped for when a step was skipped, for example
+	// because the other cluster wasn't reachable
+	MigrationFailoverStatusSkipped MigrationFailoverStatusType = "Skipped"
+	// MigrationFailoverStatusFailed for when the failover or step failed
+	MigrationFailoverStatusFailed MigrationFailoverStatusType = "Failed"
+	// MigrationFailoverStatusSuccessful for when the failover or step
+	// completed successfully
+	MigrationFailoverStatusSuccessful MigrationFailoverStatusType = "Successful"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MigrationFailover represents the failover or failback of migrated
+// applications between paired clusters
+type MigrationFailover struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+	Spec            MigrationFailoverSpec   `json:"spec"`
+	Status          MigrationFailoverStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MigrationFailoverList is a list of MigrationFailovers
+type MigrationFailoverList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+
+	Items []MigrationFailover `json:"items"`
+}
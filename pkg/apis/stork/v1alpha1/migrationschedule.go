@@ -16,6 +16,15 @@ type MigrationScheduleSpec struct {
 	Template           MigrationTemplateSpec `json:"template"`
 	SchedulePolicyName string                `json:"schedulePolicyName"`
 	Suspend            *bool                 `json:"suspend"`
+	// PauseOnUnhealthyClusterPair stops new migrations from being triggered
+	// while the ClusterPair used by the template isn't healthy. Triggering
+	// continues once the pair is healthy again
+	PauseOnUnhealthyClusterPair *bool `json:"pauseOnUnhealthyClusterPair"`
+	// RPOTarget is the maximum time allowed since the last successful
+	// migration was started. An event is raised and the RPOBreached condition
+	// is set when it is exceeded
+	// +optional
+	RPOTarget *meta.Duration `json:"rpoTarget,omitempty"`
 }
 
 // MigrationTemplateSpec describes the data a Migration should have when created
@@ -27,6 +36,22 @@ type MigrationTemplateSpec struct {
 // MigrationScheduleStatus is the status of a migration schedule
 type MigrationScheduleStatus struct {
 	Items map[SchedulePolicyType][]*ScheduledMigrationStatus `json:"items"`
+	// Paused is set while migrations aren't being triggered because the
+	// ClusterPair isn't healthy
+	Paused bool `json:"paused"`
+	// PausedReason is the reason the schedule was paused
+	PausedReason string `json:"pausedReason"`
+	// LastSuccessfulTimestamp is the time the last successful migration was
+	// started. The RPO is measured from this time
+	// +optional
+	LastSuccessfulTimestamp meta.Time `json:"lastSuccessfulTimestamp"`
+	// LastSuccessfulFinishTimestamp is the time the last successful migration
+	// completed
+	// +optional
+	LastSuccessfulFinishTimestamp meta.Time `json:"lastSuccessfulFinishTimestamp"`
+	// Conditions is the list of conditions of the schedule
+	// +optional
+	Conditions []ScheduleCondition `json:"conditions"`
 }
 
 // ScheduledMigrationStatus keeps track of the migration that was triggered by a
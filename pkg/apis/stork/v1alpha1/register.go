@@ -63,6 +63,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ApplicationBackupScheduleList{},
 		&DataExport{},
 		&DataExportList{},
+		&BackupVerificationSchedule{},
+		&BackupVerificationScheduleList{},
+		&MigrationFailover{},
+		&MigrationFailoverList{},
+		&DRPlan{},
+		&DRPlanList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -214,6 +215,26 @@ func (m *MonthlyPolicy) Validate() error {
 	return nil
 }
 
+// ScheduleConditionType is the type of a condition of a schedule
+type ScheduleConditionType string
+
+const (
+	// ScheduleConditionRPOBreached is set to True when the time since the
+	// last successful run of a schedule is more than its RPO target
+	ScheduleConditionRPOBreached ScheduleConditionType = "RPOBreached"
+)
+
+// ScheduleCondition is the state of a schedule for one of the checks
+type ScheduleCondition struct {
+	Type   ScheduleConditionType  `json:"type"`
+	Status corev1.ConditionStatus `json:"status"`
+	// LastTransitionTime is the last time the status of the condition
+	// changed
+	LastTransitionTime meta.Time `json:"lastTransitionTime"`
+	Reason             string    `json:"reason"`
+	Message            string    `json:"message"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // SchedulePolicyList is a list of schedule policies
@@ -22,7 +22,8 @@ package v1alpha1
 
 import (
 	crdv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
-	v1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -87,6 +88,34 @@ func (in *ApplicationBackupList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationBackupReplicaInfo) DeepCopyInto(out *ApplicationBackupReplicaInfo) {
+	*out = *in
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]*ApplicationBackupVolumeInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ApplicationBackupVolumeInfo)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationBackupReplicaInfo.
+func (in *ApplicationBackupReplicaInfo) DeepCopy() *ApplicationBackupReplicaInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationBackupReplicaInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationBackupResourceInfo) DeepCopyInto(out *ApplicationBackupResourceInfo) {
 	*out = *in
@@ -174,6 +203,11 @@ func (in *ApplicationBackupScheduleSpec) DeepCopyInto(out *ApplicationBackupSche
 		*out = new(bool)
 		**out = **in
 	}
+	if in.RPOTarget != nil {
+		in, out := &in.RPOTarget, &out.RPOTarget
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -211,6 +245,15 @@ func (in *ApplicationBackupScheduleStatus) DeepCopyInto(out *ApplicationBackupSc
 			(*out)[key] = outVal
 		}
 	}
+	in.LastSuccessfulTimestamp.DeepCopyInto(&out.LastSuccessfulTimestamp)
+	in.LastSuccessfulFinishTimestamp.DeepCopyInto(&out.LastSuccessfulFinishTimestamp)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ScheduleCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -239,6 +282,16 @@ func (in *ApplicationBackupSpec) DeepCopyInto(out *ApplicationBackupSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.ReplicaBackupLocations != nil {
+		in, out := &in.ReplicaBackupLocations, &out.ReplicaBackupLocations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretsEncryptionKey != nil {
+		in, out := &in.SecretsEncryptionKey, &out.SecretsEncryptionKey
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -279,6 +332,17 @@ func (in *ApplicationBackupStatus) DeepCopyInto(out *ApplicationBackupStatus) {
 	}
 	in.TriggerTimestamp.DeepCopyInto(&out.TriggerTimestamp)
 	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]*ApplicationBackupReplicaInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ApplicationBackupReplicaInfo)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 	return
 }
 
@@ -590,7 +654,17 @@ func (in *ApplicationRestoreSpec) DeepCopyInto(out *ApplicationRestoreSpec) {
 	}
 	if in.EncryptionKey != nil {
 		in, out := &in.EncryptionKey, &out.EncryptionKey
-		*out = new(v1.EnvVarSource)
+		*out = new(corev1.EnvVarSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessGates != nil {
+		in, out := &in.ReadinessGates, &out.ReadinessGates
+		*out = make([]ReadinessGate, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretsEncryptionKey != nil {
+		in, out := &in.SecretsEncryptionKey, &out.SecretsEncryptionKey
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 	return
@@ -632,6 +706,18 @@ func (in *ApplicationRestoreStatus) DeepCopyInto(out *ApplicationRestoreStatus)
 		}
 	}
 	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	if in.ApplyStages != nil {
+		in, out := &in.ApplyStages, &out.ApplyStages
+		*out = make([]*ApplyStageInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ApplyStageInfo)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	in.PostExecRuleStartTimestamp.DeepCopyInto(&out.PostExecRuleStartTimestamp)
 	return
 }
 
@@ -666,6 +752,24 @@ func (in *ApplicationRestoreVolumeInfo) DeepCopy() *ApplicationRestoreVolumeInfo
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplyStageInfo) DeepCopyInto(out *ApplyStageInfo) {
+	*out = *in
+	in.StartTimestamp.DeepCopyInto(&out.StartTimestamp)
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplyStageInfo.
+func (in *ApplyStageInfo) DeepCopy() *ApplyStageInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplyStageInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureConfig) DeepCopyInto(out *AzureConfig) {
 	*out = *in
@@ -688,6 +792,7 @@ func (in *BackupLocation) DeepCopyInto(out *BackupLocation) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Location.DeepCopyInto(&out.Location)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -709,6 +814,23 @@ func (in *BackupLocation) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupLocationCredentialStatus) DeepCopyInto(out *BackupLocationCredentialStatus) {
+	*out = *in
+	in.LastUpdateTimestamp.DeepCopyInto(&out.LastUpdateTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupLocationCredentialStatus.
+func (in *BackupLocationCredentialStatus) DeepCopy() *BackupLocationCredentialStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupLocationCredentialStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupLocationItem) DeepCopyInto(out *BackupLocationItem) {
 	*out = *in
@@ -727,6 +849,11 @@ func (in *BackupLocationItem) DeepCopyInto(out *BackupLocationItem) {
 		*out = new(GoogleConfig)
 		**out = **in
 	}
+	if in.SyncNamespaces != nil {
+		in, out := &in.SyncNamespaces, &out.SyncNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -773,6 +900,167 @@ func (in *BackupLocationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupLocationStatus) DeepCopyInto(out *BackupLocationStatus) {
+	*out = *in
+	in.LastCheckedTimestamp.DeepCopyInto(&out.LastCheckedTimestamp)
+	in.SyncStatus.DeepCopyInto(&out.SyncStatus)
+	in.CredentialStatus.DeepCopyInto(&out.CredentialStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupLocationStatus.
+func (in *BackupLocationStatus) DeepCopy() *BackupLocationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupLocationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupLocationSyncStatus) DeepCopyInto(out *BackupLocationSyncStatus) {
+	*out = *in
+	in.LastSyncTimestamp.DeepCopyInto(&out.LastSyncTimestamp)
+	in.PathChangedTimestamp.DeepCopyInto(&out.PathChangedTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupLocationSyncStatus.
+func (in *BackupLocationSyncStatus) DeepCopy() *BackupLocationSyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupLocationSyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupVerificationSchedule) DeepCopyInto(out *BackupVerificationSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupVerificationSchedule.
+func (in *BackupVerificationSchedule) DeepCopy() *BackupVerificationSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupVerificationSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackupVerificationSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupVerificationScheduleList) DeepCopyInto(out *BackupVerificationScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BackupVerificationSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupVerificationScheduleList.
+func (in *BackupVerificationScheduleList) DeepCopy() *BackupVerificationScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupVerificationScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackupVerificationScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupVerificationScheduleSpec) DeepCopyInto(out *BackupVerificationScheduleSpec) {
+	*out = *in
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReadinessGates != nil {
+		in, out := &in.ReadinessGates, &out.ReadinessGates
+		*out = make([]ReadinessGate, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupVerificationScheduleSpec.
+func (in *BackupVerificationScheduleSpec) DeepCopy() *BackupVerificationScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupVerificationScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupVerificationScheduleStatus) DeepCopyInto(out *BackupVerificationScheduleStatus) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make(map[SchedulePolicyType][]*ScheduledBackupVerificationStatus, len(*in))
+		for key, val := range *in {
+			var outVal []*ScheduledBackupVerificationStatus
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]*ScheduledBackupVerificationStatus, len(*in))
+				for i := range *in {
+					if (*in)[i] != nil {
+						in, out := &(*in)[i], &(*out)[i]
+						*out = new(ScheduledBackupVerificationStatus)
+						(*in).DeepCopyInto(*out)
+					}
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupVerificationScheduleStatus.
+func (in *BackupVerificationScheduleStatus) DeepCopy() *BackupVerificationScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupVerificationScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterDomainInfo) DeepCopyInto(out *ClusterDomainInfo) {
 	*out = *in
@@ -969,7 +1257,7 @@ func (in *ClusterPair) DeepCopyInto(out *ClusterPair) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -991,6 +1279,23 @@ func (in *ClusterPair) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPairCondition) DeepCopyInto(out *ClusterPairCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPairCondition.
+func (in *ClusterPairCondition) DeepCopy() *ClusterPairCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPairCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterPairList) DeepCopyInto(out *ClusterPairList) {
 	*out = *in
@@ -1051,6 +1356,15 @@ func (in *ClusterPairSpec) DeepCopy() *ClusterPairSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterPairStatus) DeepCopyInto(out *ClusterPairStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ClusterPairCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastCheckedTimestamp.DeepCopyInto(&out.LastCheckedTimestamp)
+	out.SchedulerLatency = in.SchedulerLatency
 	return
 }
 
@@ -1065,43 +1379,244 @@ func (in *ClusterPairStatus) DeepCopy() *ClusterPairStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DailyPolicy) DeepCopyInto(out *DailyPolicy) {
+func (in *DRPlan) DeepCopyInto(out *DRPlan) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DailyPolicy.
-func (in *DailyPolicy) DeepCopy() *DailyPolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DRPlan.
+func (in *DRPlan) DeepCopy() *DRPlan {
 	if in == nil {
 		return nil
 	}
-	out := new(DailyPolicy)
+	out := new(DRPlan)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DRPlan) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataExport) DeepCopyInto(out *DataExport) {
+func (in *DRPlanList) DeepCopyInto(out *DRPlanList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DRPlan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataExport.
-func (in *DataExport) DeepCopy() *DataExport {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DRPlanList.
+func (in *DRPlanList) DeepCopy() *DRPlanList {
 	if in == nil {
 		return nil
 	}
-	out := new(DataExport)
+	out := new(DRPlanList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataExport) DeepCopyObject() runtime.Object {
+func (in *DRPlanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DRPlanSpec) DeepCopyInto(out *DRPlanSpec) {
+	*out = *in
+	if in.Waves != nil {
+		in, out := &in.Waves, &out.Waves
+		*out = make([]DRPlanWave, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DRPlanSpec.
+func (in *DRPlanSpec) DeepCopy() *DRPlanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DRPlanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DRPlanStatus) DeepCopyInto(out *DRPlanStatus) {
+	*out = *in
+	if in.Waves != nil {
+		in, out := &in.Waves, &out.Waves
+		*out = make([]*DRPlanWaveInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(DRPlanWaveInfo)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	in.StartTimestamp.DeepCopyInto(&out.StartTimestamp)
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DRPlanStatus.
+func (in *DRPlanStatus) DeepCopy() *DRPlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DRPlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DRPlanWave) DeepCopyInto(out *DRPlanWave) {
+	*out = *in
+	if in.MigrationSchedules != nil {
+		in, out := &in.MigrationSchedules, &out.MigrationSchedules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HealthCheckTimeout != nil {
+		in, out := &in.HealthCheckTimeout, &out.HealthCheckTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DRPlanWave.
+func (in *DRPlanWave) DeepCopy() *DRPlanWave {
+	if in == nil {
+		return nil
+	}
+	out := new(DRPlanWave)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DRPlanWaveInfo) DeepCopyInto(out *DRPlanWaveInfo) {
+	*out = *in
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]*DRPlanWaveStageInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(DRPlanWaveStageInfo)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MigrationFailovers != nil {
+		in, out := &in.MigrationFailovers, &out.MigrationFailovers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTimestamp.DeepCopyInto(&out.StartTimestamp)
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DRPlanWaveInfo.
+func (in *DRPlanWaveInfo) DeepCopy() *DRPlanWaveInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(DRPlanWaveInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DRPlanWaveStageInfo) DeepCopyInto(out *DRPlanWaveStageInfo) {
+	*out = *in
+	in.StartTimestamp.DeepCopyInto(&out.StartTimestamp)
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DRPlanWaveStageInfo.
+func (in *DRPlanWaveStageInfo) DeepCopy() *DRPlanWaveStageInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(DRPlanWaveStageInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DailyPolicy) DeepCopyInto(out *DailyPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DailyPolicy.
+func (in *DailyPolicy) DeepCopy() *DailyPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DailyPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataExport) DeepCopyInto(out *DataExport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataExport.
+func (in *DataExport) DeepCopy() *DataExport {
+	if in == nil {
+		return nil
+	}
+	out := new(DataExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataExport) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1113,7 +1628,7 @@ func (in *DataExportDestination) DeepCopyInto(out *DataExportDestination) {
 	*out = *in
 	if in.PersistentVolumeClaim != nil {
 		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
-		*out = new(v1.PersistentVolumeClaim)
+		*out = new(corev1.PersistentVolumeClaim)
 		(*in).DeepCopyInto(*out)
 	}
 	return
@@ -1167,7 +1682,7 @@ func (in *DataExportSource) DeepCopyInto(out *DataExportSource) {
 	*out = *in
 	if in.PersistentVolumeClaim != nil {
 		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
-		*out = new(v1.PersistentVolumeClaim)
+		*out = new(corev1.PersistentVolumeClaim)
 		(*in).DeepCopyInto(*out)
 	}
 	return
@@ -1394,6 +1909,203 @@ func (in *Migration) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationDriftInfo) DeepCopyInto(out *MigrationDriftInfo) {
+	*out = *in
+	out.GroupVersionKind = in.GroupVersionKind
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationDriftInfo.
+func (in *MigrationDriftInfo) DeepCopy() *MigrationDriftInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationDriftInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationDriftStatus) DeepCopyInto(out *MigrationDriftStatus) {
+	*out = *in
+	in.CheckTimestamp.DeepCopyInto(&out.CheckTimestamp)
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]*MigrationDriftInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(MigrationDriftInfo)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationDriftStatus.
+func (in *MigrationDriftStatus) DeepCopy() *MigrationDriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationDriftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationFailover) DeepCopyInto(out *MigrationFailover) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationFailover.
+func (in *MigrationFailover) DeepCopy() *MigrationFailover {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationFailover)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationFailover) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationFailoverList) DeepCopyInto(out *MigrationFailoverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MigrationFailover, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationFailoverList.
+func (in *MigrationFailoverList) DeepCopy() *MigrationFailoverList {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationFailoverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationFailoverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationFailoverSpec) DeepCopyInto(out *MigrationFailoverSpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceMapping != nil {
+		in, out := &in.NamespaceMapping, &out.NamespaceMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationFailoverSpec.
+func (in *MigrationFailoverSpec) DeepCopy() *MigrationFailoverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationFailoverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationFailoverStatus) DeepCopyInto(out *MigrationFailoverStatus) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]*MigrationFailoverStepInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(MigrationFailoverStepInfo)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceMapping != nil {
+		in, out := &in.NamespaceMapping, &out.NamespaceMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationFailoverStatus.
+func (in *MigrationFailoverStatus) DeepCopy() *MigrationFailoverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationFailoverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationFailoverStepInfo) DeepCopyInto(out *MigrationFailoverStepInfo) {
+	*out = *in
+	in.StartTimestamp.DeepCopyInto(&out.StartTimestamp)
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationFailoverStepInfo.
+func (in *MigrationFailoverStepInfo) DeepCopy() *MigrationFailoverStepInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationFailoverStepInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MigrationList) DeepCopyInto(out *MigrationList) {
 	*out = *in
@@ -1514,6 +2226,16 @@ func (in *MigrationScheduleSpec) DeepCopyInto(out *MigrationScheduleSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PauseOnUnhealthyClusterPair != nil {
+		in, out := &in.PauseOnUnhealthyClusterPair, &out.PauseOnUnhealthyClusterPair
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RPOTarget != nil {
+		in, out := &in.RPOTarget, &out.RPOTarget
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -1551,6 +2273,15 @@ func (in *MigrationScheduleStatus) DeepCopyInto(out *MigrationScheduleStatus) {
 			(*out)[key] = outVal
 		}
 	}
+	in.LastSuccessfulTimestamp.DeepCopyInto(&out.LastSuccessfulTimestamp)
+	in.LastSuccessfulFinishTimestamp.DeepCopyInto(&out.LastSuccessfulFinishTimestamp)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ScheduleCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1599,6 +2330,28 @@ func (in *MigrationSpec) DeepCopyInto(out *MigrationSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.ReadinessGates != nil {
+		in, out := &in.ReadinessGates, &out.ReadinessGates
+		*out = make([]ReadinessGate, len(*in))
+		copy(*out, *in)
+	}
+	if in.CheckDrift != nil {
+		in, out := &in.CheckDrift, &out.CheckDrift
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NamespaceMapping != nil {
+		in, out := &in.NamespaceMapping, &out.NamespaceMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SkipUnchangedResources != nil {
+		in, out := &in.SkipUnchangedResources, &out.SkipUnchangedResources
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -1638,6 +2391,22 @@ func (in *MigrationStatus) DeepCopyInto(out *MigrationStatus) {
 		}
 	}
 	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	if in.ApplyStages != nil {
+		in, out := &in.ApplyStages, &out.ApplyStages
+		*out = make([]*ApplyStageInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ApplyStageInfo)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(MigrationDriftStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1717,6 +2486,22 @@ func (in *PVCSelectorSpec) DeepCopy() *PVCSelectorSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessGate) DeepCopyInto(out *ReadinessGate) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessGate.
+func (in *ReadinessGate) DeepCopy() *ReadinessGate {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreVolumeInfo) DeepCopyInto(out *RestoreVolumeInfo) {
 	*out = *in
@@ -1859,6 +2644,23 @@ func (in *S3Config) DeepCopy() *S3Config {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleCondition) DeepCopyInto(out *ScheduleCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleCondition.
+func (in *ScheduleCondition) DeepCopy() *ScheduleCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SchedulePolicy) DeepCopyInto(out *SchedulePolicy) {
 	*out = *in
@@ -1973,6 +2775,35 @@ func (in *ScheduledApplicationBackupStatus) DeepCopy() *ScheduledApplicationBack
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledBackupVerificationStatus) DeepCopyInto(out *ScheduledBackupVerificationStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.CreationTimestamp.DeepCopyInto(&out.CreationTimestamp)
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	out.Duration = in.Duration
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledBackupVerificationStatus.
+func (in *ScheduledBackupVerificationStatus) DeepCopy() *ScheduledBackupVerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledBackupVerificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduledMigrationStatus) DeepCopyInto(out *ScheduledMigrationStatus) {
 	*out = *in
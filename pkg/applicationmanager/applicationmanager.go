@@ -65,6 +65,22 @@ func (a *ApplicationManager) Init(adminNamespace string, stopChannel chan os.Sig
 		return err
 	}
 
+	verificationScheduleController := &controllers.BackupVerificationScheduleController{
+		Recorder: a.Recorder,
+	}
+	if err := verificationScheduleController.Init(adminNamespace); err != nil {
+		return err
+	}
+
+	locationController := &controllers.BackupLocationController{
+		Driver:             a.Driver,
+		Recorder:           a.Recorder,
+		ValidationInterval: 5 * time.Minute,
+	}
+	if err := locationController.Init(); err != nil {
+		return err
+	}
+
 	syncController := &controllers.BackupSyncController{
 		Recorder:     a.Recorder,
 		SyncInterval: 1 * time.Minute,
@@ -16,6 +16,7 @@ import (
 	"github.com/libopenstorage/stork/pkg/crypto"
 	"github.com/libopenstorage/stork/pkg/log"
 	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/libopenstorage/stork/pkg/queue"
 	"github.com/libopenstorage/stork/pkg/resourcecollector"
 	"github.com/libopenstorage/stork/pkg/rule"
 	"github.com/operator-framework/operator-sdk/pkg/sdk"
@@ -23,6 +24,7 @@ import (
 	"github.com/portworx/sched-ops/k8s/core"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/sirupsen/logrus"
+	"gocloud.dev/blob"
 	"gocloud.dev/gcerrors"
 	v1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
@@ -41,7 +43,9 @@ const (
 	validateCRDTimeout  time.Duration = 1 * time.Minute
 
 	resourceObjectName = "resources.json"
-	metadataObjectName = "metadata.json"
+	// secretsObjectName is the object in which the Secrets are stored, encrypted
+	// with the SecretsEncryptionKey, when SecretsMode is set to Encrypt
+	secretsObjectName = "secrets.json"
 
 	backupCancelBackoffInitialDelay = 5 * time.Second
 	backupCancelBackoffFactor       = 1
@@ -73,6 +77,9 @@ func (a *ApplicationBackupController) Init(backupAdminNamespace string) error {
 		logrus.Errorf("Failed to perform recovery for backup rules: %v", err)
 		return err
 	}
+	if err := a.trackStartedBackups(); err != nil {
+		return err
+	}
 
 	return controller.Register(
 		&schema.GroupVersionKind{
@@ -90,6 +97,29 @@ func setKind(snap *stork_api.ApplicationBackup) {
 	snap.APIVersion = stork_api.SchemeGroupVersion.String()
 }
 
+// trackStartedBackups adds the backups that were already started to the queue
+// so that they are accounted for before any queued operations can start
+func (a *ApplicationBackupController) trackStartedBackups() error {
+	applicationBackups, err := storkops.Instance().ListApplicationBackups(v1.NamespaceAll)
+	if err != nil {
+		return fmt.Errorf("error listing application backups: %v", err)
+	}
+	for _, backup := range applicationBackups.Items {
+		if backup.Status.Stage == stork_api.ApplicationBackupStageInitial ||
+			backup.Status.Stage == stork_api.ApplicationBackupStageFinal {
+			continue
+		}
+		a.setDefaults(&backup)
+		op, err := a.getQueueOperation(&backup)
+		if err != nil {
+			log.ApplicationBackupLog(&backup).Warnf("Error getting volumes to add backup to queue: %v", err)
+			continue
+		}
+		queue.Instance().Track(op)
+	}
+	return nil
+}
+
 // performRuleRecovery terminates potential background commands running pods for
 // all applicationBackup objects
 func (a *ApplicationBackupController) performRuleRecovery() error {
@@ -129,6 +159,7 @@ func (a *ApplicationBackupController) Handle(ctx context.Context, event sdk.Even
 	case *stork_api.ApplicationBackup:
 		backup := o
 		if event.Deleted {
+			queue.Instance().Release(backup.UID)
 			return a.deleteBackup(backup)
 		}
 
@@ -137,7 +168,7 @@ func (a *ApplicationBackupController) Handle(ctx context.Context, event sdk.Even
 		// except for the namespace designated by the admin
 		if !a.namespaceBackupAllowed(backup) {
 			err := fmt.Errorf("Spec.Namespaces should only contain the current namespace")
-			log.ApplicationBackupLog(backup).Errorf(err.Error())
+			log.ApplicationBackupLog(backup).Error(err.Error())
 			a.Recorder.Event(backup,
 				v1.EventTypeWarning,
 				string(stork_api.ApplicationBackupStatusFailed),
@@ -149,6 +180,14 @@ func (a *ApplicationBackupController) Handle(ctx context.Context, event sdk.Even
 		var err error
 
 		a.setDefaults(backup)
+		if _, ok := backup.Annotations[stork.RetryAnnotation]; ok {
+			return a.retryBackup(backup)
+		}
+
+		if backup.Status.Stage == stork_api.ApplicationBackupStageFinal {
+			queue.Instance().Release(backup.UID)
+		}
+
 		switch backup.Status.Stage {
 		case stork_api.ApplicationBackupStageInitial:
 			// Make sure the namespaces exist
@@ -159,7 +198,7 @@ func (a *ApplicationBackupController) Handle(ctx context.Context, event sdk.Even
 					backup.Status.Stage = stork_api.ApplicationBackupStageFinal
 					backup.Status.FinishTimestamp = metav1.Now()
 					err = fmt.Errorf("error getting namespace %v: %v", ns, err)
-					log.ApplicationBackupLog(backup).Errorf(err.Error())
+					log.ApplicationBackupLog(backup).Error(err.Error())
 					a.Recorder.Event(backup,
 						v1.EventTypeWarning,
 						string(stork_api.ApplicationBackupStatusFailed),
@@ -176,7 +215,7 @@ func (a *ApplicationBackupController) Handle(ctx context.Context, event sdk.Even
 				_, err := storkops.Instance().GetRule(backup.Spec.PreExecRule, backup.Namespace)
 				if err != nil {
 					message := fmt.Sprintf("Error getting PreExecRule %v: %v", backup.Spec.PreExecRule, err)
-					log.ApplicationBackupLog(backup).Errorf(message)
+					log.ApplicationBackupLog(backup).Error(message)
 					a.Recorder.Event(backup,
 						v1.EventTypeWarning,
 						string(stork_api.ApplicationBackupStatusFailed),
@@ -188,7 +227,7 @@ func (a *ApplicationBackupController) Handle(ctx context.Context, event sdk.Even
 				_, err := storkops.Instance().GetRule(backup.Spec.PostExecRule, backup.Namespace)
 				if err != nil {
 					message := fmt.Sprintf("Error getting PostExecRule %v: %v", backup.Spec.PreExecRule, err)
-					log.ApplicationBackupLog(backup).Errorf(message)
+					log.ApplicationBackupLog(backup).Error(message)
 					a.Recorder.Event(backup,
 						v1.EventTypeWarning,
 						string(stork_api.ApplicationBackupStatusFailed),
@@ -196,12 +235,45 @@ func (a *ApplicationBackupController) Handle(ctx context.Context, event sdk.Even
 					return nil
 				}
 			}
+			if err := validateSecretsMode(backup); err != nil {
+				message := fmt.Sprintf("Error validating SecretsMode: %v", err)
+				log.ApplicationBackupLog(backup).Error(message)
+				a.Recorder.Event(backup,
+					v1.EventTypeWarning,
+					string(stork_api.ApplicationBackupStatusFailed),
+					message)
+				return nil
+			}
+			if err := validateReplicaBackupLocations(backup); err != nil {
+				message := fmt.Sprintf("Error validating ReplicaBackupLocations: %v", err)
+				log.ApplicationBackupLog(backup).Error(message)
+				a.Recorder.Event(backup,
+					v1.EventTypeWarning,
+					string(stork_api.ApplicationBackupStatusFailed),
+					message)
+				return nil
+			}
+			// Wait for other operations to complete if the limits for
+			// concurrent operations have been reached
+			queued, err := a.queueBackup(backup)
+			if err != nil {
+				message := fmt.Sprintf("Error queueing backup: %v", err)
+				log.ApplicationBackupLog(backup).Error(message)
+				a.Recorder.Event(backup,
+					v1.EventTypeWarning,
+					string(stork_api.ApplicationBackupStatusFailed),
+					message)
+				return nil
+			}
+			if queued {
+				return nil
+			}
 			fallthrough
 		case stork_api.ApplicationBackupStagePreExecRule:
 			terminationChannels, err = a.runPreExecRule(backup)
 			if err != nil {
 				message := fmt.Sprintf("Error running PreExecRule: %v", err)
-				log.ApplicationBackupLog(backup).Errorf(message)
+				log.ApplicationBackupLog(backup).Error(message)
 				a.Recorder.Event(backup,
 					v1.EventTypeWarning,
 					string(stork_api.ApplicationBackupStatusFailed),
@@ -219,7 +291,7 @@ func (a *ApplicationBackupController) Handle(ctx context.Context, event sdk.Even
 			err := a.backupVolumes(backup, terminationChannels)
 			if err != nil {
 				message := fmt.Sprintf("Error backing up volumes: %v", err)
-				log.ApplicationBackupLog(backup).Errorf(message)
+				log.ApplicationBackupLog(backup).Error(message)
 				a.Recorder.Event(backup,
 					v1.EventTypeWarning,
 					string(stork_api.ApplicationBackupStatusFailed),
@@ -230,7 +302,7 @@ func (a *ApplicationBackupController) Handle(ctx context.Context, event sdk.Even
 			err := a.backupResources(backup)
 			if err != nil {
 				message := fmt.Sprintf("Error backing up resources: %v", err)
-				log.ApplicationBackupLog(backup).Errorf(message)
+				log.ApplicationBackupLog(backup).Error(message)
 				a.Recorder.Event(backup,
 					v1.EventTypeWarning,
 					string(stork_api.ApplicationBackupStatusFailed),
@@ -239,7 +311,10 @@ func (a *ApplicationBackupController) Handle(ctx context.Context, event sdk.Even
 			}
 
 		case stork_api.ApplicationBackupStageFinal:
-			// Do Nothing
+			// Copy successful backups to the replica locations if any
+			if backup.Status.Status == stork_api.ApplicationBackupStatusSuccessful {
+				return a.copyToReplicas(backup)
+			}
 			return nil
 		default:
 			log.ApplicationBackupLog(backup).Errorf("Invalid stage for backup: %v", backup.Status.Stage)
@@ -312,7 +387,7 @@ func (a *ApplicationBackupController) backupVolumes(backup *stork_api.Applicatio
 				// as Cancelling, cancel any other started backups and then mark
 				// it as failed
 				message := fmt.Sprintf("Error starting ApplicationBackup for volumes: %v", err)
-				log.ApplicationBackupLog(backup).Errorf(message)
+				log.ApplicationBackupLog(backup).Error(message)
 				a.Recorder.Event(backup,
 					v1.EventTypeWarning,
 					string(stork_api.ApplicationBackupStatusFailed),
@@ -344,7 +419,7 @@ func (a *ApplicationBackupController) backupVolumes(backup *stork_api.Applicatio
 			err = a.runPostExecRule(backup)
 			if err != nil {
 				message := fmt.Sprintf("Error running PostExecRule: %v", err)
-				log.ApplicationBackupLog(backup).Errorf(message)
+				log.ApplicationBackupLog(backup).Error(message)
 				a.Recorder.Event(backup,
 					v1.EventTypeWarning,
 					string(stork_api.ApplicationBackupStatusFailed),
@@ -431,7 +506,7 @@ func (a *ApplicationBackupController) backupVolumes(backup *stork_api.Applicatio
 		err = a.backupResources(backup)
 		if err != nil {
 			message := fmt.Sprintf("Error backing up resources: %v", err)
-			log.ApplicationBackupLog(backup).Errorf(message)
+			log.ApplicationBackupLog(backup).Error(message)
 			a.Recorder.Event(backup,
 				v1.EventTypeWarning,
 				string(stork_api.ApplicationBackupStatusFailed),
@@ -447,6 +522,190 @@ func (a *ApplicationBackupController) backupVolumes(backup *stork_api.Applicatio
 	return nil
 }
 
+func (a *ApplicationBackupController) newQueueOperation(backup *stork_api.ApplicationBackup) *queue.Operation {
+	return &queue.Operation{
+		Kind:              "ApplicationBackup",
+		Namespace:         backup.Namespace,
+		Name:              backup.Name,
+		UID:               backup.UID,
+		Priority:          backup.Spec.Priority,
+		CreationTimestamp: backup.CreationTimestamp.Time,
+		Volumes:           make(map[string]int),
+	}
+}
+
+// getQueueOperation returns the operation used to limit the number of
+// concurrent backups. The volumes are counted from the PVCs to be backed up
+// if the backup hasn't started yet
+func (a *ApplicationBackupController) getQueueOperation(backup *stork_api.ApplicationBackup) (*queue.Operation, error) {
+	op := a.newQueueOperation(backup)
+	if backup.Status.Volumes == nil {
+		volumes, err := queue.GetPVCVolumes(backup.Spec.Namespaces, backup.Spec.Selectors)
+		if err != nil {
+			return nil, err
+		}
+		op.Volumes = volumes
+		return op, nil
+	}
+	for _, vInfo := range backup.Status.Volumes {
+		op.Volumes[vInfo.DriverName]++
+	}
+	return op, nil
+}
+
+// queueBackup checks whether the backup can start. Returns true if the backup
+// has to wait for other operations to complete, in which case its status is
+// set to Queued along with its position in the queue
+func (a *ApplicationBackupController) queueBackup(backup *stork_api.ApplicationBackup) (bool, error) {
+	op, err := a.getQueueOperation(backup)
+	if err != nil {
+		return false, err
+	}
+	if ok, position := queue.Instance().Acquire(op); !ok {
+		if backup.Status.Status == stork_api.ApplicationBackupStatusQueued &&
+			backup.Status.QueuePosition == position {
+			return true, nil
+		}
+		if backup.Status.Status != stork_api.ApplicationBackupStatusQueued {
+			a.Recorder.Event(backup,
+				v1.EventTypeNormal,
+				string(stork_api.ApplicationBackupStatusQueued),
+				fmt.Sprintf("Backup queued at position %v since the limit for concurrent operations has been reached", position))
+		}
+		backup.Status.Status = stork_api.ApplicationBackupStatusQueued
+		backup.Status.QueuePosition = position
+		return true, sdk.Update(backup)
+	}
+	if backup.Status.Status == stork_api.ApplicationBackupStatusQueued {
+		backup.Status.Status = stork_api.ApplicationBackupStatusInitial
+		backup.Status.QueuePosition = 0
+	}
+	return false, nil
+}
+
+// retryBackup starts the backup again for the volumes that failed. Volumes
+// that were backed up successfully are kept, and the resources are backed up
+// again once all the volumes are done
+func (a *ApplicationBackupController) retryBackup(backup *stork_api.ApplicationBackup) error {
+	if backup.Status.Status != stork_api.ApplicationBackupStatusFailed &&
+		backup.Status.Status != stork_api.ApplicationBackupStatusPartialSuccess {
+		delete(backup.Annotations, stork.RetryAnnotation)
+		message := fmt.Sprintf("Ignoring retry for backup with status %v", backup.Status.Status)
+		log.ApplicationBackupLog(backup).Warn(message)
+		a.Recorder.Event(backup,
+			v1.EventTypeWarning,
+			string(backup.Status.Status),
+			message)
+		return sdk.Update(backup)
+	}
+	// Wait for other operations to complete if the limits for concurrent
+	// operations have been reached
+	if queued, err := a.queueRetry(backup); err != nil || queued {
+		return err
+	}
+
+	delete(backup.Annotations, stork.RetryAnnotation)
+	// The backup is added to the index again with its new finish time once
+	// it completes
+	a.removeFromBackupIndex(backup, backup.Spec.BackupLocation, backup.Status.BackupPath, backup.Status.FinishTimestamp)
+	backup.Status.Retries++
+	failedVolumes := make(map[string][]*stork_api.ApplicationBackupVolumeInfo)
+	volumeInfos := make([]*stork_api.ApplicationBackupVolumeInfo, 0)
+	for _, vInfo := range backup.Status.Volumes {
+		if vInfo.Status == stork_api.ApplicationBackupStatusFailed {
+			failedVolumes[vInfo.DriverName] = append(failedVolumes[vInfo.DriverName], vInfo)
+		} else {
+			volumeInfos = append(volumeInfos, vInfo)
+		}
+	}
+
+	retried := 0
+	for driverName, failed := range failedVolumes {
+		infos, err := a.retryVolumeBackups(backup, driverName, failed)
+		if err != nil {
+			message := fmt.Sprintf("Error retrying backup for volumes: %v", err)
+			log.ApplicationBackupLog(backup).Error(message)
+			a.Recorder.Event(backup,
+				v1.EventTypeWarning,
+				string(stork_api.ApplicationBackupStatusFailed),
+				message)
+			// Keep the failed volumes so that they can be retried again
+			volumeInfos = append(volumeInfos, failed...)
+			continue
+		}
+		retried += len(infos)
+		volumeInfos = append(volumeInfos, infos...)
+	}
+
+	backup.Status.Volumes = volumeInfos
+	backup.Status.Stage = stork_api.ApplicationBackupStageVolumes
+	backup.Status.Status = stork_api.ApplicationBackupStatusInProgress
+	backup.Status.FinishTimestamp = metav1.Time{}
+	message := fmt.Sprintf("Retrying backup of %v failed volumes", retried)
+	log.ApplicationBackupLog(backup).Info(message)
+	a.Recorder.Event(backup,
+		v1.EventTypeNormal,
+		string(stork_api.ApplicationBackupStatusInProgress),
+		message)
+	return sdk.Update(backup)
+}
+
+// queueRetry checks whether the failed volumes of the backup can be backed up
+// again. Returns true if the retry has to wait for other operations to
+// complete, in which case its position in the queue is saved and the retry
+// annotation is kept so that it is checked again on the next update
+func (a *ApplicationBackupController) queueRetry(backup *stork_api.ApplicationBackup) (bool, error) {
+	op := a.newQueueOperation(backup)
+	for _, vInfo := range backup.Status.Volumes {
+		if vInfo.Status == stork_api.ApplicationBackupStatusFailed {
+			op.Volumes[vInfo.DriverName]++
+		}
+	}
+	ok, position := queue.Instance().Acquire(op)
+	if ok {
+		backup.Status.QueuePosition = 0
+		return false, nil
+	}
+	if backup.Status.QueuePosition == position {
+		return true, nil
+	}
+	if backup.Status.QueuePosition == 0 {
+		a.Recorder.Event(backup,
+			v1.EventTypeNormal,
+			string(stork_api.ApplicationBackupStatusQueued),
+			fmt.Sprintf("Retry queued at position %v since the limit for concurrent operations has been reached", position))
+	}
+	backup.Status.QueuePosition = position
+	return true, sdk.Update(backup)
+}
+
+func (a *ApplicationBackupController) retryVolumeBackups(
+	backup *stork_api.ApplicationBackup,
+	driverName string,
+	failed []*stork_api.ApplicationBackupVolumeInfo,
+) ([]*stork_api.ApplicationBackupVolumeInfo, error) {
+	driver, err := volume.Get(driverName)
+	if err != nil {
+		return nil, err
+	}
+	pvcs := make([]v1.PersistentVolumeClaim, 0)
+	for _, vInfo := range failed {
+		pvc, err := core.Instance().GetPersistentVolumeClaim(vInfo.PersistentVolumeClaim, vInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error getting PVC %v/%v: %v", vInfo.Namespace, vInfo.PersistentVolumeClaim, err)
+		}
+		pvcs = append(pvcs, *pvc)
+	}
+	volumeInfos, err := driver.StartBackup(backup, pvcs)
+	if err != nil {
+		return nil, err
+	}
+	for _, vInfo := range volumeInfos {
+		vInfo.Retries = backup.Status.Retries
+	}
+	return volumeInfos, nil
+}
+
 func (a *ApplicationBackupController) runPreExecRule(backup *stork_api.ApplicationBackup) ([]chan bool, error) {
 	if backup.Spec.PreExecRule == "" {
 		backup.Status.Stage = stork_api.ApplicationBackupStageVolumes
@@ -533,7 +792,17 @@ func (a *ApplicationBackupController) uploadObject(
 	objectName string,
 	data []byte,
 ) error {
-	backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+	return a.uploadObjectToLocation(backup, backup.Spec.BackupLocation, objectName, data)
+}
+
+// Uploads the given data for the backup to the given backup location
+func (a *ApplicationBackupController) uploadObjectToLocation(
+	backup *stork_api.ApplicationBackup,
+	locationName string,
+	objectName string,
+	data []byte,
+) error {
+	backupLocation, err := storkops.Instance().GetBackupLocation(locationName, backup.Namespace)
 	if err != nil {
 		return err
 	}
@@ -549,7 +818,7 @@ func (a *ApplicationBackupController) uploadObject(
 	}
 
 	objectPath := a.getObjectPath(backup)
-	writer, err := bucket.NewWriter(context.TODO(), filepath.Join(objectPath, objectName), nil)
+	writer, err := bucket.NewWriter(context.TODO(), filepath.Join(objectPath, objectName), objectstore.GetWriterOptions(backupLocation))
 	if err != nil {
 		return err
 	}
@@ -583,6 +852,26 @@ func (a *ApplicationBackupController) uploadResources(
 	return a.uploadObject(backup, resourceObjectName, jsonBytes)
 }
 
+// Convert the list of Secrets to json, encrypt them with the
+// SecretsEncryptionKey and upload them to the backup location
+func (a *ApplicationBackupController) uploadSecrets(
+	backup *stork_api.ApplicationBackup,
+	secrets []runtime.Unstructured,
+) error {
+	key, err := getSecretsEncryptionKey(backup.Spec.SecretsEncryptionKey, backup.Namespace)
+	if err != nil {
+		return err
+	}
+	jsonBytes, err := json.MarshalIndent(secrets, "", " ")
+	if err != nil {
+		return err
+	}
+	if jsonBytes, err = crypto.Encrypt(jsonBytes, key); err != nil {
+		return err
+	}
+	return a.uploadObject(backup, secretsObjectName, jsonBytes)
+}
+
 // Upload the backup object which should have all the required metadata
 func (a *ApplicationBackupController) uploadMetadata(
 	backup *stork_api.ApplicationBackup,
@@ -592,7 +881,56 @@ func (a *ApplicationBackupController) uploadMetadata(
 		return err
 	}
 
-	return a.uploadObject(backup, metadataObjectName, jsonBytes)
+	if err := a.uploadObject(backup, objectstore.BackupMetadataObjectName, jsonBytes); err != nil {
+		return err
+	}
+	a.addToBackupIndex(backup, backup.Spec.BackupLocation, backup.Status.BackupPath, backup.Status.FinishTimestamp)
+	return nil
+}
+
+// addToBackupIndex adds a backup to the index of a location so that it is
+// picked up by incremental syncs. Errors are only logged since backups that
+// aren't in the index are still found by the periodic full sync
+func (a *ApplicationBackupController) addToBackupIndex(
+	backup *stork_api.ApplicationBackup,
+	locationName string,
+	backupPath string,
+	finishTime metav1.Time,
+) {
+	backupLocation, err := storkops.Instance().GetBackupLocation(locationName, backup.Namespace)
+	if err == nil {
+		var bucket *blob.Bucket
+		if bucket, err = objectstore.GetBucket(backupLocation); err == nil {
+			err = objectstore.AddBackupToIndex(backupLocation, bucket, backupPath, finishTime.Time)
+		}
+	}
+	if err != nil {
+		log.ApplicationBackupLog(backup).Warnf("Error adding backup to index of location %v: %v", locationName, err)
+	}
+}
+
+// removeFromBackupIndex removes a backup from the index of a location. Errors
+// are only logged since a stale entry only causes the backup to be checked
+// again by the sync
+func (a *ApplicationBackupController) removeFromBackupIndex(
+	backup *stork_api.ApplicationBackup,
+	locationName string,
+	backupPath string,
+	finishTime metav1.Time,
+) {
+	if backupPath == "" || finishTime.IsZero() {
+		return
+	}
+	backupLocation, err := storkops.Instance().GetBackupLocation(locationName, backup.Namespace)
+	if err == nil {
+		var bucket *blob.Bucket
+		if bucket, err = objectstore.GetBucket(backupLocation); err == nil {
+			err = objectstore.RemoveBackupFromIndex(backupLocation, bucket, backupPath, finishTime.Time)
+		}
+	}
+	if err != nil {
+		log.ApplicationBackupLog(backup).Warnf("Error removing backup from index of location %v: %v", locationName, err)
+	}
 }
 
 func (a *ApplicationBackupController) backupResources(
@@ -603,6 +941,9 @@ func (a *ApplicationBackupController) backupResources(
 		log.ApplicationBackupLog(backup).Errorf("Error getting resources: %v", err)
 		return err
 	}
+	if backup.Spec.SecretsMode == stork_api.ApplicationBackupSecretsModeExclude {
+		allObjects, _ = splitSecrets(allObjects)
+	}
 
 	// Save the collected resources infos in the status
 	resourceInfos := make([]*stork_api.ApplicationBackupResourceInfo, 0)
@@ -642,6 +983,21 @@ func (a *ApplicationBackupController) backupResources(
 		return err
 	}
 
+	// Store the Secrets separately if they need to be encrypted with their
+	// own key
+	if backup.Spec.SecretsMode == stork_api.ApplicationBackupSecretsModeEncrypt {
+		var secrets []runtime.Unstructured
+		allObjects, secrets = splitSecrets(allObjects)
+		if err = a.uploadSecrets(backup, secrets); err != nil {
+			a.Recorder.Event(backup,
+				v1.EventTypeWarning,
+				string(stork_api.ApplicationBackupStatusFailed),
+				fmt.Sprintf("Error uploading secrets: %v", err))
+			log.ApplicationBackupLog(backup).Errorf("Error uploading secrets: %v", err)
+			return err
+		}
+	}
+
 	// Upload the resources to the backup location
 	if err = a.uploadResources(backup, allObjects); err != nil {
 		a.Recorder.Event(backup,
@@ -700,6 +1056,10 @@ func (a *ApplicationBackupController) deleteBackup(backup *stork_api.Application
 		}
 	}
 
+	if err := a.deleteReplicas(backup); err != nil {
+		return err
+	}
+
 	backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
 	if err != nil {
 		// Can't do anything if the backup location is deleted
@@ -719,9 +1079,17 @@ func (a *ApplicationBackupController) deleteBackup(backup *stork_api.Application
 			return fmt.Errorf("error deleting resources for backup %v/%v: %v", backup.Namespace, backup.Name, err)
 		}
 
-		if err = bucket.Delete(context.TODO(), filepath.Join(objectPath, metadataObjectName)); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+		if err = bucket.Delete(context.TODO(), filepath.Join(objectPath, secretsObjectName)); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+			return fmt.Errorf("error deleting secrets for backup %v/%v: %v", backup.Namespace, backup.Name, err)
+		}
+
+		if err = bucket.Delete(context.TODO(), filepath.Join(objectPath, objectstore.BackupMetadataObjectName)); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
 			return fmt.Errorf("error deleting metadata for backup %v/%v: %v", backup.Namespace, backup.Name, err)
 		}
+
+		if err = objectstore.RemoveBackupFromIndex(backupLocation, bucket, objectPath, backup.Status.FinishTimestamp.Time); err != nil {
+			return fmt.Errorf("error removing backup %v/%v from index: %v", backup.Namespace, backup.Name, err)
+		}
 	}
 
 	return nil
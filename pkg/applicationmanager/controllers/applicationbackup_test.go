@@ -0,0 +1,56 @@
+// +build unittest
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/queue"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestTrackStartedBackups(t *testing.T) {
+	setupStorkOps()
+	queue.Instance().SetLimits(queue.Limits{MaxOperationsPerNamespace: 1})
+	defer queue.Instance().SetLimits(queue.Limits{})
+
+	started := newReplicaTestBackup(copyTestDriverName)
+	started.Status.Stage = stork_api.ApplicationBackupStageVolumes
+	started.Status.Status = stork_api.ApplicationBackupStatusInProgress
+	_, err := storkops.Instance().CreateApplicationBackup(started)
+	require.NoError(t, err, "Error creating started backup")
+	defer queue.Instance().Release(started.UID)
+
+	finished := newReplicaTestBackup(copyTestDriverName)
+	finished.Name = "finished"
+	finished.UID = "finished-uid"
+	finished.Namespace = "other"
+	finished.Status.Stage = stork_api.ApplicationBackupStageFinal
+	_, err = storkops.Instance().CreateApplicationBackup(finished)
+	require.NoError(t, err, "Error creating finished backup")
+
+	controller := &ApplicationBackupController{Recorder: record.NewFakeRecorder(100)}
+	require.NoError(t, controller.trackStartedBackups(), "Error tracking started backups")
+
+	op := &queue.Operation{
+		Kind:              "ApplicationBackup",
+		Namespace:         "ns",
+		Name:              "queued",
+		UID:               "queued-uid",
+		CreationTimestamp: time.Now(),
+	}
+	ok, position := queue.Instance().Acquire(op)
+	require.False(t, ok, "Backup should be queued behind the started backup")
+	require.Equal(t, 1, position, "Unexpected queue position")
+	queue.Instance().Release(op.UID)
+
+	op.Namespace = "other"
+	op.UID = "other-uid"
+	ok, _ = queue.Instance().Acquire(op)
+	require.True(t, ok, "Finished backups shouldn't be tracked")
+	queue.Instance().Release(op.UID)
+}
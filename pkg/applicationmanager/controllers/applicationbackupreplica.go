@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	storkerrors "github.com/libopenstorage/stork/pkg/errors"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/operator-framework/operator-sdk/pkg/sdk"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"gocloud.dev/gcerrors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// validateReplicaBackupLocations checks that the volume backups for the
+// volumes being backed up can be copied to the replica backup locations
+func validateReplicaBackupLocations(backup *stork_api.ApplicationBackup) error {
+	if len(backup.Spec.ReplicaBackupLocations) == 0 {
+		return nil
+	}
+	drivers := make(map[string]bool)
+	for _, namespace := range backup.Spec.Namespaces {
+		pvcList, err := core.Instance().GetPersistentVolumeClaims(namespace, backup.Spec.Selectors)
+		if err != nil {
+			return fmt.Errorf("error getting list of volumes to backup: %v", err)
+		}
+		for _, pvc := range pvcList.Items {
+			driverName, err := volume.GetPVCDriver(&pvc)
+			if err != nil {
+				return err
+			}
+			if driverName == "" || drivers[driverName] {
+				continue
+			}
+			drivers[driverName] = true
+			driver, err := volume.Get(driverName)
+			if err != nil {
+				return err
+			}
+			if err := driver.ValidateBackupCopy(); err != nil {
+				return fmt.Errorf("copying volume backups to replica locations isn't supported by driver %v: %v", driverName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// copyToReplicas copies a successful backup to each of the replica backup
+// locations. The volume backups are copied first followed by the resources
+// and the metadata, so a replica can be synced to another cluster once its
+// metadata shows up
+func (a *ApplicationBackupController) copyToReplicas(backup *stork_api.ApplicationBackup) error {
+	if !a.updateReplicas(backup) {
+		return nil
+	}
+	return sdk.Update(backup)
+}
+
+// updateReplicas moves each replica that isn't done to its next step.
+// Returns true if the status of the backup needs to be updated
+func (a *ApplicationBackupController) updateReplicas(backup *stork_api.ApplicationBackup) bool {
+	if len(backup.Spec.ReplicaBackupLocations) == 0 {
+		return false
+	}
+	if backup.Status.Replicas == nil {
+		backup.Status.Replicas = make([]*stork_api.ApplicationBackupReplicaInfo, 0)
+		for _, location := range backup.Spec.ReplicaBackupLocations {
+			backup.Status.Replicas = append(backup.Status.Replicas, &stork_api.ApplicationBackupReplicaInfo{
+				BackupLocation: location,
+				Status:         stork_api.ApplicationBackupStatusPending,
+				Reason:         "Waiting to copy backup",
+			})
+		}
+	}
+
+	updated := false
+	for _, replica := range backup.Status.Replicas {
+		if replica.Status == stork_api.ApplicationBackupStatusSuccessful ||
+			replica.Status == stork_api.ApplicationBackupStatusFailed {
+			continue
+		}
+		updated = true
+		if err := a.copyToReplica(backup, replica); err != nil {
+			message := fmt.Sprintf("Error copying backup to replica location %v: %v", replica.BackupLocation, err)
+			log.ApplicationBackupLog(backup).Errorf("%v", message)
+			a.Recorder.Event(backup,
+				v1.EventTypeWarning,
+				string(stork_api.ApplicationBackupStatusFailed),
+				message)
+			replica.Status = stork_api.ApplicationBackupStatusFailed
+			replica.Reason = err.Error()
+			replica.FinishTimestamp = metav1.Now()
+		}
+	}
+	return updated
+}
+
+func (a *ApplicationBackupController) copyToReplica(
+	backup *stork_api.ApplicationBackup,
+	replica *stork_api.ApplicationBackupReplicaInfo,
+) error {
+	switch replica.Status {
+	case stork_api.ApplicationBackupStatusPending:
+		if _, err := storkops.Instance().GetBackupLocation(replica.BackupLocation, backup.Namespace); err != nil {
+			return fmt.Errorf("error getting backup location: %v", err)
+		}
+		replica.Volumes = make([]*stork_api.ApplicationBackupVolumeInfo, 0)
+		for driverName, vInfos := range a.getVolumeInfosByDriver(backup.Status.Volumes) {
+			driver, err := volume.Get(driverName)
+			if err != nil {
+				return err
+			}
+			volumeInfos, err := driver.StartBackupCopy(backup, replica, vInfos)
+			if _, ok := err.(*storkerrors.ErrNotSupported); ok {
+				return fmt.Errorf("copying volume backups isn't supported by driver %v: %v", driverName, err)
+			} else if err != nil {
+				return fmt.Errorf("error starting copy of volume backups: %v", err)
+			}
+			replica.Volumes = append(replica.Volumes, volumeInfos...)
+		}
+		replica.Status = stork_api.ApplicationBackupStatusInProgress
+		replica.Reason = "Copying volume backups"
+		return nil
+
+	case stork_api.ApplicationBackupStatusInProgress:
+		volumeInfos := make([]*stork_api.ApplicationBackupVolumeInfo, 0)
+		for driverName, vInfos := range a.getVolumeInfosByDriver(replica.Volumes) {
+			driver, err := volume.Get(driverName)
+			if err != nil {
+				return err
+			}
+			status, err := driver.GetBackupCopyStatus(backup, replica, vInfos)
+			if _, ok := err.(*storkerrors.ErrNotSupported); ok {
+				return fmt.Errorf("copying volume backups isn't supported by driver %v: %v", driverName, err)
+			} else if err != nil {
+				// Check again on the next update
+				log.ApplicationBackupLog(backup).Errorf("Error getting backup copy status for driver %v: %v", driverName, err)
+				return nil
+			}
+			volumeInfos = append(volumeInfos, status...)
+		}
+		replica.Volumes = volumeInfos
+
+		inProgress := false
+		for _, vInfo := range volumeInfos {
+			if vInfo.Status == stork_api.ApplicationBackupStatusFailed {
+				return fmt.Errorf("error copying backup for volume %v: %v", vInfo.Volume, vInfo.Reason)
+			} else if vInfo.Status != stork_api.ApplicationBackupStatusSuccessful {
+				inProgress = true
+			}
+		}
+		if inProgress {
+			return nil
+		}
+		return a.copyObjectsToReplica(backup, replica)
+	}
+	return nil
+}
+
+// copyObjectsToReplica copies the resources for the backup from the primary
+// backup location to the replica, re-encrypting them with the encryption key
+// for the replica, and then uploads the metadata
+func (a *ApplicationBackupController) copyObjectsToReplica(
+	backup *stork_api.ApplicationBackup,
+	replica *stork_api.ApplicationBackupReplicaInfo,
+) error {
+	backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+	if err != nil {
+		return err
+	}
+	bucket, err := objectstore.GetBucket(backupLocation)
+	if err != nil {
+		return err
+	}
+	objectNames := []string{resourceObjectName}
+	if backup.Spec.SecretsMode == stork_api.ApplicationBackupSecretsModeEncrypt {
+		objectNames = append(objectNames, secretsObjectName)
+	}
+	for _, objectName := range objectNames {
+		data, err := bucket.ReadAll(context.TODO(), filepath.Join(backup.Status.BackupPath, objectName))
+		if err != nil {
+			return fmt.Errorf("error reading %v: %v", objectName, err)
+		}
+		if backupLocation.Location.EncryptionKey != "" {
+			if data, err = crypto.Decrypt(data, backupLocation.Location.EncryptionKey); err != nil {
+				return fmt.Errorf("error decrypting %v: %v", objectName, err)
+			}
+		}
+		if err := a.uploadObjectToLocation(backup, replica.BackupLocation, objectName, data); err != nil {
+			return fmt.Errorf("error uploading %v: %v", objectName, err)
+		}
+	}
+
+	replica.BackupPath = a.getObjectPath(backup)
+	replica.Status = stork_api.ApplicationBackupStatusSuccessful
+	replica.Reason = "Backup copied successfully"
+	if shared := getSharedVolumeBackups(replica); shared > 0 {
+		replica.Reason = fmt.Sprintf("Resources copied successfully, backups of %v volume(s) are shared with the primary backup location", shared)
+	}
+	replica.FinishTimestamp = metav1.Now()
+
+	jsonBytes, err := json.MarshalIndent(getReplicaBackup(backup, replica), "", " ")
+	if err != nil {
+		return err
+	}
+	if err := a.uploadObjectToLocation(backup, replica.BackupLocation, objectstore.BackupMetadataObjectName, jsonBytes); err != nil {
+		return fmt.Errorf("error uploading metadata: %v", err)
+	}
+	a.addToBackupIndex(backup, replica.BackupLocation, replica.BackupPath, replica.FinishTimestamp)
+
+	a.Recorder.Event(backup,
+		v1.EventTypeNormal,
+		string(stork_api.ApplicationBackupStatusSuccessful),
+		fmt.Sprintf("Backup copied to replica location %v", replica.BackupLocation))
+	return nil
+}
+
+// getSharedVolumeBackups returns the number of volume backups for the replica
+// that weren't copied since they are shared with the primary backup location
+func getSharedVolumeBackups(replica *stork_api.ApplicationBackupReplicaInfo) int {
+	shared := 0
+	for _, vInfo := range replica.Volumes {
+		if vInfo.Reason == volume.BackupCopySharedReason {
+			shared++
+		}
+	}
+	return shared
+}
+
+// getReplicaBackup returns the backup as it should be seen from the replica
+// location, so that it can be synced and restored from there without the
+// primary location
+func getReplicaBackup(
+	backup *stork_api.ApplicationBackup,
+	replica *stork_api.ApplicationBackupReplicaInfo,
+) *stork_api.ApplicationBackup {
+	replicaBackup := backup.DeepCopy()
+	replicaBackup.Spec.BackupLocation = replica.BackupLocation
+	replicaBackup.Spec.ReplicaBackupLocations = nil
+	replicaBackup.Status.Volumes = replica.Volumes
+	replicaBackup.Status.BackupPath = replica.BackupPath
+	replicaBackup.Status.Replicas = nil
+	return replicaBackup
+}
+
+func (a *ApplicationBackupController) deleteReplicas(backup *stork_api.ApplicationBackup) error {
+	for _, replica := range backup.Status.Replicas {
+		for driverName, vInfos := range a.getVolumeInfosByDriver(replica.Volumes) {
+			driver, err := volume.Get(driverName)
+			if err != nil {
+				return err
+			}
+			if err := driver.DeleteBackupCopy(backup, replica, vInfos); err != nil {
+				return err
+			}
+		}
+
+		if replica.BackupPath == "" {
+			continue
+		}
+		backupLocation, err := storkops.Instance().GetBackupLocation(replica.BackupLocation, backup.Namespace)
+		if err != nil {
+			// Can't do anything if the backup location is deleted
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		bucket, err := objectstore.GetBucket(backupLocation)
+		if err != nil {
+			return err
+		}
+		for _, objectName := range []string{resourceObjectName, secretsObjectName, objectstore.BackupMetadataObjectName} {
+			if err = bucket.Delete(context.TODO(), filepath.Join(replica.BackupPath, objectName)); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+				return fmt.Errorf("error deleting %v for backup %v/%v from replica location %v: %v",
+					objectName, backup.Namespace, backup.Name, replica.BackupLocation, err)
+			}
+		}
+		if err = objectstore.RemoveBackupFromIndex(backupLocation, bucket, replica.BackupPath, replica.FinishTimestamp.Time); err != nil {
+			return fmt.Errorf("error removing backup %v/%v from index of replica location %v: %v",
+				backup.Namespace, backup.Name, replica.BackupLocation, err)
+		}
+	}
+	return nil
+}
+
+func (a *ApplicationBackupController) getVolumeInfosByDriver(
+	volumeInfos []*stork_api.ApplicationBackupVolumeInfo,
+) map[string][]*stork_api.ApplicationBackupVolumeInfo {
+	volumeInfoMappings := make(map[string][]*stork_api.ApplicationBackupVolumeInfo)
+	for _, vInfo := range volumeInfos {
+		volumeInfoMappings[vInfo.DriverName] = append(volumeInfoMappings[vInfo.DriverName], vInfo)
+	}
+	return volumeInfoMappings
+}
@@ -0,0 +1,217 @@
+// +build unittest
+
+package controllers
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/drivers/volume/mock"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	fakeclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned/fake"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	storkerrors "github.com/libopenstorage/stork/pkg/errors"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+const copyTestDriverName = "CopyTestDriver"
+
+// copyTestDriver is a volume driver whose volume backups don't need to be
+// copied to replica locations
+type copyTestDriver struct {
+	mock.Driver
+	copy volume.BackupCopyNotRequired
+}
+
+func (d *copyTestDriver) String() string {
+	return copyTestDriverName
+}
+
+func (d *copyTestDriver) ValidateBackupCopy() error {
+	return d.copy.ValidateBackupCopy()
+}
+
+func (d *copyTestDriver) StartBackupCopy(
+	backup *stork_api.ApplicationBackup,
+	replica *stork_api.ApplicationBackupReplicaInfo,
+	volumeBackupInfos []*stork_api.ApplicationBackupVolumeInfo,
+) ([]*stork_api.ApplicationBackupVolumeInfo, error) {
+	return d.copy.StartBackupCopy(backup, replica, volumeBackupInfos)
+}
+
+func (d *copyTestDriver) GetBackupCopyStatus(
+	backup *stork_api.ApplicationBackup,
+	replica *stork_api.ApplicationBackupReplicaInfo,
+	volumeBackupInfos []*stork_api.ApplicationBackupVolumeInfo,
+) ([]*stork_api.ApplicationBackupVolumeInfo, error) {
+	return d.copy.GetBackupCopyStatus(backup, replica, volumeBackupInfos)
+}
+
+func (d *copyTestDriver) DeleteBackupCopy(
+	backup *stork_api.ApplicationBackup,
+	replica *stork_api.ApplicationBackupReplicaInfo,
+	volumeBackupInfos []*stork_api.ApplicationBackupVolumeInfo,
+) error {
+	return d.copy.DeleteBackupCopy(backup, replica, volumeBackupInfos)
+}
+
+func init() {
+	if err := volume.Register(copyTestDriverName, &copyTestDriver{}); err != nil {
+		logrus.Panicf("Error registering copy test volume driver: %v", err)
+	}
+}
+
+func setupStorkOps() {
+	storkops.SetInstance(storkops.New(kubernetes.NewSimpleClientset(), fakeclient.NewSimpleClientset(), nil))
+}
+
+func createBackupLocation(t *testing.T, name string, encryptionKey string) *stork_api.BackupLocation {
+	location, err := storkops.Instance().CreateBackupLocation(&stork_api.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+		},
+		Location: stork_api.BackupLocationItem{
+			Type:          stork_api.BackupLocationFilesystem,
+			Path:          t.TempDir(),
+			EncryptionKey: encryptionKey,
+		},
+	})
+	require.NoError(t, err, "Error creating backup location")
+	return location
+}
+
+func newReplicaTestBackup(driverName string, replicaLocations ...string) *stork_api.ApplicationBackup {
+	return &stork_api.ApplicationBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup",
+			Namespace: "ns",
+			UID:       "backup-uid",
+		},
+		Spec: stork_api.ApplicationBackupSpec{
+			BackupLocation:         "primary",
+			ReplicaBackupLocations: replicaLocations,
+			Namespaces:             []string{"app"},
+		},
+		Status: stork_api.ApplicationBackupStatus{
+			Status:          stork_api.ApplicationBackupStatusSuccessful,
+			BackupPath:      "ns/backup/backup-uid",
+			FinishTimestamp: metav1.Now(),
+			Volumes: []*stork_api.ApplicationBackupVolumeInfo{
+				{
+					Volume:                "vol1",
+					Namespace:             "app",
+					PersistentVolumeClaim: "pvc1",
+					DriverName:            driverName,
+					BackupID:              "backup1",
+					Status:                stork_api.ApplicationBackupStatusSuccessful,
+				},
+			},
+		},
+	}
+}
+
+func TestCopyToReplicas(t *testing.T) {
+	setupStorkOps()
+	createBackupLocation(t, "primary", "primarykey")
+	replicaLocation := createBackupLocation(t, "replica", "replicakey")
+	controller := &ApplicationBackupController{Recorder: record.NewFakeRecorder(100)}
+	backup := newReplicaTestBackup(copyTestDriverName, "replica")
+
+	resources := []byte(`[{"kind":"ConfigMap"}]`)
+	err := controller.uploadObject(backup, resourceObjectName, resources)
+	require.NoError(t, err, "Error uploading resources")
+
+	require.True(t, controller.updateReplicas(backup), "Backup should have been updated")
+	require.Len(t, backup.Status.Replicas, 1)
+	replica := backup.Status.Replicas[0]
+	require.Equal(t, stork_api.ApplicationBackupStatusInProgress, replica.Status, replica.Reason)
+	require.Len(t, replica.Volumes, 1)
+	require.Equal(t, "backup1", replica.Volumes[0].BackupID)
+	require.Equal(t, volume.BackupCopySharedReason, replica.Volumes[0].Reason)
+
+	require.True(t, controller.updateReplicas(backup), "Backup should have been updated")
+	require.Equal(t, stork_api.ApplicationBackupStatusSuccessful, replica.Status, replica.Reason)
+	require.Equal(t, backup.Status.BackupPath, replica.BackupPath)
+	require.Contains(t, replica.Reason, "backups of 1 volume(s) are shared with the primary backup location")
+
+	// The resources should be re-encrypted with the key for the replica and
+	// the metadata should point to the replica location
+	bucket, err := objectstore.GetBucket(replicaLocation)
+	require.NoError(t, err, "Error getting bucket")
+	defer bucket.Close()
+	data, err := bucket.ReadAll(context.TODO(), filepath.Join(replica.BackupPath, resourceObjectName))
+	require.NoError(t, err, "Error reading resources from replica")
+	data, err = crypto.Decrypt(data, "replicakey")
+	require.NoError(t, err, "Error decrypting resources from replica")
+	require.Equal(t, resources, data)
+
+	replicaBackup, err := objectstore.ReadBackupMetadata(replicaLocation, bucket, replica.BackupPath)
+	require.NoError(t, err, "Error reading metadata from replica")
+	require.Equal(t, "replica", replicaBackup.Spec.BackupLocation)
+	require.Empty(t, replicaBackup.Spec.ReplicaBackupLocations)
+	require.Empty(t, replicaBackup.Status.Replicas)
+
+	indexed, err := objectstore.ListIndexedBackups(bucket, "ns", time.Now().Add(-time.Hour))
+	require.NoError(t, err, "Error listing index of replica")
+	require.Equal(t, []string{replica.BackupPath}, indexed)
+
+	require.False(t, controller.updateReplicas(backup), "Completed replicas shouldn't be updated")
+}
+
+func TestCopyToReplicasFailures(t *testing.T) {
+	setupStorkOps()
+	createBackupLocation(t, "primary", "")
+	createBackupLocation(t, "replica", "")
+	controller := &ApplicationBackupController{Recorder: record.NewFakeRecorder(100)}
+
+	require.False(t, controller.updateReplicas(newReplicaTestBackup(copyTestDriverName)),
+		"Backup without replicas shouldn't be updated")
+
+	// Drivers that can't copy volume backups should fail the replica
+	backup := newReplicaTestBackup("MockDriver", "replica", "missing")
+	require.True(t, controller.updateReplicas(backup), "Backup should have been updated")
+	require.Len(t, backup.Status.Replicas, 2)
+	require.Equal(t, stork_api.ApplicationBackupStatusFailed, backup.Status.Replicas[0].Status)
+	require.Contains(t, backup.Status.Replicas[0].Reason, "isn't supported by driver MockDriver")
+	require.False(t, backup.Status.Replicas[0].FinishTimestamp.IsZero())
+	require.Empty(t, backup.Status.Replicas[0].BackupPath)
+
+	// Replica locations that don't exist should fail the replica
+	require.Equal(t, stork_api.ApplicationBackupStatusFailed, backup.Status.Replicas[1].Status)
+	require.Contains(t, backup.Status.Replicas[1].Reason, "error getting backup location")
+
+	require.False(t, controller.updateReplicas(backup), "Failed replicas shouldn't be retried")
+}
+
+func TestValidateReplicaBackupLocations(t *testing.T) {
+	fakeKube := kubernetes.NewSimpleClientset(&v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pvc1",
+			Namespace: "app",
+		},
+	})
+	core.SetInstance(core.New(fakeKube, fakeKube.CoreV1(), fakeKube.StorageV1()))
+
+	backup := newReplicaTestBackup(copyTestDriverName)
+	require.NoError(t, validateReplicaBackupLocations(backup), "Backup without replicas should be valid")
+
+	backup = newReplicaTestBackup(copyTestDriverName, "replica")
+	backup.Spec.Namespaces = []string{"empty"}
+	require.NoError(t, validateReplicaBackupLocations(backup), "Backup without volumes should be valid")
+
+	require.NoError(t, (&copyTestDriver{}).ValidateBackupCopy())
+	_, ok := (&mock.Driver{}).ValidateBackupCopy().(*storkerrors.ErrNotSupported)
+	require.True(t, ok, "Mock driver shouldn't support copying volume backups")
+}
@@ -80,6 +80,10 @@ func (s *ApplicationBackupScheduleController) Handle(ctx context.Context, event
 			return err
 		}
 
+		if err := s.updateRPOStatus(backupSchedule); err != nil {
+			return err
+		}
+
 		if backupSchedule.Spec.Suspend == nil || !*backupSchedule.Spec.Suspend {
 			// Then check if any of the policies require a trigger
 			policyType, start, err := s.shouldStartApplicationBackup(backupSchedule)
@@ -189,6 +193,52 @@ func (s *ApplicationBackupScheduleController) updateApplicationBackupStatus(back
 	return nil
 }
 
+// updateRPOStatus updates the start and finish times of the last successful
+// backup and the RPOBreached condition, and raises an event when the RPO target is
+// breached or met again. The status is only updated when either of them
+// changes, the current lag is computed by clients
+func (s *ApplicationBackupScheduleController) updateRPOStatus(backupSchedule *stork_api.ApplicationBackupSchedule) error {
+	lastSuccessful := backupSchedule.Status.LastSuccessfulTimestamp
+	lastSuccessfulFinish := backupSchedule.Status.LastSuccessfulFinishTimestamp
+	for _, policyApplicationBackup := range backupSchedule.Status.Items {
+		for _, backup := range policyApplicationBackup {
+			if backup.Status == stork_api.ApplicationBackupStatusSuccessful && lastSuccessful.Before(&backup.CreationTimestamp) {
+				lastSuccessful = backup.CreationTimestamp
+				lastSuccessfulFinish = backup.FinishTimestamp
+			}
+		}
+	}
+	lag := schedule.GetRPOLag(lastSuccessful, backupSchedule.CreationTimestamp)
+	conditions, changed := schedule.UpdateRPOCondition(backupSchedule.Status.Conditions, backupSchedule.Spec.RPOTarget, lag)
+	if changed {
+		if schedule.IsRPOBreached(conditions) {
+			msg := fmt.Sprintf("RPO target (%v) breached, last successful backup was started %v ago", backupSchedule.Spec.RPOTarget.Duration, lag)
+			s.Recorder.Event(backupSchedule,
+				v1.EventTypeWarning,
+				schedule.RPOBreachedReason,
+				msg)
+			log.ApplicationBackupScheduleLog(backupSchedule).Warn(msg)
+		} else {
+			msg := "RPO target is being met again"
+			s.Recorder.Event(backupSchedule,
+				v1.EventTypeNormal,
+				schedule.RPOMetReason,
+				msg)
+			log.ApplicationBackupScheduleLog(backupSchedule).Info(msg)
+		}
+	}
+
+	if lastSuccessful.Equal(&backupSchedule.Status.LastSuccessfulTimestamp) &&
+		lastSuccessfulFinish.Equal(&backupSchedule.Status.LastSuccessfulFinishTimestamp) &&
+		reflect.DeepEqual(conditions, backupSchedule.Status.Conditions) {
+		return nil
+	}
+	backupSchedule.Status.LastSuccessfulTimestamp = lastSuccessful
+	backupSchedule.Status.LastSuccessfulFinishTimestamp = lastSuccessfulFinish
+	backupSchedule.Status.Conditions = conditions
+	return sdk.Update(backupSchedule)
+}
+
 func (s *ApplicationBackupScheduleController) isApplicationBackupComplete(status stork_api.ApplicationBackupStatusType) bool {
 	return status == stork_api.ApplicationBackupStatusFailed ||
 		status == stork_api.ApplicationBackupStatusPartialSuccess ||
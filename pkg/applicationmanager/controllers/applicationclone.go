@@ -10,6 +10,7 @@ import (
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/controller"
 	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/queue"
 	"github.com/libopenstorage/stork/pkg/resourcecollector"
 	"github.com/libopenstorage/stork/pkg/rule"
 	"github.com/operator-framework/operator-sdk/pkg/sdk"
@@ -55,6 +56,9 @@ func (a *ApplicationCloneController) Init(adminNamespace string) error {
 		logrus.Errorf("Failed to perform recovery for application clone rules: %v", err)
 		return err
 	}
+	if err := a.trackStartedClones(); err != nil {
+		return err
+	}
 
 	config, err := rest.InClusterConfig()
 	if err != nil {
@@ -106,6 +110,28 @@ func (a *ApplicationCloneController) performRuleRecovery() error {
 	return lastError
 }
 
+// trackStartedClones adds the clones that were already started to the queue
+// so that they are accounted for before any queued operations can start
+func (a *ApplicationCloneController) trackStartedClones() error {
+	applicationClones, err := storkops.Instance().ListApplicationClones(v1.NamespaceAll)
+	if err != nil {
+		return fmt.Errorf("error listing application clones: %v", err)
+	}
+	for _, clone := range applicationClones.Items {
+		if clone.Status.Stage == stork_api.ApplicationCloneStageInitial ||
+			clone.Status.Stage == stork_api.ApplicationCloneStageFinal {
+			continue
+		}
+		op, err := a.getQueueOperation(&clone)
+		if err != nil {
+			log.ApplicationCloneLog(&clone).Warnf("Error getting volumes to add clone to queue: %v", err)
+			continue
+		}
+		queue.Instance().Track(op)
+	}
+	return nil
+}
+
 func (a *ApplicationCloneController) setDefaults(clone *stork_api.ApplicationClone) {
 	if clone.Spec.ReplacePolicy == "" {
 		clone.Spec.ReplacePolicy = stork_api.ApplicationCloneReplacePolicyRetain
@@ -132,6 +158,7 @@ func (a *ApplicationCloneController) Handle(ctx context.Context, event sdk.Event
 	case *stork_api.ApplicationClone:
 		clone := o
 		if event.Deleted {
+			queue.Instance().Release(clone.UID)
 			return a.deleteClone(clone)
 		}
 
@@ -140,7 +167,7 @@ func (a *ApplicationCloneController) Handle(ctx context.Context, event sdk.Event
 		// except for the namespace designated by the admin
 		if !a.namespaceCloneAllowed(clone) {
 			err := fmt.Errorf("application clone objects can only be created in the admin namespace (%v)", a.adminNamespace)
-			log.ApplicationCloneLog(clone).Errorf(err.Error())
+			log.ApplicationCloneLog(clone).Error(err.Error())
 			a.Recorder.Event(clone,
 				v1.EventTypeWarning,
 				string(stork_api.ApplicationCloneStatusFailed),
@@ -152,11 +179,15 @@ func (a *ApplicationCloneController) Handle(ctx context.Context, event sdk.Event
 		var err error
 
 		a.setDefaults(clone)
+		if clone.Status.Stage == stork_api.ApplicationCloneStageFinal {
+			queue.Instance().Release(clone.UID)
+		}
+
 		switch clone.Status.Stage {
 		case stork_api.ApplicationCloneStageInitial:
 			err = a.verifyNamespaces(clone)
 			if err != nil {
-				log.ApplicationCloneLog(clone).Errorf(err.Error())
+				log.ApplicationCloneLog(clone).Error(err.Error())
 				a.Recorder.Event(clone,
 					v1.EventTypeWarning,
 					string(stork_api.ApplicationCloneStatusFailed),
@@ -168,7 +199,7 @@ func (a *ApplicationCloneController) Handle(ctx context.Context, event sdk.Event
 				_, err := storkops.Instance().GetRule(clone.Spec.PreExecRule, clone.Namespace)
 				if err != nil {
 					message := fmt.Sprintf("Error getting PreExecRule %v: %v", clone.Spec.PreExecRule, err)
-					log.ApplicationCloneLog(clone).Errorf(message)
+					log.ApplicationCloneLog(clone).Error(message)
 					a.Recorder.Event(clone,
 						v1.EventTypeWarning,
 						string(stork_api.ApplicationCloneStatusFailed),
@@ -180,7 +211,7 @@ func (a *ApplicationCloneController) Handle(ctx context.Context, event sdk.Event
 				_, err := storkops.Instance().GetRule(clone.Spec.PostExecRule, clone.Namespace)
 				if err != nil {
 					message := fmt.Sprintf("Error getting PostExecRule %v: %v", clone.Spec.PostExecRule, err)
-					log.ApplicationCloneLog(clone).Errorf(message)
+					log.ApplicationCloneLog(clone).Error(message)
 					a.Recorder.Event(clone,
 						v1.EventTypeWarning,
 						string(stork_api.ApplicationCloneStatusFailed),
@@ -188,12 +219,27 @@ func (a *ApplicationCloneController) Handle(ctx context.Context, event sdk.Event
 					return nil
 				}
 			}
+			// Wait for other operations to complete if the limits for
+			// concurrent operations have been reached
+			queued, err := a.queueClone(clone)
+			if err != nil {
+				message := fmt.Sprintf("Error queueing clone: %v", err)
+				log.ApplicationCloneLog(clone).Error(message)
+				a.Recorder.Event(clone,
+					v1.EventTypeWarning,
+					string(stork_api.ApplicationCloneStatusFailed),
+					message)
+				return nil
+			}
+			if queued {
+				return nil
+			}
 			fallthrough
 		case stork_api.ApplicationCloneStagePreExecRule:
 			terminationChannel, err = a.runPreExecRule(clone)
 			if err != nil {
 				message := fmt.Sprintf("Error running PreExecRule: %v", err)
-				log.ApplicationCloneLog(clone).Errorf(message)
+				log.ApplicationCloneLog(clone).Error(message)
 				a.Recorder.Event(clone,
 					v1.EventTypeWarning,
 					string(stork_api.ApplicationCloneStatusFailed),
@@ -211,7 +257,7 @@ func (a *ApplicationCloneController) Handle(ctx context.Context, event sdk.Event
 			err := a.cloneVolumes(clone, terminationChannel)
 			if err != nil {
 				message := fmt.Sprintf("Error cloning volumes: %v", err)
-				log.ApplicationCloneLog(clone).Errorf(message)
+				log.ApplicationCloneLog(clone).Error(message)
 				a.Recorder.Event(clone,
 					v1.EventTypeWarning,
 					string(stork_api.ApplicationCloneStatusFailed),
@@ -222,7 +268,7 @@ func (a *ApplicationCloneController) Handle(ctx context.Context, event sdk.Event
 			err := a.cloneResources(clone)
 			if err != nil {
 				message := fmt.Sprintf("Error cloning resources: %v", err)
-				log.ApplicationCloneLog(clone).Errorf(message)
+				log.ApplicationCloneLog(clone).Error(message)
 				a.Recorder.Event(clone,
 					v1.EventTypeWarning,
 					string(stork_api.ApplicationCloneStatusFailed),
@@ -246,6 +292,66 @@ func (a *ApplicationCloneController) namespaceCloneAllowed(clone *stork_api.Appl
 	return clone.Namespace == a.adminNamespace
 }
 
+// getQueueOperation returns the operation used to limit the number of
+// concurrent clones. The volumes are counted from the PVCs to be cloned if the
+// clone hasn't started yet
+func (a *ApplicationCloneController) getQueueOperation(clone *stork_api.ApplicationClone) (*queue.Operation, error) {
+	op := &queue.Operation{
+		Kind:              "ApplicationClone",
+		Namespace:         clone.Namespace,
+		Name:              clone.Name,
+		UID:               clone.UID,
+		Priority:          clone.Spec.Priority,
+		CreationTimestamp: clone.CreationTimestamp.Time,
+		Volumes:           make(map[string]int),
+	}
+	driverName := a.Driver.String()
+	if clone.Status.Volumes != nil {
+		if len(clone.Status.Volumes) != 0 {
+			op.Volumes[driverName] = len(clone.Status.Volumes)
+		}
+		return op, nil
+	}
+	volumes, err := queue.GetPVCVolumes([]string{clone.Spec.SourceNamespace}, clone.Spec.Selectors)
+	if err != nil {
+		return nil, err
+	}
+	if volumes[driverName] != 0 {
+		op.Volumes[driverName] = volumes[driverName]
+	}
+	return op, nil
+}
+
+// queueClone checks whether the clone can start. Returns true if the clone has
+// to wait for other operations to complete, in which case its status is set to
+// Queued along with its position in the queue
+func (a *ApplicationCloneController) queueClone(clone *stork_api.ApplicationClone) (bool, error) {
+	op, err := a.getQueueOperation(clone)
+	if err != nil {
+		return false, err
+	}
+	if ok, position := queue.Instance().Acquire(op); !ok {
+		if clone.Status.Status == stork_api.ApplicationCloneStatusQueued &&
+			clone.Status.QueuePosition == position {
+			return true, nil
+		}
+		if clone.Status.Status != stork_api.ApplicationCloneStatusQueued {
+			a.Recorder.Event(clone,
+				v1.EventTypeNormal,
+				string(stork_api.ApplicationCloneStatusQueued),
+				fmt.Sprintf("Clone queued at position %v since the limit for concurrent operations has been reached", position))
+		}
+		clone.Status.Status = stork_api.ApplicationCloneStatusQueued
+		clone.Status.QueuePosition = position
+		return true, sdk.Update(clone)
+	}
+	if clone.Status.Status == stork_api.ApplicationCloneStatusQueued {
+		clone.Status.Status = stork_api.ApplicationCloneStatusInitial
+		clone.Status.QueuePosition = 0
+	}
+	return false, nil
+}
+
 func (a *ApplicationCloneController) generateCloneVolumeNames(clone *stork_api.ApplicationClone) error {
 	pvcList, err := core.Instance().GetPersistentVolumeClaims(clone.Spec.SourceNamespace, clone.Spec.Selectors)
 	if err != nil {
@@ -312,7 +418,7 @@ func (a *ApplicationCloneController) cloneVolumes(clone *stork_api.ApplicationCl
 		if clone.Spec.PostExecRule != "" {
 			if err := a.runPostExecRule(clone); err != nil {
 				message := fmt.Sprintf("Error running PostExecRule: %v", err)
-				log.ApplicationCloneLog(clone).Errorf(message)
+				log.ApplicationCloneLog(clone).Error(message)
 				a.Recorder.Event(clone,
 					v1.EventTypeWarning,
 					string(stork_api.ApplicationCloneStatusFailed),
@@ -364,7 +470,7 @@ func (a *ApplicationCloneController) cloneVolumes(clone *stork_api.ApplicationCl
 		err = a.cloneResources(clone)
 		if err != nil {
 			message := fmt.Sprintf("Error cloning resources: %v", err)
-			log.ApplicationCloneLog(clone).Errorf(message)
+			log.ApplicationCloneLog(clone).Error(message)
 			a.Recorder.Event(clone,
 				v1.EventTypeWarning,
 				string(stork_api.ApplicationCloneStatusFailed),
@@ -569,6 +675,21 @@ func (a *ApplicationCloneController) resourceToBeCloned(
 	}
 }
 
+func (a *ApplicationCloneController) rebindPVCs(
+	clone *stork_api.ApplicationClone,
+	objects []runtime.Unstructured,
+) error {
+	pvcs, err := a.ResourceCollector.GetPVCsToRebind(a.dynamicInterface, objects)
+	if err != nil {
+		return err
+	}
+	if len(pvcs) == 0 {
+		return nil
+	}
+	log.ApplicationCloneLog(clone).Infof("Replacing %v existing PVCs to bind them to the cloned volumes", len(pvcs))
+	return a.ResourceCollector.DeleteResources(a.dynamicInterface, pvcs)
+}
+
 func (a *ApplicationCloneController) applyResources(
 	clone *stork_api.ApplicationClone,
 	objects []runtime.Unstructured,
@@ -584,6 +705,12 @@ func (a *ApplicationCloneController) applyResources(
 		if err != nil {
 			return err
 		}
+	} else if clone.Spec.ReplacePolicy == stork_api.ApplicationCloneReplacePolicyUpdate {
+		// Existing PVCs can't be updated to use the cloned volumes, so they
+		// need to be deleted and created again
+		if err := a.rebindPVCs(clone, objects); err != nil {
+			return err
+		}
 	}
 
 	for _, o := range objects {
@@ -598,6 +725,7 @@ func (a *ApplicationCloneController) applyResources(
 
 		log.ApplicationCloneLog(clone).Infof("Applying %v %v", objectType.GetKind(), metadata.GetName())
 		retained := false
+		updated := false
 		err = a.ResourceCollector.ApplyResource(
 			a.dynamicInterface,
 			o)
@@ -609,6 +737,17 @@ func (a *ApplicationCloneController) applyResources(
 				log.ApplicationCloneLog(clone).Warningf("Error deleting %v %v during clone, ReplacePolicy set to Retain: %v", objectType.GetKind(), metadata.GetName(), err)
 				retained = true
 				err = nil
+			case stork_api.ApplicationCloneReplacePolicyUpdate:
+				// Cluster scoped resources are shared with the source namespace
+				// so they are always retained
+				if metadata.GetNamespace() == "" {
+					break
+				}
+				log.ApplicationCloneLog(clone).Infof("Updating existing %v %v", objectType.GetKind(), metadata.GetName())
+				err = a.ResourceCollector.UpdateResource(
+					a.dynamicInterface,
+					o)
+				updated = err == nil
 			}
 			if metadata.GetNamespace() == "" {
 				retained = true
@@ -632,6 +771,14 @@ func (a *ApplicationCloneController) applyResources(
 				"Resource clone skipped as it was already present and ReplacePolicy is set to Retain"); err != nil {
 				return err
 			}
+		} else if updated {
+			if err := a.updateResourceStatus(
+				clone,
+				o,
+				stork_api.ApplicationCloneStatusSuccessful,
+				fmt.Sprintf("Resource was already present in namespace %v and was updated since ReplacePolicy is set to Update", clone.Spec.DestinationNamespace)); err != nil {
+				return err
+			}
 		} else {
 			if err := a.updateResourceStatus(
 				clone,
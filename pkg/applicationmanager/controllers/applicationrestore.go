@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"reflect"
+	"time"
 
 	"github.com/libopenstorage/stork/drivers/volume"
 	"github.com/libopenstorage/stork/pkg/apis/stork"
@@ -14,7 +15,9 @@ import (
 	"github.com/libopenstorage/stork/pkg/crypto"
 	"github.com/libopenstorage/stork/pkg/log"
 	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/libopenstorage/stork/pkg/queue"
 	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	"github.com/libopenstorage/stork/pkg/rule"
 	"github.com/operator-framework/operator-sdk/pkg/sdk"
 	"github.com/portworx/sched-ops/k8s/apiextensions"
 	"github.com/portworx/sched-ops/k8s/core"
@@ -32,6 +35,11 @@ import (
 	"k8s.io/client-go/tools/record"
 )
 
+const (
+	// Time to wait for the pods selected by the PostExecRule to be ready
+	postExecRulePodReadyTimeout = 10 * time.Minute
+)
+
 // ApplicationRestoreController reconciles applicationrestore objects
 type ApplicationRestoreController struct {
 	Recorder              record.EventRecorder
@@ -48,6 +56,9 @@ func (a *ApplicationRestoreController) Init(restoreAdminNamespace string) error
 	}
 
 	a.restoreAdminNamespace = restoreAdminNamespace
+	if err := a.trackStartedRestores(); err != nil {
+		return err
+	}
 
 	config, err := rest.InClusterConfig()
 	if err != nil {
@@ -70,13 +81,35 @@ func (a *ApplicationRestoreController) Init(restoreAdminNamespace string) error
 		a)
 }
 
+// trackStartedRestores adds the restores that were already started to the
+// queue so that they are accounted for before any queued operations can start
+func (a *ApplicationRestoreController) trackStartedRestores() error {
+	applicationRestores, err := storkops.Instance().ListApplicationRestores(v1.NamespaceAll)
+	if err != nil {
+		return fmt.Errorf("error listing application restores: %v", err)
+	}
+	for _, restore := range applicationRestores.Items {
+		if restore.Status.Stage == storkapi.ApplicationRestoreStageInitial ||
+			restore.Status.Stage == storkapi.ApplicationRestoreStageFinal {
+			continue
+		}
+		op, err := a.getQueueOperation(&restore)
+		if err != nil {
+			log.ApplicationRestoreLog(&restore).Warnf("Error getting volumes to add restore to queue: %v", err)
+			continue
+		}
+		queue.Instance().Track(op)
+	}
+	return nil
+}
+
 func (a *ApplicationRestoreController) setDefaults(restore *storkapi.ApplicationRestore) error {
 	if restore.Spec.ReplacePolicy == "" {
 		restore.Spec.ReplacePolicy = storkapi.ApplicationRestoreReplacePolicyRetain
 	}
 	// If no namespaces mappings are provided add mappings for all of them
 	if len(restore.Spec.NamespaceMapping) == 0 {
-		backup, err := storkops.Instance().GetApplicationBackup(restore.Spec.BackupName, restore.Namespace)
+		backup, err := a.getBackup(restore)
 		if err != nil {
 			return fmt.Errorf("error getting backup: %v", err)
 		}
@@ -118,6 +151,7 @@ func (a *ApplicationRestoreController) Handle(ctx context.Context, event sdk.Eve
 	case *storkapi.ApplicationRestore:
 		restore := o
 		if event.Deleted {
+			queue.Instance().Release(restore.UID)
 			drivers := a.getDriversForRestore(restore)
 
 			for driverName := range drivers {
@@ -132,7 +166,7 @@ func (a *ApplicationRestoreController) Handle(ctx context.Context, event sdk.Eve
 
 		err := a.setDefaults(restore)
 		if err != nil {
-			log.ApplicationRestoreLog(restore).Errorf(err.Error())
+			log.ApplicationRestoreLog(restore).Error(err.Error())
 			a.Recorder.Event(restore,
 				v1.EventTypeWarning,
 				string(storkapi.ApplicationRestoreStatusFailed),
@@ -142,7 +176,7 @@ func (a *ApplicationRestoreController) Handle(ctx context.Context, event sdk.Eve
 
 		err = a.verifyNamespaces(restore)
 		if err != nil {
-			log.ApplicationRestoreLog(restore).Errorf(err.Error())
+			log.ApplicationRestoreLog(restore).Error(err.Error())
 			a.Recorder.Event(restore,
 				v1.EventTypeWarning,
 				string(storkapi.ApplicationRestoreStatusFailed),
@@ -150,15 +184,50 @@ func (a *ApplicationRestoreController) Handle(ctx context.Context, event sdk.Eve
 			return nil
 		}
 
+		if _, ok := restore.Annotations[stork.RetryAnnotation]; ok {
+			return a.retryRestore(restore)
+		}
+
+		if restore.Status.Stage == storkapi.ApplicationRestoreStageFinal {
+			queue.Instance().Release(restore.UID)
+		}
+
 		switch restore.Status.Stage {
 		case storkapi.ApplicationRestoreStageInitial:
-			// Make sure the namespaces exist
+			// Make sure the rule exists if configured
+			if restore.Spec.PostExecRule != "" {
+				_, err := storkops.Instance().GetRule(restore.Spec.PostExecRule, restore.Namespace)
+				if err != nil {
+					message := fmt.Sprintf("Error getting PostExecRule %v: %v", restore.Spec.PostExecRule, err)
+					log.ApplicationRestoreLog(restore).Error(message)
+					a.Recorder.Event(restore,
+						v1.EventTypeWarning,
+						string(storkapi.ApplicationRestoreStatusFailed),
+						message)
+					return nil
+				}
+			}
+			// Wait for other operations to complete if the limits for
+			// concurrent operations have been reached
+			queued, err := a.queueRestore(restore)
+			if err != nil {
+				message := fmt.Sprintf("Error queueing restore: %v", err)
+				log.ApplicationRestoreLog(restore).Error(message)
+				a.Recorder.Event(restore,
+					v1.EventTypeWarning,
+					string(storkapi.ApplicationRestoreStatusFailed),
+					message)
+				return nil
+			}
+			if queued {
+				return nil
+			}
 			fallthrough
 		case storkapi.ApplicationRestoreStageVolumes:
 			err := a.restoreVolumes(restore)
 			if err != nil {
 				message := fmt.Sprintf("Error restoring volumes: %v", err)
-				log.ApplicationRestoreLog(restore).Errorf(message)
+				log.ApplicationRestoreLog(restore).Error(message)
 				a.Recorder.Event(restore,
 					v1.EventTypeWarning,
 					string(storkapi.ApplicationRestoreStatusFailed),
@@ -169,7 +238,7 @@ func (a *ApplicationRestoreController) Handle(ctx context.Context, event sdk.Eve
 			err := a.restoreResources(restore)
 			if err != nil {
 				message := fmt.Sprintf("Error restoring resources: %v", err)
-				log.ApplicationRestoreLog(restore).Errorf(message)
+				log.ApplicationRestoreLog(restore).Error(message)
 				a.Recorder.Event(restore,
 					v1.EventTypeWarning,
 					string(storkapi.ApplicationRestoreStatusFailed),
@@ -177,6 +246,21 @@ func (a *ApplicationRestoreController) Handle(ctx context.Context, event sdk.Eve
 				return nil
 			}
 
+		case storkapi.ApplicationRestoreStagePostExecRule:
+			// Don't run the rule again if it failed
+			if restore.Status.Status == storkapi.ApplicationRestoreStatusFailed {
+				return nil
+			}
+			err := a.runPostExecRule(restore)
+			if err != nil {
+				message := fmt.Sprintf("Error running PostExecRule: %v", err)
+				log.ApplicationRestoreLog(restore).Error(message)
+				a.Recorder.Event(restore,
+					v1.EventTypeWarning,
+					string(storkapi.ApplicationRestoreStatusFailed),
+					message)
+				return nil
+			}
 		case storkapi.ApplicationRestoreStageFinal:
 			// Do Nothing
 			return nil
@@ -211,10 +295,21 @@ func (a *ApplicationRestoreController) getDriversForRestore(restore *storkapi.Ap
 func (a *ApplicationRestoreController) restoreVolumes(restore *storkapi.ApplicationRestore) error {
 	restore.Status.Stage = storkapi.ApplicationRestoreStageVolumes
 	if restore.Status.Volumes == nil || len(restore.Status.Volumes) == 0 {
-		backup, err := storkops.Instance().GetApplicationBackup(restore.Spec.BackupName, restore.Namespace)
+		backup, err := a.getBackup(restore)
 		if err != nil {
 			return fmt.Errorf("error getting backup spec for restore: %v", err)
 		}
+		if err := a.selectBackupLocation(restore, backup); err != nil {
+			return err
+		}
+		// Use the credentials for the replica location when restoring the
+		// volumes from it
+		driverRestore := restore
+		if restore.Status.BackupLocation != backup.Spec.BackupLocation {
+			driverRestore = restore.DeepCopy()
+			driverRestore.Spec.BackupLocation = restore.Status.BackupLocation
+		}
+		backup = a.getBackupFromLocation(backup, restore.Status.BackupLocation)
 		backupVolumeInfoMappings := make(map[string][]*storkapi.ApplicationBackupVolumeInfo)
 		for _, namespace := range backup.Spec.Namespaces {
 			if _, ok := restore.Spec.NamespaceMapping[namespace]; !ok {
@@ -237,10 +332,10 @@ func (a *ApplicationRestoreController) restoreVolumes(restore *storkapi.Applicat
 				return err
 			}
 
-			restoreVolumeInfos, err := driver.StartRestore(restore, vInfos)
+			restoreVolumeInfos, err := driver.StartRestore(driverRestore, vInfos)
 			if err != nil {
 				message := fmt.Sprintf("Error starting Application Restore for volumes: %v", err)
-				log.ApplicationRestoreLog(restore).Errorf(message)
+				log.ApplicationRestoreLog(restore).Error(message)
 				a.Recorder.Event(restore,
 					v1.EventTypeWarning,
 					string(storkapi.ApplicationRestoreStatusFailed),
@@ -336,6 +431,309 @@ func (a *ApplicationRestoreController) restoreVolumes(restore *storkapi.Applicat
 	return nil
 }
 
+func (a *ApplicationRestoreController) newQueueOperation(restore *storkapi.ApplicationRestore) *queue.Operation {
+	return &queue.Operation{
+		Kind:              "ApplicationRestore",
+		Namespace:         restore.Namespace,
+		Name:              restore.Name,
+		UID:               restore.UID,
+		Priority:          restore.Spec.Priority,
+		CreationTimestamp: restore.CreationTimestamp.Time,
+		Volumes:           make(map[string]int),
+	}
+}
+
+// getQueueOperation returns the operation used to limit the number of
+// concurrent restores. The volumes are counted from the backup if the restore
+// hasn't started yet
+func (a *ApplicationRestoreController) getQueueOperation(restore *storkapi.ApplicationRestore) (*queue.Operation, error) {
+	op := a.newQueueOperation(restore)
+	if len(restore.Status.Volumes) != 0 || restore.Status.Stage != storkapi.ApplicationRestoreStageInitial {
+		for _, vInfo := range restore.Status.Volumes {
+			op.Volumes[vInfo.DriverName]++
+		}
+		return op, nil
+	}
+	backup, err := a.getBackup(restore)
+	if err != nil {
+		return nil, fmt.Errorf("error getting backup spec for restore: %v", err)
+	}
+	for _, vInfo := range backup.Status.Volumes {
+		if _, ok := restore.Spec.NamespaceMapping[vInfo.Namespace]; ok {
+			op.Volumes[vInfo.DriverName]++
+		}
+	}
+	return op, nil
+}
+
+// queueRestore checks whether the restore can start. Returns true if the
+// restore has to wait for other operations to complete, in which case its
+// status is set to Queued along with its position in the queue
+func (a *ApplicationRestoreController) queueRestore(restore *storkapi.ApplicationRestore) (bool, error) {
+	op, err := a.getQueueOperation(restore)
+	if err != nil {
+		return false, err
+	}
+	if ok, position := queue.Instance().Acquire(op); !ok {
+		if restore.Status.Status == storkapi.ApplicationRestoreStatusQueued &&
+			restore.Status.QueuePosition == position {
+			return true, nil
+		}
+		if restore.Status.Status != storkapi.ApplicationRestoreStatusQueued {
+			a.Recorder.Event(restore,
+				v1.EventTypeNormal,
+				string(storkapi.ApplicationRestoreStatusQueued),
+				fmt.Sprintf("Restore queued at position %v since the limit for concurrent operations has been reached", position))
+		}
+		restore.Status.Status = storkapi.ApplicationRestoreStatusQueued
+		restore.Status.QueuePosition = position
+		return true, sdk.Update(restore)
+	}
+	if restore.Status.Status == storkapi.ApplicationRestoreStatusQueued {
+		restore.Status.Status = storkapi.ApplicationRestoreStatusInitial
+		restore.Status.QueuePosition = 0
+	}
+	return false, nil
+}
+
+// retryRestore starts the restore again for the volumes that failed. Volumes
+// that were restored successfully are kept, and only the resources that
+// weren't restored are applied once all the volumes are done
+func (a *ApplicationRestoreController) retryRestore(restore *storkapi.ApplicationRestore) error {
+	if restore.Status.Status != storkapi.ApplicationRestoreStatusFailed &&
+		restore.Status.Status != storkapi.ApplicationRestoreStatusPartialSuccess {
+		delete(restore.Annotations, stork.RetryAnnotation)
+		message := fmt.Sprintf("Ignoring retry for restore with status %v", restore.Status.Status)
+		log.ApplicationRestoreLog(restore).Warn(message)
+		a.Recorder.Event(restore,
+			v1.EventTypeWarning,
+			string(restore.Status.Status),
+			message)
+		return sdk.Update(restore)
+	}
+	// Wait for other operations to complete if the limits for concurrent
+	// operations have been reached
+	if queued, err := a.queueRetry(restore); err != nil || queued {
+		return err
+	}
+
+	delete(restore.Annotations, stork.RetryAnnotation)
+	restore.Status.Retries++
+	failed := make([]*storkapi.ApplicationRestoreVolumeInfo, 0)
+	volumeInfos := make([]*storkapi.ApplicationRestoreVolumeInfo, 0)
+	for _, vInfo := range restore.Status.Volumes {
+		if vInfo.Status == storkapi.ApplicationRestoreStatusFailed {
+			failed = append(failed, vInfo)
+		} else {
+			volumeInfos = append(volumeInfos, vInfo)
+		}
+	}
+
+	if len(failed) != 0 {
+		infos, err := a.retryVolumeRestores(restore, failed)
+		if err != nil {
+			message := fmt.Sprintf("Error retrying restore for volumes: %v", err)
+			log.ApplicationRestoreLog(restore).Error(message)
+			a.Recorder.Event(restore,
+				v1.EventTypeWarning,
+				string(storkapi.ApplicationRestoreStatusFailed),
+				message)
+			return sdk.Update(restore)
+		}
+		restore.Status.Volumes = append(volumeInfos, infos...)
+		restore.Status.Stage = storkapi.ApplicationRestoreStageVolumes
+	} else if restore.Status.Stage != storkapi.ApplicationRestoreStagePostExecRule {
+		restore.Status.Stage = storkapi.ApplicationRestoreStageApplications
+	}
+	// Apply the stages again for the resources that weren't restored
+	if restore.Status.Stage != storkapi.ApplicationRestoreStagePostExecRule {
+		restore.Status.ApplyStages = nil
+	}
+	// Wait for the pods again before running the PostExecRule
+	restore.Status.PostExecRuleStartTimestamp = metav1.Time{}
+	restore.Status.PostExecRuleInProgress = false
+	restore.Status.PostExecRuleExecuted = false
+	restore.Status.Status = storkapi.ApplicationRestoreStatusInProgress
+	restore.Status.FinishTimestamp = metav1.Time{}
+	message := fmt.Sprintf("Retrying restore of %v failed volumes", len(failed))
+	log.ApplicationRestoreLog(restore).Info(message)
+	a.Recorder.Event(restore,
+		v1.EventTypeNormal,
+		string(storkapi.ApplicationRestoreStatusInProgress),
+		message)
+	return sdk.Update(restore)
+}
+
+// queueRetry checks whether the failed volumes of the restore can be restored
+// again. Returns true if the retry has to wait for other operations to
+// complete, in which case its position in the queue is saved and the retry
+// annotation is kept so that it is checked again on the next update
+func (a *ApplicationRestoreController) queueRetry(restore *storkapi.ApplicationRestore) (bool, error) {
+	op := a.newQueueOperation(restore)
+	for _, vInfo := range restore.Status.Volumes {
+		if vInfo.Status == storkapi.ApplicationRestoreStatusFailed {
+			op.Volumes[vInfo.DriverName]++
+		}
+	}
+	ok, position := queue.Instance().Acquire(op)
+	if ok {
+		restore.Status.QueuePosition = 0
+		return false, nil
+	}
+	if restore.Status.QueuePosition == position {
+		return true, nil
+	}
+	if restore.Status.QueuePosition == 0 {
+		a.Recorder.Event(restore,
+			v1.EventTypeNormal,
+			string(storkapi.ApplicationRestoreStatusQueued),
+			fmt.Sprintf("Retry queued at position %v since the limit for concurrent operations has been reached", position))
+	}
+	restore.Status.QueuePosition = position
+	return true, sdk.Update(restore)
+}
+
+func (a *ApplicationRestoreController) retryVolumeRestores(
+	restore *storkapi.ApplicationRestore,
+	failed []*storkapi.ApplicationRestoreVolumeInfo,
+) ([]*storkapi.ApplicationRestoreVolumeInfo, error) {
+	backup, err := a.getBackup(restore)
+	if err != nil {
+		return nil, fmt.Errorf("error getting backup spec for restore: %v", err)
+	}
+	driverRestore := restore
+	if restore.Status.BackupLocation != "" && restore.Status.BackupLocation != backup.Spec.BackupLocation {
+		driverRestore = restore.DeepCopy()
+		driverRestore.Spec.BackupLocation = restore.Status.BackupLocation
+	}
+	backup = a.getBackupFromLocation(backup, restore.Status.BackupLocation)
+
+	backupVolumeInfoMappings := make(map[string][]*storkapi.ApplicationBackupVolumeInfo)
+	for _, vInfo := range failed {
+		for _, volumeBackup := range backup.Status.Volumes {
+			if volumeBackup.Namespace == vInfo.SourceNamespace &&
+				volumeBackup.PersistentVolumeClaim == vInfo.PersistentVolumeClaim {
+				backupVolumeInfoMappings[volumeBackup.DriverName] = append(backupVolumeInfoMappings[volumeBackup.DriverName], volumeBackup)
+				break
+			}
+		}
+	}
+
+	volumeInfos := make([]*storkapi.ApplicationRestoreVolumeInfo, 0)
+	for driverName, vInfos := range backupVolumeInfoMappings {
+		driver, err := volume.Get(driverName)
+		if err != nil {
+			return nil, err
+		}
+		restoreVolumeInfos, err := driver.StartRestore(driverRestore, vInfos)
+		if err != nil {
+			return nil, err
+		}
+		for _, vInfo := range restoreVolumeInfos {
+			vInfo.Retries = restore.Status.Retries
+		}
+		volumeInfos = append(volumeInfos, restoreVolumeInfos...)
+	}
+	return volumeInfos, nil
+}
+
+// getBackup returns the backup to restore from. If a backup path has been
+// specified the backup is loaded from its metadata in the backup location,
+// otherwise the ApplicationBackup object is used
+func (a *ApplicationRestoreController) getBackup(
+	restore *storkapi.ApplicationRestore,
+) (*storkapi.ApplicationBackup, error) {
+	if restore.Spec.BackupPath == "" {
+		return storkops.Instance().GetApplicationBackup(restore.Spec.BackupName, restore.Namespace)
+	}
+	backupLocation, err := storkops.Instance().GetBackupLocation(restore.Spec.BackupLocation, restore.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	// Only allow backups from the namespace of the location to be restored
+	if err := objectstore.ValidateBackupPath(backupLocation.Namespace, restore.Spec.BackupPath); err != nil {
+		return nil, err
+	}
+	backup, err := objectstore.GetBackup(backupLocation, restore.Spec.BackupPath)
+	if err != nil {
+		return nil, err
+	}
+	// The backup could have been copied to this location from another one
+	// or the location could have a different name on this cluster, so
+	// always restore from the specified location
+	backup.Spec.BackupLocation = restore.Spec.BackupLocation
+	backup.Status.Replicas = nil
+	return backup, nil
+}
+
+// selectBackupLocation selects the location to restore the backup from. The
+// primary location of the backup is used if it is reachable, otherwise the
+// first replica location that the backup was copied to
+func (a *ApplicationRestoreController) selectBackupLocation(
+	restore *storkapi.ApplicationRestore,
+	backup *storkapi.ApplicationBackup,
+) error {
+	if restore.Status.BackupLocation != "" {
+		return nil
+	}
+	primaryErr := a.checkBackupInLocation(backup.Spec.BackupLocation, backup.Status.BackupPath, restore.Namespace)
+	if primaryErr == nil {
+		restore.Status.BackupLocation = backup.Spec.BackupLocation
+		return nil
+	}
+	for _, replica := range backup.Status.Replicas {
+		if replica.Status != storkapi.ApplicationBackupStatusSuccessful {
+			continue
+		}
+		if err := a.checkBackupInLocation(replica.BackupLocation, replica.BackupPath, restore.Namespace); err != nil {
+			log.ApplicationRestoreLog(restore).Warnf("Error accessing backup in replica location %v: %v", replica.BackupLocation, err)
+			continue
+		}
+		restore.Status.BackupLocation = replica.BackupLocation
+		a.Recorder.Event(restore,
+			v1.EventTypeNormal,
+			string(storkapi.ApplicationRestoreStatusInProgress),
+			fmt.Sprintf("Restoring from replica location %v since backup location %v is unreachable: %v",
+				replica.BackupLocation, backup.Spec.BackupLocation, primaryErr))
+		return nil
+	}
+	return fmt.Errorf("error accessing backup in location %v: %v", backup.Spec.BackupLocation, primaryErr)
+}
+
+func (a *ApplicationRestoreController) checkBackupInLocation(
+	locationName string,
+	backupPath string,
+	namespace string,
+) error {
+	backupLocation, err := storkops.Instance().GetBackupLocation(locationName, namespace)
+	if err != nil {
+		return err
+	}
+	bucket, err := objectstore.GetBucket(backupLocation)
+	if err != nil {
+		return err
+	}
+	_, err = bucket.Attributes(context.TODO(), filepath.Join(backupPath, objectstore.BackupMetadataObjectName))
+	return err
+}
+
+// getBackupFromLocation returns the backup as seen from the given location,
+// which could be one of its replica locations
+func (a *ApplicationRestoreController) getBackupFromLocation(
+	backup *storkapi.ApplicationBackup,
+	locationName string,
+) *storkapi.ApplicationBackup {
+	if locationName == "" || locationName == backup.Spec.BackupLocation {
+		return backup
+	}
+	for _, replica := range backup.Status.Replicas {
+		if replica.BackupLocation == locationName {
+			return getReplicaBackup(backup, replica)
+		}
+	}
+	return backup
+}
+
 func (a *ApplicationRestoreController) downloadObject(
 	backup *storkapi.ApplicationBackup,
 	backupLocation string,
@@ -386,29 +784,84 @@ func (a *ApplicationRestoreController) downloadResources(
 	return runtimeObjects, nil
 }
 
+// downloadSecrets downloads the Secrets that were stored separately for a
+// backup with SecretsMode set to Encrypt and decrypts them with the
+// SecretsEncryptionKey for the restore
+func (a *ApplicationRestoreController) downloadSecrets(
+	restore *storkapi.ApplicationRestore,
+	backup *storkapi.ApplicationBackup,
+) ([]runtime.Unstructured, error) {
+	keySelector := restore.Spec.SecretsEncryptionKey
+	if keySelector == nil {
+		keySelector = backup.Spec.SecretsEncryptionKey
+	}
+	key, err := getSecretsEncryptionKey(keySelector, restore.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	data, err := a.downloadObject(backup, restore.Spec.BackupLocation, restore.Namespace, secretsObjectName)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = crypto.Decrypt(data, key); err != nil {
+		return nil, fmt.Errorf("error decrypting secrets: %v", err)
+	}
+
+	objects := make([]*unstructured.Unstructured, 0)
+	if err = json.Unmarshal(data, &objects); err != nil {
+		return nil, err
+	}
+	runtimeObjects := make([]runtime.Unstructured, 0)
+	for _, o := range objects {
+		runtimeObjects = append(runtimeObjects, o)
+	}
+	return runtimeObjects, nil
+}
+
+// skipSecrets marks the Secrets in the backup as skipped when they can't be
+// restored
+func (a *ApplicationRestoreController) skipSecrets(
+	restore *storkapi.ApplicationRestore,
+	backup *storkapi.ApplicationBackup,
+	reason string,
+) error {
+	for _, resource := range backup.Status.Resources {
+		if resource.Group != "core" || resource.Kind != "Secret" {
+			continue
+		}
+		namespace := resource.Namespace
+		if mapped, ok := restore.Spec.NamespaceMapping[namespace]; ok {
+			namespace = mapped
+		}
+		object := &unstructured.Unstructured{}
+		object.SetAPIVersion(resource.Version)
+		object.SetKind(resource.Kind)
+		object.SetName(resource.Name)
+		object.SetNamespace(namespace)
+		if err := a.updateResourceStatus(
+			restore,
+			object,
+			storkapi.ApplicationRestoreStatusSkipped,
+			reason); err != nil {
+			return err
+		}
+	}
+	return sdk.Update(restore)
+}
+
 func (a *ApplicationRestoreController) updateResourceStatus(
 	restore *storkapi.ApplicationRestore,
 	object runtime.Unstructured,
 	status storkapi.ApplicationRestoreStatusType,
 	reason string,
 ) error {
-	var updatedResource *storkapi.ApplicationRestoreResourceInfo
 	gkv := object.GetObjectKind().GroupVersionKind()
 	metadata, err := meta.Accessor(object)
 	if err != nil {
 		log.ApplicationRestoreLog(restore).Errorf("Error getting metadata for object %v %v", object, err)
 		return err
 	}
-	for _, resource := range restore.Status.Resources {
-		if resource.Name == metadata.GetName() &&
-			resource.Namespace == metadata.GetNamespace() &&
-			(resource.Group == gkv.Group || (resource.Group == "core" && gkv.Group == "")) &&
-			resource.Version == gkv.Version &&
-			resource.Kind == gkv.Kind {
-			updatedResource = resource
-			break
-		}
-	}
+	updatedResource := getRestoreResourceInfo(restore, metadata, gkv)
 	if updatedResource == nil {
 		updatedResource = &storkapi.ApplicationRestoreResourceInfo{
 			Name:      metadata.GetName(),
@@ -437,6 +890,45 @@ func (a *ApplicationRestoreController) updateResourceStatus(
 	return nil
 }
 
+func getRestoreResourceInfo(
+	restore *storkapi.ApplicationRestore,
+	metadata metav1.Object,
+	gkv schema.GroupVersionKind,
+) *storkapi.ApplicationRestoreResourceInfo {
+	for _, resource := range restore.Status.Resources {
+		if resource.Name == metadata.GetName() &&
+			resource.Namespace == metadata.GetNamespace() &&
+			(resource.Group == gkv.Group || (resource.Group == "core" && gkv.Group == "")) &&
+			resource.Version == gkv.Version &&
+			resource.Kind == gkv.Kind {
+			return resource
+		}
+	}
+	return nil
+}
+
+// getResourcesToRetry returns the objects that failed to be restored or
+// weren't applied at all in the previous attempts of the restore
+func (a *ApplicationRestoreController) getResourcesToRetry(
+	restore *storkapi.ApplicationRestore,
+	objects []runtime.Unstructured,
+) ([]runtime.Unstructured, error) {
+	retryObjects := make([]runtime.Unstructured, 0)
+	for _, o := range objects {
+		metadata, err := meta.Accessor(o)
+		if err != nil {
+			return nil, err
+		}
+		resource := getRestoreResourceInfo(restore, metadata, o.GetObjectKind().GroupVersionKind())
+		if resource == nil ||
+			resource.Status == storkapi.ApplicationRestoreStatusFailed ||
+			resource.Status == storkapi.ApplicationRestoreStatusSkipped {
+			retryObjects = append(retryObjects, o)
+		}
+	}
+	return retryObjects, nil
+}
+
 func (a *ApplicationRestoreController) getPVNameMappings(
 	restore *storkapi.ApplicationRestore,
 	objects []runtime.Unstructured,
@@ -454,14 +946,32 @@ func (a *ApplicationRestoreController) getPVNameMappings(
 	return pvNameMappings, nil
 }
 
-func (a *ApplicationRestoreController) applyResources(
+func (a *ApplicationRestoreController) rebindPVCs(
 	restore *storkapi.ApplicationRestore,
 	objects []runtime.Unstructured,
 ) error {
-	pvNameMappings, err := a.getPVNameMappings(restore, objects)
+	pvcs, err := a.ResourceCollector.GetPVCsToRebind(a.dynamicInterface, objects)
 	if err != nil {
 		return err
 	}
+	if len(pvcs) == 0 {
+		return nil
+	}
+	log.ApplicationRestoreLog(restore).Infof("Replacing %v existing PVCs to bind them to the restored volumes", len(pvcs))
+	return a.ResourceCollector.DeleteResources(a.dynamicInterface, pvcs)
+}
+
+// applyResources applies the objects in stages. Returns true if a stage is
+// waiting for its objects to be ready, in which case it should be called again
+// on the next update to continue with the remaining stages
+func (a *ApplicationRestoreController) applyResources(
+	restore *storkapi.ApplicationRestore,
+	objects []runtime.Unstructured,
+) (bool, error) {
+	pvNameMappings, err := a.getPVNameMappings(restore, objects)
+	if err != nil {
+		return false, err
+	}
 
 	for _, o := range objects {
 		err = a.ResourceCollector.PrepareResourceForApply(
@@ -469,96 +979,273 @@ func (a *ApplicationRestoreController) applyResources(
 			restore.Spec.NamespaceMapping,
 			pvNameMappings)
 		if err != nil {
-			return err
+			return false, err
 		}
 	}
 
-	// First delete the existing objects if they exist and replace policy is set
-	// to Delete
-	if restore.Spec.ReplacePolicy == storkapi.ApplicationRestoreReplacePolicyDelete {
-		err = a.ResourceCollector.DeleteResources(
-			a.dynamicInterface,
-			objects)
-		if err != nil {
-			return err
+	// Only apply the resources that weren't restored when retrying
+	if restore.Status.Retries > 0 {
+		if objects, err = a.getResourcesToRetry(restore, objects); err != nil {
+			return false, err
+		}
+	}
+
+	stages, err := resourcecollector.GroupObjectsByApplyStage(objects)
+	if err != nil {
+		return false, err
+	}
+	if restore.Status.ApplyStages == nil {
+		// First delete the existing objects if they exist and replace policy
+		// is set to Delete
+		if restore.Spec.ReplacePolicy == storkapi.ApplicationRestoreReplacePolicyDelete {
+			err = a.ResourceCollector.DeleteResources(
+				a.dynamicInterface,
+				objects)
+			if err != nil {
+				return false, err
+			}
+		} else if restore.Spec.ReplacePolicy == storkapi.ApplicationRestoreReplacePolicyUpdate {
+			// Existing PVCs can't be updated to use the restored volumes, so
+			// they need to be deleted and created again
+			if err := a.rebindPVCs(restore, objects); err != nil {
+				return false, err
+			}
 		}
+		restore.Status.ApplyStages = resourcecollector.NewApplyStageInfos(stages)
+	}
+
+	stageObjects := make(map[storkapi.ApplyStageType][]runtime.Unstructured)
+	for _, stage := range stages {
+		stageObjects[stage.Stage] = stage.Objects
+	}
+	// Continue with the stages that haven't completed in case the restore
+	// was waiting for resources to be ready
+	for _, stageInfo := range restore.Status.ApplyStages {
+		switch stageInfo.Status {
+		case storkapi.ApplyStageStatusSuccessful, storkapi.ApplyStageStatusFailed:
+			continue
+		case storkapi.ApplyStageStatusWaiting:
+			if waiting, err := a.checkApplyStageReady(restore, stageInfo); err != nil || waiting {
+				return waiting, err
+			}
+			continue
+		}
+		if err := a.applyStage(restore, stageInfo, stageObjects[stageInfo.Stage]); err != nil {
+			return false, err
+		}
+		if stageInfo.Status == storkapi.ApplyStageStatusWaiting {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// applyStage applies the objects in a stage. If a readiness gate is
+// configured for the stage it is left waiting for the objects to be ready,
+// which is checked on the following updates
+func (a *ApplicationRestoreController) applyStage(
+	restore *storkapi.ApplicationRestore,
+	stageInfo *storkapi.ApplyStageInfo,
+	objects []runtime.Unstructured,
+) error {
+	stageInfo.Status = storkapi.ApplyStageStatusInProgress
+	stageInfo.Reason = "Applying resources"
+	stageInfo.StartTimestamp = metav1.Now()
+	if err := sdk.Update(restore); err != nil {
+		return err
 	}
 
 	for _, o := range objects {
-		metadata, err := meta.Accessor(o)
-		if err != nil {
+		if err := a.applyResource(restore, o); err != nil {
 			return err
 		}
-		objectType, err := meta.TypeAccessor(o)
-		if err != nil {
-			return err
+		stageInfo.AppliedResources++
+	}
+
+	if _, ok := resourcecollector.GetReadinessTimeout(restore.Spec.ReadinessGates, stageInfo.Stage); ok {
+		stageInfo.Status = storkapi.ApplyStageStatusWaiting
+		stageInfo.Reason = "Waiting for resources to be ready"
+		return sdk.Update(restore)
+	}
+
+	stageInfo.Status = storkapi.ApplyStageStatusSuccessful
+	stageInfo.Reason = "Resources applied successfully"
+	stageInfo.FinishTimestamp = metav1.Now()
+	return sdk.Update(restore)
+}
+
+// checkApplyStageReady checks if the resources restored in a stage that is
+// waiting for its readiness gate are ready. Returns true if the stage needs to
+// keep waiting. The stage is marked as failed if the resources aren't ready
+// before the gate times out, and the restore carries on with the next stage
+func (a *ApplicationRestoreController) checkApplyStageReady(
+	restore *storkapi.ApplicationRestore,
+	stageInfo *storkapi.ApplyStageInfo,
+) (bool, error) {
+	objects := make([]runtime.Unstructured, 0)
+	for _, resource := range restore.Status.Resources {
+		if resource.Status != storkapi.ApplicationRestoreStatusSuccessful &&
+			resource.Status != storkapi.ApplicationRestoreStatusRetained {
+			continue
+		}
+		gvk := schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: resource.Kind}
+		object := resourcecollector.NewObjectReference(gvk, resource.Namespace, resource.Name)
+		if stage, err := resourcecollector.GetApplyStage(object); err != nil {
+			return false, err
+		} else if stage == stageInfo.Stage {
+			objects = append(objects, object)
 		}
+	}
 
-		log.ApplicationRestoreLog(restore).Infof("Applying %v %v", objectType.GetKind(), metadata.GetName())
-		retained := false
-
-		err = a.ResourceCollector.ApplyResource(
-			a.dynamicInterface,
-			o)
-		if err != nil && errors.IsAlreadyExists(err) {
-			switch restore.Spec.ReplacePolicy {
-			case storkapi.ApplicationRestoreReplacePolicyDelete:
-				log.ApplicationRestoreLog(restore).Errorf("Error deleting %v %v during restore: %v", objectType.GetKind(), metadata.GetName(), err)
-			case storkapi.ApplicationRestoreReplacePolicyRetain:
-				log.ApplicationRestoreLog(restore).Warningf("Error deleting %v %v during restore, ReplacePolicy set to Retain: %v", objectType.GetKind(), metadata.GetName(), err)
-				retained = true
-				err = nil
-			}
+	if err := a.ResourceCollector.CheckResourcesReady(a.dynamicInterface, objects); err != nil {
+		timeout, _ := resourcecollector.GetReadinessTimeout(restore.Spec.ReadinessGates, stageInfo.Stage)
+		if !resourcecollector.ReadinessTimedOut(stageInfo, timeout) {
+			log.ApplicationRestoreLog(restore).Infof("Waiting for resources in stage %v to be ready: %v", stageInfo.Stage, err)
+			return true, nil
 		}
+		message := fmt.Sprintf("Readiness gate for stage %v failed: timed out waiting for resources to be ready: %v", stageInfo.Stage, err)
+		log.ApplicationRestoreLog(restore).Error(message)
+		a.Recorder.Event(restore,
+			v1.EventTypeWarning,
+			string(storkapi.ApplyStageStatusFailed),
+			message)
+		stageInfo.Status = storkapi.ApplyStageStatusFailed
+		stageInfo.Reason = err.Error()
+	} else {
+		stageInfo.Status = storkapi.ApplyStageStatusSuccessful
+		stageInfo.Reason = "Resources applied successfully"
+	}
+	stageInfo.FinishTimestamp = metav1.Now()
+	return false, sdk.Update(restore)
+}
 
-		if err != nil {
-			if err := a.updateResourceStatus(
-				restore,
-				o,
-				storkapi.ApplicationRestoreStatusFailed,
-				fmt.Sprintf("Error applying resource: %v", err)); err != nil {
-				return err
-			}
-		} else if retained {
-			if err := a.updateResourceStatus(
-				restore,
-				o,
-				storkapi.ApplicationRestoreStatusRetained,
-				"Resource restore skipped as it was already present and ReplacePolicy is set to Retain"); err != nil {
-				return err
-			}
-		} else {
-			if err := a.updateResourceStatus(
-				restore,
-				o,
-				storkapi.ApplicationRestoreStatusSuccessful,
-				"Resource restored successfully"); err != nil {
-				return err
-			}
+func (a *ApplicationRestoreController) applyResource(
+	restore *storkapi.ApplicationRestore,
+	o runtime.Unstructured,
+) error {
+	metadata, err := meta.Accessor(o)
+	if err != nil {
+		return err
+	}
+	objectType, err := meta.TypeAccessor(o)
+	if err != nil {
+		return err
+	}
+
+	log.ApplicationRestoreLog(restore).Infof("Applying %v %v", objectType.GetKind(), metadata.GetName())
+	retained := false
+	updated := false
+
+	err = a.ResourceCollector.ApplyResource(
+		a.dynamicInterface,
+		o)
+	if err != nil && errors.IsAlreadyExists(err) {
+		switch restore.Spec.ReplacePolicy {
+		case storkapi.ApplicationRestoreReplacePolicyDelete:
+			log.ApplicationRestoreLog(restore).Errorf("Error deleting %v %v during restore: %v", objectType.GetKind(), metadata.GetName(), err)
+		case storkapi.ApplicationRestoreReplacePolicyRetain:
+			log.ApplicationRestoreLog(restore).Warningf("Error deleting %v %v during restore, ReplacePolicy set to Retain: %v", objectType.GetKind(), metadata.GetName(), err)
+			retained = true
+			err = nil
+		case storkapi.ApplicationRestoreReplacePolicyUpdate:
+			log.ApplicationRestoreLog(restore).Infof("Updating existing %v %v", objectType.GetKind(), metadata.GetName())
+			err = a.ResourceCollector.UpdateResource(
+				a.dynamicInterface,
+				o)
+			updated = err == nil
 		}
 	}
-	return nil
+
+	if err != nil {
+		return a.updateResourceStatus(
+			restore,
+			o,
+			storkapi.ApplicationRestoreStatusFailed,
+			fmt.Sprintf("Error applying resource: %v", err))
+	} else if retained {
+		return a.updateResourceStatus(
+			restore,
+			o,
+			storkapi.ApplicationRestoreStatusRetained,
+			"Resource restore skipped as it was already present and ReplacePolicy is set to Retain")
+	} else if updated {
+		return a.updateResourceStatus(
+			restore,
+			o,
+			storkapi.ApplicationRestoreStatusSuccessful,
+			"Resource was already present and was updated since ReplacePolicy is set to Update")
+	}
+	return a.updateResourceStatus(
+		restore,
+		o,
+		storkapi.ApplicationRestoreStatusSuccessful,
+		"Resource restored successfully")
 }
 
 func (a *ApplicationRestoreController) restoreResources(
 	restore *storkapi.ApplicationRestore,
 ) error {
-	backup, err := storkops.Instance().GetApplicationBackup(restore.Spec.BackupName, restore.Namespace)
+	// Check if the stage that was applied last is ready before downloading
+	// the resources again
+	for _, stageInfo := range restore.Status.ApplyStages {
+		if stageInfo.Status != storkapi.ApplyStageStatusWaiting {
+			continue
+		}
+		if waiting, err := a.checkApplyStageReady(restore, stageInfo); err != nil || waiting {
+			return err
+		}
+	}
+
+	backup, err := a.getBackup(restore)
 	if err != nil {
 		log.ApplicationRestoreLog(restore).Errorf("Error getting backup: %v", err)
 		return err
 	}
 
+	backup = a.getBackupFromLocation(backup, restore.Status.BackupLocation)
 	objects, err := a.downloadResources(backup, restore.Spec.BackupLocation, restore.Namespace)
 	if err != nil {
 		log.ApplicationRestoreLog(restore).Errorf("Error downloading resources: %v", err)
 		return err
 	}
 
-	if err := a.applyResources(restore, objects); err != nil {
+	// Secrets that were encrypted separately are skipped if they can't be
+	// decrypted, the rest of the resources are still restored
+	if backup.Spec.SecretsMode == storkapi.ApplicationBackupSecretsModeEncrypt {
+		secrets, err := a.downloadSecrets(restore, backup)
+		if err != nil {
+			message := fmt.Sprintf("Skipping restore of secrets: %v", err)
+			log.ApplicationRestoreLog(restore).Warn(message)
+			a.Recorder.Event(restore,
+				v1.EventTypeWarning,
+				string(storkapi.ApplicationRestoreStatusSkipped),
+				message)
+			if err := a.skipSecrets(restore, backup, message); err != nil {
+				return err
+			}
+		} else {
+			objects = append(objects, secrets...)
+		}
+	}
+
+	if waiting, err := a.applyResources(restore, objects); err != nil || waiting {
 		return err
 	}
 
+	if restore.Spec.PostExecRule != "" {
+		restore.Status.Stage = storkapi.ApplicationRestoreStagePostExecRule
+		restore.Status.Status = storkapi.ApplicationRestoreStatusInProgress
+		if err := sdk.Update(restore); err != nil {
+			return err
+		}
+		return a.runPostExecRule(restore)
+	}
+	return a.finishRestore(restore)
+}
+
+func (a *ApplicationRestoreController) finishRestore(
+	restore *storkapi.ApplicationRestore,
+) error {
 	restore.Status.Stage = storkapi.ApplicationRestoreStageFinal
 	restore.Status.FinishTimestamp = metav1.Now()
 	restore.Status.Status = storkapi.ApplicationRestoreStatusSuccessful
@@ -568,11 +1255,127 @@ func (a *ApplicationRestoreController) restoreResources(
 			break
 		}
 	}
+	for _, stage := range restore.Status.ApplyStages {
+		if stage.Status == storkapi.ApplyStageStatusFailed {
+			restore.Status.Status = storkapi.ApplicationRestoreStatusPartialSuccess
+			break
+		}
+	}
+
+	if err := sdk.Update(restore); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// runPostExecRule checks if the pods selected by the PostExecRule are ready in
+// the restored namespaces and runs the rule once they are. The pods are
+// checked again on the next update if they aren't ready yet. The restore is
+// marked as failed if the rule fails, or if the pods aren't ready in time
+func (a *ApplicationRestoreController) runPostExecRule(
+	restore *storkapi.ApplicationRestore,
+) error {
+	if restore.Status.PostExecRuleExecuted {
+		return a.finishRestore(restore)
+	}
+	// The rule might have been executed partially if the restore was
+	// interrupted, so don't run it again unless the restore is retried
+	if restore.Status.PostExecRuleInProgress {
+		return a.failPostExecRule(restore, fmt.Errorf("restore was interrupted while executing the rule"))
+	}
+
+	if restore.Status.PostExecRuleStartTimestamp.IsZero() {
+		a.Recorder.Event(restore,
+			v1.EventTypeNormal,
+			string(storkapi.ApplicationRestoreStatusInProgress),
+			fmt.Sprintf("Waiting for pods to be ready to run PostExecRule %v", restore.Spec.PostExecRule))
+		restore.Status.PostExecRuleStartTimestamp = metav1.Now()
+		if err := sdk.Update(restore); err != nil {
+			return err
+		}
+	}
+
+	namespaces := make(map[string]bool)
+	for _, ns := range restore.Spec.NamespaceMapping {
+		namespaces[ns] = true
+	}
+
+	r, err := storkops.Instance().GetRule(restore.Spec.PostExecRule, restore.Namespace)
+	if err != nil {
+		return a.failPostExecRule(restore, err)
+	}
+	if err := a.checkRulePods(r, namespaces); err != nil {
+		if time.Since(restore.Status.PostExecRuleStartTimestamp.Time) < postExecRulePodReadyTimeout {
+			log.ApplicationRestoreLog(restore).Infof("Waiting for pods to be ready to run PostExecRule: %v", err)
+			return nil
+		}
+		return a.failPostExecRule(restore, fmt.Errorf("timed out waiting for pods to be ready: %v", err))
+	}
 
+	restore.Status.PostExecRuleInProgress = true
 	if err := sdk.Update(restore); err != nil {
 		return err
 	}
+	for ns := range namespaces {
+		if _, err = rule.ExecuteRule(r, rule.PostExecRule, restore, ns); err != nil {
+			return a.failPostExecRule(restore, fmt.Errorf("error executing PostExecRule for namespace %v: %v", ns, err))
+		}
+	}
+	restore.Status.PostExecRuleInProgress = false
+	restore.Status.PostExecRuleExecuted = true
+
+	a.Recorder.Event(restore,
+		v1.EventTypeNormal,
+		string(storkapi.ApplicationRestoreStatusSuccessful),
+		fmt.Sprintf("PostExecRule %v completed successfully", restore.Spec.PostExecRule))
+	return a.finishRestore(restore)
+}
+
+func (a *ApplicationRestoreController) failPostExecRule(
+	restore *storkapi.ApplicationRestore,
+	err error,
+) error {
+	message := fmt.Sprintf("Error running PostExecRule: %v", err)
+	log.ApplicationRestoreLog(restore).Error(message)
+	a.Recorder.Event(restore,
+		v1.EventTypeWarning,
+		string(storkapi.ApplicationRestoreStatusFailed),
+		message)
+	restore.Status.Stage = storkapi.ApplicationRestoreStagePostExecRule
+	restore.Status.FinishTimestamp = metav1.Now()
+	restore.Status.Status = storkapi.ApplicationRestoreStatusFailed
+	return sdk.Update(restore)
+}
 
+// checkRulePods returns an error unless each of the pod selectors in the rule
+// matches at least one pod in the namespaces and all the matching pods are
+// ready
+func (a *ApplicationRestoreController) checkRulePods(
+	r *storkapi.Rule,
+	namespaces map[string]bool,
+) error {
+	for _, item := range r.Rules {
+		numPods := 0
+		for ns := range namespaces {
+			pods, err := core.Instance().GetPods(ns, item.PodSelector)
+			if err != nil {
+				return err
+			}
+			for _, pod := range pods.Items {
+				if pod.DeletionTimestamp != nil {
+					continue
+				}
+				if !core.Instance().IsPodReady(pod) {
+					return fmt.Errorf("pod %v/%v is not ready", pod.Namespace, pod.Name)
+				}
+				numPods++
+			}
+		}
+		if numPods == 0 {
+			return fmt.Errorf("no pods found for selector %v", item.PodSelector)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,132 @@
+// +build unittest
+
+package controllers
+
+import (
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSelectBackupLocation(t *testing.T) {
+	setupStorkOps()
+	primaryLocation := createBackupLocation(t, "primary", "")
+	replicaLocation := createBackupLocation(t, "replica", "")
+	createBackupLocation(t, "incomplete", "")
+	backupController := &ApplicationBackupController{Recorder: record.NewFakeRecorder(100)}
+	recorder := record.NewFakeRecorder(100)
+	controller := &ApplicationRestoreController{Recorder: recorder}
+
+	backup := newReplicaTestBackup(copyTestDriverName, "incomplete", "replica")
+	backup.Status.Replicas = []*stork_api.ApplicationBackupReplicaInfo{
+		{
+			BackupLocation: "incomplete",
+			BackupPath:     backup.Status.BackupPath,
+			Status:         stork_api.ApplicationBackupStatusInProgress,
+		},
+		{
+			BackupLocation: "replica",
+			BackupPath:     backup.Status.BackupPath,
+			Status:         stork_api.ApplicationBackupStatusSuccessful,
+		},
+	}
+	newRestore := func() *stork_api.ApplicationRestore {
+		return &stork_api.ApplicationRestore{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "restore",
+				Namespace: "ns",
+			},
+		}
+	}
+
+	// Nothing has been uploaded to any of the locations
+	restore := newRestore()
+	err := controller.selectBackupLocation(restore, backup)
+	require.Error(t, err, "Restore should fail without any backup")
+	require.Contains(t, err.Error(), "error accessing backup in location primary")
+	require.Empty(t, restore.Status.BackupLocation)
+
+	// The replica should be used while the primary location doesn't have
+	// the backup. Replicas that haven't completed shouldn't be used even if
+	// the metadata is present
+	for _, location := range []string{"incomplete", "replica"} {
+		err = backupController.uploadObjectToLocation(backup, location, objectstore.BackupMetadataObjectName, []byte("{}"))
+		require.NoError(t, err, "Error uploading metadata")
+	}
+	restore = newRestore()
+	err = controller.selectBackupLocation(restore, backup)
+	require.NoError(t, err, "Error selecting backup location")
+	require.Equal(t, replicaLocation.Name, restore.Status.BackupLocation)
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events, "Restoring from replica location replica")
+
+	replicaBackup := controller.getBackupFromLocation(backup, restore.Status.BackupLocation)
+	require.Equal(t, "replica", replicaBackup.Spec.BackupLocation)
+	require.Equal(t, "primary", backup.Spec.BackupLocation, "Original backup shouldn't be modified")
+
+	// The location shouldn't change once it has been selected
+	err = backupController.uploadMetadata(backup)
+	require.NoError(t, err, "Error uploading metadata")
+	err = controller.selectBackupLocation(restore, backup)
+	require.NoError(t, err, "Error selecting backup location")
+	require.Equal(t, replicaLocation.Name, restore.Status.BackupLocation)
+
+	// The primary location should be used when it has the backup
+	restore = newRestore()
+	err = controller.selectBackupLocation(restore, backup)
+	require.NoError(t, err, "Error selecting backup location")
+	require.Equal(t, primaryLocation.Name, restore.Status.BackupLocation)
+	require.Len(t, recorder.Events, 0)
+	require.Equal(t, backup, controller.getBackupFromLocation(backup, restore.Status.BackupLocation))
+}
+
+func TestCheckRulePods(t *testing.T) {
+	fakeKubeClient := kubernetes.NewSimpleClientset()
+	core.SetInstance(core.New(fakeKubeClient, fakeKubeClient.CoreV1(), fakeKubeClient.StorageV1()))
+	controller := &ApplicationRestoreController{}
+	r := &stork_api.Rule{
+		Rules: []stork_api.RuleItem{
+			{PodSelector: map[string]string{"app": "db"}},
+		},
+	}
+	namespaces := map[string]bool{"ns1": true, "ns2": true}
+
+	err := controller.checkRulePods(r, namespaces)
+	require.Error(t, err, "Rule shouldn't run without pods")
+	require.Contains(t, err.Error(), "no pods found")
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db-0",
+			Namespace: "ns2",
+			Labels:    map[string]string{"app": "db"},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodPending,
+		},
+	}
+	pod, err = core.Instance().CreatePod(pod)
+	require.NoError(t, err, "Error creating pod")
+	err = controller.checkRulePods(r, namespaces)
+	require.Error(t, err, "Rule shouldn't run while pods aren't ready")
+	require.Contains(t, err.Error(), "pod ns2/db-0 is not ready")
+
+	pod.Status.Phase = v1.PodRunning
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{
+		{
+			Name:  "db",
+			Ready: true,
+			State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+		},
+	}
+	_, err = core.Instance().UpdatePod(pod)
+	require.NoError(t, err, "Error updating pod")
+	require.NoError(t, controller.checkRulePods(r, namespaces), "Pods should be ready")
+}
@@ -0,0 +1,393 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/pkg/apis/stork"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/controller"
+	"github.com/libopenstorage/stork/pkg/errors"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/operator-framework/operator-sdk/pkg/sdk"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"gocloud.dev/blob"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// Objects used to validate a location are stored under this prefix so
+	// that they don't show up as backups during sync
+	validationObjectPrefix = ".stork-validation"
+)
+
+// BackupLocationController validates backuplocation objects
+type BackupLocationController struct {
+	Driver             volume.Driver
+	Recorder           record.EventRecorder
+	ValidationInterval time.Duration
+
+	// Hash of the location that was last validated for each backup location,
+	// used to re-validate immediately when the location is updated
+	validatedLocations     map[types.UID][32]byte
+	validatedLocationsLock sync.Mutex
+}
+
+// Init Initialize the backup location controller
+func (b *BackupLocationController) Init() error {
+	b.validatedLocations = make(map[types.UID][32]byte)
+	return controller.Register(
+		&schema.GroupVersionKind{
+			Group:   stork.GroupName,
+			Version: stork_api.SchemeGroupVersion.Version,
+			Kind:    reflect.TypeOf(stork_api.BackupLocation{}).Name(),
+		},
+		"",
+		b.ValidationInterval,
+		b)
+}
+
+// Handle updates for BackupLocation objects
+func (b *BackupLocationController) Handle(ctx context.Context, event sdk.Event) error {
+	switch o := event.Object.(type) {
+	case *stork_api.BackupLocation:
+		location := o
+		if event.Deleted {
+			b.validatedLocationsLock.Lock()
+			delete(b.validatedLocations, location.UID)
+			b.validatedLocationsLock.Unlock()
+			b.deleteCredentials(location, location.Status.CredentialStatus)
+			return nil
+		}
+
+		locationHash, err := b.getLocationHash(location)
+		if err != nil {
+			return err
+		}
+		if !b.validationRequired(location, locationHash) {
+			return nil
+		}
+
+		previousCredentials := location.Status.CredentialStatus
+		b.updateCredentials(location)
+
+		previousStatus := location.Status.Status
+		if err := b.validateLocation(location); err != nil {
+			location.Status.Status = stork_api.BackupLocationStatusError
+			location.Status.Reason = err.Error()
+			log.BackupLocationLog(location).Errorf("Error validating backup location: %v", err)
+		} else {
+			location.Status.Status = stork_api.BackupLocationStatusReady
+			location.Status.Reason = ""
+		}
+		location.Status.LastCheckedTimestamp = metav1.Now()
+
+		// Only raise events when the status changes so that periodic
+		// validations don't flood the events
+		if previousStatus != location.Status.Status {
+			if location.Status.Status == stork_api.BackupLocationStatusReady {
+				b.Recorder.Event(location,
+					v1.EventTypeNormal,
+					string(location.Status.Status),
+					"Backup location validated successfully")
+			} else {
+				b.Recorder.Event(location,
+					v1.EventTypeWarning,
+					string(location.Status.Status),
+					fmt.Sprintf("Error validating backup location: %v", location.Status.Reason))
+			}
+		}
+
+		err = sdk.Update(location)
+		b.cleanupCredentials(location, previousCredentials, err == nil)
+		if err != nil {
+			return err
+		}
+		b.validatedLocationsLock.Lock()
+		b.validatedLocations[location.UID] = locationHash
+		b.validatedLocationsLock.Unlock()
+	}
+	return nil
+}
+
+// updateCredentials creates new credentials for the location in the volume
+// driver if the config for the location has changed. The credentials that
+// were replaced are only deleted by cleanupCredentials once the status of the
+// location has been saved. Errors are reported in the credential status of
+// the location
+func (b *BackupLocationController) updateCredentials(location *stork_api.BackupLocation) {
+	if b.Driver == nil {
+		return
+	}
+	credentialStatus := &location.Status.CredentialStatus
+	previousStatus := credentialStatus.Status
+	driverName := b.Driver.String()
+
+	// Get the location through the API to merge in the config from the
+	// secret, since that is what the credentials need to be created from
+	mergedLocation, err := storkops.Instance().GetBackupLocation(location.Name, location.Namespace)
+	if err == nil {
+		if !credentialsRequired(mergedLocation) {
+			b.clearCredentials(location)
+			return
+		}
+		var locationHash [32]byte
+		if locationHash, err = b.getLocationHash(mergedLocation); err == nil {
+			configHash := hex.EncodeToString(locationHash[:])
+			if credentialStatus.Status == stork_api.BackupLocationStatusReady &&
+				credentialStatus.DriverName == driverName &&
+				credentialStatus.ConfigHash == configHash {
+				return
+			}
+
+			// Credentials created by a different driver can't be replaced
+			credentialID := credentialStatus.CredentialID
+			if credentialStatus.DriverName != driverName {
+				credentialID = ""
+			}
+			var newCredentialID string
+			newCredentialID, err = b.Driver.UpdateBackupLocationCredentials(mergedLocation, credentialID)
+			if _, ok := err.(*errors.ErrNotSupported); ok {
+				return
+			} else if err == nil {
+				credentialStatus.DriverName = driverName
+				credentialStatus.CredentialID = newCredentialID
+				credentialStatus.ConfigHash = configHash
+				credentialStatus.Status = stork_api.BackupLocationStatusReady
+				credentialStatus.Reason = ""
+			}
+		}
+	}
+	if err != nil {
+		credentialStatus.Status = stork_api.BackupLocationStatusError
+		credentialStatus.Reason = err.Error()
+		log.BackupLocationLog(location).Errorf("Error updating credentials for backup location: %v", err)
+	}
+	credentialStatus.LastUpdateTimestamp = metav1.Now()
+
+	if previousStatus != credentialStatus.Status {
+		if credentialStatus.Status == stork_api.BackupLocationStatusReady {
+			b.Recorder.Event(location,
+				v1.EventTypeNormal,
+				string(credentialStatus.Status),
+				fmt.Sprintf("Credentials %v updated in %v", credentialStatus.CredentialID, driverName))
+		} else {
+			b.Recorder.Event(location,
+				v1.EventTypeWarning,
+				string(credentialStatus.Status),
+				fmt.Sprintf("Error updating credentials: %v", credentialStatus.Reason))
+		}
+	}
+}
+
+// credentialsRequired returns false for locations that volume drivers can't
+// be given credentials for, either because they aren't object stores or
+// because they don't use static keys
+func credentialsRequired(location *stork_api.BackupLocation) bool {
+	switch location.Location.Type {
+	case stork_api.BackupLocationFilesystem:
+		return false
+	case stork_api.BackupLocationS3:
+		return location.Location.S3Config == nil ||
+			location.Location.S3Config.AuthType == stork_api.S3AuthStatic
+	}
+	return true
+}
+
+// clearCredentials removes the credentials from the status of the location
+// once its config changed to one that doesn't need them. They are deleted by
+// cleanupCredentials once the status has been saved
+func (b *BackupLocationController) clearCredentials(location *stork_api.BackupLocation) {
+	location.Status.CredentialStatus = stork_api.BackupLocationCredentialStatus{}
+}
+
+// cleanupCredentials deletes the credentials that the location no longer
+// refers to once its status has been saved. If the status couldn't be saved
+// the new credentials are deleted instead, since the location still refers to
+// the previous ones
+func (b *BackupLocationController) cleanupCredentials(
+	location *stork_api.BackupLocation,
+	previous stork_api.BackupLocationCredentialStatus,
+	saved bool,
+) {
+	current := location.Status.CredentialStatus
+	if current.CredentialID == previous.CredentialID && current.DriverName == previous.DriverName {
+		return
+	}
+	if saved {
+		b.deleteCredentials(location, previous)
+	} else {
+		b.deleteCredentials(location, current)
+	}
+}
+
+// deleteCredentials deletes the given credentials that were created for the
+// location in the volume driver
+func (b *BackupLocationController) deleteCredentials(
+	location *stork_api.BackupLocation,
+	credentials stork_api.BackupLocationCredentialStatus,
+) {
+	if b.Driver == nil ||
+		credentials.CredentialID == "" ||
+		credentials.DriverName != b.Driver.String() {
+		return
+	}
+	if err := b.Driver.DeleteBackupLocationCredentials(location, credentials.CredentialID); err != nil {
+		if _, ok := err.(*errors.ErrNotSupported); !ok {
+			log.BackupLocationLog(location).Errorf("Error deleting credentials %v for backup location: %v",
+				credentials.CredentialID, err)
+		}
+	}
+}
+
+func (b *BackupLocationController) getLocationHash(location *stork_api.BackupLocation) ([32]byte, error) {
+	data, err := json.Marshal(location.Location)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("error generating hash for location: %v", err)
+	}
+	return sha256.Sum256(data), nil
+}
+
+func (b *BackupLocationController) validationRequired(location *stork_api.BackupLocation, locationHash [32]byte) bool {
+	if location.Status.Status == stork_api.BackupLocationStatusInitial {
+		return true
+	}
+	b.validatedLocationsLock.Lock()
+	validatedHash, ok := b.validatedLocations[location.UID]
+	b.validatedLocationsLock.Unlock()
+	if !ok || validatedHash != locationHash {
+		return true
+	}
+	return time.Since(location.Status.LastCheckedTimestamp.Time) >= b.ValidationInterval
+}
+
+// validateLocation makes sure that objects can be created, read, listed and
+// deleted from the location and that the encryption key can be used to
+// decrypt existing backups
+func (b *BackupLocationController) validateLocation(location *stork_api.BackupLocation) error {
+	// Get the location through the API to merge in the config from the
+	// secret. Don't want to update the object with the merged config, so
+	// keep this separate
+	mergedLocation, err := storkops.Instance().GetBackupLocation(location.Name, location.Namespace)
+	if err != nil {
+		return fmt.Errorf("error getting config for location: %v", err)
+	}
+
+	// No need to try creating the bucket again if it was validated
+	// successfully previously
+	if mergedLocation.Location.CreateBucket && location.Status.Status != stork_api.BackupLocationStatusReady {
+		if err := objectstore.CreateBucket(mergedLocation); err != nil {
+			return fmt.Errorf("error creating bucket %v: %v", mergedLocation.Location.Path, err)
+		}
+	}
+
+	bucket, err := objectstore.GetBucket(mergedLocation)
+	if err != nil {
+		return fmt.Errorf("error getting bucket: %v", err)
+	}
+	if err := b.probeBucket(mergedLocation, bucket); err != nil {
+		return err
+	}
+	return b.validateEncryptionKey(mergedLocation, bucket)
+}
+
+func (b *BackupLocationController) probeBucket(location *stork_api.BackupLocation, bucket *blob.Bucket) error {
+	ctx := context.TODO()
+	prefix := filepath.Join(validationObjectPrefix, location.Namespace, location.Name) + "/"
+	key := prefix + string(location.UID)
+	data := []byte(metav1.Now().String())
+
+	writer, err := bucket.NewWriter(ctx, key, objectstore.GetWriterOptions(location))
+	if err != nil {
+		return fmt.Errorf("error creating test object: %v", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		if closeErr := writer.Close(); closeErr != nil {
+			log.BackupLocationLog(location).Errorf("Error closing writer for objectstore: %v", closeErr)
+		}
+		return fmt.Errorf("error writing test object: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error writing test object: %v", err)
+	}
+
+	readData, err := bucket.ReadAll(ctx, key)
+	if err != nil {
+		return fmt.Errorf("error reading test object: %v", err)
+	}
+	if !bytes.Equal(data, readData) {
+		return fmt.Errorf("data read from test object doesn't match data written")
+	}
+
+	found := false
+	iterator := bucket.List(&blob.ListOptions{
+		Prefix: prefix,
+	})
+	for {
+		object, err := iterator.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error listing objects: %v", err)
+		}
+		if object.Key == key {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("test object not found when listing objects")
+	}
+
+	if err := bucket.Delete(ctx, key); err != nil {
+		return fmt.Errorf("error deleting test object: %v", err)
+	}
+	return nil
+}
+
+// validateEncryptionKey checks that the metadata of an existing backup in the
+// location can be decrypted with the encryption key of the location
+func (b *BackupLocationController) validateEncryptionKey(location *stork_api.BackupLocation, bucket *blob.Bucket) error {
+	ctx := context.TODO()
+	iterator := bucket.List(&blob.ListOptions{
+		Prefix: location.Namespace + "/",
+	})
+	for {
+		object, err := iterator.Next(ctx)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error listing objects: %v", err)
+		}
+		if filepath.Base(object.Key) != objectstore.BackupMetadataObjectName {
+			continue
+		}
+
+		backupPath := filepath.Dir(object.Key)
+		if _, err := objectstore.ReadBackupMetadata(location, bucket, backupPath); err != nil {
+			if location.Location.EncryptionKey == "" {
+				return fmt.Errorf("error reading existing backup %v, it might be encrypted and encryptionKey isn't set: %v", backupPath, err)
+			}
+			return fmt.Errorf("error reading existing backup %v with encryptionKey: %v", backupPath, err)
+		}
+		// Only need to validate with one backup
+		return nil
+	}
+}
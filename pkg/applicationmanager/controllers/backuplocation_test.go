@@ -0,0 +1,127 @@
+// +build unittest
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/libopenstorage/stork/drivers/volume/mock"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// credentialsTestDriver keeps track of the credentials created for backup
+// locations
+type credentialsTestDriver struct {
+	mock.Driver
+	created []string
+	deleted []string
+}
+
+func (d *credentialsTestDriver) String() string {
+	return "CredentialsTestDriver"
+}
+
+func (d *credentialsTestDriver) UpdateBackupLocationCredentials(
+	location *stork_api.BackupLocation,
+	credentialID string,
+) (string, error) {
+	newCredentialID := fmt.Sprintf("%v-%v", location.Name, len(d.created))
+	d.created = append(d.created, newCredentialID)
+	return newCredentialID, nil
+}
+
+func (d *credentialsTestDriver) DeleteBackupLocationCredentials(
+	location *stork_api.BackupLocation,
+	credentialID string,
+) error {
+	d.deleted = append(d.deleted, credentialID)
+	return nil
+}
+
+func TestUpdateCredentials(t *testing.T) {
+	setupStorkOps()
+	driver := &credentialsTestDriver{}
+	controller := &BackupLocationController{
+		Driver:   driver,
+		Recorder: record.NewFakeRecorder(100),
+	}
+	createLocation := func(name string, locationItem stork_api.BackupLocationItem) *stork_api.BackupLocation {
+		location, err := storkops.Instance().CreateBackupLocation(&stork_api.BackupLocation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ns",
+			},
+			Location: locationItem,
+		})
+		require.NoError(t, err, "Error creating backup location")
+		return location
+	}
+
+	// Locations that don't need credentials are skipped
+	filesystem := createLocation("filesystem", stork_api.BackupLocationItem{
+		Type: stork_api.BackupLocationFilesystem,
+		Path: t.TempDir(),
+	})
+	controller.updateCredentials(filesystem)
+	require.Empty(t, driver.created)
+	require.Empty(t, filesystem.Status.CredentialStatus.Status)
+
+	static := createLocation("static", stork_api.BackupLocationItem{
+		Type:     stork_api.BackupLocationS3,
+		Path:     "bucket",
+		S3Config: &stork_api.S3Config{AccessKeyID: "access", SecretAccessKey: "secret"},
+	})
+	controller.updateCredentials(static)
+	require.Equal(t, []string{"static-0"}, driver.created)
+	require.Equal(t, stork_api.BackupLocationStatusReady, static.Status.CredentialStatus.Status)
+	require.Equal(t, "static-0", static.Status.CredentialStatus.CredentialID)
+
+	// Credentials are only updated when the config changes
+	controller.updateCredentials(static)
+	require.Len(t, driver.created, 1)
+
+	// The new credentials are deleted if the status of the location couldn't
+	// be saved, since the location still refers to the previous ones
+	static.Location.S3Config.SecretAccessKey = "newsecret"
+	_, err := storkops.Instance().UpdateBackupLocation(static)
+	require.NoError(t, err, "Error updating backup location")
+	previous := static.Status.CredentialStatus
+	controller.updateCredentials(static)
+	require.Equal(t, "static-1", static.Status.CredentialStatus.CredentialID)
+	controller.cleanupCredentials(static, previous, false)
+	require.Equal(t, []string{"static-1"}, driver.deleted)
+
+	// The previous credentials are deleted once the status has been saved
+	static.Status.CredentialStatus = previous
+	controller.updateCredentials(static)
+	require.Equal(t, "static-2", static.Status.CredentialStatus.CredentialID)
+	controller.cleanupCredentials(static, previous, true)
+	require.Equal(t, []string{"static-1", "static-0"}, driver.deleted)
+
+	// Credentials are deleted once the location doesn't use static keys
+	static.Location.S3Config.AuthType = stork_api.S3AuthIAM
+	_, err = storkops.Instance().UpdateBackupLocation(static)
+	require.NoError(t, err, "Error updating backup location")
+	previous = static.Status.CredentialStatus
+	controller.updateCredentials(static)
+	require.Len(t, driver.created, 3)
+	require.Equal(t, stork_api.BackupLocationCredentialStatus{}, static.Status.CredentialStatus)
+	controller.cleanupCredentials(static, previous, true)
+	require.Equal(t, []string{"static-1", "static-0", "static-2"}, driver.deleted)
+
+	for _, authType := range []stork_api.S3AuthType{stork_api.S3AuthIAM, stork_api.S3AuthWebIdentity} {
+		location := createLocation(string(authType), stork_api.BackupLocationItem{
+			Type:     stork_api.BackupLocationS3,
+			Path:     "bucket",
+			S3Config: &stork_api.S3Config{AuthType: authType, RoleARN: "role"},
+		})
+		controller.updateCredentials(location)
+		require.Len(t, driver.created, 3, "Credentials shouldn't be created for authType %v", authType)
+		require.Empty(t, location.Status.CredentialStatus.Status)
+	}
+}
@@ -2,33 +2,81 @@ package controllers
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
-	"github.com/libopenstorage/stork/pkg/crypto"
 	"github.com/libopenstorage/stork/pkg/log"
 	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/operator-framework/operator-sdk/pkg/sdk"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/sirupsen/logrus"
 	"gocloud.dev/blob"
-	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 )
 
+const (
+	// fullSyncInterval is how often all the objects in a location are listed.
+	// In between only the index of backups added to the location is listed
+	fullSyncInterval = time.Hour
+	// indexLookback is how far before the last sync the index is listed
+	// again to allow for clock skew between clusters writing to the location
+	indexLookback = time.Hour
+	// staleBackupDeleteSyncs is the number of syncs in a row that the data
+	// for a backup needs to be missing before it is deleted with the Delete
+	// policy. It is only marked before then in case the data was missing
+	// because of a transient error
+	staleBackupDeleteSyncs = 3
+
+	// BackupDataMissingAnnotation is added to ApplicationBackups whose data
+	// couldn't be found in the backup location during sync
+	BackupDataMissingAnnotation = annotationPrefix + "backupDataMissing"
+)
+
 // BackupSyncController reconciles applicationbackup objects
 type BackupSyncController struct {
 	Recorder     record.EventRecorder
 	SyncInterval time.Duration
 	stopChannel  chan os.Signal
+
+	// Info about the backups read from each location, keyed by the UID of
+	// the location. Used to avoid reading the metadata for every backup on
+	// each sync
+	locationCache map[types.UID]*locationSyncCache
+}
+
+type locationSyncCache struct {
+	path          string
+	encryptionKey string
+	// Keyed by the path of the backup in the location
+	backups map[string]*syncedBackupInfo
+	// lastFullSync is when all the objects in the location were last listed
+	lastFullSync time.Time
+	// indexedSince is the time from which the index needs to be listed on
+	// the next incremental sync
+	indexedSince time.Time
+	// missingSyncs is the number of syncs in a row that the data for a local
+	// backup hasn't been found, keyed by the UID of the backup
+	missingSyncs map[types.UID]int
+}
+
+type syncedBackupInfo struct {
+	uid        types.UID
+	syncedName string
+	namespaces []string
 }
 
 // Init Initializes the backup sync controller
 func (b *BackupSyncController) Init(stopChannel chan os.Signal) error {
 	b.stopChannel = stopChannel
+	b.locationCache = make(map[types.UID]*locationSyncCache)
 	go b.startBackupSync()
 	return nil
 }
@@ -42,11 +90,16 @@ func (b *BackupSyncController) startBackupSync() {
 				logrus.Errorf("Error getting backup location to sync: %v", err)
 				continue
 			}
-			for _, backupLocation := range backupLocations.Items {
-				err := b.syncBackupsFromLocation(&backupLocation)
-				if err != nil {
-					log.BackupLocationLog(&backupLocation).Errorf("Error syncing backups from location: %v", err)
-					continue
+			locations := make(map[types.UID]bool)
+			for i := range backupLocations.Items {
+				backupLocation := &backupLocations.Items[i]
+				locations[backupLocation.UID] = true
+				b.syncLocation(backupLocation)
+			}
+			// Drop the cache for locations that have been deleted
+			for uid := range b.locationCache {
+				if !locations[uid] {
+					delete(b.locationCache, uid)
 				}
 			}
 
@@ -56,100 +109,346 @@ func (b *BackupSyncController) startBackupSync() {
 	}
 }
 
-func (b *BackupSyncController) syncBackupsFromLocation(location *storkv1.BackupLocation) error {
+func (b *BackupSyncController) syncLocation(location *storkv1.BackupLocation) {
 	if !location.Location.Sync {
-		return nil
+		delete(b.locationCache, location.UID)
+		return
+	}
+
+	syncStatus := storkv1.BackupLocationSyncStatus{
+		Path:                 location.Location.Path,
+		PathChangedTimestamp: location.Status.SyncStatus.PathChangedTimestamp,
 	}
+	if location.Status.SyncStatus.Path != "" && location.Status.SyncStatus.Path != location.Location.Path {
+		syncStatus.PathChangedTimestamp = metav1.Now()
+	}
+	if err := b.syncBackupsFromLocation(location, &syncStatus); err != nil {
+		log.BackupLocationLog(location).Errorf("Error syncing backups from location: %v", err)
+		syncStatus.Status = storkv1.BackupLocationSyncStatusFailed
+		syncStatus.Reason = err.Error()
+	} else {
+		syncStatus.Status = storkv1.BackupLocationSyncStatusSuccessful
+	}
+	syncStatus.LastSyncTimestamp = metav1.Now()
+
+	// Only raise events when the status changes so that periodic syncs
+	// don't flood the events
+	if location.Status.SyncStatus.Status != syncStatus.Status {
+		if syncStatus.Status == storkv1.BackupLocationSyncStatusSuccessful {
+			b.Recorder.Event(location,
+				v1.EventTypeNormal,
+				string(syncStatus.Status),
+				"Backups synced successfully from location")
+		} else {
+			b.Recorder.Event(location,
+				v1.EventTypeWarning,
+				string(syncStatus.Status),
+				fmt.Sprintf("Error syncing backups from location: %v", syncStatus.Reason))
+		}
+	}
+
+	if err := b.updateSyncStatus(location, &syncStatus); err != nil {
+		log.BackupLocationLog(location).Errorf("Error updating sync status for location: %v", err)
+	}
+}
+
+// updateSyncStatus updates the sync status of the location. The location is
+// fetched again without merging in the config from the secret so that it
+// doesn't get stored in the object
+func (b *BackupSyncController) updateSyncStatus(
+	location *storkv1.BackupLocation,
+	syncStatus *storkv1.BackupLocationSyncStatus,
+) error {
+	backupLocation := &storkv1.BackupLocation{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       reflect.TypeOf(storkv1.BackupLocation{}).Name(),
+			APIVersion: storkv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      location.Name,
+			Namespace: location.Namespace,
+		},
+	}
+	if err := sdk.Get(backupLocation); err != nil {
+		return err
+	}
+	backupLocation.Status.SyncStatus = *syncStatus
+	return sdk.Update(backupLocation)
+}
+
+func (b *BackupSyncController) getLocationCache(location *storkv1.BackupLocation) *locationSyncCache {
+	cache, ok := b.locationCache[location.UID]
+	// Start over if the path or encryption key for the location changes since
+	// the backups might be different
+	if !ok || cache.path != location.Location.Path || cache.encryptionKey != location.Location.EncryptionKey {
+		cache = &locationSyncCache{
+			path:          location.Location.Path,
+			encryptionKey: location.Location.EncryptionKey,
+			backups:       make(map[string]*syncedBackupInfo),
+			missingSyncs:  make(map[types.UID]int),
+		}
+		b.locationCache[location.UID] = cache
+	}
+	return cache
+}
+
+func (b *BackupSyncController) syncBackupsFromLocation(
+	location *storkv1.BackupLocation,
+	syncStatus *storkv1.BackupLocationSyncStatus,
+) error {
+	startTime := time.Now()
 	bucket, err := objectstore.GetBucket(location)
 	if err != nil {
 		return err
 	}
+
+	cache := b.getLocationCache(location)
+	fullSync := startTime.Sub(cache.lastFullSync) >= fullSyncInterval
+	backupPaths, err := b.listBackupPaths(location, bucket, cache, fullSync)
+	if err != nil {
+		return err
+	}
+	if fullSync {
+		cache.lastFullSync = startTime
+	}
+	cache.indexedSince = startTime.Add(-indexLookback)
+	syncStatus.TotalBackups = len(backupPaths)
+
+	localBackups, err := storkops.Instance().ListApplicationBackups(location.Namespace)
+	if err != nil {
+		return err
+	}
+	localNames := make(map[string]bool)
+	localUIDs := make(map[types.UID]bool)
+	for _, backup := range localBackups.Items {
+		localNames[backup.Name] = true
+		localUIDs[backup.UID] = true
+	}
+
+	for backupPath := range cache.backups {
+		if !backupPaths[backupPath] {
+			delete(cache.backups, backupPath)
+		}
+	}
+
+	for backupPath := range backupPaths {
+		var backupInfo *storkv1.ApplicationBackup
+		info, ok := cache.backups[backupPath]
+		if !ok {
+			if backupInfo, err = objectstore.ReadBackupMetadata(location, bucket, backupPath); err != nil {
+				log.BackupLocationLog(location).Errorf("Error syncing backup %v: %v", backupPath, err)
+				continue
+			}
+			info = &syncedBackupInfo{
+				uid:        backupInfo.UID,
+				syncedName: b.getSyncedBackupName(backupInfo),
+				namespaces: backupInfo.Spec.Namespaces,
+			}
+			cache.backups[backupPath] = info
+		}
+
+		// The UIDs will match if it was originally created on this
+		// cluster. We don't want to sync those backups
+		if localUIDs[info.uid] {
+			continue
+		}
+		// Now check if we've synced this backup to this cluster already
+		// using the generated name
+		if localNames[info.syncedName] {
+			continue
+		}
+		if !b.namespacesSelected(location, info.namespaces) {
+			continue
+		}
+
+		if backupInfo == nil {
+			if backupInfo, err = objectstore.ReadBackupMetadata(location, bucket, backupPath); err != nil {
+				log.BackupLocationLog(location).Errorf("Error syncing backup %v: %v", backupPath, err)
+				// Read it again on the next sync in case it was removed
+				delete(cache.backups, backupPath)
+				continue
+			}
+		}
+		backupInfo.Name = info.syncedName
+		backupInfo.UID = ""
+		backupInfo.ResourceVersion = ""
+		backupInfo.SelfLink = ""
+		backupInfo.OwnerReferences = nil
+		backupInfo.Spec.ReclaimPolicy = storkv1.ApplicationBackupReclaimPolicyRetain
+		// Copies to replica locations are managed by the cluster that
+		// created the backup
+		backupInfo.Spec.ReplicaBackupLocations = nil
+		_, err = storkops.Instance().CreateApplicationBackup(backupInfo)
+		if err != nil {
+			return err
+		}
+		syncStatus.SyncedBackups++
+	}
+
+	// Don't check for stale backups if nothing was found in the location
+	// since every backup would look stale if the objects couldn't be listed
+	// because of a transient error
+	if len(backupPaths) == 0 {
+		log.BackupLocationLog(location).Warn("No backups found in location, skipping check for stale backups")
+		return nil
+	}
+	missingSyncs := make(map[types.UID]int)
+	for i := range localBackups.Items {
+		backup := &localBackups.Items[i]
+		if backup.Spec.BackupLocation != location.Name ||
+			backup.Status.Status != storkv1.ApplicationBackupStatusSuccessful ||
+			backup.Status.BackupPath == "" {
+			continue
+		}
+		// Backups that finished after we started listing might not have
+		// shown up in the list
+		if backup.Status.FinishTimestamp.Time.After(startTime) {
+			continue
+		}
+		// Backups that finished before the path of the location was changed
+		// are in the previous path
+		if backup.Status.FinishTimestamp.Time.Before(syncStatus.PathChangedTimestamp.Time) {
+			continue
+		}
+		// Incremental syncs only list backups that were added recently, so
+		// check for the others directly
+		if !fullSync && !backupPaths[backup.Status.BackupPath] {
+			metadataKey := filepath.Join(backup.Status.BackupPath, objectstore.BackupMetadataObjectName)
+			exists, err := bucket.Exists(context.TODO(), metadataKey)
+			if err != nil {
+				log.ApplicationBackupLog(backup).Errorf("Error checking for backup in location: %v", err)
+				continue
+			}
+			backupPaths[backup.Status.BackupPath] = exists
+		}
+		if backupPaths[backup.Status.BackupPath] {
+			if err := b.clearStaleBackup(backup); err != nil {
+				log.ApplicationBackupLog(backup).Errorf("Error updating backup: %v", err)
+			}
+			continue
+		}
+		syncStatus.StaleBackups++
+		missingSyncs[backup.UID] = cache.missingSyncs[backup.UID] + 1
+		if err := b.handleStaleBackup(location, backup, missingSyncs[backup.UID]); err != nil {
+			log.ApplicationBackupLog(backup).Errorf("Error handling backup with missing data: %v", err)
+		}
+	}
+	cache.missingSyncs = missingSyncs
+	return nil
+}
+
+// listBackupPaths returns the paths of the backups in the location. A full
+// sync lists all the objects for the namespace of the location. Otherwise the
+// backups that were already synced are combined with the ones added to the
+// index of the location since the last sync
+func (b *BackupSyncController) listBackupPaths(
+	location *storkv1.BackupLocation,
+	bucket *blob.Bucket,
+	cache *locationSyncCache,
+	fullSync bool,
+) (map[string]bool, error) {
+	backupPaths := make(map[string]bool)
+	if !fullSync {
+		for backupPath := range cache.backups {
+			backupPaths[backupPath] = true
+		}
+		indexedPaths, err := objectstore.ListIndexedBackups(bucket, location.Namespace, cache.indexedSince)
+		if err != nil {
+			return nil, err
+		}
+		for _, backupPath := range indexedPaths {
+			backupPaths[backupPath] = true
+		}
+		return backupPaths, nil
+	}
+
+	// List all the objects for the namespace in one pass instead of listing
+	// each backup separately. Backups are identified by their metadata
+	// object, which is uploaded last
 	iterator := bucket.List(&blob.ListOptions{
-		Prefix:    location.Namespace + "/",
-		Delimiter: "/",
+		Prefix: location.Namespace + "/",
 	})
-	backups := make(map[string]bool)
 	for {
 		object, err := iterator.Next(context.TODO())
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if object.IsDir {
-			backups[object.Key] = true
+		if filepath.Base(object.Key) == objectstore.BackupMetadataObjectName {
+			backupPaths[filepath.Dir(object.Key)] = true
 		}
 	}
-	for backupName := range backups {
-		iterator := bucket.List(&blob.ListOptions{
-			Prefix:    backupName,
-			Delimiter: "/",
-		})
-		for {
-			object, err := iterator.Next(context.TODO())
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				return err
-			}
-			if object.IsDir {
-				data, err := bucket.ReadAll(context.TODO(), filepath.Join(object.Key, metadataObjectName))
-				if err != nil {
-					log.BackupLocationLog(location).Errorf("Error syncing backup %v: %v", backupName, err)
-					continue
-				}
-				if location.Location.EncryptionKey != "" {
-					if data, err = crypto.Decrypt(data, location.Location.EncryptionKey); err != nil {
-						log.BackupLocationLog(location).Errorf("Error decrypting backup %v during sync: %v", backupName, err)
-						continue
-					}
-				}
-				backupInfo := storkv1.ApplicationBackup{}
-				if err = json.Unmarshal(data, &backupInfo); err != nil {
-					log.BackupLocationLog(location).Errorf("Error parsing backup %v during sync: %v", backupName, err)
-					continue
-				}
-
-				localBackupInfo, err := storkops.Instance().GetApplicationBackup(backupInfo.Name, backupInfo.Namespace)
-				if err == nil {
-					// The UIDs will match if it was originally created on this
-					// cluster. We don't want to sync those backups
-					if localBackupInfo.UID == backupInfo.UID {
-						continue
-					}
-				} else if !errors.IsNotFound(err) {
-					// Ignore any other error except NotFound
-					continue
-				}
-
-				// Now check if we've synced this backup to this cluster
-				// already using the generated name
-				syncedBackupName := b.getSyncedBackupName(&backupInfo)
-				_, err = storkops.Instance().GetApplicationBackup(syncedBackupName, backupInfo.Namespace)
-				if !errors.IsNotFound(err) {
-					// If we get anything other than NotFound ignore it
-					continue
-				}
+	return backupPaths, nil
+}
 
-				backupInfo.Name = syncedBackupName
-				backupInfo.UID = ""
-				backupInfo.ResourceVersion = ""
-				backupInfo.SelfLink = ""
-				backupInfo.OwnerReferences = nil
-				backupInfo.Spec.ReclaimPolicy = storkv1.ApplicationBackupReclaimPolicyRetain
-				_, err = storkops.Instance().CreateApplicationBackup(&backupInfo)
-				if err != nil {
-					return err
-				}
+// namespacesSelected returns true if the backup includes at least one of the
+// namespaces selected for sync in the location
+func (b *BackupSyncController) namespacesSelected(location *storkv1.BackupLocation, namespaces []string) bool {
+	if len(location.Location.SyncNamespaces) == 0 {
+		return true
+	}
+	for _, syncNamespace := range location.Location.SyncNamespaces {
+		for _, ns := range namespaces {
+			if ns == syncNamespace {
+				return true
+			}
+		}
+	}
+	return false
+}
 
+// handleStaleBackup marks or deletes a backup whose data has been removed from
+// the location depending on the policy set in the location. Backups are only
+// deleted once their data has been missing for several syncs in a row
+func (b *BackupSyncController) handleStaleBackup(
+	location *storkv1.BackupLocation,
+	backup *storkv1.ApplicationBackup,
+	missingSyncs int,
+) error {
+	if location.Location.StaleBackupPolicy == storkv1.StaleBackupPolicyDelete &&
+		missingSyncs >= staleBackupDeleteSyncs {
+		// Set the reclaim policy to Retain so that the volume backups aren't
+		// deleted along with the object
+		if backup.Spec.ReclaimPolicy != storkv1.ApplicationBackupReclaimPolicyRetain {
+			backup.Spec.ReclaimPolicy = storkv1.ApplicationBackupReclaimPolicyRetain
+			if _, err := storkops.Instance().UpdateApplicationBackup(backup); err != nil {
+				return err
 			}
 		}
+		log.ApplicationBackupLog(backup).Infof("Deleting backup since data was not found in backup location")
+		return storkops.Instance().DeleteApplicationBackup(backup.Name, backup.Namespace)
+	}
+
+	if _, ok := backup.Annotations[BackupDataMissingAnnotation]; ok {
+		return nil
 	}
+	if backup.Annotations == nil {
+		backup.Annotations = make(map[string]string)
+	}
+	backup.Annotations[BackupDataMissingAnnotation] = "true"
+	if _, err := storkops.Instance().UpdateApplicationBackup(backup); err != nil {
+		return err
+	}
+	b.Recorder.Event(backup,
+		v1.EventTypeWarning,
+		string(storkv1.ApplicationBackupStatusFailed),
+		fmt.Sprintf("Data for backup not found in backup location %v", location.Name))
 	return nil
 }
 
+// clearStaleBackup removes the annotation from a backup that was marked stale
+// if its data shows up in the location again
+func (b *BackupSyncController) clearStaleBackup(backup *storkv1.ApplicationBackup) error {
+	if _, ok := backup.Annotations[BackupDataMissingAnnotation]; !ok {
+		return nil
+	}
+	delete(backup.Annotations, BackupDataMissingAnnotation)
+	_, err := storkops.Instance().UpdateApplicationBackup(backup)
+	return err
+}
+
 func (b *BackupSyncController) getSyncedBackupName(backup *storkv1.ApplicationBackup) string {
 	// For scheduled backups use the original name
 	if _, ok := backup.Annotations[ApplicationBackupScheduleNameAnnotation]; ok {
@@ -0,0 +1,305 @@
+// +build unittest
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	fakeclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned/fake"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func setupBackupSyncTest(t *testing.T) (*BackupSyncController, *storkv1.BackupLocation, *blob.Bucket) {
+	storkops.SetInstance(storkops.New(kubernetes.NewSimpleClientset(), fakeclient.NewSimpleClientset(), nil))
+	location := &storkv1.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "location",
+			Namespace: "ns",
+			UID:       "location-uid",
+		},
+		Location: storkv1.BackupLocationItem{
+			Type: storkv1.BackupLocationFilesystem,
+			Path: t.TempDir(),
+			Sync: true,
+		},
+	}
+	bucket, err := objectstore.GetBucket(location)
+	require.NoError(t, err, "Error getting bucket")
+	t.Cleanup(func() { _ = bucket.Close() })
+
+	controller := &BackupSyncController{
+		Recorder:      record.NewFakeRecorder(10),
+		locationCache: make(map[types.UID]*locationSyncCache),
+	}
+	return controller, location, bucket
+}
+
+// uploadBackup writes the metadata for a backup created on another cluster
+// to the location and returns its path
+func uploadBackup(
+	t *testing.T,
+	location *storkv1.BackupLocation,
+	bucket *blob.Bucket,
+	name string,
+	namespaces []string,
+	index bool,
+) string {
+	backup := &storkv1.ApplicationBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: location.Namespace,
+			UID:       types.UID(name + "-uid"),
+		},
+		Spec: storkv1.ApplicationBackupSpec{
+			BackupLocation: location.Name,
+			Namespaces:     namespaces,
+		},
+		Status: storkv1.ApplicationBackupStatus{
+			Status:           storkv1.ApplicationBackupStatusSuccessful,
+			TriggerTimestamp: metav1.Now(),
+			FinishTimestamp:  metav1.Now(),
+		},
+	}
+	backupPath := filepath.Join(location.Namespace, name, string(backup.UID))
+	data, err := json.Marshal(backup)
+	require.NoError(t, err, "Error marshalling backup")
+	err = bucket.WriteAll(context.TODO(), filepath.Join(backupPath, objectstore.BackupMetadataObjectName), data, nil)
+	require.NoError(t, err, "Error uploading metadata")
+	if index {
+		err = objectstore.AddBackupToIndex(location, bucket, backupPath, backup.Status.FinishTimestamp.Time)
+		require.NoError(t, err, "Error adding backup to index")
+	}
+	return backupPath
+}
+
+func createLocalBackup(t *testing.T, location *storkv1.BackupLocation, name string) *storkv1.ApplicationBackup {
+	backup, err := storkops.Instance().CreateApplicationBackup(&storkv1.ApplicationBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: location.Namespace,
+			UID:       types.UID(name + "-local-uid"),
+		},
+		Spec: storkv1.ApplicationBackupSpec{
+			BackupLocation: location.Name,
+			ReclaimPolicy:  storkv1.ApplicationBackupReclaimPolicyDelete,
+		},
+		Status: storkv1.ApplicationBackupStatus{
+			Status:          storkv1.ApplicationBackupStatusSuccessful,
+			BackupPath:      filepath.Join(location.Namespace, name, name+"-local-uid"),
+			FinishTimestamp: metav1.NewTime(time.Now().Add(-time.Minute)),
+		},
+	})
+	require.NoError(t, err, "Error creating backup")
+	return backup
+}
+
+func TestNamespacesSelected(t *testing.T) {
+	controller := &BackupSyncController{}
+	tests := []struct {
+		name           string
+		syncNamespaces []string
+		namespaces     []string
+		selected       bool
+	}{
+		{"no filter", nil, []string{"ns1"}, true},
+		{"matching namespace", []string{"ns1"}, []string{"ns1", "ns2"}, true},
+		{"one of the filters matches", []string{"ns3", "ns2"}, []string{"ns1", "ns2"}, true},
+		{"no matching namespace", []string{"ns3"}, []string{"ns1", "ns2"}, false},
+		{"backup without namespaces", []string{"ns1"}, nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			location := &storkv1.BackupLocation{
+				Location: storkv1.BackupLocationItem{SyncNamespaces: test.syncNamespaces},
+			}
+			require.Equal(t, test.selected, controller.namespacesSelected(location, test.namespaces))
+		})
+	}
+}
+
+func TestSyncBackupsFromLocation(t *testing.T) {
+	controller, location, bucket := setupBackupSyncTest(t)
+	location.Location.SyncNamespaces = []string{"app"}
+	uploadBackup(t, location, bucket, "selected", []string{"app"}, false)
+	uploadBackup(t, location, bucket, "filtered", []string{"other"}, false)
+
+	syncStatus := storkv1.BackupLocationSyncStatus{}
+	err := controller.syncBackupsFromLocation(location, &syncStatus)
+	require.NoError(t, err, "Error syncing backups")
+	require.Equal(t, 2, syncStatus.TotalBackups)
+	require.Equal(t, 1, syncStatus.SyncedBackups)
+
+	backups, err := storkops.Instance().ListApplicationBackups(location.Namespace)
+	require.NoError(t, err, "Error listing backups")
+	require.Len(t, backups.Items, 1)
+	synced := backups.Items[0]
+	require.Contains(t, synced.Name, "selected-")
+	require.Equal(t, storkv1.ApplicationBackupReclaimPolicyRetain, synced.Spec.ReclaimPolicy)
+
+	// Backups that were already synced shouldn't be synced again
+	syncStatus = storkv1.BackupLocationSyncStatus{}
+	err = controller.syncBackupsFromLocation(location, &syncStatus)
+	require.NoError(t, err, "Error syncing backups")
+	require.Equal(t, 0, syncStatus.SyncedBackups)
+}
+
+func TestSyncBackupsFromIndex(t *testing.T) {
+	controller, location, bucket := setupBackupSyncTest(t)
+	uploadBackup(t, location, bucket, "first", []string{"app"}, true)
+
+	syncStatus := storkv1.BackupLocationSyncStatus{}
+	err := controller.syncBackupsFromLocation(location, &syncStatus)
+	require.NoError(t, err, "Error syncing backups")
+	require.Equal(t, 1, syncStatus.SyncedBackups)
+	require.False(t, controller.locationCache[location.UID].lastFullSync.IsZero())
+
+	// Backups in the index are picked up by the incremental sync, backups
+	// that aren't are only found by the next full sync
+	uploadBackup(t, location, bucket, "indexed", []string{"app"}, true)
+	uploadBackup(t, location, bucket, "unindexed", []string{"app"}, false)
+	syncStatus = storkv1.BackupLocationSyncStatus{}
+	err = controller.syncBackupsFromLocation(location, &syncStatus)
+	require.NoError(t, err, "Error syncing backups")
+	require.Equal(t, 2, syncStatus.TotalBackups)
+	require.Equal(t, 1, syncStatus.SyncedBackups)
+
+	controller.locationCache[location.UID].lastFullSync = time.Now().Add(-fullSyncInterval)
+	syncStatus = storkv1.BackupLocationSyncStatus{}
+	err = controller.syncBackupsFromLocation(location, &syncStatus)
+	require.NoError(t, err, "Error syncing backups")
+	require.Equal(t, 3, syncStatus.TotalBackups)
+	require.Equal(t, 1, syncStatus.SyncedBackups)
+}
+
+func TestSyncStaleBackupMark(t *testing.T) {
+	controller, location, bucket := setupBackupSyncTest(t)
+	location.Location.StaleBackupPolicy = storkv1.StaleBackupPolicyMark
+	uploadBackup(t, location, bucket, "other", []string{"ns1"}, false)
+	backup := createLocalBackup(t, location, "stale")
+
+	syncStatus := storkv1.BackupLocationSyncStatus{}
+	err := controller.syncBackupsFromLocation(location, &syncStatus)
+	require.NoError(t, err, "Error syncing backups")
+	require.Equal(t, 1, syncStatus.StaleBackups)
+	backup, err = storkops.Instance().GetApplicationBackup(backup.Name, backup.Namespace)
+	require.NoError(t, err, "Error getting backup")
+	require.Contains(t, backup.Annotations, BackupDataMissingAnnotation)
+
+	// The annotation should be removed once the data shows up again, even
+	// if it isn't in the index
+	err = bucket.WriteAll(context.TODO(), filepath.Join(backup.Status.BackupPath, objectstore.BackupMetadataObjectName), []byte("{}"), nil)
+	require.NoError(t, err, "Error uploading metadata")
+	syncStatus = storkv1.BackupLocationSyncStatus{}
+	err = controller.syncBackupsFromLocation(location, &syncStatus)
+	require.NoError(t, err, "Error syncing backups")
+	require.Equal(t, 0, syncStatus.StaleBackups)
+	backup, err = storkops.Instance().GetApplicationBackup(backup.Name, backup.Namespace)
+	require.NoError(t, err, "Error getting backup")
+	require.NotContains(t, backup.Annotations, BackupDataMissingAnnotation)
+}
+
+func TestSyncStaleBackupDelete(t *testing.T) {
+	controller, location, bucket := setupBackupSyncTest(t)
+	location.Location.StaleBackupPolicy = storkv1.StaleBackupPolicyDelete
+	uploadBackup(t, location, bucket, "other", []string{"ns1"}, false)
+	backup := createLocalBackup(t, location, "stale")
+	recent := createLocalBackup(t, location, "recent")
+	// Backups that finished after the sync started are skipped
+	recent.Status.FinishTimestamp = metav1.NewTime(time.Now().Add(time.Hour))
+	_, err := storkops.Instance().UpdateApplicationBackup(recent)
+	require.NoError(t, err, "Error updating backup")
+
+	// Backups are only marked until the data has been missing for several
+	// syncs in a row
+	for i := 1; i < staleBackupDeleteSyncs; i++ {
+		syncStatus := storkv1.BackupLocationSyncStatus{}
+		err = controller.syncBackupsFromLocation(location, &syncStatus)
+		require.NoError(t, err, "Error syncing backups")
+		require.Equal(t, 1, syncStatus.StaleBackups)
+		backup, err = storkops.Instance().GetApplicationBackup(backup.Name, backup.Namespace)
+		require.NoError(t, err, "Stale backup shouldn't have been deleted after %v syncs", i)
+		require.Contains(t, backup.Annotations, BackupDataMissingAnnotation)
+	}
+
+	syncStatus := storkv1.BackupLocationSyncStatus{}
+	err = controller.syncBackupsFromLocation(location, &syncStatus)
+	require.NoError(t, err, "Error syncing backups")
+	require.Equal(t, 1, syncStatus.StaleBackups)
+	_, err = storkops.Instance().GetApplicationBackup(backup.Name, backup.Namespace)
+	require.Error(t, err, "Stale backup should have been deleted")
+	_, err = storkops.Instance().GetApplicationBackup(recent.Name, recent.Namespace)
+	require.NoError(t, err, "Recent backup shouldn't have been deleted")
+}
+
+func TestSyncStaleBackupDeleteReset(t *testing.T) {
+	controller, location, bucket := setupBackupSyncTest(t)
+	location.Location.StaleBackupPolicy = storkv1.StaleBackupPolicyDelete
+	uploadBackup(t, location, bucket, "other", []string{"ns1"}, false)
+	backup := createLocalBackup(t, location, "stale")
+	metadataKey := filepath.Join(backup.Status.BackupPath, objectstore.BackupMetadataObjectName)
+
+	// The count is reset when the data is found again
+	for i := 0; i < 2*staleBackupDeleteSyncs; i++ {
+		if i == staleBackupDeleteSyncs-1 {
+			err := bucket.WriteAll(context.TODO(), metadataKey, []byte("{}"), nil)
+			require.NoError(t, err, "Error uploading metadata")
+		} else if i == staleBackupDeleteSyncs {
+			require.NoError(t, bucket.Delete(context.TODO(), metadataKey), "Error deleting metadata")
+		}
+		syncStatus := storkv1.BackupLocationSyncStatus{}
+		err := controller.syncBackupsFromLocation(location, &syncStatus)
+		require.NoError(t, err, "Error syncing backups")
+		if i < 2*staleBackupDeleteSyncs-1 {
+			_, err = storkops.Instance().GetApplicationBackup(backup.Name, backup.Namespace)
+			require.NoError(t, err, "Stale backup shouldn't have been deleted after sync %v", i)
+		}
+	}
+	_, err := storkops.Instance().GetApplicationBackup(backup.Name, backup.Namespace)
+	require.Error(t, err, "Stale backup should have been deleted")
+}
+
+func TestSyncStaleBackupEmptyLocation(t *testing.T) {
+	controller, location, _ := setupBackupSyncTest(t)
+	location.Location.StaleBackupPolicy = storkv1.StaleBackupPolicyDelete
+	backup := createLocalBackup(t, location, "stale")
+
+	// Nothing is pruned if no backups are found in the location
+	for i := 0; i < staleBackupDeleteSyncs; i++ {
+		syncStatus := storkv1.BackupLocationSyncStatus{}
+		err := controller.syncBackupsFromLocation(location, &syncStatus)
+		require.NoError(t, err, "Error syncing backups")
+		require.Equal(t, 0, syncStatus.StaleBackups)
+	}
+	backup, err := storkops.Instance().GetApplicationBackup(backup.Name, backup.Namespace)
+	require.NoError(t, err, "Backup shouldn't have been deleted")
+	require.NotContains(t, backup.Annotations, BackupDataMissingAnnotation)
+}
+
+func TestSyncStaleBackupPathChanged(t *testing.T) {
+	controller, location, bucket := setupBackupSyncTest(t)
+	location.Location.StaleBackupPolicy = storkv1.StaleBackupPolicyDelete
+	uploadBackup(t, location, bucket, "other", []string{"ns1"}, false)
+	backup := createLocalBackup(t, location, "stale")
+
+	// Backups that finished before the path was changed aren't checked
+	syncStatus := storkv1.BackupLocationSyncStatus{PathChangedTimestamp: metav1.Now()}
+	for i := 0; i < staleBackupDeleteSyncs; i++ {
+		err := controller.syncBackupsFromLocation(location, &syncStatus)
+		require.NoError(t, err, "Error syncing backups")
+		require.Equal(t, 0, syncStatus.StaleBackups)
+	}
+	_, err := storkops.Instance().GetApplicationBackup(backup.Name, backup.Namespace)
+	require.NoError(t, err, "Backup from the previous path shouldn't have been deleted")
+}
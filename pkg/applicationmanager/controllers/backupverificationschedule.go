@@ -0,0 +1,611 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/libopenstorage/stork/pkg/apis/stork"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/controller"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/schedule"
+	"github.com/operator-framework/operator-sdk/pkg/sdk"
+	"github.com/portworx/sched-ops/k8s/apiextensions"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// BackupVerificationScheduleNameLabel is the label added to the
+	// namespaces that backups are restored into for verification
+	BackupVerificationScheduleNameLabel = annotationPrefix + "backupVerificationScheduleName"
+
+	defaultVerificationTimeout = time.Hour
+	// Maximum length of the source namespace used in the name of the
+	// namespace the backup is restored into, so that it stays within the
+	// limit for namespace names
+	maxVerificationNamespacePrefix = 40
+)
+
+// BackupVerificationScheduleController reconciles BackupVerificationSchedule
+// objects
+type BackupVerificationScheduleController struct {
+	Recorder       record.EventRecorder
+	adminNamespace string
+	kubeClient     kubernetes.Interface
+}
+
+// Init Initialize the backup verification schedule controller
+func (s *BackupVerificationScheduleController) Init(adminNamespace string) error {
+	err := s.createCRD()
+	if err != nil {
+		return err
+	}
+	s.adminNamespace = adminNamespace
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return fmt.Errorf("error getting cluster config: %v", err)
+	}
+	s.kubeClient, err = kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	return controller.Register(
+		&schema.GroupVersionKind{
+			Group:   stork.GroupName,
+			Version: stork_api.SchemeGroupVersion.Version,
+			Kind:    reflect.TypeOf(stork_api.BackupVerificationSchedule{}).Name(),
+		},
+		"",
+		1*time.Minute,
+		s)
+}
+
+// Handle updates for BackupVerificationSchedule objects
+func (s *BackupVerificationScheduleController) Handle(ctx context.Context, event sdk.Event) error {
+	switch o := event.Object.(type) {
+	case *stork_api.BackupVerificationSchedule:
+		verificationSchedule := o
+		// The restores are deleted through their owner reference, only the
+		// namespaces and volumes need to be cleaned up
+		if event.Deleted {
+			return s.cleanupVerifications(verificationSchedule)
+		}
+
+		// Backups are restored into new namespaces, which is only allowed
+		// from the admin namespace. So only the backup schedules in the admin
+		// namespace can be verified
+		if verificationSchedule.Namespace != s.adminNamespace {
+			msg := fmt.Sprintf("BackupVerificationSchedule can only be created in the admin namespace %v", s.adminNamespace)
+			s.Recorder.Event(verificationSchedule,
+				v1.EventTypeWarning,
+				string(stork_api.BackupVerificationStatusFailed),
+				msg)
+			log.BackupVerificationScheduleLog(verificationSchedule).Error(msg)
+			return nil
+		}
+
+		// First update the status of any pending verifications
+		err := s.updateVerificationStatus(verificationSchedule)
+		if err != nil {
+			msg := fmt.Sprintf("Error updating verification status: %v", err)
+			s.Recorder.Event(verificationSchedule,
+				v1.EventTypeWarning,
+				string(stork_api.BackupVerificationStatusFailed),
+				msg)
+			log.BackupVerificationScheduleLog(verificationSchedule).Error(msg)
+			return err
+		}
+
+		if verificationSchedule.Spec.Suspend == nil || !*verificationSchedule.Spec.Suspend {
+			// Then check if any of the policies require a trigger
+			policyType, start, err := s.shouldStartVerification(verificationSchedule)
+			if err != nil {
+				msg := fmt.Sprintf("Error checking if verification should be triggered: %v", err)
+				s.Recorder.Event(verificationSchedule,
+					v1.EventTypeWarning,
+					string(stork_api.BackupVerificationStatusFailed),
+					msg)
+				log.BackupVerificationScheduleLog(verificationSchedule).Error(msg)
+				return nil
+			}
+
+			// Start a verification for a policy if required
+			if start {
+				err := s.startVerification(verificationSchedule, policyType)
+				if err != nil {
+					msg := fmt.Sprintf("Error triggering verification for schedule(%v): %v", policyType, err)
+					s.Recorder.Event(verificationSchedule,
+						v1.EventTypeWarning,
+						string(stork_api.BackupVerificationStatusFailed),
+						msg)
+					log.BackupVerificationScheduleLog(verificationSchedule).Error(msg)
+					return err
+				}
+			}
+		}
+
+		// Finally, prune any old verifications that were triggered for this
+		// schedule
+		err = s.pruneVerifications(verificationSchedule)
+		if err != nil {
+			msg := fmt.Sprintf("Error pruning old verifications: %v", err)
+			s.Recorder.Event(verificationSchedule,
+				v1.EventTypeWarning,
+				string(stork_api.BackupVerificationStatusFailed),
+				msg)
+			log.BackupVerificationScheduleLog(verificationSchedule).Error(msg)
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *BackupVerificationScheduleController) isVerificationComplete(status stork_api.BackupVerificationStatusType) bool {
+	return status == stork_api.BackupVerificationStatusFailed ||
+		status == stork_api.BackupVerificationStatusSuccessful
+}
+
+func getVerificationTimeout(verificationSchedule *stork_api.BackupVerificationSchedule) time.Duration {
+	if verificationSchedule.Spec.TimeoutSeconds <= 0 {
+		return defaultVerificationTimeout
+	}
+	return time.Duration(verificationSchedule.Spec.TimeoutSeconds) * time.Second
+}
+
+func getVerificationReadinessGates(verificationSchedule *stork_api.BackupVerificationSchedule) []stork_api.ReadinessGate {
+	if len(verificationSchedule.Spec.ReadinessGates) != 0 {
+		return verificationSchedule.Spec.ReadinessGates
+	}
+	return []stork_api.ReadinessGate{
+		{Stage: stork_api.ApplyStageStorage},
+		{Stage: stork_api.ApplyStageWorkloads},
+	}
+}
+
+// getRestoreFailureReason returns why the restore used for a verification
+// didn't succeed
+func getRestoreFailureReason(restore *stork_api.ApplicationRestore) string {
+	reasons := make([]string, 0)
+	for _, vInfo := range restore.Status.Volumes {
+		if vInfo.Status == stork_api.ApplicationRestoreStatusFailed {
+			reasons = append(reasons, fmt.Sprintf("volume %v: %v", vInfo.PersistentVolumeClaim, vInfo.Reason))
+		}
+	}
+	for _, stage := range restore.Status.ApplyStages {
+		if stage.Status == stork_api.ApplyStageStatusFailed {
+			reasons = append(reasons, fmt.Sprintf("stage %v: %v", stage.Stage, stage.Reason))
+		}
+	}
+	for _, resource := range restore.Status.Resources {
+		if resource.Status == stork_api.ApplicationRestoreStatusFailed {
+			reasons = append(reasons, fmt.Sprintf("%v %v/%v: %v", resource.Kind, resource.Namespace, resource.Name, resource.Reason))
+		}
+	}
+	if len(reasons) == 0 {
+		return fmt.Sprintf("Restore %v in stage %v", restore.Status.Status, restore.Status.Stage)
+	}
+	return fmt.Sprintf("Restore %v: %v", restore.Status.Status, strings.Join(reasons, ", "))
+}
+
+func (s *BackupVerificationScheduleController) updateVerificationStatus(verificationSchedule *stork_api.BackupVerificationSchedule) error {
+	if s.updateVerifications(verificationSchedule) {
+		return sdk.Update(verificationSchedule)
+	}
+	return nil
+}
+
+// getRestoredVolumes returns the names of the PersistentVolumes for the
+// volumes that were restored
+func getRestoredVolumes(restore *stork_api.ApplicationRestore) []string {
+	volumes := make([]string, 0)
+	for _, vInfo := range restore.Status.Volumes {
+		if vInfo.RestoreVolume != "" {
+			volumes = append(volumes, vInfo.RestoreVolume)
+		}
+	}
+	sort.Strings(volumes)
+	return volumes
+}
+
+// updateVerifications updates the status of the verifications that are in
+// progress and cleans up the ones that completed. Returns true if the status
+// of the schedule was updated
+func (s *BackupVerificationScheduleController) updateVerifications(verificationSchedule *stork_api.BackupVerificationSchedule) bool {
+	updated := false
+	for _, policyVerification := range verificationSchedule.Status.Items {
+		for _, verification := range policyVerification {
+			if s.isVerificationComplete(verification.Status) {
+				// Retry the cleanup if it failed earlier
+				if !verification.Cleaned {
+					if err := s.cleanupVerification(verificationSchedule, verification); err != nil {
+						log.BackupVerificationScheduleLog(verificationSchedule).Warnf("Error cleaning up verification %v: %v", verification.Name, err)
+						continue
+					}
+					updated = true
+				}
+				continue
+			}
+
+			restore, err := storkops.Instance().GetApplicationRestore(verification.Name, verificationSchedule.Namespace)
+			if err != nil {
+				// If there was an error other than not found move to the
+				// next one. Otherwise we want to mark it as failed since the
+				// applicationrestore object is no longer present
+				if !errors.IsNotFound(err) {
+					s.Recorder.Event(verificationSchedule,
+						v1.EventTypeWarning,
+						string(stork_api.BackupVerificationStatusFailed),
+						fmt.Sprintf("Error getting status of verification %v: %v", verification.Name, err))
+					continue
+				}
+				verification.Status = stork_api.BackupVerificationStatusFailed
+				verification.Reason = "ApplicationRestore for the verification was deleted"
+			} else {
+				// Keep track of the restored volumes so that they can be
+				// cleaned up even if the restore is deleted
+				if volumes := getRestoredVolumes(restore); !reflect.DeepEqual(volumes, verification.Volumes) && len(volumes) != 0 {
+					verification.Volumes = volumes
+					updated = true
+				}
+				switch restore.Status.Status {
+				case stork_api.ApplicationRestoreStatusSuccessful:
+					verification.Status = stork_api.BackupVerificationStatusSuccessful
+					verification.Reason = "Backup restored and checks passed"
+				case stork_api.ApplicationRestoreStatusFailed,
+					stork_api.ApplicationRestoreStatusPartialSuccess:
+					verification.Status = stork_api.BackupVerificationStatusFailed
+					verification.Reason = getRestoreFailureReason(restore)
+				default:
+					if time.Since(verification.CreationTimestamp.Time) <= getVerificationTimeout(verificationSchedule) {
+						continue
+					}
+					verification.Status = stork_api.BackupVerificationStatusFailed
+					verification.Reason = fmt.Sprintf("Timed out after %v waiting for restore to complete", getVerificationTimeout(verificationSchedule))
+					// Stop the restore so that it doesn't keep restoring
+					// into the namespaces that are being deleted
+					if err := storkops.Instance().DeleteApplicationRestore(restore.Name, restore.Namespace); err != nil && !errors.IsNotFound(err) {
+						log.BackupVerificationScheduleLog(verificationSchedule).Warnf("Error deleting restore %v: %v", restore.Name, err)
+					}
+				}
+			}
+
+			verification.FinishTimestamp = meta.NewTime(schedule.GetCurrentTime())
+			verification.Duration = meta.Duration{Duration: verification.FinishTimestamp.Sub(verification.CreationTimestamp.Time)}
+			if verification.Status == stork_api.BackupVerificationStatusSuccessful {
+				s.Recorder.Event(verificationSchedule,
+					v1.EventTypeNormal,
+					string(stork_api.BackupVerificationStatusSuccessful),
+					fmt.Sprintf("Verification (%v) of backup %v completed successfully in %v",
+						verification.Name, verification.BackupName, verification.Duration.Duration))
+			} else {
+				s.Recorder.Event(verificationSchedule,
+					v1.EventTypeWarning,
+					string(stork_api.BackupVerificationStatusFailed),
+					fmt.Sprintf("Verification (%v) of backup %v failed: %v",
+						verification.Name, verification.BackupName, verification.Reason))
+			}
+			if err := s.cleanupVerification(verificationSchedule, verification); err != nil {
+				log.BackupVerificationScheduleLog(verificationSchedule).Warnf("Error cleaning up verification %v: %v", verification.Name, err)
+			}
+			updated = true
+		}
+	}
+	return updated
+}
+
+// cleanupVerification deletes the namespaces the backup was restored into.
+// Only the namespaces created for the verification are deleted, so namespaces
+// that already existed are left alone along with their volumes. The reclaim
+// policy of the restored volumes is set to Delete first so that they are
+// deleted along with their PVCs instead of being left behind when the backup
+// had volumes with the Retain policy
+func (s *BackupVerificationScheduleController) cleanupVerification(
+	verificationSchedule *stork_api.BackupVerificationSchedule,
+	verification *stork_api.ScheduledBackupVerificationStatus,
+) error {
+	created := make(map[string]bool)
+	for _, ns := range verification.Namespaces {
+		namespace, err := core.Instance().GetNamespace(ns)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if namespace.Labels[BackupVerificationScheduleNameLabel] != verificationSchedule.Name {
+			log.BackupVerificationScheduleLog(verificationSchedule).Warnf("Not deleting namespace %v for verification %v since it wasn't created for it", ns, verification.Name)
+			continue
+		}
+		created[ns] = true
+	}
+	for _, volume := range verification.Volumes {
+		pv, err := s.kubeClient.CoreV1().PersistentVolumes().Get(volume, meta.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if pv.Spec.PersistentVolumeReclaimPolicy == v1.PersistentVolumeReclaimDelete ||
+			(pv.Spec.ClaimRef != nil && !created[pv.Spec.ClaimRef.Namespace]) {
+			continue
+		}
+		pv.Spec.PersistentVolumeReclaimPolicy = v1.PersistentVolumeReclaimDelete
+		if _, err := s.kubeClient.CoreV1().PersistentVolumes().Update(pv); err != nil {
+			return fmt.Errorf("error updating reclaim policy of volume %v: %v", volume, err)
+		}
+	}
+	for ns := range created {
+		if err := core.Instance().DeleteNamespace(ns); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	verification.Cleaned = true
+	return nil
+}
+
+func (s *BackupVerificationScheduleController) cleanupVerifications(verificationSchedule *stork_api.BackupVerificationSchedule) error {
+	for _, policyVerification := range verificationSchedule.Status.Items {
+		for _, verification := range policyVerification {
+			if verification.Cleaned {
+				continue
+			}
+			if err := s.cleanupVerification(verificationSchedule, verification); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *BackupVerificationScheduleController) shouldStartVerification(verificationSchedule *stork_api.BackupVerificationSchedule) (stork_api.SchedulePolicyType, bool, error) {
+	// Don't trigger a new verification if one is already in progress
+	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
+		policyVerification, present := verificationSchedule.Status.Items[policyType]
+		if present {
+			for _, verification := range policyVerification {
+				if !s.isVerificationComplete(verification.Status) {
+					return stork_api.SchedulePolicyTypeInvalid, false, nil
+				}
+			}
+		}
+	}
+
+	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
+		var latestVerificationTimestamp meta.Time
+		policyVerification, present := verificationSchedule.Status.Items[policyType]
+		if present {
+			for _, verification := range policyVerification {
+				if latestVerificationTimestamp.Before(&verification.CreationTimestamp) {
+					latestVerificationTimestamp = verification.CreationTimestamp
+				}
+			}
+		}
+		trigger, err := schedule.TriggerRequired(
+			verificationSchedule.Spec.SchedulePolicyName,
+			policyType,
+			latestVerificationTimestamp,
+		)
+		if err != nil {
+			return stork_api.SchedulePolicyTypeInvalid, false, err
+		}
+		if trigger {
+			return policyType, true, nil
+		}
+	}
+	return stork_api.SchedulePolicyTypeInvalid, false, nil
+}
+
+// getLatestBackup returns the latest successful backup triggered by the
+// backup schedule
+func (s *BackupVerificationScheduleController) getLatestBackup(verificationSchedule *stork_api.BackupVerificationSchedule) (*stork_api.ApplicationBackup, error) {
+	backupSchedule, err := storkops.Instance().GetApplicationBackupSchedule(verificationSchedule.Spec.BackupScheduleName, verificationSchedule.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error getting backup schedule %v: %v", verificationSchedule.Spec.BackupScheduleName, err)
+	}
+	var latest *stork_api.ScheduledApplicationBackupStatus
+	for _, policyApplicationBackup := range backupSchedule.Status.Items {
+		for _, backup := range policyApplicationBackup {
+			if backup.Status != stork_api.ApplicationBackupStatusSuccessful {
+				continue
+			}
+			if latest == nil || latest.CreationTimestamp.Before(&backup.CreationTimestamp) {
+				latest = backup
+			}
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no successful backups found for backup schedule %v", backupSchedule.Name)
+	}
+	return storkops.Instance().GetApplicationBackup(latest.Name, verificationSchedule.Namespace)
+}
+
+// getVerificationNamespaces returns the mapping from the namespaces in the
+// backup to the new namespaces that it should be restored into
+func getVerificationNamespaces(backup *stork_api.ApplicationBackup, suffix string) map[string]string {
+	namespaceMapping := make(map[string]string)
+	used := make(map[string]bool)
+	for i, ns := range backup.Spec.Namespaces {
+		prefix := ns
+		if len(prefix) > maxVerificationNamespacePrefix {
+			prefix = prefix[:maxVerificationNamespacePrefix]
+		}
+		name := fmt.Sprintf("%v-verify-%v", prefix, suffix)
+		if used[name] {
+			name = fmt.Sprintf("%v-verify-%v-%v", prefix, suffix, i)
+		}
+		used[name] = true
+		namespaceMapping[ns] = name
+	}
+	return namespaceMapping
+}
+
+func (s *BackupVerificationScheduleController) formatVerificationName(verificationSchedule *stork_api.BackupVerificationSchedule, policyType stork_api.SchedulePolicyType) string {
+	return strings.Join([]string{verificationSchedule.Name, strings.ToLower(string(policyType)), time.Now().Format(nameTimeSuffixFormat)}, "-")
+}
+
+func (s *BackupVerificationScheduleController) startVerification(verificationSchedule *stork_api.BackupVerificationSchedule, policyType stork_api.SchedulePolicyType) error {
+	verificationName := s.formatVerificationName(verificationSchedule, policyType)
+	if verificationSchedule.Status.Items == nil {
+		verificationSchedule.Status.Items = make(map[stork_api.SchedulePolicyType][]*stork_api.ScheduledBackupVerificationStatus)
+	}
+	if verificationSchedule.Status.Items[policyType] == nil {
+		verificationSchedule.Status.Items[policyType] = make([]*stork_api.ScheduledBackupVerificationStatus, 0)
+	}
+	verification := &stork_api.ScheduledBackupVerificationStatus{
+		Name:              verificationName,
+		CreationTimestamp: meta.NewTime(schedule.GetCurrentTime()),
+		Status:            stork_api.BackupVerificationStatusPending,
+		Namespaces:        make([]string, 0),
+	}
+	verificationSchedule.Status.Items[policyType] = append(verificationSchedule.Status.Items[policyType], verification)
+
+	// Record the failure in the status so that it shows up in the results of
+	// the schedule
+	fail := func(err error) error {
+		verification.Status = stork_api.BackupVerificationStatusFailed
+		verification.Reason = err.Error()
+		verification.FinishTimestamp = meta.NewTime(schedule.GetCurrentTime())
+		if cleanupErr := s.cleanupVerification(verificationSchedule, verification); cleanupErr != nil {
+			log.BackupVerificationScheduleLog(verificationSchedule).Warnf("Error cleaning up verification %v: %v", verification.Name, cleanupErr)
+		}
+		if updateErr := sdk.Update(verificationSchedule); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+
+	backup, err := s.getLatestBackup(verificationSchedule)
+	if err != nil {
+		return fail(err)
+	}
+	verification.BackupName = backup.Name
+	namespaceMapping := getVerificationNamespaces(backup, fmt.Sprintf("%v", verification.CreationTimestamp.Unix()))
+	for _, ns := range namespaceMapping {
+		verification.Namespaces = append(verification.Namespaces, ns)
+	}
+	sort.Strings(verification.Namespaces)
+	if err := sdk.Update(verificationSchedule); err != nil {
+		return err
+	}
+
+	for _, ns := range verification.Namespaces {
+		if _, err := core.Instance().CreateNamespace(ns, map[string]string{
+			BackupVerificationScheduleNameLabel: verificationSchedule.Name,
+		}); err != nil {
+			return fail(fmt.Errorf("error creating namespace %v: %v", ns, err))
+		}
+	}
+
+	restore := &stork_api.ApplicationRestore{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      verificationName,
+			Namespace: verificationSchedule.Namespace,
+			Labels:    verificationSchedule.Labels,
+			OwnerReferences: []meta.OwnerReference{
+				{
+					Name:       verificationSchedule.Name,
+					UID:        verificationSchedule.UID,
+					Kind:       verificationSchedule.GetObjectKind().GroupVersionKind().Kind,
+					APIVersion: verificationSchedule.GetObjectKind().GroupVersionKind().GroupVersion().String(),
+				},
+			},
+		},
+		Spec: stork_api.ApplicationRestoreSpec{
+			BackupName:       backup.Name,
+			BackupLocation:   backup.Spec.BackupLocation,
+			NamespaceMapping: namespaceMapping,
+			ReplacePolicy:    stork_api.ApplicationRestoreReplacePolicyRetain,
+			PostExecRule:     verificationSchedule.Spec.Rule,
+			ReadinessGates:   getVerificationReadinessGates(verificationSchedule),
+		},
+	}
+	log.BackupVerificationScheduleLog(verificationSchedule).Infof("Starting verification %v of backup %v", verificationName, backup.Name)
+	if _, err := storkops.Instance().CreateApplicationRestore(restore); err != nil {
+		return fail(fmt.Errorf("error creating restore: %v", err))
+	}
+
+	verification.Status = stork_api.BackupVerificationStatusInProgress
+	return sdk.Update(verificationSchedule)
+}
+
+func (s *BackupVerificationScheduleController) pruneVerifications(verificationSchedule *stork_api.BackupVerificationSchedule) error {
+	for policyType, policyVerification := range verificationSchedule.Status.Items {
+		numVerifications := len(policyVerification)
+		deleteBefore := 0
+		retainNum, err := schedule.GetRetain(verificationSchedule.Spec.SchedulePolicyName, policyType)
+		if err != nil {
+			return err
+		}
+		numReady := 0
+
+		// Keep up to retainNum successful verification statuses and all failed
+		// verifications until there is a successful one
+		if numVerifications > int(retainNum) {
+			// Start from the end and find the retainNum successful verifications
+			for i := range policyVerification {
+				if policyVerification[(numVerifications-1-i)].Status == stork_api.BackupVerificationStatusSuccessful {
+					numReady++
+					if numReady > int(retainNum) {
+						deleteBefore = numVerifications - i
+						break
+					}
+				}
+			}
+			failedDeletes := make([]*stork_api.ScheduledBackupVerificationStatus, 0)
+			if numReady > int(retainNum) {
+				for i := 0; i < deleteBefore; i++ {
+					// Keep track of the ones whose namespaces haven't been
+					// cleaned up yet
+					if !policyVerification[i].Cleaned {
+						failedDeletes = append(failedDeletes, policyVerification[i])
+						continue
+					}
+					err := storkops.Instance().DeleteApplicationRestore(policyVerification[i].Name, verificationSchedule.Namespace)
+					if err != nil && !errors.IsNotFound(err) {
+						log.BackupVerificationScheduleLog(verificationSchedule).Warnf("Error deleting %v: %v", policyVerification[i].Name, err)
+						// Keep a track of the failed deletes
+						failedDeletes = append(failedDeletes, policyVerification[i])
+					}
+				}
+			}
+			// Remove all the ones we tried to delete above
+			verificationSchedule.Status.Items[policyType] = policyVerification[deleteBefore:]
+			// And re-add the ones that failed so that we don't lose track
+			// of them
+			verificationSchedule.Status.Items[policyType] = append(failedDeletes, verificationSchedule.Status.Items[policyType]...)
+		}
+	}
+	return sdk.Update(verificationSchedule)
+}
+
+func (s *BackupVerificationScheduleController) createCRD() error {
+	resource := apiextensions.CustomResource{
+		Name:    stork_api.BackupVerificationScheduleResourceName,
+		Plural:  stork_api.BackupVerificationScheduleResourcePlural,
+		Group:   stork.GroupName,
+		Version: stork_api.SchemeGroupVersion.Version,
+		Scope:   apiextensionsv1beta1.NamespaceScoped,
+		Kind:    reflect.TypeOf(stork_api.BackupVerificationSchedule{}).Name(),
+	}
+	err := apiextensions.Instance().CreateCRD(resource)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	return apiextensions.Instance().ValidateCRD(resource, validateCRDTimeout, validateCRDInterval)
+}
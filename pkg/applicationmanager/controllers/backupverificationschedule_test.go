@@ -0,0 +1,168 @@
+// +build unittest
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	fakeclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned/fake"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestGetVerificationNamespaces(t *testing.T) {
+	longNamespace := strings.Repeat("a", 50)
+	tests := []struct {
+		name       string
+		namespaces []string
+		expected   map[string]string
+	}{
+		{
+			name:       "short namespaces",
+			namespaces: []string{"ns1", "ns2"},
+			expected:   map[string]string{"ns1": "ns1-verify-100", "ns2": "ns2-verify-100"},
+		},
+		{
+			name:       "long namespace is truncated",
+			namespaces: []string{longNamespace},
+			expected:   map[string]string{longNamespace: longNamespace[:maxVerificationNamespacePrefix] + "-verify-100"},
+		},
+		{
+			name:       "truncated namespaces don't conflict",
+			namespaces: []string{longNamespace, longNamespace + "b"},
+			expected: map[string]string{
+				longNamespace:       longNamespace[:maxVerificationNamespacePrefix] + "-verify-100",
+				longNamespace + "b": longNamespace[:maxVerificationNamespacePrefix] + "-verify-100-1",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backup := &stork_api.ApplicationBackup{
+				Spec: stork_api.ApplicationBackupSpec{Namespaces: test.namespaces},
+			}
+			require.Equal(t, test.expected, getVerificationNamespaces(backup, "100"))
+		})
+	}
+}
+
+func TestUpdateVerifications(t *testing.T) {
+	fakeKubeClient := kubernetes.NewSimpleClientset()
+	core.SetInstance(core.New(fakeKubeClient, fakeKubeClient.CoreV1(), fakeKubeClient.StorageV1()))
+	storkops.SetInstance(storkops.New(fakeKubeClient, fakeclient.NewSimpleClientset(), nil))
+	recorder := record.NewFakeRecorder(100)
+	controller := &BackupVerificationScheduleController{
+		Recorder:   recorder,
+		kubeClient: fakeKubeClient,
+	}
+
+	newVerification := func(name string, creationTimestamp time.Time) *stork_api.ScheduledBackupVerificationStatus {
+		ns := name + "-ns"
+		_, err := core.Instance().CreateNamespace(ns, map[string]string{
+			BackupVerificationScheduleNameLabel: "schedule",
+		})
+		require.NoError(t, err, "Error creating namespace")
+		return &stork_api.ScheduledBackupVerificationStatus{
+			Name:              name,
+			BackupName:        "backup",
+			Namespaces:        []string{ns},
+			CreationTimestamp: meta.NewTime(creationTimestamp),
+			Status:            stork_api.BackupVerificationStatusInProgress,
+		}
+	}
+	createRestore := func(name string, status stork_api.ApplicationRestoreStatusType, volume string, claimNamespace string) {
+		restore := &stork_api.ApplicationRestore{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "admin"},
+			Status: stork_api.ApplicationRestoreStatus{
+				Status: status,
+				Volumes: []*stork_api.ApplicationRestoreVolumeInfo{
+					{PersistentVolumeClaim: "pvc", RestoreVolume: volume},
+				},
+			},
+		}
+		_, err := storkops.Instance().CreateApplicationRestore(restore)
+		require.NoError(t, err, "Error creating restore")
+		_, err = fakeKubeClient.CoreV1().PersistentVolumes().Create(&v1.PersistentVolume{
+			ObjectMeta: meta.ObjectMeta{Name: volume},
+			Spec: v1.PersistentVolumeSpec{
+				PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRetain,
+				ClaimRef:                      &v1.ObjectReference{Namespace: claimNamespace, Name: "pvc"},
+			},
+		})
+		require.NoError(t, err, "Error creating volume")
+	}
+
+	successful := newVerification("successful", time.Now())
+	createRestore("successful", stork_api.ApplicationRestoreStatusSuccessful, "pv-successful", "successful-ns")
+	inProgress := newVerification("inprogress", time.Now())
+	createRestore("inprogress", stork_api.ApplicationRestoreStatusInProgress, "pv-inprogress", "inprogress-ns")
+	timedOut := newVerification("timedout", time.Now().Add(-2*defaultVerificationTimeout))
+	createRestore("timedout", stork_api.ApplicationRestoreStatusInProgress, "pv-timedout", "timedout-ns")
+	deleted := newVerification("deleted", time.Now())
+	// Namespaces that already existed shouldn't be deleted along with their
+	// volumes
+	existing := newVerification("existing", time.Now())
+	_, err := core.Instance().CreateNamespace("app", nil)
+	require.NoError(t, err, "Error creating namespace")
+	existing.Namespaces = append(existing.Namespaces, "app")
+	createRestore("existing", stork_api.ApplicationRestoreStatusSuccessful, "pv-existing", "app")
+	verificationSchedule := &stork_api.BackupVerificationSchedule{
+		ObjectMeta: meta.ObjectMeta{Name: "schedule", Namespace: "admin"},
+		Status: stork_api.BackupVerificationScheduleStatus{
+			Items: map[stork_api.SchedulePolicyType][]*stork_api.ScheduledBackupVerificationStatus{
+				stork_api.SchedulePolicyTypeDaily: {successful, inProgress, timedOut, deleted, existing},
+			},
+		},
+	}
+
+	require.True(t, controller.updateVerifications(verificationSchedule), "Schedule should have been updated")
+
+	require.Equal(t, stork_api.BackupVerificationStatusSuccessful, successful.Status)
+	require.Equal(t, stork_api.BackupVerificationStatusInProgress, inProgress.Status)
+	require.Equal(t, stork_api.BackupVerificationStatusFailed, timedOut.Status)
+	require.Contains(t, timedOut.Reason, "Timed out")
+	require.Equal(t, stork_api.BackupVerificationStatusFailed, deleted.Status)
+	require.Contains(t, deleted.Reason, "was deleted")
+	_, err = storkops.Instance().GetApplicationRestore("timedout", "admin")
+	require.True(t, errors.IsNotFound(err), "Restore should be deleted after timing out")
+
+	// The restored volumes should be recorded for all the restores, and the
+	// ones for completed verifications should be deleted with the namespaces
+	require.Equal(t, []string{"pv-inprogress"}, inProgress.Volumes)
+	require.False(t, inProgress.Cleaned)
+	for _, verification := range []*stork_api.ScheduledBackupVerificationStatus{successful, timedOut} {
+		require.Equal(t, []string{"pv-" + verification.Name}, verification.Volumes)
+		require.True(t, verification.Cleaned, "Verification %v should be cleaned up", verification.Name)
+		_, err := core.Instance().GetNamespace(verification.Namespaces[0])
+		require.True(t, errors.IsNotFound(err), "Namespace for %v should be deleted", verification.Name)
+		pv, err := fakeKubeClient.CoreV1().PersistentVolumes().Get("pv-"+verification.Name, meta.GetOptions{})
+		require.NoError(t, err, "Error getting volume")
+		require.Equal(t, v1.PersistentVolumeReclaimDelete, pv.Spec.PersistentVolumeReclaimPolicy)
+	}
+	pv, err := fakeKubeClient.CoreV1().PersistentVolumes().Get("pv-inprogress", meta.GetOptions{})
+	require.NoError(t, err, "Error getting volume")
+	require.Equal(t, v1.PersistentVolumeReclaimRetain, pv.Spec.PersistentVolumeReclaimPolicy,
+		"Volumes for verifications in progress shouldn't be changed")
+	require.True(t, deleted.Cleaned)
+	require.True(t, existing.Cleaned)
+	_, err = core.Instance().GetNamespace("existing-ns")
+	require.True(t, errors.IsNotFound(err), "Namespace created for the verification should be deleted")
+	_, err = core.Instance().GetNamespace("app")
+	require.NoError(t, err, "Namespace that already existed shouldn't be deleted")
+	pv, err = fakeKubeClient.CoreV1().PersistentVolumes().Get("pv-existing", meta.GetOptions{})
+	require.NoError(t, err, "Error getting volume")
+	require.Equal(t, v1.PersistentVolumeReclaimRetain, pv.Spec.PersistentVolumeReclaimPolicy,
+		"Volumes in namespaces that already existed shouldn't be changed")
+
+	// Nothing should change until the restore in progress completes
+	require.False(t, controller.updateVerifications(verificationSchedule), "Schedule shouldn't have been updated")
+}
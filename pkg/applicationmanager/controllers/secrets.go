@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"fmt"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/core"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// validateSecretsMode checks that the SecretsMode for the backup is valid and
+// that the key to encrypt the Secrets can be read if they need to be encrypted
+func validateSecretsMode(backup *stork_api.ApplicationBackup) error {
+	switch backup.Spec.SecretsMode {
+	case "",
+		stork_api.ApplicationBackupSecretsModeInclude,
+		stork_api.ApplicationBackupSecretsModeExclude:
+		return nil
+	case stork_api.ApplicationBackupSecretsModeEncrypt:
+		_, err := getSecretsEncryptionKey(backup.Spec.SecretsEncryptionKey, backup.Namespace)
+		return err
+	}
+	return fmt.Errorf("invalid SecretsMode %v", backup.Spec.SecretsMode)
+}
+
+// getSecretsEncryptionKey reads the key used to encrypt Secrets from the
+// Secret in the given namespace
+func getSecretsEncryptionKey(selector *v1.SecretKeySelector, namespace string) (string, error) {
+	if selector == nil || selector.Name == "" || selector.Key == "" {
+		return "", fmt.Errorf("SecretsEncryptionKey is required when SecretsMode is set to %v",
+			stork_api.ApplicationBackupSecretsModeEncrypt)
+	}
+	secret, err := core.Instance().GetSecret(selector.Name, namespace)
+	if err != nil {
+		return "", fmt.Errorf("error getting secret %v for SecretsEncryptionKey: %v", selector.Name, err)
+	}
+	key, ok := secret.Data[selector.Key]
+	if !ok || len(key) == 0 {
+		return "", fmt.Errorf("key %v not found in secret %v for SecretsEncryptionKey", selector.Key, selector.Name)
+	}
+	return string(key), nil
+}
+
+func isSecret(object runtime.Unstructured) bool {
+	gvk := object.GetObjectKind().GroupVersionKind()
+	return gvk.Group == "" && gvk.Kind == "Secret"
+}
+
+// splitSecrets separates the Secrets from the rest of the objects
+func splitSecrets(objects []runtime.Unstructured) ([]runtime.Unstructured, []runtime.Unstructured) {
+	resources := make([]runtime.Unstructured, 0)
+	secrets := make([]runtime.Unstructured, 0)
+	for _, o := range objects {
+		if isSecret(o) {
+			secrets = append(secrets, o)
+		} else {
+			resources = append(resources, o)
+		}
+	}
+	return resources, secrets
+}
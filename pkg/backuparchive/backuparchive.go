@@ -0,0 +1,673 @@
+// Package backuparchive implements portable archives for ApplicationBackups, so
+// that a backup can be handed over or moved between BackupLocations that can't
+// reach each other.
+//
+// An archive is a gzip compressed tar file which is encrypted in chunks with
+// a passphrase (see crypto.NewEncryptWriter), so that archives are streamed
+// and never need to fit in memory. The tar file has the following entries, in
+// this order:
+//
+//	manifest.json   The Manifest for the archive, always the first entry
+//	metadata.json   The ApplicationBackup object for the backup, always the
+//	                second entry
+//	resources.json  The resources that were backed up
+//	secrets.json    The Secrets, still encrypted with their own key, for
+//	                backups taken with SecretsMode set to Encrypt
+//	objects/<name>  Any other objects stored under the path of the backup in
+//	                the BackupLocation
+//	volumes/<index>/<name>
+//	                The objects with the data for the volume backup at the
+//	                index in the status of the backup, for drivers that store
+//	                it in the BackupLocation (see volume.BackupDataPathOption)
+//
+// The entries are stored unencrypted inside the archive, they are encrypted
+// again with the encryption key of the BackupLocation they are imported into.
+// Only the entries listed in the manifest are imported, and their names must
+// be clean relative paths. Volume backups that drivers store outside of the
+// BackupLocation, like cloud snapshots, aren't included. They are listed in
+// the manifest and can only be restored where the driver can access them.
+//
+// The version in the manifest is incremented whenever the layout changes in a
+// way that older versions can't read.
+package backuparchive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/sirupsen/logrus"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// Version is the version of the archive format that is created
+	Version = 1
+
+	manifestEntryName  = "manifest.json"
+	resourcesEntryName = "resources.json"
+	secretsEntryName   = "secrets.json"
+	objectsEntryPrefix = "objects/"
+	volumesEntryPrefix = "volumes/"
+
+	// volumeDataDir is the directory under the path of an imported backup
+	// in which the data for its volume backups is uploaded
+	volumeDataDir = ".volume-data"
+)
+
+// Manifest describes the contents of an archive
+type Manifest struct {
+	// Version of the archive format
+	Version           int         `json:"version"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+	BackupName        string      `json:"backupName"`
+	BackupNamespace   string      `json:"backupNamespace"`
+	// Objects are the names of the other objects included from the path of
+	// the backup, relative to that path
+	Objects []string `json:"objects"`
+	// Volumes are the volume backups that are part of the backup
+	Volumes []*VolumeInfo `json:"volumes"`
+}
+
+// VolumeInfo is a volume backup that is part of the archived backup
+type VolumeInfo struct {
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+	Namespace             string `json:"namespace"`
+	Volume                string `json:"volume"`
+	DriverName            string `json:"driverName"`
+	BackupID              string `json:"backupID"`
+	// DataObjects are the names of the objects with the data for the volume
+	// backup that are included in the archive, relative to the data path of
+	// the volume backup. Empty if the driver stores the data outside of the
+	// BackupLocation
+	DataObjects []string `json:"dataObjects"`
+}
+
+// archiveObject is an object from the BackupLocation to be written to an
+// entry of the archive
+type archiveObject struct {
+	entryName string
+	key       string
+	size      int64
+}
+
+// Export writes an archive for the backup stored at the given path in the
+// backup location to w. The archive is encrypted with the passphrase
+func Export(
+	backupLocation *stork_api.BackupLocation,
+	backupPath string,
+	passphrase string,
+	w io.Writer,
+) (*Manifest, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is required to encrypt the archive")
+	}
+	bucket, err := objectstore.GetBucket(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	backup, err := objectstore.ReadBackupMetadata(backupLocation, bucket, backupPath)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := json.MarshalIndent(backup, "", " ")
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Version:           Version,
+		CreationTimestamp: metav1.Now(),
+		BackupName:        backup.Name,
+		BackupNamespace:   backup.Namespace,
+		Objects:           make([]string, 0),
+		Volumes:           make([]*VolumeInfo, 0),
+	}
+
+	// List the objects first since the manifest needs to be written before
+	// them. The data stored by drivers for the volume backups is listed
+	// separately so that it can be moved along with the volume backups
+	archiveObjects := make([]archiveObject, 0)
+	dataPaths := make([]string, 0)
+	for i, vInfo := range backup.Status.Volumes {
+		info := &VolumeInfo{
+			PersistentVolumeClaim: vInfo.PersistentVolumeClaim,
+			Namespace:             vInfo.Namespace,
+			Volume:                vInfo.Volume,
+			DriverName:            vInfo.DriverName,
+			BackupID:              vInfo.BackupID,
+			DataObjects:           make([]string, 0),
+		}
+		manifest.Volumes = append(manifest.Volumes, info)
+		dataPath := vInfo.Options[volume.BackupDataPathOption]
+		if dataPath == "" {
+			continue
+		}
+		if err := validateObjectName(dataPath); err != nil {
+			return nil, fmt.Errorf("invalid data path for volume %v: %v", vInfo.Volume, err)
+		}
+		dataPaths = append(dataPaths, dataPath+"/")
+		objects, err := listObjects(bucket, dataPath+"/")
+		if err != nil {
+			return nil, fmt.Errorf("error listing data for volume %v: %v", vInfo.Volume, err)
+		}
+		for _, object := range objects {
+			name := strings.TrimPrefix(object.Key, dataPath+"/")
+			info.DataObjects = append(info.DataObjects, name)
+			archiveObjects = append(archiveObjects, archiveObject{
+				entryName: fmt.Sprintf("%v%v/%v", volumesEntryPrefix, i, name),
+				key:       object.Key,
+				size:      object.Size,
+			})
+		}
+	}
+
+	prefix := backupPath + "/"
+	objects, err := listObjects(bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("error listing objects for backup: %v", err)
+	}
+	foundResources := false
+	encryptedEntries := make([]string, 0)
+	for _, object := range objects {
+		name := strings.TrimPrefix(object.Key, prefix)
+		switch name {
+		case objectstore.BackupMetadataObjectName:
+		case resourcesEntryName, secretsEntryName:
+			encryptedEntries = append(encryptedEntries, name)
+			foundResources = foundResources || name == resourcesEntryName
+		default:
+			if underDataPath(object.Key, dataPaths) {
+				continue
+			}
+			manifest.Objects = append(manifest.Objects, name)
+			archiveObjects = append(archiveObjects, archiveObject{
+				entryName: objectsEntryPrefix + name,
+				key:       object.Key,
+				size:      object.Size,
+			})
+		}
+	}
+	if !foundResources {
+		return nil, fmt.Errorf("resources not found for backup at %v", backupPath)
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", " ")
+	if err != nil {
+		return nil, err
+	}
+
+	encryptWriter, err := crypto.NewEncryptWriter(w, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("error encrypting archive: %v", err)
+	}
+	gzipWriter := gzip.NewWriter(encryptWriter)
+	tarWriter := tar.NewWriter(gzipWriter)
+	if err := writeEntry(tarWriter, manifestEntryName, manifestData); err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tarWriter, objectstore.BackupMetadataObjectName, metadata); err != nil {
+		return nil, err
+	}
+	// The resources and secrets are stored decrypted, they are small enough
+	// to be read into memory
+	for _, name := range encryptedEntries {
+		data, err := bucket.ReadAll(context.TODO(), prefix+name)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %v: %v", name, err)
+		}
+		if backupLocation.Location.EncryptionKey != "" {
+			if data, err = crypto.Decrypt(data, backupLocation.Location.EncryptionKey); err != nil {
+				return nil, fmt.Errorf("error decrypting %v: %v", name, err)
+			}
+		}
+		if err := writeEntry(tarWriter, name, data); err != nil {
+			return nil, err
+		}
+	}
+	for _, object := range archiveObjects {
+		if err := copyObjectToArchive(tarWriter, bucket, object.key, object.entryName, object.size); err != nil {
+			return nil, err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := encryptWriter.Close(); err != nil {
+		return nil, fmt.Errorf("error encrypting archive: %v", err)
+	}
+	return manifest, nil
+}
+
+// Import uploads the backup in the archive read from r to the backup
+// location, with the given name in the namespace of the backup location. The
+// name of the backup in the archive is used if no name is given. Returns the
+// imported backup, which can be used to create an ApplicationBackup for it.
+// The objects that were uploaded are deleted if the import fails
+func Import(
+	backupLocation *stork_api.BackupLocation,
+	r io.Reader,
+	passphrase string,
+	name string,
+) (*stork_api.ApplicationBackup, *Manifest, error) {
+	tarReader, manifest, err := readManifest(r, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	bucket, err := objectstore.GetBucket(backupLocation)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	backup, uploaded, err := importEntries(tarReader, backupLocation, bucket, manifest, name)
+	if err == nil {
+		// Upload the metadata last so that the backup isn't picked up
+		// before all the objects have been uploaded
+		var metadata []byte
+		if metadata, err = json.MarshalIndent(backup, "", " "); err == nil {
+			metadataKey := filepath.Join(backup.Status.BackupPath, objectstore.BackupMetadataObjectName)
+			err = uploadObject(backupLocation, bucket, metadataKey, bytes.NewReader(metadata), true)
+			if err == nil {
+				uploaded = append(uploaded, metadataKey)
+				err = objectstore.AddBackupToIndex(backupLocation, bucket, backup.Status.BackupPath, backup.Status.FinishTimestamp.Time)
+			}
+		}
+	}
+	if err != nil {
+		for _, key := range uploaded {
+			if deleteErr := bucket.Delete(context.TODO(), key); deleteErr != nil && gcerrors.Code(deleteErr) != gcerrors.NotFound {
+				logrus.Warnf("Error deleting %v after failed import: %v", key, deleteErr)
+			}
+		}
+		return nil, nil, err
+	}
+	return backup, manifest, nil
+}
+
+// importEntries uploads the entries after the manifest to the backup
+// location. Returns the backup to import and the keys of the objects that
+// were uploaded, which are also returned on errors
+func importEntries(
+	tarReader *tar.Reader,
+	backupLocation *stork_api.BackupLocation,
+	bucket *blob.Bucket,
+	manifest *Manifest,
+	name string,
+) (*stork_api.ApplicationBackup, []string, error) {
+	var backup *stork_api.ApplicationBackup
+	uploaded := make([]string, 0)
+	foundResources := false
+	foundObjects := make(map[string]bool)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, uploaded, fmt.Errorf("error reading archive: %v", err)
+		}
+
+		if header.Name == objectstore.BackupMetadataObjectName {
+			if backup, err = readBackup(tarReader, backupLocation, bucket, manifest, name); err != nil {
+				return nil, uploaded, err
+			}
+			continue
+		}
+		// Everything else is uploaded under the path of the backup, so the
+		// metadata needs to have been read first
+		if backup == nil {
+			return nil, uploaded, fmt.Errorf("%v missing from archive", objectstore.BackupMetadataObjectName)
+		}
+		key := ""
+		encrypt := false
+		switch {
+		case header.Name == resourcesEntryName || header.Name == secretsEntryName:
+			key = filepath.Join(backup.Status.BackupPath, header.Name)
+			encrypt = true
+			foundResources = foundResources || header.Name == resourcesEntryName
+		case strings.HasPrefix(header.Name, objectsEntryPrefix) || strings.HasPrefix(header.Name, volumesEntryPrefix):
+			objectName, ok := getManifestObjectName(manifest, header.Name)
+			if !ok {
+				return nil, uploaded, fmt.Errorf("%v in archive isn't listed in the manifest", header.Name)
+			}
+			key = filepath.Join(backup.Status.BackupPath, objectName)
+		default:
+			continue
+		}
+		if foundObjects[key] {
+			return nil, uploaded, fmt.Errorf("%v found more than once in archive", header.Name)
+		}
+		foundObjects[key] = true
+		uploaded = append(uploaded, key)
+		if err := uploadObject(backupLocation, bucket, key, tarReader, encrypt); err != nil {
+			return nil, uploaded, err
+		}
+	}
+
+	if backup == nil {
+		return nil, uploaded, fmt.Errorf("%v missing from archive", objectstore.BackupMetadataObjectName)
+	}
+	if !foundResources {
+		return nil, uploaded, fmt.Errorf("%v missing from archive", resourcesEntryName)
+	}
+	for _, objectName := range manifest.Objects {
+		if !foundObjects[filepath.Join(backup.Status.BackupPath, objectName)] {
+			return nil, uploaded, fmt.Errorf("object %v missing from archive", objectName)
+		}
+	}
+	for i, vInfo := range manifest.Volumes {
+		for _, objectName := range vInfo.DataObjects {
+			if !foundObjects[filepath.Join(backup.Status.BackupPath, getVolumeDataPath(i), objectName)] {
+				return nil, uploaded, fmt.Errorf("data object %v for volume %v missing from archive", objectName, vInfo.Volume)
+			}
+		}
+	}
+	return backup, uploaded, nil
+}
+
+// getManifestObjectName returns the name of the object for an objects or
+// volumes entry relative to the path of the imported backup. Returns false if
+// the object isn't listed in the manifest
+func getManifestObjectName(manifest *Manifest, entryName string) (string, bool) {
+	if strings.HasPrefix(entryName, objectsEntryPrefix) {
+		objectName := strings.TrimPrefix(entryName, objectsEntryPrefix)
+		for _, name := range manifest.Objects {
+			if name == objectName {
+				return objectName, true
+			}
+		}
+		return "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(entryName, volumesEntryPrefix), "/", 2)
+	if len(parts) != 2 {
+		return "", false
+	}
+	index, err := strconv.Atoi(parts[0])
+	if err != nil || index < 0 || index >= len(manifest.Volumes) || strconv.Itoa(index) != parts[0] {
+		return "", false
+	}
+	for _, name := range manifest.Volumes[index].DataObjects {
+		if name == parts[1] {
+			return filepath.Join(getVolumeDataPath(index), name), true
+		}
+	}
+	return "", false
+}
+
+// getVolumeDataPath returns the path, relative to the path of an imported
+// backup, under which the data for the volume backup at the index is stored
+func getVolumeDataPath(index int) string {
+	return filepath.Join(volumeDataDir, strconv.Itoa(index))
+}
+
+// validateManifest checks that the names of the objects in the manifest are
+// clean relative paths that can't be used to write outside of the path of
+// the imported backup or to replace its other objects
+func validateManifest(manifest *Manifest) error {
+	for _, name := range manifest.Objects {
+		if err := validateObjectName(name); err != nil {
+			return fmt.Errorf("invalid object in manifest: %v", err)
+		}
+		switch {
+		case name == objectstore.BackupMetadataObjectName,
+			name == resourcesEntryName,
+			name == secretsEntryName,
+			name == volumeDataDir,
+			strings.HasPrefix(name, volumeDataDir+"/"):
+			return fmt.Errorf("invalid object in manifest: %v is reserved", name)
+		}
+	}
+	for _, vInfo := range manifest.Volumes {
+		for _, name := range vInfo.DataObjects {
+			if err := validateObjectName(name); err != nil {
+				return fmt.Errorf("invalid data object for volume %v in manifest: %v", vInfo.Volume, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateObjectName checks that the name is a clean relative path without
+// any parent directory references
+func validateObjectName(name string) error {
+	if name == "" || name != path.Clean(name) || path.IsAbs(name) {
+		return fmt.Errorf("%q is not a clean relative path", name)
+	}
+	for _, element := range strings.Split(name, "/") {
+		if element == ".." || element == "." {
+			return fmt.Errorf("%q is not a clean relative path", name)
+		}
+	}
+	return nil
+}
+
+// readManifest decrypts the archive and reads the manifest, which is the
+// first entry. Returns the reader for the remaining entries
+func readManifest(
+	r io.Reader,
+	passphrase string,
+) (*tar.Reader, *Manifest, error) {
+	decryptReader, err := crypto.NewDecryptReader(r, passphrase)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decrypting archive: %v", err)
+	}
+	gzipReader, err := gzip.NewReader(decryptReader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decrypting archive: %v", err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	header, err := tarReader.Next()
+	if err == io.EOF || (err == nil && header.Name != manifestEntryName) {
+		return nil, nil, fmt.Errorf("manifest missing from archive")
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("error reading archive: %v", err)
+	}
+	data, err := ioutil.ReadAll(tarReader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading %v from archive: %v", header.Name, err)
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, nil, fmt.Errorf("error parsing manifest: %v", err)
+	}
+	if manifest.Version > Version {
+		return nil, nil, fmt.Errorf("unsupported archive version %v, only versions up to %v are supported", manifest.Version, Version)
+	}
+	if err := validateManifest(manifest); err != nil {
+		return nil, nil, err
+	}
+	return tarReader, manifest, nil
+}
+
+// readBackup reads the backup from the metadata entry and updates it to be
+// imported into the backup location. The path of the backup in the location
+// can't already have a backup
+func readBackup(
+	tarReader *tar.Reader,
+	backupLocation *stork_api.BackupLocation,
+	bucket *blob.Bucket,
+	manifest *Manifest,
+	name string,
+) (*stork_api.ApplicationBackup, error) {
+	data, err := ioutil.ReadAll(tarReader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %v from archive: %v", objectstore.BackupMetadataObjectName, err)
+	}
+	backup := &stork_api.ApplicationBackup{}
+	if err := json.Unmarshal(data, backup); err != nil {
+		return nil, fmt.Errorf("error parsing metadata: %v", err)
+	}
+	if name != "" {
+		backup.Name = name
+	}
+	// The name and ID are used in the path of the backup, so they can't be
+	// allowed to point to other paths in the location
+	if errs := validation.IsDNS1123Subdomain(backup.Name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid backup name %q: %v", backup.Name, strings.Join(errs, ", "))
+	}
+	backup.Namespace = backupLocation.Namespace
+	backupID := string(backup.UID)
+	if backupID == "" {
+		backupID = manifest.CreationTimestamp.Format("2006-01-02-150405")
+	}
+	if err := validateObjectName(backupID); err != nil || strings.Contains(backupID, "/") {
+		return nil, fmt.Errorf("invalid backup ID %q", backupID)
+	}
+	backupPath := filepath.Join(backup.Namespace, backup.Name, backupID)
+	backup.Spec.BackupLocation = backupLocation.Name
+	backup.Spec.ReplicaBackupLocations = nil
+	backup.Status.BackupPath = backupPath
+	backup.Status.Replicas = nil
+
+	// Point the volume backups to the data imported with them
+	for i, vInfo := range manifest.Volumes {
+		if len(vInfo.DataObjects) == 0 {
+			continue
+		}
+		if i >= len(backup.Status.Volumes) || backup.Status.Volumes[i].Volume != vInfo.Volume {
+			return nil, fmt.Errorf("volume %v in manifest doesn't match the volumes of the backup", vInfo.Volume)
+		}
+		if backup.Status.Volumes[i].Options == nil {
+			backup.Status.Volumes[i].Options = make(map[string]string)
+		}
+		backup.Status.Volumes[i].Options[volume.BackupDataPathOption] = filepath.Join(backupPath, getVolumeDataPath(i))
+	}
+
+	metadataKey := filepath.Join(backupPath, objectstore.BackupMetadataObjectName)
+	if _, err := bucket.Attributes(context.TODO(), metadataKey); err == nil {
+		return nil, fmt.Errorf("backup already exists at %v in BackupLocation %v", backupPath, backupLocation.Name)
+	} else if gcerrors.Code(err) != gcerrors.NotFound {
+		return nil, err
+	}
+	return backup, nil
+}
+
+// listObjects returns the objects in the bucket with the given prefix
+func listObjects(bucket *blob.Bucket, prefix string) ([]*blob.ListObject, error) {
+	objects := make([]*blob.ListObject, 0)
+	iterator := bucket.List(&blob.ListOptions{
+		Prefix: prefix,
+	})
+	for {
+		object, err := iterator.Next(context.TODO())
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// underDataPath returns true if the key is under one of the data paths of the
+// volume backups
+func underDataPath(key string, dataPaths []string) bool {
+	for _, dataPath := range dataPaths {
+		if strings.HasPrefix(key, dataPath) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeEntry(tarWriter *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("error writing %v to archive: %v", name, err)
+	}
+	if _, err := tarWriter.Write(data); err != nil {
+		return fmt.Errorf("error writing %v to archive: %v", name, err)
+	}
+	return nil
+}
+
+// copyObjectToArchive streams an object from the bucket into an entry in the
+// archive
+func copyObjectToArchive(
+	tarWriter *tar.Writer,
+	bucket *blob.Bucket,
+	key string,
+	name string,
+	size int64,
+) error {
+	reader, err := bucket.NewReader(context.TODO(), key, nil)
+	if err != nil {
+		return fmt.Errorf("error reading %v: %v", key, err)
+	}
+	defer reader.Close() // nolint: errcheck
+
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    size,
+		ModTime: time.Now(),
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("error writing %v to archive: %v", name, err)
+	}
+	if _, err := io.Copy(tarWriter, reader); err != nil {
+		return fmt.Errorf("error writing %v to archive: %v", name, err)
+	}
+	return nil
+}
+
+// uploadObject uploads the data from the reader to the bucket. Data that
+// needs to be encrypted is read into memory since it is encrypted as a
+// whole, so it is only used for the metadata, resources and secrets
+func uploadObject(
+	backupLocation *stork_api.BackupLocation,
+	bucket *blob.Bucket,
+	key string,
+	reader io.Reader,
+	encrypt bool,
+) error {
+	if encrypt && backupLocation.Location.EncryptionKey != "" {
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("error reading %v: %v", key, err)
+		}
+		if data, err = crypto.Encrypt(data, backupLocation.Location.EncryptionKey); err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	writer, err := bucket.NewWriter(context.TODO(), key, objectstore.GetWriterOptions(backupLocation))
+	if err != nil {
+		return fmt.Errorf("error uploading %v: %v", key, err)
+	}
+	if _, err := io.Copy(writer, reader); err != nil {
+		_ = writer.Close()
+		return fmt.Errorf("error uploading %v: %v", key, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error uploading %v: %v", key, err)
+	}
+	return nil
+}
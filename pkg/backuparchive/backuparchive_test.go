@@ -0,0 +1,329 @@
+// +build unittest
+
+package backuparchive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const testPassphrase = "archivekey"
+
+func newTestLocation(t *testing.T, name string, encryptionKey string) *stork_api.BackupLocation {
+	return &stork_api.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+		},
+		Location: stork_api.BackupLocationItem{
+			Type:          stork_api.BackupLocationFilesystem,
+			Path:          t.TempDir(),
+			EncryptionKey: encryptionKey,
+		},
+	}
+}
+
+// uploadTestBackup uploads a backup with resources and a volume object to the
+// location and returns its path
+func uploadTestBackup(t *testing.T, location *stork_api.BackupLocation, volumeData []byte) string {
+	backup := &stork_api.ApplicationBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup",
+			Namespace: location.Namespace,
+			UID:       "backup-uid",
+		},
+		Spec: stork_api.ApplicationBackupSpec{
+			BackupLocation: location.Name,
+		},
+		Status: stork_api.ApplicationBackupStatus{
+			Volumes: []*stork_api.ApplicationBackupVolumeInfo{
+				{PersistentVolumeClaim: "pvc", Namespace: "app", Volume: "vol", DriverName: "kdmp", BackupID: "id"},
+			},
+		},
+	}
+	backupPath := filepath.Join(location.Namespace, "backup", "backup-uid")
+	backup.Status.BackupPath = backupPath
+	bucket, err := objectstore.GetBucket(location)
+	require.NoError(t, err, "Error getting bucket")
+	defer bucket.Close()
+
+	metadata, err := json.Marshal(backup)
+	require.NoError(t, err)
+	for name, data := range map[string][]byte{
+		objectstore.BackupMetadataObjectName: metadata,
+		resourcesEntryName:                   []byte(`[{"kind":"ConfigMap"}]`),
+	} {
+		if location.Location.EncryptionKey != "" {
+			data, err = crypto.Encrypt(data, location.Location.EncryptionKey)
+			require.NoError(t, err)
+		}
+		err = bucket.WriteAll(context.TODO(), filepath.Join(backupPath, name), data, nil)
+		require.NoError(t, err, "Error uploading %v", name)
+	}
+	err = bucket.WriteAll(context.TODO(), filepath.Join(backupPath, "volumes", "vol"), volumeData, nil)
+	require.NoError(t, err, "Error uploading volume data")
+	return backupPath
+}
+
+// writeTestArchive writes an archive with the given entries in order
+func writeTestArchive(t *testing.T, entries ...[2]string) *bytes.Buffer {
+	var buf bytes.Buffer
+	encryptWriter, err := crypto.NewEncryptWriter(&buf, testPassphrase)
+	require.NoError(t, err)
+	gzipWriter := gzip.NewWriter(encryptWriter)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, entry := range entries {
+		require.NoError(t, writeEntry(tarWriter, entry[0], []byte(entry[1])))
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	require.NoError(t, encryptWriter.Close())
+	return &buf
+}
+
+func TestExportImport(t *testing.T) {
+	source := newTestLocation(t, "source", "sourcekey")
+	dest := newTestLocation(t, "dest", "destkey")
+	// Volume data larger than a chunk of the archive encryption
+	volumeData := bytes.Repeat([]byte("volumedata"), 20000)
+	backupPath := uploadTestBackup(t, source, volumeData)
+
+	var archive bytes.Buffer
+	_, err := Export(source, backupPath, "", &archive)
+	require.Error(t, err, "Export should fail without passphrase")
+	manifest, err := Export(source, backupPath, testPassphrase, &archive)
+	require.NoError(t, err, "Error exporting backup")
+	require.Equal(t, Version, manifest.Version)
+	require.Equal(t, []string{"volumes/vol"}, manifest.Objects)
+	require.Len(t, manifest.Volumes, 1)
+	require.Equal(t, "kdmp", manifest.Volumes[0].DriverName)
+
+	_, _, err = Import(dest, bytes.NewReader(archive.Bytes()), "wrongkey", "imported")
+	require.Error(t, err, "Import should fail with wrong passphrase")
+	require.Contains(t, err.Error(), "error decrypting archive")
+
+	backup, importedManifest, err := Import(dest, bytes.NewReader(archive.Bytes()), testPassphrase, "imported")
+	require.NoError(t, err, "Error importing backup")
+	require.Equal(t, manifest.Objects, importedManifest.Objects)
+	require.Equal(t, "imported", backup.Name)
+	require.Equal(t, "dest", backup.Spec.BackupLocation)
+	require.Equal(t, "ns/imported/backup-uid", backup.Status.BackupPath)
+
+	// Resources are encrypted with the key of the destination, other objects
+	// are copied as is
+	bucket, err := objectstore.GetBucket(dest)
+	require.NoError(t, err, "Error getting bucket")
+	defer bucket.Close()
+	importedBackup, err := objectstore.ReadBackupMetadata(dest, bucket, backup.Status.BackupPath)
+	require.NoError(t, err, "Error reading imported metadata")
+	require.Equal(t, backup.Name, importedBackup.Name)
+	data, err := bucket.ReadAll(context.TODO(), filepath.Join(backup.Status.BackupPath, resourcesEntryName))
+	require.NoError(t, err, "Error reading imported resources")
+	data, err = crypto.Decrypt(data, "destkey")
+	require.NoError(t, err, "Error decrypting imported resources")
+	require.Equal(t, `[{"kind":"ConfigMap"}]`, string(data))
+	data, err = bucket.ReadAll(context.TODO(), filepath.Join(backup.Status.BackupPath, "volumes", "vol"))
+	require.NoError(t, err, "Error reading imported volume data")
+	require.Equal(t, volumeData, data)
+
+	_, _, err = Import(dest, bytes.NewReader(archive.Bytes()), testPassphrase, "imported")
+	require.Error(t, err, "Import should fail when the backup already exists")
+	require.Contains(t, err.Error(), "backup already exists")
+}
+
+func TestImportVersion(t *testing.T) {
+	dest := newTestLocation(t, "dest", "")
+	manifest, err := json.Marshal(&Manifest{Version: Version + 1})
+	require.NoError(t, err)
+	archive := writeTestArchive(t, [2]string{manifestEntryName, string(manifest)})
+
+	_, _, err = Import(dest, archive, testPassphrase, "")
+	require.Error(t, err, "Import should fail for newer versions")
+	require.Contains(t, err.Error(), "unsupported archive version 2")
+}
+
+func TestImportMissingEntries(t *testing.T) {
+	manifest, err := json.Marshal(&Manifest{Version: Version, Objects: []string{"volumes/vol"}})
+	require.NoError(t, err)
+	metadata, err := json.Marshal(&stork_api.ApplicationBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", UID: "backup-uid"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		entries  [][2]string
+		expected string
+	}{
+		{
+			name:     "empty archive",
+			entries:  nil,
+			expected: "manifest missing from archive",
+		},
+		{
+			name:     "manifest isn't first",
+			entries:  [][2]string{{objectstore.BackupMetadataObjectName, string(metadata)}, {manifestEntryName, string(manifest)}},
+			expected: "manifest missing from archive",
+		},
+		{
+			name:     "missing metadata",
+			entries:  [][2]string{{manifestEntryName, string(manifest)}, {resourcesEntryName, "[]"}},
+			expected: "metadata.json missing from archive",
+		},
+		{
+			name:     "missing resources",
+			entries:  [][2]string{{manifestEntryName, string(manifest)}, {objectstore.BackupMetadataObjectName, string(metadata)}},
+			expected: "resources.json missing from archive",
+		},
+		{
+			name: "missing object",
+			entries: [][2]string{
+				{manifestEntryName, string(manifest)},
+				{objectstore.BackupMetadataObjectName, string(metadata)},
+				{resourcesEntryName, "[]"},
+			},
+			expected: "object volumes/vol missing from archive",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dest := newTestLocation(t, "dest", "")
+			_, _, err := Import(dest, writeTestArchive(t, test.entries...), testPassphrase, "")
+			require.Error(t, err, "Import should fail")
+			require.Contains(t, err.Error(), test.expected)
+
+			// Nothing should be left in the location after a failed import
+			files, err := ioutil.ReadDir(filepath.Join(dest.Location.Path, "ns", "backup", "backup-uid"))
+			if err == nil {
+				require.Empty(t, files, "Objects should be deleted after a failed import")
+			}
+		})
+	}
+}
+
+func TestExportImportVolumeData(t *testing.T) {
+	source := newTestLocation(t, "source", "")
+	dest := newTestLocation(t, "dest", "")
+	backupPath := uploadTestBackup(t, source, []byte("volumedata"))
+
+	// Point the volume backup to data stored by the driver in the location
+	bucket, err := objectstore.GetBucket(source)
+	require.NoError(t, err, "Error getting bucket")
+	defer bucket.Close()
+	backup, err := objectstore.ReadBackupMetadata(source, bucket, backupPath)
+	require.NoError(t, err, "Error reading metadata")
+	backup.Status.Volumes[0].Options = map[string]string{volume.BackupDataPathOption: "driverdata/vol"}
+	metadata, err := json.Marshal(backup)
+	require.NoError(t, err)
+	err = bucket.WriteAll(context.TODO(), filepath.Join(backupPath, objectstore.BackupMetadataObjectName), metadata, nil)
+	require.NoError(t, err, "Error uploading metadata")
+	err = bucket.WriteAll(context.TODO(), "driverdata/vol/chunk1", []byte("chunk"), nil)
+	require.NoError(t, err, "Error uploading volume data")
+
+	var archive bytes.Buffer
+	manifest, err := Export(source, backupPath, testPassphrase, &archive)
+	require.NoError(t, err, "Error exporting backup")
+	require.Equal(t, []string{"chunk1"}, manifest.Volumes[0].DataObjects)
+
+	imported, _, err := Import(dest, bytes.NewReader(archive.Bytes()), testPassphrase, "")
+	require.NoError(t, err, "Error importing backup")
+	dataPath := imported.Status.Volumes[0].Options[volume.BackupDataPathOption]
+	require.Equal(t, filepath.Join(imported.Status.BackupPath, ".volume-data", "0"), dataPath)
+	data, err := ioutil.ReadFile(filepath.Join(dest.Location.Path, dataPath, "chunk1"))
+	require.NoError(t, err, "Error reading imported volume data")
+	require.Equal(t, "chunk", string(data))
+
+	// Imported backups are added to the index of the location
+	backups, err := objectstore.ListBackups(dest)
+	require.NoError(t, err, "Error listing backups")
+	require.Len(t, backups, 1)
+	require.Equal(t, imported.Status.BackupPath, backups[0].Status.BackupPath)
+}
+
+func TestImportInvalidNames(t *testing.T) {
+	newManifest := func(objects ...string) string {
+		manifest, err := json.Marshal(&Manifest{Version: Version, Objects: objects})
+		require.NoError(t, err)
+		return string(manifest)
+	}
+	newMetadata := func(name string, uid string) string {
+		metadata, err := json.Marshal(&stork_api.ApplicationBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(uid)},
+		})
+		require.NoError(t, err)
+		return string(metadata)
+	}
+
+	tests := []struct {
+		name     string
+		entries  [][2]string
+		expected string
+	}{
+		{
+			name:     "object outside of backup path",
+			entries:  [][2]string{{manifestEntryName, newManifest("../../other/uid/resources.json")}},
+			expected: "is not a clean relative path",
+		},
+		{
+			name:     "object replacing metadata",
+			entries:  [][2]string{{manifestEntryName, newManifest("metadata.json")}},
+			expected: "metadata.json is reserved",
+		},
+		{
+			name: "backup name outside of namespace",
+			entries: [][2]string{
+				{manifestEntryName, newManifest()},
+				{objectstore.BackupMetadataObjectName, newMetadata("../other", "backup-uid")},
+			},
+			expected: "invalid backup name",
+		},
+		{
+			name: "backup ID outside of backup",
+			entries: [][2]string{
+				{manifestEntryName, newManifest()},
+				{objectstore.BackupMetadataObjectName, newMetadata("backup", "../other")},
+			},
+			expected: "invalid backup ID",
+		},
+		{
+			name: "object not in manifest",
+			entries: [][2]string{
+				{manifestEntryName, newManifest()},
+				{objectstore.BackupMetadataObjectName, newMetadata("backup", "backup-uid")},
+				{objectsEntryPrefix + "../../other/uid/resources.json", "[]"},
+			},
+			expected: "isn't listed in the manifest",
+		},
+		{
+			name: "volume data not in manifest",
+			entries: [][2]string{
+				{manifestEntryName, newManifest()},
+				{objectstore.BackupMetadataObjectName, newMetadata("backup", "backup-uid")},
+				{volumesEntryPrefix + "0/chunk", "data"},
+			},
+			expected: "isn't listed in the manifest",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dest := newTestLocation(t, "dest", "")
+			_, _, err := Import(dest, writeTestArchive(t, test.entries...), testPassphrase, "")
+			require.Error(t, err, "Import should fail")
+			require.Contains(t, err.Error(), test.expected)
+		})
+	}
+}
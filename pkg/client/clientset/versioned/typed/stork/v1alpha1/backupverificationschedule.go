@@ -0,0 +1,191 @@
+/*
+Copyright 2018 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	scheme "github.com/libopenstorage/stork/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// BackupVerificationSchedulesGetter has a method to return a BackupVerificationScheduleInterface.
+// A group's client should implement this interface.
+type BackupVerificationSchedulesGetter interface {
+	BackupVerificationSchedules(namespace string) BackupVerificationScheduleInterface
+}
+
+// BackupVerificationScheduleInterface has methods to work with BackupVerificationSchedule resources.
+type BackupVerificationScheduleInterface interface {
+	Create(*v1alpha1.BackupVerificationSchedule) (*v1alpha1.BackupVerificationSchedule, error)
+	Update(*v1alpha1.BackupVerificationSchedule) (*v1alpha1.BackupVerificationSchedule, error)
+	UpdateStatus(*v1alpha1.BackupVerificationSchedule) (*v1alpha1.BackupVerificationSchedule, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.BackupVerificationSchedule, error)
+	List(opts v1.ListOptions) (*v1alpha1.BackupVerificationScheduleList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.BackupVerificationSchedule, err error)
+	BackupVerificationScheduleExpansion
+}
+
+// backupVerificationSchedules implements BackupVerificationScheduleInterface
+type backupVerificationSchedules struct {
+	client rest.Interface
+	ns     string
+}
+
+// newBackupVerificationSchedules returns a BackupVerificationSchedules
+func newBackupVerificationSchedules(c *StorkV1alpha1Client, namespace string) *backupVerificationSchedules {
+	return &backupVerificationSchedules{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the backupVerificationSchedule, and returns the corresponding backupVerificationSchedule object, and an error if there is any.
+func (c *backupVerificationSchedules) Get(name string, options v1.GetOptions) (result *v1alpha1.BackupVerificationSchedule, err error) {
+	result = &v1alpha1.BackupVerificationSchedule{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("backupverificationschedules").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of BackupVerificationSchedules that match those selectors.
+func (c *backupVerificationSchedules) List(opts v1.ListOptions) (result *v1alpha1.BackupVerificationScheduleList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.BackupVerificationScheduleList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("backupverificationschedules").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested backupVerificationSchedules.
+func (c *backupVerificationSchedules) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("backupverificationschedules").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a backupVerificationSchedule and creates it.  Returns the server's representation of the backupVerificationSchedule, and an error, if there is any.
+func (c *backupVerificationSchedules) Create(backupVerificationSchedule *v1alpha1.BackupVerificationSchedule) (result *v1alpha1.BackupVerificationSchedule, err error) {
+	result = &v1alpha1.BackupVerificationSchedule{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("backupverificationschedules").
+		Body(backupVerificationSchedule).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a backupVerificationSchedule and updates it. Returns the server's representation of the backupVerificationSchedule, and an error, if there is any.
+func (c *backupVerificationSchedules) Update(backupVerificationSchedule *v1alpha1.BackupVerificationSchedule) (result *v1alpha1.BackupVerificationSchedule, err error) {
+	result = &v1alpha1.BackupVerificationSchedule{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("backupverificationschedules").
+		Name(backupVerificationSchedule.Name).
+		Body(backupVerificationSchedule).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *backupVerificationSchedules) UpdateStatus(backupVerificationSchedule *v1alpha1.BackupVerificationSchedule) (result *v1alpha1.BackupVerificationSchedule, err error) {
+	result = &v1alpha1.BackupVerificationSchedule{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("backupverificationschedules").
+		Name(backupVerificationSchedule.Name).
+		SubResource("status").
+		Body(backupVerificationSchedule).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the backupVerificationSchedule and deletes it. Returns an error if one occurs.
+func (c *backupVerificationSchedules) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("backupverificationschedules").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *backupVerificationSchedules) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("backupverificationschedules").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched backupVerificationSchedule.
+func (c *backupVerificationSchedules) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.BackupVerificationSchedule, err error) {
+	result = &v1alpha1.BackupVerificationSchedule{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("backupverificationschedules").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2018 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	scheme "github.com/libopenstorage/stork/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// DRPlansGetter has a method to return a DRPlanInterface.
+// A group's client should implement this interface.
+type DRPlansGetter interface {
+	DRPlans(namespace string) DRPlanInterface
+}
+
+// DRPlanInterface has methods to work with DRPlan resources.
+type DRPlanInterface interface {
+	Create(*v1alpha1.DRPlan) (*v1alpha1.DRPlan, error)
+	Update(*v1alpha1.DRPlan) (*v1alpha1.DRPlan, error)
+	UpdateStatus(*v1alpha1.DRPlan) (*v1alpha1.DRPlan, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.DRPlan, error)
+	List(opts v1.ListOptions) (*v1alpha1.DRPlanList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.DRPlan, err error)
+	DRPlanExpansion
+}
+
+// dRPlans implements DRPlanInterface
+type dRPlans struct {
+	client rest.Interface
+	ns     string
+}
+
+// newDRPlans returns a DRPlans
+func newDRPlans(c *StorkV1alpha1Client, namespace string) *dRPlans {
+	return &dRPlans{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the dRPlan, and returns the corresponding dRPlan object, and an error if there is any.
+func (c *dRPlans) Get(name string, options v1.GetOptions) (result *v1alpha1.DRPlan, err error) {
+	result = &v1alpha1.DRPlan{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("drplans").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of DRPlans that match those selectors.
+func (c *dRPlans) List(opts v1.ListOptions) (result *v1alpha1.DRPlanList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.DRPlanList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("drplans").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested dRPlans.
+func (c *dRPlans) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("drplans").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a dRPlan and creates it.  Returns the server's representation of the dRPlan, and an error, if there is any.
+func (c *dRPlans) Create(dRPlan *v1alpha1.DRPlan) (result *v1alpha1.DRPlan, err error) {
+	result = &v1alpha1.DRPlan{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("drplans").
+		Body(dRPlan).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a dRPlan and updates it. Returns the server's representation of the dRPlan, and an error, if there is any.
+func (c *dRPlans) Update(dRPlan *v1alpha1.DRPlan) (result *v1alpha1.DRPlan, err error) {
+	result = &v1alpha1.DRPlan{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("drplans").
+		Name(dRPlan.Name).
+		Body(dRPlan).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *dRPlans) UpdateStatus(dRPlan *v1alpha1.DRPlan) (result *v1alpha1.DRPlan, err error) {
+	result = &v1alpha1.DRPlan{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("drplans").
+		Name(dRPlan.Name).
+		SubResource("status").
+		Body(dRPlan).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the dRPlan and deletes it. Returns an error if one occurs.
+func (c *dRPlans) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("drplans").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *dRPlans) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("drplans").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched dRPlan.
+func (c *dRPlans) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.DRPlan, err error) {
+	result = &v1alpha1.DRPlan{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("drplans").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
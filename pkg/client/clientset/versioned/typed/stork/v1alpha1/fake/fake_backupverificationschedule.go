@@ -0,0 +1,140 @@
+/*
+Copyright 2018 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeBackupVerificationSchedules implements BackupVerificationScheduleInterface
+type FakeBackupVerificationSchedules struct {
+	Fake *FakeStorkV1alpha1
+	ns   string
+}
+
+var backupverificationschedulesResource = schema.GroupVersionResource{Group: "stork.libopenstorage.org", Version: "v1alpha1", Resource: "backupverificationschedules"}
+
+var backupverificationschedulesKind = schema.GroupVersionKind{Group: "stork.libopenstorage.org", Version: "v1alpha1", Kind: "BackupVerificationSchedule"}
+
+// Get takes name of the backupVerificationSchedule, and returns the corresponding backupVerificationSchedule object, and an error if there is any.
+func (c *FakeBackupVerificationSchedules) Get(name string, options v1.GetOptions) (result *v1alpha1.BackupVerificationSchedule, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(backupverificationschedulesResource, c.ns, name), &v1alpha1.BackupVerificationSchedule{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupVerificationSchedule), err
+}
+
+// List takes label and field selectors, and returns the list of BackupVerificationSchedules that match those selectors.
+func (c *FakeBackupVerificationSchedules) List(opts v1.ListOptions) (result *v1alpha1.BackupVerificationScheduleList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(backupverificationschedulesResource, backupverificationschedulesKind, c.ns, opts), &v1alpha1.BackupVerificationScheduleList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.BackupVerificationScheduleList{ListMeta: obj.(*v1alpha1.BackupVerificationScheduleList).ListMeta}
+	for _, item := range obj.(*v1alpha1.BackupVerificationScheduleList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested backupVerificationSchedules.
+func (c *FakeBackupVerificationSchedules) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(backupverificationschedulesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a backupVerificationSchedule and creates it.  Returns the server's representation of the backupVerificationSchedule, and an error, if there is any.
+func (c *FakeBackupVerificationSchedules) Create(backupVerificationSchedule *v1alpha1.BackupVerificationSchedule) (result *v1alpha1.BackupVerificationSchedule, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(backupverificationschedulesResource, c.ns, backupVerificationSchedule), &v1alpha1.BackupVerificationSchedule{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupVerificationSchedule), err
+}
+
+// Update takes the representation of a backupVerificationSchedule and updates it. Returns the server's representation of the backupVerificationSchedule, and an error, if there is any.
+func (c *FakeBackupVerificationSchedules) Update(backupVerificationSchedule *v1alpha1.BackupVerificationSchedule) (result *v1alpha1.BackupVerificationSchedule, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(backupverificationschedulesResource, c.ns, backupVerificationSchedule), &v1alpha1.BackupVerificationSchedule{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupVerificationSchedule), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeBackupVerificationSchedules) UpdateStatus(backupVerificationSchedule *v1alpha1.BackupVerificationSchedule) (*v1alpha1.BackupVerificationSchedule, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(backupverificationschedulesResource, "status", c.ns, backupVerificationSchedule), &v1alpha1.BackupVerificationSchedule{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupVerificationSchedule), err
+}
+
+// Delete takes name of the backupVerificationSchedule and deletes it. Returns an error if one occurs.
+func (c *FakeBackupVerificationSchedules) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(backupverificationschedulesResource, c.ns, name), &v1alpha1.BackupVerificationSchedule{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeBackupVerificationSchedules) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(backupverificationschedulesResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.BackupVerificationScheduleList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched backupVerificationSchedule.
+func (c *FakeBackupVerificationSchedules) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.BackupVerificationSchedule, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(backupverificationschedulesResource, c.ns, name, pt, data, subresources...), &v1alpha1.BackupVerificationSchedule{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupVerificationSchedule), err
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2018 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeDRPlans implements DRPlanInterface
+type FakeDRPlans struct {
+	Fake *FakeStorkV1alpha1
+	ns   string
+}
+
+var drplansResource = schema.GroupVersionResource{Group: "stork.libopenstorage.org", Version: "v1alpha1", Resource: "drplans"}
+
+var drplansKind = schema.GroupVersionKind{Group: "stork.libopenstorage.org", Version: "v1alpha1", Kind: "DRPlan"}
+
+// Get takes name of the dRPlan, and returns the corresponding dRPlan object, and an error if there is any.
+func (c *FakeDRPlans) Get(name string, options v1.GetOptions) (result *v1alpha1.DRPlan, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(drplansResource, c.ns, name), &v1alpha1.DRPlan{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DRPlan), err
+}
+
+// List takes label and field selectors, and returns the list of DRPlans that match those selectors.
+func (c *FakeDRPlans) List(opts v1.ListOptions) (result *v1alpha1.DRPlanList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(drplansResource, drplansKind, c.ns, opts), &v1alpha1.DRPlanList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.DRPlanList{ListMeta: obj.(*v1alpha1.DRPlanList).ListMeta}
+	for _, item := range obj.(*v1alpha1.DRPlanList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested dRPlans.
+func (c *FakeDRPlans) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(drplansResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a dRPlan and creates it.  Returns the server's representation of the dRPlan, and an error, if there is any.
+func (c *FakeDRPlans) Create(dRPlan *v1alpha1.DRPlan) (result *v1alpha1.DRPlan, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(drplansResource, c.ns, dRPlan), &v1alpha1.DRPlan{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DRPlan), err
+}
+
+// Update takes the representation of a dRPlan and updates it. Returns the server's representation of the dRPlan, and an error, if there is any.
+func (c *FakeDRPlans) Update(dRPlan *v1alpha1.DRPlan) (result *v1alpha1.DRPlan, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(drplansResource, c.ns, dRPlan), &v1alpha1.DRPlan{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DRPlan), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeDRPlans) UpdateStatus(dRPlan *v1alpha1.DRPlan) (*v1alpha1.DRPlan, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(drplansResource, "status", c.ns, dRPlan), &v1alpha1.DRPlan{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DRPlan), err
+}
+
+// Delete takes name of the dRPlan and deletes it. Returns an error if one occurs.
+func (c *FakeDRPlans) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(drplansResource, c.ns, name), &v1alpha1.DRPlan{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeDRPlans) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(drplansResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.DRPlanList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched dRPlan.
+func (c *FakeDRPlans) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.DRPlan, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(drplansResource, c.ns, name, pt, data, subresources...), &v1alpha1.DRPlan{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DRPlan), err
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2018 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeMigrationFailovers implements MigrationFailoverInterface
+type FakeMigrationFailovers struct {
+	Fake *FakeStorkV1alpha1
+	ns   string
+}
+
+var migrationfailoversResource = schema.GroupVersionResource{Group: "stork.libopenstorage.org", Version: "v1alpha1", Resource: "migrationfailovers"}
+
+var migrationfailoversKind = schema.GroupVersionKind{Group: "stork.libopenstorage.org", Version: "v1alpha1", Kind: "MigrationFailover"}
+
+// Get takes name of the migrationFailover, and returns the corresponding migrationFailover object, and an error if there is any.
+func (c *FakeMigrationFailovers) Get(name string, options v1.GetOptions) (result *v1alpha1.MigrationFailover, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(migrationfailoversResource, c.ns, name), &v1alpha1.MigrationFailover{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.MigrationFailover), err
+}
+
+// List takes label and field selectors, and returns the list of MigrationFailovers that match those selectors.
+func (c *FakeMigrationFailovers) List(opts v1.ListOptions) (result *v1alpha1.MigrationFailoverList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(migrationfailoversResource, migrationfailoversKind, c.ns, opts), &v1alpha1.MigrationFailoverList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.MigrationFailoverList{ListMeta: obj.(*v1alpha1.MigrationFailoverList).ListMeta}
+	for _, item := range obj.(*v1alpha1.MigrationFailoverList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested migrationFailovers.
+func (c *FakeMigrationFailovers) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(migrationfailoversResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a migrationFailover and creates it.  Returns the server's representation of the migrationFailover, and an error, if there is any.
+func (c *FakeMigrationFailovers) Create(migrationFailover *v1alpha1.MigrationFailover) (result *v1alpha1.MigrationFailover, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(migrationfailoversResource, c.ns, migrationFailover), &v1alpha1.MigrationFailover{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.MigrationFailover), err
+}
+
+// Update takes the representation of a migrationFailover and updates it. Returns the server's representation of the migrationFailover, and an error, if there is any.
+func (c *FakeMigrationFailovers) Update(migrationFailover *v1alpha1.MigrationFailover) (result *v1alpha1.MigrationFailover, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(migrationfailoversResource, c.ns, migrationFailover), &v1alpha1.MigrationFailover{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.MigrationFailover), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeMigrationFailovers) UpdateStatus(migrationFailover *v1alpha1.MigrationFailover) (*v1alpha1.MigrationFailover, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(migrationfailoversResource, "status", c.ns, migrationFailover), &v1alpha1.MigrationFailover{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.MigrationFailover), err
+}
+
+// Delete takes name of the migrationFailover and deletes it. Returns an error if one occurs.
+func (c *FakeMigrationFailovers) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(migrationfailoversResource, c.ns, name), &v1alpha1.MigrationFailover{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeMigrationFailovers) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(migrationfailoversResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.MigrationFailoverList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched migrationFailover.
+func (c *FakeMigrationFailovers) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.MigrationFailover, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(migrationfailoversResource, c.ns, name, pt, data, subresources...), &v1alpha1.MigrationFailover{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.MigrationFailover), err
+}
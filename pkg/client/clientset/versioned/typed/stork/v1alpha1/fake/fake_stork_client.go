@@ -48,6 +48,10 @@ func (c *FakeStorkV1alpha1) BackupLocations(namespace string) v1alpha1.BackupLoc
 	return &FakeBackupLocations{c, namespace}
 }
 
+func (c *FakeStorkV1alpha1) BackupVerificationSchedules(namespace string) v1alpha1.BackupVerificationScheduleInterface {
+	return &FakeBackupVerificationSchedules{c, namespace}
+}
+
 func (c *FakeStorkV1alpha1) ClusterDomainUpdates() v1alpha1.ClusterDomainUpdateInterface {
 	return &FakeClusterDomainUpdates{c}
 }
@@ -60,6 +64,10 @@ func (c *FakeStorkV1alpha1) ClusterPairs(namespace string) v1alpha1.ClusterPairI
 	return &FakeClusterPairs{c, namespace}
 }
 
+func (c *FakeStorkV1alpha1) DRPlans(namespace string) v1alpha1.DRPlanInterface {
+	return &FakeDRPlans{c, namespace}
+}
+
 func (c *FakeStorkV1alpha1) DataExports(namespace string) v1alpha1.DataExportInterface {
 	return &FakeDataExports{c, namespace}
 }
@@ -72,6 +80,10 @@ func (c *FakeStorkV1alpha1) Migrations(namespace string) v1alpha1.MigrationInter
 	return &FakeMigrations{c, namespace}
 }
 
+func (c *FakeStorkV1alpha1) MigrationFailovers(namespace string) v1alpha1.MigrationFailoverInterface {
+	return &FakeMigrationFailovers{c, namespace}
+}
+
 func (c *FakeStorkV1alpha1) MigrationSchedules(namespace string) v1alpha1.MigrationScheduleInterface {
 	return &FakeMigrationSchedules{c, namespace}
 }
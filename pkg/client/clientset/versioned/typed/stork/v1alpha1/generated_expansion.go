@@ -28,18 +28,24 @@ type ApplicationRestoreExpansion interface{}
 
 type BackupLocationExpansion interface{}
 
+type BackupVerificationScheduleExpansion interface{}
+
 type ClusterDomainUpdateExpansion interface{}
 
 type ClusterDomainsStatusExpansion interface{}
 
 type ClusterPairExpansion interface{}
 
+type DRPlanExpansion interface{}
+
 type DataExportExpansion interface{}
 
 type GroupVolumeSnapshotExpansion interface{}
 
 type MigrationExpansion interface{}
 
+type MigrationFailoverExpansion interface{}
+
 type MigrationScheduleExpansion interface{}
 
 type RuleExpansion interface{}
@@ -0,0 +1,191 @@
+/*
+Copyright 2018 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	scheme "github.com/libopenstorage/stork/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// MigrationFailoversGetter has a method to return a MigrationFailoverInterface.
+// A group's client should implement this interface.
+type MigrationFailoversGetter interface {
+	MigrationFailovers(namespace string) MigrationFailoverInterface
+}
+
+// MigrationFailoverInterface has methods to work with MigrationFailover resources.
+type MigrationFailoverInterface interface {
+	Create(*v1alpha1.MigrationFailover) (*v1alpha1.MigrationFailover, error)
+	Update(*v1alpha1.MigrationFailover) (*v1alpha1.MigrationFailover, error)
+	UpdateStatus(*v1alpha1.MigrationFailover) (*v1alpha1.MigrationFailover, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.MigrationFailover, error)
+	List(opts v1.ListOptions) (*v1alpha1.MigrationFailoverList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.MigrationFailover, err error)
+	MigrationFailoverExpansion
+}
+
+// migrationFailovers implements MigrationFailoverInterface
+type migrationFailovers struct {
+	client rest.Interface
+	ns     string
+}
+
+// newMigrationFailovers returns a MigrationFailovers
+func newMigrationFailovers(c *StorkV1alpha1Client, namespace string) *migrationFailovers {
+	return &migrationFailovers{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the migrationFailover, and returns the corresponding migrationFailover object, and an error if there is any.
+func (c *migrationFailovers) Get(name string, options v1.GetOptions) (result *v1alpha1.MigrationFailover, err error) {
+	result = &v1alpha1.MigrationFailover{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("migrationfailovers").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of MigrationFailovers that match those selectors.
+func (c *migrationFailovers) List(opts v1.ListOptions) (result *v1alpha1.MigrationFailoverList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.MigrationFailoverList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("migrationfailovers").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested migrationFailovers.
+func (c *migrationFailovers) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("migrationfailovers").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a migrationFailover and creates it.  Returns the server's representation of the migrationFailover, and an error, if there is any.
+func (c *migrationFailovers) Create(migrationFailover *v1alpha1.MigrationFailover) (result *v1alpha1.MigrationFailover, err error) {
+	result = &v1alpha1.MigrationFailover{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("migrationfailovers").
+		Body(migrationFailover).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a migrationFailover and updates it. Returns the server's representation of the migrationFailover, and an error, if there is any.
+func (c *migrationFailovers) Update(migrationFailover *v1alpha1.MigrationFailover) (result *v1alpha1.MigrationFailover, err error) {
+	result = &v1alpha1.MigrationFailover{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("migrationfailovers").
+		Name(migrationFailover.Name).
+		Body(migrationFailover).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *migrationFailovers) UpdateStatus(migrationFailover *v1alpha1.MigrationFailover) (result *v1alpha1.MigrationFailover, err error) {
+	result = &v1alpha1.MigrationFailover{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("migrationfailovers").
+		Name(migrationFailover.Name).
+		SubResource("status").
+		Body(migrationFailover).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the migrationFailover and deletes it. Returns an error if one occurs.
+func (c *migrationFailovers) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("migrationfailovers").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *migrationFailovers) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("migrationfailovers").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched migrationFailover.
+func (c *migrationFailovers) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.MigrationFailover, err error) {
+	result = &v1alpha1.MigrationFailover{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("migrationfailovers").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -31,12 +31,15 @@ type StorkV1alpha1Interface interface {
 	ApplicationClonesGetter
 	ApplicationRestoresGetter
 	BackupLocationsGetter
+	BackupVerificationSchedulesGetter
 	ClusterDomainUpdatesGetter
 	ClusterDomainsStatusesGetter
 	ClusterPairsGetter
+	DRPlansGetter
 	DataExportsGetter
 	GroupVolumeSnapshotsGetter
 	MigrationsGetter
+	MigrationFailoversGetter
 	MigrationSchedulesGetter
 	RulesGetter
 	SchedulePoliciesGetter
@@ -69,6 +72,10 @@ func (c *StorkV1alpha1Client) BackupLocations(namespace string) BackupLocationIn
 	return newBackupLocations(c, namespace)
 }
 
+func (c *StorkV1alpha1Client) BackupVerificationSchedules(namespace string) BackupVerificationScheduleInterface {
+	return newBackupVerificationSchedules(c, namespace)
+}
+
 func (c *StorkV1alpha1Client) ClusterDomainUpdates() ClusterDomainUpdateInterface {
 	return newClusterDomainUpdates(c)
 }
@@ -81,6 +88,10 @@ func (c *StorkV1alpha1Client) ClusterPairs(namespace string) ClusterPairInterfac
 	return newClusterPairs(c, namespace)
 }
 
+func (c *StorkV1alpha1Client) DRPlans(namespace string) DRPlanInterface {
+	return newDRPlans(c, namespace)
+}
+
 func (c *StorkV1alpha1Client) DataExports(namespace string) DataExportInterface {
 	return newDataExports(c, namespace)
 }
@@ -93,6 +104,10 @@ func (c *StorkV1alpha1Client) Migrations(namespace string) MigrationInterface {
 	return newMigrations(c, namespace)
 }
 
+func (c *StorkV1alpha1Client) MigrationFailovers(namespace string) MigrationFailoverInterface {
+	return newMigrationFailovers(c, namespace)
+}
+
 func (c *StorkV1alpha1Client) MigrationSchedules(namespace string) MigrationScheduleInterface {
 	return newMigrationSchedules(c, namespace)
 }
@@ -63,18 +63,24 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().ApplicationRestores().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("backuplocations"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().BackupLocations().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("backupverificationschedules"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().BackupVerificationSchedules().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("clusterdomainupdates"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().ClusterDomainUpdates().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("clusterdomainsstatuses"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().ClusterDomainsStatuses().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("clusterpairs"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().ClusterPairs().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("drplans"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().DRPlans().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("dataexports"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().DataExports().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("groupvolumesnapshots"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().GroupVolumeSnapshots().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("migrations"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().Migrations().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("migrationfailovers"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().MigrationFailovers().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("migrationschedules"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().MigrationSchedules().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("rules"):
@@ -34,18 +34,24 @@ type Interface interface {
 	ApplicationRestores() ApplicationRestoreInformer
 	// BackupLocations returns a BackupLocationInformer.
 	BackupLocations() BackupLocationInformer
+	// BackupVerificationSchedules returns a BackupVerificationScheduleInformer.
+	BackupVerificationSchedules() BackupVerificationScheduleInformer
 	// ClusterDomainUpdates returns a ClusterDomainUpdateInformer.
 	ClusterDomainUpdates() ClusterDomainUpdateInformer
 	// ClusterDomainsStatuses returns a ClusterDomainsStatusInformer.
 	ClusterDomainsStatuses() ClusterDomainsStatusInformer
 	// ClusterPairs returns a ClusterPairInformer.
 	ClusterPairs() ClusterPairInformer
+	// DRPlans returns a DRPlanInformer.
+	DRPlans() DRPlanInformer
 	// DataExports returns a DataExportInformer.
 	DataExports() DataExportInformer
 	// GroupVolumeSnapshots returns a GroupVolumeSnapshotInformer.
 	GroupVolumeSnapshots() GroupVolumeSnapshotInformer
 	// Migrations returns a MigrationInformer.
 	Migrations() MigrationInformer
+	// MigrationFailovers returns a MigrationFailoverInformer.
+	MigrationFailovers() MigrationFailoverInformer
 	// MigrationSchedules returns a MigrationScheduleInformer.
 	MigrationSchedules() MigrationScheduleInformer
 	// Rules returns a RuleInformer.
@@ -94,6 +100,11 @@ func (v *version) BackupLocations() BackupLocationInformer {
 	return &backupLocationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// BackupVerificationSchedules returns a BackupVerificationScheduleInformer.
+func (v *version) BackupVerificationSchedules() BackupVerificationScheduleInformer {
+	return &backupVerificationScheduleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ClusterDomainUpdates returns a ClusterDomainUpdateInformer.
 func (v *version) ClusterDomainUpdates() ClusterDomainUpdateInformer {
 	return &clusterDomainUpdateInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -109,6 +120,11 @@ func (v *version) ClusterPairs() ClusterPairInformer {
 	return &clusterPairInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// DRPlans returns a DRPlanInformer.
+func (v *version) DRPlans() DRPlanInformer {
+	return &dRPlanInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // DataExports returns a DataExportInformer.
 func (v *version) DataExports() DataExportInformer {
 	return &dataExportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -124,6 +140,11 @@ func (v *version) Migrations() MigrationInformer {
 	return &migrationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// MigrationFailovers returns a MigrationFailoverInformer.
+func (v *version) MigrationFailovers() MigrationFailoverInformer {
+	return &migrationFailoverInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // MigrationSchedules returns a MigrationScheduleInformer.
 func (v *version) MigrationSchedules() MigrationScheduleInformer {
 	return &migrationScheduleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// BackupVerificationScheduleLister helps list BackupVerificationSchedules.
+type BackupVerificationScheduleLister interface {
+	// List lists all BackupVerificationSchedules in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.BackupVerificationSchedule, err error)
+	// BackupVerificationSchedules returns an object that can list and get BackupVerificationSchedules.
+	BackupVerificationSchedules(namespace string) BackupVerificationScheduleNamespaceLister
+	BackupVerificationScheduleListerExpansion
+}
+
+// backupVerificationScheduleLister implements the BackupVerificationScheduleLister interface.
+type backupVerificationScheduleLister struct {
+	indexer cache.Indexer
+}
+
+// NewBackupVerificationScheduleLister returns a new BackupVerificationScheduleLister.
+func NewBackupVerificationScheduleLister(indexer cache.Indexer) BackupVerificationScheduleLister {
+	return &backupVerificationScheduleLister{indexer: indexer}
+}
+
+// List lists all BackupVerificationSchedules in the indexer.
+func (s *backupVerificationScheduleLister) List(selector labels.Selector) (ret []*v1alpha1.BackupVerificationSchedule, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.BackupVerificationSchedule))
+	})
+	return ret, err
+}
+
+// BackupVerificationSchedules returns an object that can list and get BackupVerificationSchedules.
+func (s *backupVerificationScheduleLister) BackupVerificationSchedules(namespace string) BackupVerificationScheduleNamespaceLister {
+	return backupVerificationScheduleNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// BackupVerificationScheduleNamespaceLister helps list and get BackupVerificationSchedules.
+type BackupVerificationScheduleNamespaceLister interface {
+	// List lists all BackupVerificationSchedules in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.BackupVerificationSchedule, err error)
+	// Get retrieves the BackupVerificationSchedule from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.BackupVerificationSchedule, error)
+	BackupVerificationScheduleNamespaceListerExpansion
+}
+
+// backupVerificationScheduleNamespaceLister implements the BackupVerificationScheduleNamespaceLister
+// interface.
+type backupVerificationScheduleNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all BackupVerificationSchedules in the indexer for a given namespace.
+func (s backupVerificationScheduleNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.BackupVerificationSchedule, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.BackupVerificationSchedule))
+	})
+	return ret, err
+}
+
+// Get retrieves the BackupVerificationSchedule from the indexer for a given namespace and name.
+func (s backupVerificationScheduleNamespaceLister) Get(name string) (*v1alpha1.BackupVerificationSchedule, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("backupverificationschedule"), name)
+	}
+	return obj.(*v1alpha1.BackupVerificationSchedule), nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DRPlanLister helps list DRPlans.
+type DRPlanLister interface {
+	// List lists all DRPlans in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.DRPlan, err error)
+	// DRPlans returns an object that can list and get DRPlans.
+	DRPlans(namespace string) DRPlanNamespaceLister
+	DRPlanListerExpansion
+}
+
+// dRPlanLister implements the DRPlanLister interface.
+type dRPlanLister struct {
+	indexer cache.Indexer
+}
+
+// NewDRPlanLister returns a new DRPlanLister.
+func NewDRPlanLister(indexer cache.Indexer) DRPlanLister {
+	return &dRPlanLister{indexer: indexer}
+}
+
+// List lists all DRPlans in the indexer.
+func (s *dRPlanLister) List(selector labels.Selector) (ret []*v1alpha1.DRPlan, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.DRPlan))
+	})
+	return ret, err
+}
+
+// DRPlans returns an object that can list and get DRPlans.
+func (s *dRPlanLister) DRPlans(namespace string) DRPlanNamespaceLister {
+	return dRPlanNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// DRPlanNamespaceLister helps list and get DRPlans.
+type DRPlanNamespaceLister interface {
+	// List lists all DRPlans in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.DRPlan, err error)
+	// Get retrieves the DRPlan from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.DRPlan, error)
+	DRPlanNamespaceListerExpansion
+}
+
+// dRPlanNamespaceLister implements the DRPlanNamespaceLister
+// interface.
+type dRPlanNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all DRPlans in the indexer for a given namespace.
+func (s dRPlanNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.DRPlan, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.DRPlan))
+	})
+	return ret, err
+}
+
+// Get retrieves the DRPlan from the indexer for a given namespace and name.
+func (s dRPlanNamespaceLister) Get(name string) (*v1alpha1.DRPlan, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("drplan"), name)
+	}
+	return obj.(*v1alpha1.DRPlan), nil
+}
@@ -58,6 +58,14 @@ type BackupLocationListerExpansion interface{}
 // BackupLocationNamespaceLister.
 type BackupLocationNamespaceListerExpansion interface{}
 
+// BackupVerificationScheduleListerExpansion allows custom methods to be added to
+// BackupVerificationScheduleLister.
+type BackupVerificationScheduleListerExpansion interface{}
+
+// BackupVerificationScheduleNamespaceListerExpansion allows custom methods to be added to
+// BackupVerificationScheduleNamespaceLister.
+type BackupVerificationScheduleNamespaceListerExpansion interface{}
+
 // ClusterDomainUpdateListerExpansion allows custom methods to be added to
 // ClusterDomainUpdateLister.
 type ClusterDomainUpdateListerExpansion interface{}
@@ -74,6 +82,14 @@ type ClusterPairListerExpansion interface{}
 // ClusterPairNamespaceLister.
 type ClusterPairNamespaceListerExpansion interface{}
 
+// DRPlanListerExpansion allows custom methods to be added to
+// DRPlanLister.
+type DRPlanListerExpansion interface{}
+
+// DRPlanNamespaceListerExpansion allows custom methods to be added to
+// DRPlanNamespaceLister.
+type DRPlanNamespaceListerExpansion interface{}
+
 // DataExportListerExpansion allows custom methods to be added to
 // DataExportLister.
 type DataExportListerExpansion interface{}
@@ -98,6 +114,14 @@ type MigrationListerExpansion interface{}
 // MigrationNamespaceLister.
 type MigrationNamespaceListerExpansion interface{}
 
+// MigrationFailoverListerExpansion allows custom methods to be added to
+// MigrationFailoverLister.
+type MigrationFailoverListerExpansion interface{}
+
+// MigrationFailoverNamespaceListerExpansion allows custom methods to be added to
+// MigrationFailoverNamespaceLister.
+type MigrationFailoverNamespaceListerExpansion interface{}
+
 // MigrationScheduleListerExpansion allows custom methods to be added to
 // MigrationScheduleLister.
 type MigrationScheduleListerExpansion interface{}
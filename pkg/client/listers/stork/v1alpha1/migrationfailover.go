@@ -0,0 +1,94 @@
+/*
+Copyright 2018 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// MigrationFailoverLister helps list MigrationFailovers.
+type MigrationFailoverLister interface {
+	// List lists all MigrationFailovers in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.MigrationFailover, err error)
+	// MigrationFailovers returns an object that can list and get MigrationFailovers.
+	MigrationFailovers(namespace string) MigrationFailoverNamespaceLister
+	MigrationFailoverListerExpansion
+}
+
+// migrationFailoverLister implements the MigrationFailoverLister interface.
+type migrationFailoverLister struct {
+	indexer cache.Indexer
+}
+
+// NewMigrationFailoverLister returns a new MigrationFailoverLister.
+func NewMigrationFailoverLister(indexer cache.Indexer) MigrationFailoverLister {
+	return &migrationFailoverLister{indexer: indexer}
+}
+
+// List lists all MigrationFailovers in the indexer.
+func (s *migrationFailoverLister) List(selector labels.Selector) (ret []*v1alpha1.MigrationFailover, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.MigrationFailover))
+	})
+	return ret, err
+}
+
+// MigrationFailovers returns an object that can list and get MigrationFailovers.
+func (s *migrationFailoverLister) MigrationFailovers(namespace string) MigrationFailoverNamespaceLister {
+	return migrationFailoverNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// MigrationFailoverNamespaceLister helps list and get MigrationFailovers.
+type MigrationFailoverNamespaceLister interface {
+	// List lists all MigrationFailovers in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.MigrationFailover, err error)
+	// Get retrieves the MigrationFailover from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.MigrationFailover, error)
+	MigrationFailoverNamespaceListerExpansion
+}
+
+// migrationFailoverNamespaceLister implements the MigrationFailoverNamespaceLister
+// interface.
+type migrationFailoverNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all MigrationFailovers in the indexer for a given namespace.
+func (s migrationFailoverNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.MigrationFailover, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.MigrationFailover))
+	})
+	return ret, err
+}
+
+// Get retrieves the MigrationFailover from the indexer for a given namespace and name.
+func (s migrationFailoverNamespaceLister) Get(name string) (*v1alpha1.MigrationFailover, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("migrationfailover"), name)
+	}
+	return obj.(*v1alpha1.MigrationFailover), nil
+}
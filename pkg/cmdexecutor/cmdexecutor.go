@@ -76,7 +76,7 @@ func (c *cmdExecutor) Start(errChan chan error) error {
 	if err != nil {
 		err = fmt.Errorf("failed to create wait script in pod: [%s] %s using command: %s due to err: %v",
 			c.podNamespace, c.podName, waitScriptCreateCmd, err)
-		logrus.Errorf(err.Error())
+		logrus.Error(err.Error())
 		return err
 	}
 
@@ -88,7 +88,7 @@ func (c *cmdExecutor) Start(errChan chan error) error {
 		if err != nil {
 			err = fmt.Errorf("failed to run command: %s in pod: [%s] %s due to err: %v",
 				command, c.podNamespace, c.podName, err)
-			logrus.Errorf(err.Error())
+			logrus.Error(err.Error())
 		}
 
 		errChan <- err
@@ -1,14 +1,19 @@
 package crypto
 
 import (
+	"bufio"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 	"io"
 )
 
+// streamChunkSize is the size of the chunks that streams are encrypted in
+const streamChunkSize = 64 * 1024
+
 // Encrypt the given data with the passphrase
 func Encrypt(data []byte, passphrase string) ([]byte, error) {
 	gcm, err := getCipher(passphrase)
@@ -33,6 +38,170 @@ func Decrypt(data []byte, passphrase string) ([]byte, error) {
 	return gcm.Open(nil, nonce, encryptedData, nil)
 }
 
+// NewEncryptWriter returns a writer that encrypts the data written to it with
+// the passphrase and writes it to w. The data is encrypted in chunks, each
+// with its own nonce, so that it doesn't need to be held in memory. Close
+// needs to be called to write the last chunk, it doesn't close w
+func NewEncryptWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	gcm, err := getCipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce for encryption: %v", err)
+	}
+	if _, err := w.Write(nonce); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{
+		w:     w,
+		gcm:   gcm,
+		nonce: nonce,
+		buf:   make([]byte, 0, streamChunkSize),
+	}, nil
+}
+
+// NewDecryptReader returns a reader that decrypts the data from r that was
+// written by a writer returned by NewEncryptWriter. An error is returned if
+// the data was modified or truncated
+func NewDecryptReader(r io.Reader, passphrase string) (io.Reader, error) {
+	gcm, err := getCipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(r, nonce); err != nil {
+		return nil, fmt.Errorf("error reading nonce: %v", err)
+	}
+	return &decryptReader{
+		r:      bufio.NewReader(r),
+		gcm:    gcm,
+		nonce:  nonce,
+		sealed: make([]byte, streamChunkSize+gcm.Overhead()),
+	}, nil
+}
+
+type encryptWriter struct {
+	w       io.Writer
+	gcm     cipher.AEAD
+	nonce   []byte
+	counter uint64
+	buf     []byte
+	closed  bool
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, fmt.Errorf("write to closed encryption writer")
+	}
+	written := len(p)
+	for len(p) > 0 {
+		// Only write full chunks once more data comes in, the last chunk
+		// is written on Close
+		if len(e.buf) == streamChunkSize {
+			if err := e.writeChunk(false); err != nil {
+				return 0, err
+			}
+		}
+		n := streamChunkSize - len(e.buf)
+		if n > len(p) {
+			n = len(p)
+		}
+		e.buf = append(e.buf, p[:n]...)
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.writeChunk(true)
+}
+
+func (e *encryptWriter) writeChunk(last bool) error {
+	sealed := e.gcm.Seal(nil, chunkNonce(e.nonce, e.counter), e.buf, chunkData(last))
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+type decryptReader struct {
+	r       *bufio.Reader
+	gcm     cipher.AEAD
+	nonce   []byte
+	counter uint64
+	sealed  []byte
+	buf     []byte
+	done    bool
+	err     error
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.err = d.readChunk()
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+func (d *decryptReader) readChunk() error {
+	n, err := io.ReadFull(d.r, d.sealed)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	} else if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	// The last chunk is the one at the end of the data, which can be a full
+	// chunk too
+	last := err == io.ErrUnexpectedEOF
+	if !last {
+		if _, err := d.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+	if d.buf, err = d.gcm.Open(d.sealed[:0], chunkNonce(d.nonce, d.counter), d.sealed[:n], chunkData(last)); err != nil {
+		return err
+	}
+	d.counter++
+	d.done = last
+	return nil
+}
+
+// chunkNonce returns the nonce for a chunk by adding the counter of the chunk
+// to the end of the nonce for the stream
+func chunkNonce(nonce []byte, counter uint64) []byte {
+	chunkNonce := make([]byte, len(nonce))
+	copy(chunkNonce, nonce)
+	offset := len(chunkNonce) - 8
+	binary.BigEndian.PutUint64(chunkNonce[offset:], binary.BigEndian.Uint64(chunkNonce[offset:])+counter)
+	return chunkNonce
+}
+
+// chunkData returns the additional data to authenticate for a chunk, which
+// marks the last chunk so that truncated streams are detected
+func chunkData(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
 func getCipher(passphrase string) (cipher.AEAD, error) {
 	// AES requires either 16, 24 or 32 bytes for the key
 	// So generate a 32 byte sha256 from the input key and use that with AES
@@ -3,8 +3,10 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/rand"
 	"io"
+	"io/ioutil"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -57,3 +59,61 @@ func TestDecryptInvalidiData(t *testing.T) {
 	require.Error(t, err, "Decrypting data should have failed")
 	require.Nil(t, decryptedData, "Decrypted data should be nil on error")
 }
+
+func TestEncryptDecryptStream(t *testing.T) {
+	passphrase := "testkey"
+	for _, size := range []int{0, 1, streamChunkSize - 1, streamChunkSize, streamChunkSize + 1, 3 * streamChunkSize} {
+		originalData := make([]byte, size)
+		_, err := io.ReadFull(rand.Reader, originalData)
+		require.NoError(t, err, "Error generating test data")
+
+		var encryptedData bytes.Buffer
+		writer, err := NewEncryptWriter(&encryptedData, passphrase)
+		require.NoError(t, err, "Error creating encryption writer")
+		// Write in pieces that don't line up with the chunks
+		for data := originalData; len(data) > 0; {
+			n := 1000
+			if n > len(data) {
+				n = len(data)
+			}
+			_, err = writer.Write(data[:n])
+			require.NoError(t, err, "Error encrypting data")
+			data = data[n:]
+		}
+		require.NoError(t, writer.Close(), "Error closing encryption writer")
+
+		reader, err := NewDecryptReader(bytes.NewReader(encryptedData.Bytes()), passphrase)
+		require.NoError(t, err, "Error creating decryption reader")
+		decryptedData, err := ioutil.ReadAll(reader)
+		require.NoError(t, err, "Error decrypting data of size %v", size)
+		require.Equal(t, originalData, decryptedData, "Original and decrypted data mismatch for size %v", size)
+
+		reader, err = NewDecryptReader(bytes.NewReader(encryptedData.Bytes()), "invalidKey")
+		require.NoError(t, err, "Error creating decryption reader")
+		_, err = ioutil.ReadAll(reader)
+		require.Error(t, err, "Decrypting data with invalid key should have failed")
+	}
+}
+
+func TestDecryptTruncatedStream(t *testing.T) {
+	passphrase := "testkey"
+	originalData := make([]byte, 2*streamChunkSize+10)
+	_, err := io.ReadFull(rand.Reader, originalData)
+	require.NoError(t, err, "Error generating test data")
+
+	var encryptedData bytes.Buffer
+	writer, err := NewEncryptWriter(&encryptedData, passphrase)
+	require.NoError(t, err, "Error creating encryption writer")
+	_, err = writer.Write(originalData)
+	require.NoError(t, err, "Error encrypting data")
+	require.NoError(t, writer.Close(), "Error closing encryption writer")
+
+	// Drop the last chunk, the remaining chunks are still valid on their own
+	nonceSize := 12
+	chunkSize := streamChunkSize + 16
+	truncated := encryptedData.Bytes()[:nonceSize+2*chunkSize]
+	reader, err := NewDecryptReader(bytes.NewReader(truncated), passphrase)
+	require.NoError(t, err, "Error creating decryption reader")
+	_, err = ioutil.ReadAll(reader)
+	require.Error(t, err, "Decrypting truncated data should have failed")
+}
@@ -169,6 +169,32 @@ func MigrationScheduleLog(migrationSchedule *storkv1.MigrationSchedule) *logrus.
 	return logrus.WithFields(logrus.Fields{})
 }
 
+// MigrationFailoverLog formats a log message with migrationfailover information
+func MigrationFailoverLog(failover *storkv1.MigrationFailover) *logrus.Entry {
+	if failover != nil {
+		return logrus.WithFields(logrus.Fields{
+			"MigrationFailoverName": failover.Name,
+			"Namespace":             failover.Namespace,
+			"Type":                  failover.Spec.Type,
+		})
+	}
+
+	return logrus.WithFields(logrus.Fields{})
+}
+
+// DRPlanLog formats a log message with drplan information
+func DRPlanLog(plan *storkv1.DRPlan) *logrus.Entry {
+	if plan != nil {
+		return logrus.WithFields(logrus.Fields{
+			"DRPlanName": plan.Name,
+			"Namespace":  plan.Namespace,
+			"Action":     plan.Status.Action,
+		})
+	}
+
+	return logrus.WithFields(logrus.Fields{})
+}
+
 // GroupSnapshotLog formats a log message with groupsnapshot information
 func GroupSnapshotLog(groupsnapshot *storkv1.GroupVolumeSnapshot) *logrus.Entry {
 	if groupsnapshot != nil {
@@ -262,6 +288,17 @@ func ApplicationBackupScheduleLog(backupSchedule *storkv1.ApplicationBackupSched
 	return logrus.WithFields(logrus.Fields{})
 }
 
+// BackupVerificationScheduleLog formats a log message with backupverificationschedule information
+func BackupVerificationScheduleLog(verificationSchedule *storkv1.BackupVerificationSchedule) *logrus.Entry {
+	if verificationSchedule != nil {
+		return logrus.WithFields(logrus.Fields{
+			"BackupVerificationScheduleName": verificationSchedule.Name,
+			"Namespace":                      verificationSchedule.Namespace,
+		})
+	}
+	return logrus.WithFields(logrus.Fields{})
+}
+
 // BackupLocationLog formats a log message with backuplocation information
 func BackupLocationLog(location *storkv1.BackupLocation) *logrus.Entry {
 	if location != nil {
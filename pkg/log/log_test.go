@@ -30,6 +30,8 @@ func TestLog(t *testing.T) {
 	t.Run("applicationCloneLogTest", applicationCloneLogTest)
 	t.Run("applicationBackupScheduleLogTest", applicationBackupScheduleLogTest)
 	t.Run("volumeSnapshotRestoreLogTest", volumeSnapshotRestoreLogTest)
+	t.Run("backupVerificationScheduleLogTest", backupVerificationScheduleLogTest)
+	t.Run("migrationFailoverLogTest", migrationFailoverLogTest)
 	t.Run("backupLocationLogTest", backupLocationLogTest)
 }
 
@@ -259,6 +261,30 @@ func applicationBackupScheduleLogTest(t *testing.T) {
 	ApplicationBackupScheduleLog(nil).Infof("applicationbackupschedule nil log")
 }
 
+func backupVerificationScheduleLogTest(t *testing.T) {
+	metadata := metav1.ObjectMeta{
+		Name:      "testbackupverificationschedule",
+		Namespace: "testnamespace",
+	}
+	verificationSchedule := &storkv1.BackupVerificationSchedule{
+		ObjectMeta: metadata,
+	}
+	BackupVerificationScheduleLog(verificationSchedule).Infof("backupverificationschedule log")
+	BackupVerificationScheduleLog(nil).Infof("backupverificationschedule nil log")
+}
+
+func migrationFailoverLogTest(t *testing.T) {
+	metadata := metav1.ObjectMeta{
+		Name:      "testmigrationfailover",
+		Namespace: "testnamespace",
+	}
+	failover := &storkv1.MigrationFailover{
+		ObjectMeta: metadata,
+	}
+	MigrationFailoverLog(failover).Infof("migrationfailover log")
+	MigrationFailoverLog(nil).Infof("migrationfailover nil log")
+}
+
 func backupLocationLogTest(t *testing.T) {
 	metadata := metav1.ObjectMeta{
 		Name:      "testbackuplocation",
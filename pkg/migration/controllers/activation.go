@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	"github.com/portworx/sched-ops/k8s/core"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// StorkMigrationActivationConfigMap is the name of the config map that
+	// is created in a namespace when the migrated applications in it are
+	// activated through stork. Scaling up migrated applications is only
+	// allowed in namespaces that have been activated
+	StorkMigrationActivationConfigMap = "stork-migration-activation"
+	// StorkMigrationActivationTimeKey is the key in the activation config map
+	// with the time the namespace was activated
+	StorkMigrationActivationTimeKey = "activationTime"
+	// StorkMigrationAllowScaleAnnotation can be set to "true" on a migrated
+	// application to allow it to be scaled up without activating the
+	// namespace
+	StorkMigrationAllowScaleAnnotation = "stork.libopenstorage.org/allowScaleUp"
+	// StorkMigrationResumeValueAnnotation is used to save the value of the
+	// field that suspends an application, other than the replicas, when it is
+	// suspended after migration
+	StorkMigrationResumeValueAnnotation = "stork.libopenstorage.org/migrationResumeValue"
+)
+
+// SetNamespaceActivated records whether the migrated applications in a
+// namespace have been activated through stork
+func SetNamespaceActivated(coreOps core.Ops, namespace string, activated bool) error {
+	if !activated {
+		err := coreOps.DeleteConfigMap(StorkMigrationActivationConfigMap, namespace)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	_, err := coreOps.CreateConfigMap(&v1.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      StorkMigrationActivationConfigMap,
+			Namespace: namespace,
+		},
+		Data: map[string]string{
+			StorkMigrationActivationTimeKey: time.Now().Format(time.RFC3339),
+		},
+	})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// IsNamespaceActivated returns true if the migrated applications in a
+// namespace have been activated through stork
+func IsNamespaceActivated(coreOps core.Ops, namespace string) (bool, error) {
+	_, err := coreOps.GetConfigMap(StorkMigrationActivationConfigMap, namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// getResumeAnnotation returns the annotation used to save the value of the
+// suspend field. The replicas annotation is used for the replicas so that
+// applications migrated by older versions can still be activated
+func getResumeAnnotation(options *resourcecollector.ApplicationSuspendOptions) string {
+	if options.Path == "spec.replicas" {
+		return StorkMigrationReplicasAnnotation
+	}
+	return StorkMigrationResumeValueAnnotation
+}
+
+// IsApplicationSuspended returns true if the application has the annotation
+// with the value to resume it with
+func IsApplicationSuspended(options *resourcecollector.ApplicationSuspendOptions, annotations map[string]string) bool {
+	_, ok := annotations[getResumeAnnotation(options)]
+	return ok
+}
+
+// SuspendApplication sets the field that suspends an application and saves
+// the current value in an annotation so that it can be resumed. The saved
+// value is kept if the application is already suspended
+func SuspendApplication(options *resourcecollector.ApplicationSuspendOptions, o *unstructured.Unstructured) error {
+	content := o.UnstructuredContent()
+	value, err := options.GetValue(content)
+	if err != nil {
+		return err
+	}
+	annotations := o.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if _, ok := annotations[getResumeAnnotation(options)]; !ok || !reflect.DeepEqual(value, options.Value) {
+		encoded, err := resourcecollector.EncodeValue(value)
+		if err != nil {
+			return fmt.Errorf("error saving %v: %v", options.Path, err)
+		}
+		annotations[getResumeAnnotation(options)] = encoded
+		o.SetAnnotations(annotations)
+	}
+	return options.SetValue(content, options.Value)
+}
+
+// ResumeApplication restores the value of the field that suspends an
+// application from the annotation saved when it was suspended. Returns the
+// restored value
+func ResumeApplication(options *resourcecollector.ApplicationSuspendOptions, o *unstructured.Unstructured) (interface{}, error) {
+	encoded, ok := o.GetAnnotations()[getResumeAnnotation(options)]
+	if !ok {
+		return nil, fmt.Errorf("%v %v/%v wasn't suspended", options.Kind, o.GetNamespace(), o.GetName())
+	}
+	value, err := resourcecollector.DecodeValue(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing saved %v: %v", options.Path, err)
+	}
+	return value, options.SetValue(o.UnstructuredContent(), value)
+}
@@ -2,36 +2,49 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/libopenstorage/stork/drivers/volume"
 	"github.com/libopenstorage/stork/pkg/apis/stork"
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/controller"
+	storkerrors "github.com/libopenstorage/stork/pkg/errors"
 	"github.com/operator-framework/operator-sdk/pkg/sdk"
 	"github.com/portworx/sched-ops/k8s/apiextensions"
+	"github.com/portworx/sched-ops/k8s/core"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	v1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/tools/record"
 )
 
 const (
 	validateCRDInterval time.Duration = 5 * time.Second
 	validateCRDTimeout  time.Duration = 1 * time.Minute
+
+	defaultClusterPairHealthCheckInterval = 5 * time.Minute
+	clusterPairHealthCheckTimeout         = 30 * time.Second
 )
 
 // ClusterPairController controller to watch over ClusterPair
 type ClusterPairController struct {
 	Driver   volume.Driver
 	Recorder record.EventRecorder
+	// HealthCheckInterval is how often ready pairs are revalidated. Defaults
+	// to 5 minutes
+	HealthCheckInterval time.Duration
 }
 
 // Init initialize the cluster pair controller
@@ -65,68 +78,237 @@ func (c *ClusterPairController) Handle(ctx context.Context, event sdk.Event) err
 			return nil
 		}
 
-		if len(clusterPair.Spec.Options) == 0 {
+		options, err := getClusterPairOptions(clusterPair)
+		if err != nil {
+			c.Recorder.Event(clusterPair,
+				v1.EventTypeWarning,
+				string(stork_api.ClusterPairStatusError),
+				err.Error())
+			return err
+		}
+		if len(options) == 0 {
 			clusterPair.Status.StorageStatus = stork_api.ClusterPairStatusNotProvided
 			c.Recorder.Event(clusterPair,
 				v1.EventTypeNormal,
 				string(clusterPair.Status.StorageStatus),
 				"Skipping storage pairing since no storage options provided")
-			err := sdk.Update(clusterPair)
+			err = sdk.Update(clusterPair)
 			if err != nil {
 				return err
 			}
-		} else {
-			if clusterPair.Status.StorageStatus != stork_api.ClusterPairStatusReady {
-				remoteID, err := c.Driver.CreatePair(clusterPair)
-				if err != nil {
-					clusterPair.Status.StorageStatus = stork_api.ClusterPairStatusError
-					c.Recorder.Event(clusterPair,
-						v1.EventTypeWarning,
-						string(clusterPair.Status.StorageStatus),
-						err.Error())
-				} else {
-					clusterPair.Status.StorageStatus = stork_api.ClusterPairStatusReady
-					c.Recorder.Event(clusterPair,
-						v1.EventTypeNormal,
-						string(clusterPair.Status.StorageStatus),
-						"Storage successfully paired")
-					clusterPair.Status.RemoteStorageID = remoteID
-				}
-				err = sdk.Update(clusterPair)
-				if err != nil {
-					return err
-				}
-			}
-		}
-		if clusterPair.Status.SchedulerStatus != stork_api.ClusterPairStatusReady {
-			remoteConfig, err := getClusterPairSchedulerConfig(clusterPair.Name, clusterPair.Namespace)
+		} else if c.updateStoragePair(clusterPair, options) {
+			err = sdk.Update(clusterPair)
 			if err != nil {
 				return err
 			}
-
-			client, err := kubernetes.NewForConfig(remoteConfig)
-			if err != nil {
+		}
+		if clusterPair.Status.SchedulerStatus != stork_api.ClusterPairStatusReady ||
+			c.healthCheckRequired(clusterPair) {
+			if err := c.checkHealth(clusterPair); err != nil {
 				return err
 			}
-			if _, err = client.ServerVersion(); err != nil {
-				clusterPair.Status.SchedulerStatus = stork_api.ClusterPairStatusError
-				c.Recorder.Event(clusterPair,
-					v1.EventTypeWarning,
-					string(clusterPair.Status.SchedulerStatus),
-					err.Error())
-			} else {
-				clusterPair.Status.SchedulerStatus = stork_api.ClusterPairStatusReady
-				c.Recorder.Event(clusterPair,
-					v1.EventTypeNormal,
-					string(clusterPair.Status.SchedulerStatus),
-					"Scheduler successfully paired")
+			return sdk.Update(clusterPair)
+		}
+	}
+	return nil
+}
+
+// updateStoragePair creates the pair with the storage driver if it hasn't
+// been created yet or if the options have changed since it was created, for
+// example when a token in the credentials Secret is rotated. A degraded pair
+// that still has the same options is revalidated with the health checks
+// instead. Returns true if the status was updated
+func (c *ClusterPairController) updateStoragePair(clusterPair *stork_api.ClusterPair, options map[string]string) bool {
+	optionsHash := getClusterPairOptionsHash(options)
+	created := clusterPair.Status.StorageStatus == stork_api.ClusterPairStatusReady ||
+		clusterPair.Status.StorageStatus == stork_api.ClusterPairStatusDegraded
+	if created && clusterPair.Status.OptionsHash == optionsHash {
+		return false
+	}
+	// Pairs created before the hash was recorded are assumed to have been
+	// created with the current options
+	if created && clusterPair.Status.OptionsHash == "" {
+		clusterPair.Status.OptionsHash = optionsHash
+		return true
+	}
+
+	// Pass the options from the credentials secret to the driver without
+	// saving them in the pair
+	pair := clusterPair.DeepCopy()
+	pair.Spec.Options = options
+	remoteID, err := c.Driver.CreatePair(pair)
+	if err != nil {
+		clusterPair.Status.StorageStatus = stork_api.ClusterPairStatusError
+		c.Recorder.Event(clusterPair,
+			v1.EventTypeWarning,
+			string(clusterPair.Status.StorageStatus),
+			err.Error())
+		return true
+	}
+
+	message := "Storage successfully paired"
+	if created {
+		message = "Storage pair updated with the new options"
+	}
+	clusterPair.Status.StorageStatus = stork_api.ClusterPairStatusReady
+	clusterPair.Status.RemoteStorageID = remoteID
+	clusterPair.Status.OptionsHash = optionsHash
+	c.Recorder.Event(clusterPair,
+		v1.EventTypeNormal,
+		string(clusterPair.Status.StorageStatus),
+		message)
+	return true
+}
+
+// getClusterPairOptionsHash returns a hash of the storage options of a pair so
+// that changes can be detected without saving the options in the status
+func getClusterPairOptionsHash(options map[string]string) string {
+	keys := make([]string, 0, len(options))
+	for k := range options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(hash, "%q=%q\n", k, options[k])
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+func (c *ClusterPairController) healthCheckRequired(clusterPair *stork_api.ClusterPair) bool {
+	interval := c.HealthCheckInterval
+	if interval == 0 {
+		interval = defaultClusterPairHealthCheckInterval
+	}
+	return time.Since(clusterPair.Status.LastCheckedTimestamp.Time) >= interval
+}
+
+// checkHealth checks that the remote scheduler is reachable and that the
+// storage pair is healthy and updates the status and conditions of the pair.
+// Events are only raised when the status changes
+func (c *ClusterPairController) checkHealth(clusterPair *stork_api.ClusterPair) error {
+	remoteConfig, err := getClusterPairSchedulerConfig(clusterPair.Name, clusterPair.Namespace)
+	if err != nil {
+		return err
+	}
+	remoteConfig.Timeout = clusterPairHealthCheckTimeout
+	client, err := kubernetes.NewForConfig(remoteConfig)
+	if err != nil {
+		return err
+	}
+
+	schedulerStatus := clusterPair.Status.SchedulerStatus
+	start := time.Now()
+	if _, err = client.ServerVersion(); err != nil {
+		clusterPair.Status.SchedulerStatus = stork_api.ClusterPairStatusError
+		reason := "Unreachable"
+		if errors.IsUnauthorized(err) || errors.IsForbidden(err) {
+			reason = "Unauthorized"
+		}
+		setClusterPairCondition(clusterPair, stork_api.ClusterPairConditionSchedulerReachable,
+			v1.ConditionFalse, reason, err.Error())
+		if schedulerStatus != clusterPair.Status.SchedulerStatus {
+			c.Recorder.Event(clusterPair,
+				v1.EventTypeWarning,
+				string(clusterPair.Status.SchedulerStatus),
+				err.Error())
+		}
+	} else {
+		clusterPair.Status.SchedulerLatency = meta.Duration{Duration: time.Since(start)}
+		clusterPair.Status.SchedulerStatus = stork_api.ClusterPairStatusReady
+		setClusterPairCondition(clusterPair, stork_api.ClusterPairConditionSchedulerReachable,
+			v1.ConditionTrue, "Reachable", "")
+		if schedulerStatus != clusterPair.Status.SchedulerStatus {
+			msg := "Scheduler successfully paired"
+			if schedulerStatus == stork_api.ClusterPairStatusError {
+				msg = "Scheduler is reachable again"
 			}
-			err = sdk.Update(clusterPair)
-			if err != nil {
-				return err
+			c.Recorder.Event(clusterPair,
+				v1.EventTypeNormal,
+				string(clusterPair.Status.SchedulerStatus),
+				msg)
+		}
+	}
+
+	// Only validate the storage pair once it has been created
+	storageStatus := clusterPair.Status.StorageStatus
+	if (storageStatus == stork_api.ClusterPairStatusReady ||
+		storageStatus == stork_api.ClusterPairStatusDegraded) &&
+		clusterPair.Status.RemoteStorageID != "" {
+		// Nothing to update if the driver can't validate pairs
+		err := c.Driver.ValidatePair(clusterPair)
+		if _, ok := err.(*storkerrors.ErrNotSupported); !ok {
+			c.updateStorageHealth(clusterPair, err)
+		}
+	}
+	clusterPair.Status.LastCheckedTimestamp = meta.Now()
+	return nil
+}
+
+func (c *ClusterPairController) updateStorageHealth(clusterPair *stork_api.ClusterPair, validateErr error) {
+	storageStatus := clusterPair.Status.StorageStatus
+	if validateErr != nil {
+		clusterPair.Status.StorageStatus = stork_api.ClusterPairStatusDegraded
+		setClusterPairCondition(clusterPair, stork_api.ClusterPairConditionStorageHealthy,
+			v1.ConditionFalse, "ValidationFailed", validateErr.Error())
+		if storageStatus != clusterPair.Status.StorageStatus {
+			c.Recorder.Event(clusterPair,
+				v1.EventTypeWarning,
+				string(clusterPair.Status.StorageStatus),
+				fmt.Sprintf("Storage pair is unhealthy: %v", validateErr))
+		}
+		return
+	}
+	clusterPair.Status.StorageStatus = stork_api.ClusterPairStatusReady
+	setClusterPairCondition(clusterPair, stork_api.ClusterPairConditionStorageHealthy,
+		v1.ConditionTrue, "Validated", "")
+	if storageStatus != clusterPair.Status.StorageStatus {
+		c.Recorder.Event(clusterPair,
+			v1.EventTypeNormal,
+			string(clusterPair.Status.StorageStatus),
+			"Storage pair is healthy again")
+	}
+}
+
+// setClusterPairCondition updates the condition of the given type, adding it
+// if it isn't present. The transition time is only updated if the status of
+// the condition changes
+func setClusterPairCondition(
+	clusterPair *stork_api.ClusterPair,
+	conditionType stork_api.ClusterPairConditionType,
+	status v1.ConditionStatus,
+	reason string,
+	message string,
+) {
+	condition := stork_api.ClusterPairCondition{
+		Type:               conditionType,
+		Status:             status,
+		LastTransitionTime: meta.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+	for i, existing := range clusterPair.Status.Conditions {
+		if existing.Type == conditionType {
+			if existing.Status == status {
+				condition.LastTransitionTime = existing.LastTransitionTime
 			}
+			clusterPair.Status.Conditions[i] = condition
+			return
 		}
 	}
+	clusterPair.Status.Conditions = append(clusterPair.Status.Conditions, condition)
+}
+
+// isClusterPairHealthy returns an error describing the problem if the
+// scheduler or storage pairing isn't healthy
+func isClusterPairHealthy(clusterPair *stork_api.ClusterPair) error {
+	if clusterPair.Status.SchedulerStatus != stork_api.ClusterPairStatusReady {
+		return fmt.Errorf("scheduler status for clusterpair %v is %v", clusterPair.Name, clusterPair.Status.SchedulerStatus)
+	}
+	if clusterPair.Status.StorageStatus != stork_api.ClusterPairStatusReady &&
+		clusterPair.Status.StorageStatus != stork_api.ClusterPairStatusNotProvided {
+		return fmt.Errorf("storage status for clusterpair %v is %v", clusterPair.Name, clusterPair.Status.StorageStatus)
+	}
 	return nil
 }
 
@@ -135,14 +317,81 @@ func getClusterPairSchedulerConfig(clusterPairName string, namespace string) (*r
 	if err != nil {
 		return nil, fmt.Errorf("error getting clusterpair (%v/%v): %v", namespace, clusterPairName, err)
 	}
+	config, err := getClusterPairConfig(clusterPair)
+	if err != nil {
+		return nil, err
+	}
 	remoteClientConfig := clientcmd.NewNonInteractiveClientConfig(
-		clusterPair.Spec.Config,
-		clusterPair.Spec.Config.CurrentContext,
+		*config,
+		config.CurrentContext,
 		&clientcmd.ConfigOverrides{},
 		clientcmd.NewDefaultClientConfigLoadingRules())
 	return remoteClientConfig.ClientConfig()
 }
 
+func getClusterPairCredentials(clusterPair *stork_api.ClusterPair) (map[string][]byte, error) {
+	if clusterPair.Spec.CredentialsSecret == "" {
+		return nil, nil
+	}
+	secret, err := core.Instance().GetSecret(clusterPair.Spec.CredentialsSecret, clusterPair.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error getting credentials secret %v for clusterpair %v: %v",
+			clusterPair.Spec.CredentialsSecret, clusterPair.Name, err)
+	}
+	return secret.Data, nil
+}
+
+// getClusterPairConfig returns the kubeconfig for the remote cluster with the
+// credentials from the Secret of the pair, if any
+func getClusterPairConfig(clusterPair *stork_api.ClusterPair) (*clientcmdapi.Config, error) {
+	credentials, err := getClusterPairCredentials(clusterPair)
+	if err != nil {
+		return nil, err
+	}
+	config := clusterPair.Spec.Config.DeepCopy()
+	if kubeconfig, ok := credentials[stork_api.ClusterPairSecretKubeconfigKey]; ok {
+		config, err = clientcmd.Load(kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing kubeconfig from credentials secret %v: %v",
+				clusterPair.Spec.CredentialsSecret, err)
+		}
+	}
+	if token, ok := credentials[stork_api.ClusterPairSecretTokenKey]; ok {
+		context, ok := config.Contexts[config.CurrentContext]
+		if !ok {
+			return nil, fmt.Errorf("current context %v not found in config for clusterpair %v",
+				config.CurrentContext, clusterPair.Name)
+		}
+		if config.AuthInfos == nil {
+			config.AuthInfos = make(map[string]*clientcmdapi.AuthInfo)
+		}
+		// Replace any other credentials with the token
+		config.AuthInfos[context.AuthInfo] = &clientcmdapi.AuthInfo{
+			Token: string(token),
+		}
+	}
+	return config, nil
+}
+
+// getClusterPairOptions returns the storage options of the pair including the
+// ones from the Secret of the pair, if any
+func getClusterPairOptions(clusterPair *stork_api.ClusterPair) (map[string]string, error) {
+	credentials, err := getClusterPairCredentials(clusterPair)
+	if err != nil {
+		return nil, err
+	}
+	options := make(map[string]string)
+	for k, v := range clusterPair.Spec.Options {
+		options[k] = v
+	}
+	for k, v := range credentials {
+		if strings.HasPrefix(k, stork_api.ClusterPairSecretOptionsPrefix) {
+			options[strings.TrimPrefix(k, stork_api.ClusterPairSecretOptionsPrefix)] = string(v)
+		}
+	}
+	return options, nil
+}
+
 func getClusterPairStorageStatus(clusterPairName string, namespace string) (stork_api.ClusterPairStatusType, error) {
 	clusterPair, err := storkops.Instance().GetClusterPair(clusterPairName, namespace)
 	if err != nil {
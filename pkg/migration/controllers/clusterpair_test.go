@@ -0,0 +1,110 @@
+// +build unittest
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/libopenstorage/stork/drivers/volume/mock"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+// pairTestDriver records the options that pairs are created with
+type pairTestDriver struct {
+	mock.Driver
+	options []map[string]string
+	err     error
+}
+
+func (d *pairTestDriver) CreatePair(pair *stork_api.ClusterPair) (string, error) {
+	if d.err != nil {
+		return "", d.err
+	}
+	d.options = append(d.options, pair.Spec.Options)
+	return fmt.Sprintf("remote-%v", len(d.options)), nil
+}
+
+func TestUpdateStoragePair(t *testing.T) {
+	fakeKube := kubernetes.NewSimpleClientset()
+	core.SetInstance(core.New(fakeKube, fakeKube.CoreV1(), fakeKube.StorageV1()))
+	secret, err := core.Instance().CreateSecret(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "credentials",
+			Namespace: "ns",
+		},
+		Data: map[string][]byte{
+			stork_api.ClusterPairSecretOptionsPrefix + "token": []byte("token1"),
+		},
+	})
+	require.NoError(t, err, "Error creating credentials secret")
+	driver := &pairTestDriver{}
+	recorder := record.NewFakeRecorder(100)
+	controller := &ClusterPairController{Driver: driver, Recorder: recorder}
+	clusterPair := &stork_api.ClusterPair{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pair",
+			Namespace: "ns",
+		},
+		Spec: stork_api.ClusterPairSpec{
+			Options:           map[string]string{"ip": "10.0.0.1"},
+			CredentialsSecret: "credentials",
+		},
+	}
+	updateStoragePair := func() bool {
+		options, err := getClusterPairOptions(clusterPair)
+		require.NoError(t, err, "Error getting options")
+		return controller.updateStoragePair(clusterPair, options)
+	}
+
+	require.True(t, updateStoragePair(), "Pair should have been created")
+	require.Equal(t, stork_api.ClusterPairStatusReady, clusterPair.Status.StorageStatus)
+	require.Equal(t, "remote-1", clusterPair.Status.RemoteStorageID)
+	require.NotEmpty(t, clusterPair.Status.OptionsHash)
+	require.Equal(t, []map[string]string{{"ip": "10.0.0.1", "token": "token1"}}, driver.options)
+	require.Equal(t, map[string]string{"ip": "10.0.0.1"}, clusterPair.Spec.Options,
+		"Options from the secret shouldn't be saved in the pair")
+	require.Contains(t, <-recorder.Events, "Storage successfully paired")
+
+	// Pairs whose options haven't changed aren't created again, even when
+	// they are degraded
+	clusterPair.Status.StorageStatus = stork_api.ClusterPairStatusDegraded
+	require.False(t, updateStoragePair(), "Pair shouldn't be updated")
+	require.Len(t, driver.options, 1)
+
+	// Rotated options should be applied to pairs that have been created
+	secret.Data[stork_api.ClusterPairSecretOptionsPrefix+"token"] = []byte("token2")
+	secret, err = core.Instance().UpdateSecret(secret)
+	require.NoError(t, err, "Error updating credentials secret")
+	require.True(t, updateStoragePair(), "Pair should have been updated")
+	require.Equal(t, stork_api.ClusterPairStatusReady, clusterPair.Status.StorageStatus)
+	require.Equal(t, "remote-2", clusterPair.Status.RemoteStorageID)
+	require.Equal(t, "token2", driver.options[1]["token"])
+	require.Contains(t, <-recorder.Events, "Storage pair updated with the new options")
+
+	// Failures are retried until the pair is created with the new options
+	secret.Data[stork_api.ClusterPairSecretOptionsPrefix+"token"] = []byte("token3")
+	_, err = core.Instance().UpdateSecret(secret)
+	require.NoError(t, err, "Error updating credentials secret")
+	driver.err = fmt.Errorf("invalid token")
+	require.True(t, updateStoragePair(), "Pair should have been updated")
+	require.Equal(t, stork_api.ClusterPairStatusError, clusterPair.Status.StorageStatus)
+	require.Contains(t, <-recorder.Events, "invalid token")
+	driver.err = nil
+	require.True(t, updateStoragePair(), "Pair should have been created again")
+	require.Equal(t, stork_api.ClusterPairStatusReady, clusterPair.Status.StorageStatus)
+	require.Equal(t, "token3", driver.options[2]["token"])
+
+	// The hash is recorded for pairs created before it was added
+	hash := clusterPair.Status.OptionsHash
+	clusterPair.Status.OptionsHash = ""
+	require.True(t, updateStoragePair(), "Hash should have been recorded")
+	require.Equal(t, hash, clusterPair.Status.OptionsHash)
+	require.Len(t, driver.options, 3)
+}
@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	"github.com/portworx/sched-ops/k8s/core"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Annotations that are expected to be different on the destination, either
+// because they are set by stork during migration or by the controllers on
+// each cluster
+var driftIgnoredAnnotationPrefixes = []string{
+	"stork.libopenstorage.org/",
+	"pv.kubernetes.io/",
+	"deployment.kubernetes.io/revision",
+}
+
+// CompareMigration collects the resources selected by the migration spec from
+// the source cluster and from the cluster the ClusterPair points to and
+// returns the resources that are missing, extra or different on the
+// destination. srcCollector should already have been initialized for the
+// source cluster
+func CompareMigration(
+	srcCollector *resourcecollector.ResourceCollector,
+	spec *stork_api.MigrationSpec,
+	namespace string,
+	allDrivers bool,
+) ([]*stork_api.MigrationDriftInfo, error) {
+	remoteConfig, err := getClusterPairSchedulerConfig(spec.ClusterPair, namespace)
+	if err != nil {
+		return nil, err
+	}
+	destCollector := resourcecollector.ResourceCollector{
+		Driver:         srcCollector.Driver,
+		AdminNamespace: srcCollector.AdminNamespace,
+	}
+	if err := destCollector.Init(remoteConfig); err != nil {
+		return nil, fmt.Errorf("error initializing resource collector for destination: %v", err)
+	}
+
+	srcObjects, err := srcCollector.GetResources(spec.Namespaces, spec.Selectors, allDrivers)
+	if err != nil {
+		return nil, fmt.Errorf("error getting resources from source: %v", err)
+	}
+	destObjects, err := destCollector.GetResources(getDestinationNamespaces(spec), spec.Selectors, allDrivers)
+	if err != nil {
+		return nil, fmt.Errorf("error getting resources from destination: %v", err)
+	}
+	// Compare the source objects with the namespaces they were migrated to
+	for _, o := range srcObjects {
+		if err := mapObjectNamespace(spec, o); err != nil {
+			return nil, err
+		}
+	}
+	registry, err := resourcecollector.GetApplicationRegistry(core.Instance(), srcCollector.AdminNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return CompareResources(srcObjects, destObjects, registry)
+}
+
+// CompareResources compares the objects collected from the source and
+// destination clusters. Fields that stork changes when migrating, like the
+// fields that suspend applications in the registry and the migration
+// annotations, are ignored
+func CompareResources(
+	srcObjects []runtime.Unstructured,
+	destObjects []runtime.Unstructured,
+	registry []*resourcecollector.ApplicationSuspendOptions,
+) ([]*stork_api.MigrationDriftInfo, error) {
+	destMap := make(map[string]runtime.Unstructured)
+	for _, o := range destObjects {
+		key, err := getDriftKey(o)
+		if err != nil {
+			return nil, err
+		}
+		destMap[key] = o
+	}
+
+	drift := make([]*stork_api.MigrationDriftInfo, 0)
+	for _, src := range srcObjects {
+		key, err := getDriftKey(src)
+		if err != nil {
+			return nil, err
+		}
+		dest, ok := destMap[key]
+		if !ok {
+			info, err := getDriftInfo(src, stork_api.MigrationDriftMissing)
+			if err != nil {
+				return nil, err
+			}
+			drift = append(drift, info)
+			continue
+		}
+		delete(destMap, key)
+
+		fields := make([]string, 0)
+		getDriftFields(normalizeForDrift(src, registry), normalizeForDrift(dest, registry), "", &fields)
+		if len(fields) != 0 {
+			info, err := getDriftInfo(src, stork_api.MigrationDriftDifferent)
+			if err != nil {
+				return nil, err
+			}
+			sort.Strings(fields)
+			info.Fields = fields
+			drift = append(drift, info)
+		}
+	}
+	for _, dest := range destMap {
+		info, err := getDriftInfo(dest, stork_api.MigrationDriftExtra)
+		if err != nil {
+			return nil, err
+		}
+		drift = append(drift, info)
+	}
+
+	sort.Slice(drift, func(i, j int) bool {
+		if drift[i].Namespace != drift[j].Namespace {
+			return drift[i].Namespace < drift[j].Namespace
+		}
+		if drift[i].Kind != drift[j].Kind {
+			return drift[i].Kind < drift[j].Kind
+		}
+		return drift[i].Name < drift[j].Name
+	})
+	return drift, nil
+}
+
+func getDriftKey(o runtime.Unstructured) (string, error) {
+	metadata, err := meta.Accessor(o)
+	if err != nil {
+		return "", err
+	}
+	gvk := o.GetObjectKind().GroupVersionKind()
+	return strings.Join([]string{gvk.Group, gvk.Kind, metadata.GetNamespace(), metadata.GetName()}, "/"), nil
+}
+
+func getDriftInfo(o runtime.Unstructured, driftType stork_api.MigrationDriftType) (*stork_api.MigrationDriftInfo, error) {
+	metadata, err := meta.Accessor(o)
+	if err != nil {
+		return nil, err
+	}
+	gvk := o.GetObjectKind().GroupVersionKind()
+	info := &stork_api.MigrationDriftInfo{
+		Name:      metadata.GetName(),
+		Namespace: metadata.GetNamespace(),
+		GroupVersionKind: metav1.GroupVersionKind{
+			Group:   gvk.Group,
+			Version: gvk.Version,
+			Kind:    gvk.Kind,
+		},
+		Type: driftType,
+	}
+	// core Group doesn't have a name, so override it
+	if info.Group == "" {
+		info.Group = "core"
+	}
+	return info, nil
+}
+
+// normalizeForDrift returns a copy of the content of the object without the
+// fields that are expected to be different on the destination
+func normalizeForDrift(
+	o runtime.Unstructured,
+	registry []*resourcecollector.ApplicationSuspendOptions,
+) map[string]interface{} {
+	content := runtime.DeepCopyJSON(o.UnstructuredContent())
+
+	annotations, found, err := unstructured.NestedStringMap(content, "metadata", "annotations")
+	if err == nil && found {
+		for key := range annotations {
+			for _, prefix := range driftIgnoredAnnotationPrefixes {
+				if strings.HasPrefix(key, prefix) {
+					delete(annotations, key)
+					break
+				}
+			}
+		}
+		if len(annotations) == 0 {
+			unstructured.RemoveNestedField(content, "metadata", "annotations")
+		} else if err := unstructured.SetNestedStringMap(content, annotations, "metadata", "annotations"); err != nil {
+			return content
+		}
+	}
+
+	// Applications are suspended on the destination unless they are started
+	// after migration
+	if options := resourcecollector.GetApplicationSuspendOptions(registry, o.GetObjectKind().GroupVersionKind()); options != nil {
+		options.RemoveValue(content)
+	}
+
+	switch o.GetObjectKind().GroupVersionKind().Kind {
+	case "PersistentVolume":
+		// The volume driver updates the spec to point to the migrated volume
+		unstructured.RemoveNestedField(content, "spec")
+	case "ServiceAccount":
+		// The token secrets are created separately on each cluster
+		unstructured.RemoveNestedField(content, "secrets")
+	}
+	return content
+}
+
+// getDriftFields adds the paths of the fields that are different in the two
+// objects. Maps are compared field by field, all other values are compared as
+// a whole
+func getDriftFields(src, dest interface{}, path string, fields *[]string) {
+	srcMap, srcOk := src.(map[string]interface{})
+	destMap, destOk := dest.(map[string]interface{})
+	if !srcOk || !destOk {
+		if !reflect.DeepEqual(src, dest) {
+			*fields = append(*fields, path)
+		}
+		return
+	}
+
+	for key, srcValue := range srcMap {
+		getDriftFields(srcValue, destMap[key], path+"."+key, fields)
+	}
+	for key, destValue := range destMap {
+		if _, ok := srcMap[key]; !ok {
+			getDriftFields(nil, destValue, path+"."+key, fields)
+		}
+	}
+}
@@ -0,0 +1,632 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/libopenstorage/stork/pkg/apis/stork"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/controller"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/rule"
+	"github.com/operator-framework/operator-sdk/pkg/sdk"
+	"github.com/portworx/sched-ops/k8s/apiextensions"
+	"github.com/portworx/sched-ops/k8s/apps"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// DRPlanActionAnnotation is added to a DRPlan to execute it. The value is
+	// the action to execute it for, Failover, Failback or Test
+	DRPlanActionAnnotation = "stork.libopenstorage.org/drPlanAction"
+
+	defaultDRPlanHealthCheckTimeout = 10 * time.Minute
+)
+
+// drPlanWaveStages are the stages of a wave in the order they are run
+var drPlanWaveStages = []stork_api.DRPlanWaveStageType{
+	stork_api.DRPlanWaveStagePreExecRule,
+	stork_api.DRPlanWaveStageFailover,
+	stork_api.DRPlanWaveStageHealthCheck,
+	stork_api.DRPlanWaveStagePostExecRule,
+}
+
+// DRPlanController reconciles DRPlan objects
+type DRPlanController struct {
+	Recorder record.EventRecorder
+}
+
+// Init Initialize the drplan controller
+func (d *DRPlanController) Init() error {
+	err := d.createCRD()
+	if err != nil {
+		return err
+	}
+	return controller.Register(
+		&schema.GroupVersionKind{
+			Group:   stork.GroupName,
+			Version: stork_api.SchemeGroupVersion.Version,
+			Kind:    reflect.TypeOf(stork_api.DRPlan{}).Name(),
+		},
+		"",
+		30*time.Second,
+		d)
+}
+
+// Handle updates for DRPlan objects
+func (d *DRPlanController) Handle(ctx context.Context, event sdk.Event) error {
+	switch o := event.Object.(type) {
+	case *stork_api.DRPlan:
+		plan := o
+		if event.Deleted {
+			return nil
+		}
+
+		if action, ok := plan.Annotations[DRPlanActionAnnotation]; ok {
+			return d.startPlan(plan, stork_api.DRPlanActionType(action))
+		}
+		if _, ok := plan.Annotations[stork.RetryAnnotation]; ok {
+			return d.retryPlan(plan)
+		}
+
+		if plan.Status.Status == stork_api.DRPlanStatusInProgress {
+			return d.runWaves(plan)
+		}
+	}
+	return nil
+}
+
+func validateDRPlan(plan *stork_api.DRPlan, action stork_api.DRPlanActionType) error {
+	if action != stork_api.DRPlanActionFailover &&
+		action != stork_api.DRPlanActionFailback &&
+		action != stork_api.DRPlanActionTest {
+		return fmt.Errorf("invalid action %v", action)
+	}
+	if plan.Spec.ClusterPair == "" {
+		return fmt.Errorf("ClusterPair needs to be specified")
+	}
+	if len(plan.Spec.Waves) == 0 {
+		return fmt.Errorf("at least one wave needs to be specified")
+	}
+	names := make(map[string]bool)
+	for _, wave := range plan.Spec.Waves {
+		if wave.Name == "" {
+			return fmt.Errorf("name needs to be specified for all waves")
+		}
+		if names[wave.Name] {
+			return fmt.Errorf("duplicate wave %v", wave.Name)
+		}
+		names[wave.Name] = true
+		if len(wave.MigrationSchedules) == 0 && len(wave.Namespaces) == 0 {
+			return fmt.Errorf("migration schedules or namespaces need to be specified for wave %v", wave.Name)
+		}
+	}
+	return nil
+}
+
+// getDRPlanWave returns the spec of a wave in the plan
+func getDRPlanWave(plan *stork_api.DRPlan, name string) *stork_api.DRPlanWave {
+	for i, wave := range plan.Spec.Waves {
+		if wave.Name == name {
+			return &plan.Spec.Waves[i]
+		}
+	}
+	return nil
+}
+
+func (d *DRPlanController) failPlan(plan *stork_api.DRPlan, message string) error {
+	plan.Status.Status = stork_api.DRPlanStatusFailed
+	plan.Status.FinishTimestamp = meta.Now()
+	d.Recorder.Event(plan,
+		v1.EventTypeWarning,
+		string(stork_api.DRPlanStatusFailed),
+		message)
+	log.DRPlanLog(plan).Error(message)
+	return sdk.Update(plan)
+}
+
+// startPlan resets the status of the plan and starts executing it for the
+// action from the annotation, unless it is already being executed
+func (d *DRPlanController) startPlan(plan *stork_api.DRPlan, action stork_api.DRPlanActionType) error {
+	delete(plan.Annotations, DRPlanActionAnnotation)
+	if plan.Status.Status == stork_api.DRPlanStatusInProgress {
+		message := fmt.Sprintf("Ignoring %v since the plan is already being executed for %v",
+			strings.ToLower(string(action)), strings.ToLower(string(plan.Status.Action)))
+		log.DRPlanLog(plan).Warn(message)
+		d.Recorder.Event(plan,
+			v1.EventTypeWarning,
+			string(plan.Status.Status),
+			message)
+		return sdk.Update(plan)
+	}
+
+	plan.Status = stork_api.DRPlanStatus{
+		Action:         action,
+		StartTimestamp: meta.Now(),
+	}
+	if err := validateDRPlan(plan, action); err != nil {
+		return d.failPlan(plan, fmt.Sprintf("Invalid plan: %v", err))
+	}
+
+	plan.Status.Waves = getDRPlanWaveInfos(plan)
+	plan.Status.Status = stork_api.DRPlanStatusInProgress
+	message := fmt.Sprintf("Started %v with %v waves", strings.ToLower(string(action)), len(plan.Status.Waves))
+	d.Recorder.Event(plan,
+		v1.EventTypeNormal,
+		string(stork_api.DRPlanStatusInProgress),
+		message)
+	log.DRPlanLog(plan).Info(message)
+	return sdk.Update(plan)
+}
+
+// getDRPlanWaveInfos returns the status of the waves of a plan that hasn't
+// been run yet
+func getDRPlanWaveInfos(plan *stork_api.DRPlan) []*stork_api.DRPlanWaveInfo {
+	waves := make([]*stork_api.DRPlanWaveInfo, 0)
+	for _, wave := range plan.Spec.Waves {
+		info := &stork_api.DRPlanWaveInfo{
+			Name:       wave.Name,
+			Status:     stork_api.DRPlanStatusPending,
+			Namespaces: wave.Namespaces,
+			Stages:     make([]*stork_api.DRPlanWaveStageInfo, 0),
+		}
+		for _, stage := range drPlanWaveStages {
+			info.Stages = append(info.Stages, &stork_api.DRPlanWaveStageInfo{
+				Stage:  stage,
+				Status: stork_api.DRPlanStatusPending,
+			})
+		}
+		waves = append(waves, info)
+	}
+	return waves
+}
+
+// runWaves runs the waves of the plan that haven't completed and saves the
+// status
+func (d *DRPlanController) runWaves(plan *stork_api.DRPlan) error {
+	if err := d.updateWaves(plan); err != nil {
+		return d.failPlan(plan, err.Error())
+	}
+	return sdk.Update(plan)
+}
+
+// updateWaves runs the waves of the plan that haven't completed in order,
+// until a wave is still in progress or fails
+func (d *DRPlanController) updateWaves(plan *stork_api.DRPlan) error {
+	for _, wave := range plan.Status.Waves {
+		if wave.Status == stork_api.DRPlanStatusSuccessful {
+			continue
+		}
+		if wave.Status == stork_api.DRPlanStatusPending {
+			wave.Status = stork_api.DRPlanStatusInProgress
+			wave.StartTimestamp = meta.Now()
+			message := fmt.Sprintf("Started wave %v", wave.Name)
+			d.Recorder.Event(plan,
+				v1.EventTypeNormal,
+				string(stork_api.DRPlanStatusInProgress),
+				message)
+			log.DRPlanLog(plan).Info(message)
+		}
+
+		var done bool
+		var err error
+		spec := getDRPlanWave(plan, wave.Name)
+		if spec == nil {
+			err = fmt.Errorf("wave was removed from the plan")
+		} else {
+			done, err = d.runWave(plan, spec, wave)
+		}
+		if err != nil {
+			wave.Status = stork_api.DRPlanStatusFailed
+			wave.Reason = err.Error()
+			wave.FinishTimestamp = meta.Now()
+			return fmt.Errorf("wave %v failed: %v", wave.Name, err)
+		}
+		if !done {
+			return nil
+		}
+
+		wave.Status = stork_api.DRPlanStatusSuccessful
+		wave.FinishTimestamp = meta.Now()
+		message := fmt.Sprintf("Completed wave %v", wave.Name)
+		d.Recorder.Event(plan,
+			v1.EventTypeNormal,
+			string(stork_api.DRPlanStatusSuccessful),
+			message)
+		log.DRPlanLog(plan).Info(message)
+	}
+
+	plan.Status.Status = stork_api.DRPlanStatusSuccessful
+	plan.Status.FinishTimestamp = meta.Now()
+	message := fmt.Sprintf("%v completed successfully", plan.Status.Action)
+	d.Recorder.Event(plan,
+		v1.EventTypeNormal,
+		string(stork_api.DRPlanStatusSuccessful),
+		message)
+	log.DRPlanLog(plan).Info(message)
+	return nil
+}
+
+// runWave runs the stages of a wave that haven't completed in order and
+// returns true once all of them are done. Stages that run a rule are only
+// started, without running the rule, so that the status is saved before the
+// rule is executed
+func (d *DRPlanController) runWave(
+	plan *stork_api.DRPlan,
+	spec *stork_api.DRPlanWave,
+	wave *stork_api.DRPlanWaveInfo,
+) (bool, error) {
+	for _, stage := range wave.Stages {
+		if stage.Status == stork_api.DRPlanStatusSuccessful ||
+			stage.Status == stork_api.DRPlanStatusSkipped {
+			continue
+		}
+		if stage.Status == stork_api.DRPlanStatusPending {
+			stage.Status = stork_api.DRPlanStatusInProgress
+			stage.StartTimestamp = meta.Now()
+			if name, _ := getDRPlanStageRule(spec, stage.Stage); name != "" {
+				stage.Reason = fmt.Sprintf("Running rule %v", name)
+				return false, nil
+			}
+		}
+
+		done, err := d.runStage(plan, spec, wave, stage)
+		if err != nil {
+			stage.Status = stork_api.DRPlanStatusFailed
+			stage.Reason = err.Error()
+			stage.FinishTimestamp = meta.Now()
+			return false, fmt.Errorf("error running stage %v: %v", stage.Stage, err)
+		}
+		if !done {
+			return false, nil
+		}
+
+		stage.FinishTimestamp = meta.Now()
+		if stage.Status == stork_api.DRPlanStatusSkipped {
+			continue
+		}
+		stage.Status = stork_api.DRPlanStatusSuccessful
+		stage.Reason = ""
+		log.DRPlanLog(plan).Infof("Completed stage %v of wave %v", stage.Stage, wave.Name)
+	}
+	return true, nil
+}
+
+// runStage runs a stage of a wave and returns true once it is done
+func (d *DRPlanController) runStage(
+	plan *stork_api.DRPlan,
+	spec *stork_api.DRPlanWave,
+	wave *stork_api.DRPlanWaveInfo,
+	stage *stork_api.DRPlanWaveStageInfo,
+) (bool, error) {
+	switch stage.Stage {
+	case stork_api.DRPlanWaveStagePreExecRule, stork_api.DRPlanWaveStagePostExecRule:
+		name, ruleType := getDRPlanStageRule(spec, stage.Stage)
+		if name == "" {
+			stage.Status = stork_api.DRPlanStatusSkipped
+			stage.Reason = "No rule specified"
+			return true, nil
+		}
+		return true, d.runRule(plan, name, ruleType, wave.Namespaces)
+	case stork_api.DRPlanWaveStageFailover:
+		if len(spec.MigrationSchedules) == 0 {
+			stage.Status = stork_api.DRPlanStatusSkipped
+			stage.Reason = "No migration schedules specified"
+			return true, nil
+		}
+		return d.runFailovers(plan, spec, wave)
+	case stork_api.DRPlanWaveStageHealthCheck:
+		if spec.SkipHealthCheck {
+			stage.Status = stork_api.DRPlanStatusSkipped
+			stage.Reason = "Health check was disabled"
+			return true, nil
+		}
+		return d.checkHealth(spec, wave, stage)
+	}
+	return false, fmt.Errorf("unknown stage %v", stage.Stage)
+}
+
+// getDRPlanStageRule returns the name and type of the rule run by a stage of
+// a wave. The name is empty if the stage doesn't run a rule
+func getDRPlanStageRule(spec *stork_api.DRPlanWave, stage stork_api.DRPlanWaveStageType) (string, rule.Type) {
+	switch stage {
+	case stork_api.DRPlanWaveStagePreExecRule:
+		return spec.PreExecRule, rule.PreExecRule
+	case stork_api.DRPlanWaveStagePostExecRule:
+		return spec.PostExecRule, rule.PostExecRule
+	}
+	return "", ""
+}
+
+// runRule runs a rule from the namespace of the plan in the namespaces of a
+// wave. Background actions are stopped once the rule has run in all the
+// namespaces since there is no operation to run them around
+func (d *DRPlanController) runRule(
+	plan *stork_api.DRPlan,
+	name string,
+	ruleType rule.Type,
+	namespaces []string,
+) error {
+	r, err := storkops.Instance().GetRule(name, plan.Namespace)
+	if err != nil {
+		return fmt.Errorf("error getting rule %v: %v", name, err)
+	}
+	terminationChannels := make([]chan bool, 0)
+	defer func() {
+		for _, channel := range terminationChannels {
+			channel <- true
+		}
+	}()
+	for _, ns := range namespaces {
+		ch, err := rule.ExecuteRule(r, ruleType, plan, ns)
+		if err != nil {
+			return fmt.Errorf("error executing %v for namespace %v: %v", ruleType, ns, err)
+		}
+		if ch != nil {
+			terminationChannels = append(terminationChannels, ch)
+		}
+	}
+	return nil
+}
+
+// runFailovers starts a MigrationFailover for each migration schedule of the
+// wave and waits for all of them to complete. The names of the failovers are
+// saved in the status before they are created so that the same failovers are
+// checked if the plan is interrupted
+func (d *DRPlanController) runFailovers(
+	plan *stork_api.DRPlan,
+	spec *stork_api.DRPlanWave,
+	wave *stork_api.DRPlanWaveInfo,
+) (bool, error) {
+	if len(wave.MigrationFailovers) == 0 {
+		suffix := time.Now().Format(nameTimeSuffixFormat)
+		for _, schedule := range spec.MigrationSchedules {
+			wave.MigrationFailovers = append(wave.MigrationFailovers,
+				strings.Join([]string{plan.Name, wave.Name, schedule, suffix}, "-"))
+		}
+		return false, nil
+	}
+	if len(wave.MigrationFailovers) != len(spec.MigrationSchedules) {
+		return false, fmt.Errorf("migration schedules of the wave were changed")
+	}
+
+	namespaces := make(map[string]bool)
+	for _, ns := range wave.Namespaces {
+		namespaces[ns] = true
+	}
+	done := true
+	for i, name := range wave.MigrationFailovers {
+		failover := &stork_api.MigrationFailover{
+			TypeMeta: meta.TypeMeta{
+				Kind:       reflect.TypeOf(stork_api.MigrationFailover{}).Name(),
+				APIVersion: stork_api.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: meta.ObjectMeta{
+				Name:      name,
+				Namespace: plan.Namespace,
+			},
+		}
+		err := sdk.Get(failover)
+		if errors.IsNotFound(err) {
+			failover.Spec = getDRPlanFailoverSpec(plan, spec, spec.MigrationSchedules[i])
+			if err := sdk.Create(failover); err != nil {
+				return false, fmt.Errorf("error creating migration failover %v: %v", name, err)
+			}
+			log.DRPlanLog(plan).Infof("Started migration failover %v for migration schedule %v", name, spec.MigrationSchedules[i])
+			done = false
+			continue
+		} else if err != nil {
+			return false, fmt.Errorf("error getting migration failover %v: %v", name, err)
+		}
+
+		// Wait for failovers that are being retried to be picked up by
+		// their controller
+		if _, ok := failover.Annotations[stork.RetryAnnotation]; ok {
+			done = false
+			continue
+		}
+		switch failover.Status.Status {
+		case stork_api.MigrationFailoverStatusSuccessful:
+			for _, ns := range getFailoverDestinationNamespaces(failover) {
+				if !namespaces[ns] {
+					namespaces[ns] = true
+					wave.Namespaces = append(wave.Namespaces, ns)
+				}
+			}
+		case stork_api.MigrationFailoverStatusFailed:
+			return false, fmt.Errorf("migration failover %v failed", name)
+		default:
+			done = false
+		}
+	}
+	return done, nil
+}
+
+// getDRPlanFailoverSpec returns the spec of the failover started for a
+// migration schedule of a wave
+func getDRPlanFailoverSpec(
+	plan *stork_api.DRPlan,
+	spec *stork_api.DRPlanWave,
+	migrationSchedule string,
+) stork_api.MigrationFailoverSpec {
+	failoverType := stork_api.MigrationFailoverTypeFailover
+	if plan.Status.Action == stork_api.DRPlanActionFailback {
+		failoverType = stork_api.MigrationFailoverTypeFailback
+	}
+	return stork_api.MigrationFailoverSpec{
+		Type:              failoverType,
+		ClusterPair:       plan.Spec.ClusterPair,
+		MigrationSchedule: migrationSchedule,
+		Namespaces:        spec.Namespaces,
+		SkipFinalSync:     plan.Spec.SkipFinalSync,
+		TestMode:          plan.Status.Action == stork_api.DRPlanActionTest,
+	}
+}
+
+// checkHealth returns true once the deployments and statefulsets in the
+// namespaces of the wave are ready, and fails the stage if they aren't ready
+// before the timeout
+func (d *DRPlanController) checkHealth(
+	spec *stork_api.DRPlanWave,
+	wave *stork_api.DRPlanWaveInfo,
+	stage *stork_api.DRPlanWaveStageInfo,
+) (bool, error) {
+	notReady, err := getNotReadyApplications(wave.Namespaces)
+	if err != nil {
+		return false, err
+	}
+	if len(notReady) == 0 {
+		return true, nil
+	}
+
+	timeout := defaultDRPlanHealthCheckTimeout
+	if spec.HealthCheckTimeout != nil {
+		timeout = spec.HealthCheckTimeout.Duration
+	}
+	if time.Since(stage.StartTimestamp.Time) > timeout {
+		return false, fmt.Errorf("applications weren't ready after %v: %v", timeout, strings.Join(notReady, ", "))
+	}
+	stage.Reason = fmt.Sprintf("Waiting for %v", strings.Join(notReady, ", "))
+	return false, nil
+}
+
+// getNotReadyApplications returns the deployments and statefulsets in the
+// namespaces that don't have all their replicas ready
+func getNotReadyApplications(namespaces []string) ([]string, error) {
+	notReady := make([]string, 0)
+	for _, ns := range namespaces {
+		deployments, err := apps.Instance().ListDeployments(ns, meta.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error listing deployments in namespace %v: %v", ns, err)
+		}
+		for _, deployment := range deployments.Items {
+			replicas := int32(1)
+			if deployment.Spec.Replicas != nil {
+				replicas = *deployment.Spec.Replicas
+			}
+			if deployment.Status.ObservedGeneration < deployment.Generation ||
+				deployment.Status.ReadyReplicas < replicas {
+				notReady = append(notReady, fmt.Sprintf("Deployment %v/%v", ns, deployment.Name))
+			}
+		}
+
+		statefulSets, err := apps.Instance().ListStatefulSets(ns)
+		if err != nil {
+			return nil, fmt.Errorf("error listing statefulsets in namespace %v: %v", ns, err)
+		}
+		for _, statefulSet := range statefulSets.Items {
+			replicas := int32(1)
+			if statefulSet.Spec.Replicas != nil {
+				replicas = *statefulSet.Spec.Replicas
+			}
+			if statefulSet.Status.ObservedGeneration < statefulSet.Generation ||
+				statefulSet.Status.ReadyReplicas < replicas {
+				notReady = append(notReady, fmt.Sprintf("StatefulSet %v/%v", ns, statefulSet.Name))
+			}
+		}
+	}
+	return notReady, nil
+}
+
+// retryPlan continues a failed execution of the plan from the stage that
+// failed. Failed failovers of the wave are retried from the step that failed
+func (d *DRPlanController) retryPlan(plan *stork_api.DRPlan) error {
+	delete(plan.Annotations, stork.RetryAnnotation)
+	if plan.Status.Status != stork_api.DRPlanStatusFailed || len(plan.Status.Waves) == 0 {
+		message := fmt.Sprintf("Ignoring retry for plan with status %v", plan.Status.Status)
+		log.DRPlanLog(plan).Warn(message)
+		d.Recorder.Event(plan,
+			v1.EventTypeWarning,
+			string(plan.Status.Status),
+			message)
+		return sdk.Update(plan)
+	}
+
+	for _, wave := range plan.Status.Waves {
+		if wave.Status != stork_api.DRPlanStatusFailed {
+			continue
+		}
+		for _, name := range wave.MigrationFailovers {
+			if err := retryDRPlanFailover(name, plan.Namespace); err != nil {
+				return err
+			}
+		}
+		for _, stage := range wave.Stages {
+			if stage.Status == stork_api.DRPlanStatusFailed {
+				stage.Status = stork_api.DRPlanStatusPending
+				stage.Reason = ""
+				stage.FinishTimestamp = meta.Time{}
+			}
+		}
+		wave.Status = stork_api.DRPlanStatusInProgress
+		wave.Reason = ""
+		wave.FinishTimestamp = meta.Time{}
+	}
+	plan.Status.Status = stork_api.DRPlanStatusInProgress
+	plan.Status.FinishTimestamp = meta.Time{}
+	message := fmt.Sprintf("Retrying %v from the failed wave", strings.ToLower(string(plan.Status.Action)))
+	log.DRPlanLog(plan).Info(message)
+	d.Recorder.Event(plan,
+		v1.EventTypeNormal,
+		string(stork_api.DRPlanStatusInProgress),
+		message)
+	return sdk.Update(plan)
+}
+
+// retryDRPlanFailover adds the retry annotation to a failover started for a
+// plan if it failed
+func retryDRPlanFailover(name string, namespace string) error {
+	failover := &stork_api.MigrationFailover{
+		TypeMeta: meta.TypeMeta{
+			Kind:       reflect.TypeOf(stork_api.MigrationFailover{}).Name(),
+			APIVersion: stork_api.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	if err := sdk.Get(failover); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error getting migration failover %v: %v", name, err)
+	}
+	if failover.Status.Status != stork_api.MigrationFailoverStatusFailed {
+		return nil
+	}
+	if failover.Annotations == nil {
+		failover.Annotations = make(map[string]string)
+	}
+	failover.Annotations[stork.RetryAnnotation] = "true"
+	if err := sdk.Update(failover); err != nil {
+		return fmt.Errorf("error retrying migration failover %v: %v", name, err)
+	}
+	return nil
+}
+
+func (d *DRPlanController) createCRD() error {
+	resource := apiextensions.CustomResource{
+		Name:    stork_api.DRPlanResourceName,
+		Plural:  stork_api.DRPlanResourcePlural,
+		Group:   stork.GroupName,
+		Version: stork_api.SchemeGroupVersion.Version,
+		Scope:   apiextensionsv1beta1.NamespaceScoped,
+		Kind:    reflect.TypeOf(stork_api.DRPlan{}).Name(),
+	}
+	err := apiextensions.Instance().CreateCRD(resource)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	return apiextensions.Instance().ValidateCRD(resource, validateCRDTimeout, validateCRDInterval)
+}
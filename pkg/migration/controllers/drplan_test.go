@@ -0,0 +1,200 @@
+// +build unittest
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	fakeclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned/fake"
+	"github.com/portworx/sched-ops/k8s/apps"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	appv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+// setDRPlanTestCluster sets the instances used by the plan to a fake cluster
+// with the rules and applications
+func setDRPlanTestCluster(t *testing.T, rules []string, deployments ...*appv1.Deployment) {
+	fakeKube := kubernetes.NewSimpleClientset()
+	core.SetInstance(core.New(fakeKube, fakeKube.CoreV1(), fakeKube.StorageV1()))
+	apps.SetInstance(apps.New(fakeKube.AppsV1(), fakeKube.CoreV1()))
+	storkops.SetInstance(storkops.New(fakeKube, fakeclient.NewSimpleClientset(), nil))
+	for _, name := range rules {
+		_, err := storkops.Instance().CreateRule(&stork_api.Rule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "admin",
+			},
+			Rules: []stork_api.RuleItem{
+				{
+					PodSelector: map[string]string{"app": "db"},
+					Actions: []stork_api.RuleAction{
+						{
+							Type:  stork_api.RuleActionCommand,
+							Value: "sync",
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err, "Error creating rule")
+	}
+	for _, deployment := range deployments {
+		_, err := apps.Instance().CreateDeployment(deployment)
+		require.NoError(t, err, "Error creating deployment")
+	}
+}
+
+func newTestDRPlan(waves ...stork_api.DRPlanWave) *stork_api.DRPlan {
+	plan := &stork_api.DRPlan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "plan",
+			Namespace: "admin",
+		},
+		Spec: stork_api.DRPlanSpec{
+			ClusterPair: "remote",
+			Waves:       waves,
+		},
+	}
+	plan.Status = stork_api.DRPlanStatus{
+		Action: stork_api.DRPlanActionFailover,
+		Status: stork_api.DRPlanStatusInProgress,
+		Waves:  getDRPlanWaveInfos(plan),
+	}
+	return plan
+}
+
+func newTestDRPlanController() *DRPlanController {
+	return &DRPlanController{Recorder: record.NewFakeRecorder(100)}
+}
+
+func requireStageStatus(
+	t *testing.T,
+	wave *stork_api.DRPlanWaveInfo,
+	stage stork_api.DRPlanWaveStageType,
+	status stork_api.DRPlanStatusType,
+) *stork_api.DRPlanWaveStageInfo {
+	for _, info := range wave.Stages {
+		if info.Stage == stage {
+			require.Equal(t, status, info.Status, "Status mismatch for stage %v of wave %v", stage, wave.Name)
+			return info
+		}
+	}
+	require.FailNow(t, "Stage not found", "Stage %v not found in wave %v", stage, wave.Name)
+	return nil
+}
+
+func TestDRPlanWaves(t *testing.T) {
+	setDRPlanTestCluster(t, []string{"pre", "post"})
+	controller := newTestDRPlanController()
+	plan := newTestDRPlan(
+		stork_api.DRPlanWave{
+			Name:         "db",
+			Namespaces:   []string{testAppNamespace},
+			PreExecRule:  "pre",
+			PostExecRule: "post",
+		},
+		stork_api.DRPlanWave{
+			Name:            "web",
+			Namespaces:      []string{"web"},
+			SkipHealthCheck: true,
+		},
+	)
+	db := plan.Status.Waves[0]
+	web := plan.Status.Waves[1]
+
+	// The rule stage is started and saved before the rule is run
+	require.NoError(t, controller.updateWaves(plan))
+	require.Equal(t, stork_api.DRPlanStatusInProgress, db.Status)
+	stage := requireStageStatus(t, db, stork_api.DRPlanWaveStagePreExecRule, stork_api.DRPlanStatusInProgress)
+	require.False(t, stage.StartTimestamp.IsZero(), "Stage start time should be set")
+	require.Contains(t, stage.Reason, "pre")
+	requireStageStatus(t, db, stork_api.DRPlanWaveStageFailover, stork_api.DRPlanStatusPending)
+	require.Equal(t, stork_api.DRPlanStatusPending, web.Status)
+
+	// The rule is run on the next update and the wave continues until the
+	// next rule
+	require.NoError(t, controller.updateWaves(plan))
+	stage = requireStageStatus(t, db, stork_api.DRPlanWaveStagePreExecRule, stork_api.DRPlanStatusSuccessful)
+	require.Empty(t, stage.Reason)
+	requireStageStatus(t, db, stork_api.DRPlanWaveStageFailover, stork_api.DRPlanStatusSkipped)
+	requireStageStatus(t, db, stork_api.DRPlanWaveStageHealthCheck, stork_api.DRPlanStatusSuccessful)
+	requireStageStatus(t, db, stork_api.DRPlanWaveStagePostExecRule, stork_api.DRPlanStatusInProgress)
+	require.Equal(t, stork_api.DRPlanStatusInProgress, db.Status)
+
+	// Waves without rules complete without stopping
+	require.NoError(t, controller.updateWaves(plan))
+	requireStageStatus(t, db, stork_api.DRPlanWaveStagePostExecRule, stork_api.DRPlanStatusSuccessful)
+	require.Equal(t, stork_api.DRPlanStatusSuccessful, db.Status)
+	require.Equal(t, stork_api.DRPlanStatusSuccessful, web.Status)
+	requireStageStatus(t, web, stork_api.DRPlanWaveStagePreExecRule, stork_api.DRPlanStatusSkipped)
+	requireStageStatus(t, web, stork_api.DRPlanWaveStageHealthCheck, stork_api.DRPlanStatusSkipped)
+	require.Equal(t, stork_api.DRPlanStatusSuccessful, plan.Status.Status)
+	require.False(t, plan.Status.FinishTimestamp.IsZero(), "Plan finish time should be set")
+}
+
+func TestDRPlanWavesRuleFailure(t *testing.T) {
+	setDRPlanTestCluster(t, nil)
+	controller := newTestDRPlanController()
+	plan := newTestDRPlan(
+		stork_api.DRPlanWave{
+			Name:        "db",
+			Namespaces:  []string{testAppNamespace},
+			PreExecRule: "missing",
+		},
+		stork_api.DRPlanWave{
+			Name:       "web",
+			Namespaces: []string{"web"},
+		},
+	)
+	db := plan.Status.Waves[0]
+
+	// The rule isn't accessed until the stage has been saved
+	require.NoError(t, controller.updateWaves(plan))
+	requireStageStatus(t, db, stork_api.DRPlanWaveStagePreExecRule, stork_api.DRPlanStatusInProgress)
+
+	err := controller.updateWaves(plan)
+	require.Error(t, err, "Wave should have failed")
+	require.Contains(t, err.Error(), "wave db failed")
+	require.Equal(t, stork_api.DRPlanStatusFailed, db.Status)
+	stage := requireStageStatus(t, db, stork_api.DRPlanWaveStagePreExecRule, stork_api.DRPlanStatusFailed)
+	require.Contains(t, stage.Reason, "error getting rule missing")
+	require.Equal(t, stork_api.DRPlanStatusPending, plan.Status.Waves[1].Status, "Next wave shouldn't have started")
+}
+
+func TestDRPlanWavesHealthCheck(t *testing.T) {
+	replicas := int32(1)
+	setDRPlanTestCluster(t, nil, &appv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app",
+			Namespace: testAppNamespace,
+		},
+		Spec: appv1.DeploymentSpec{
+			Replicas: &replicas,
+		},
+	})
+	controller := newTestDRPlanController()
+	plan := newTestDRPlan(stork_api.DRPlanWave{
+		Name:               "db",
+		Namespaces:         []string{testAppNamespace},
+		HealthCheckTimeout: &metav1.Duration{Duration: time.Minute},
+	})
+	db := plan.Status.Waves[0]
+
+	require.NoError(t, controller.updateWaves(plan))
+	stage := requireStageStatus(t, db, stork_api.DRPlanWaveStageHealthCheck, stork_api.DRPlanStatusInProgress)
+	require.Contains(t, stage.Reason, "Deployment app/app")
+	require.Equal(t, stork_api.DRPlanStatusInProgress, plan.Status.Status)
+
+	stage.StartTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	err := controller.updateWaves(plan)
+	require.Error(t, err, "Health check should have timed out")
+	requireStageStatus(t, db, stork_api.DRPlanWaveStageHealthCheck, stork_api.DRPlanStatusFailed)
+	require.Equal(t, stork_api.DRPlanStatusFailed, db.Status)
+}
@@ -2,6 +2,8 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -14,6 +16,7 @@ import (
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/controller"
 	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/queue"
 	"github.com/libopenstorage/stork/pkg/resourcecollector"
 	"github.com/libopenstorage/stork/pkg/rule"
 	"github.com/operator-framework/operator-sdk/pkg/sdk"
@@ -31,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/kubernetes/pkg/registry/core/service/portallocator"
 )
@@ -43,11 +47,19 @@ const (
 	// StorkMigrationAnnotation is the annotation used to keep track of resources
 	// migrated by stork
 	StorkMigrationAnnotation = "stork.libopenstorage.org/migrated"
+	// StorkMigrationLabel is added to migrated applications and the
+	// namespaces they are migrated to so that the admission webhook only
+	// needs to validate updates to them
+	StorkMigrationLabel = "stork.libopenstorage.org/migrated"
 	// StorkMigrationName is the annotation used to identify resource migrated by
 	// migration CRD name
 	StorkMigrationName = "stork.libopenstorage.org/migrationName"
 	// StorkMigrationTime is the annotation used to specify time of migration
 	StorkMigrationTime = "stork.libopenstorage.org/migrationTime"
+	// StorkMigrationHashAnnotation is the annotation used to keep track of
+	// the content of a resource when it was migrated, so that resources that
+	// haven't changed can be skipped
+	StorkMigrationHashAnnotation = "stork.libopenstorage.org/migrationHash"
 	// Max number of times to retry applying resources on the desination
 	maxApplyRetries = 10
 )
@@ -72,6 +84,9 @@ func (m *MigrationController) Init(migrationAdminNamespace string) error {
 		logrus.Errorf("Failed to perform recovery for migration rules: %v", err)
 		return err
 	}
+	if err := m.trackStartedMigrations(); err != nil {
+		return err
+	}
 
 	return controller.Register(
 		&schema.GroupVersionKind{
@@ -89,6 +104,28 @@ func setKind(snap *stork_api.Migration) {
 	snap.APIVersion = stork_api.SchemeGroupVersion.String()
 }
 
+// trackStartedMigrations adds the migrations that were already started to the
+// queue so that they are accounted for before any queued operations can start
+func (m *MigrationController) trackStartedMigrations() error {
+	migrations, err := storkops.Instance().ListMigrations(v1.NamespaceAll)
+	if err != nil {
+		return fmt.Errorf("error listing migrations: %v", err)
+	}
+	for _, migration := range migrations.Items {
+		if migration.Status.Stage == stork_api.MigrationStageInitial ||
+			migration.Status.Stage == stork_api.MigrationStageFinal {
+			continue
+		}
+		op, err := m.getQueueOperation(setDefaults(&migration))
+		if err != nil {
+			log.MigrationLog(&migration).Warnf("Error getting volumes to add migration to queue: %v", err)
+			continue
+		}
+		queue.Instance().Track(op)
+	}
+	return nil
+}
+
 // performRuleRecovery terminates potential background commands running pods for
 // all migration objects
 func (m *MigrationController) performRuleRecovery() error {
@@ -130,6 +167,10 @@ func setDefaults(migration *stork_api.Migration) *stork_api.Migration {
 		defaultBool := false
 		migration.Spec.PurgeDeletedResources = &defaultBool
 	}
+	if migration.Spec.SkipUnchangedResources == nil {
+		defaultBool := false
+		migration.Spec.SkipUnchangedResources = &defaultBool
+	}
 	return migration
 }
 
@@ -139,6 +180,7 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 	case *stork_api.Migration:
 		migration := o
 		if event.Deleted {
+			queue.Instance().Release(migration.UID)
 			if migration.Status.Stage != stork_api.MigrationStageFinal {
 				return m.Driver.CancelMigration(migration)
 			}
@@ -148,7 +190,7 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 
 		if migration.Spec.ClusterPair == "" {
 			err := fmt.Errorf("clusterPair to migrate to cannot be empty")
-			log.MigrationLog(migration).Errorf(err.Error())
+			log.MigrationLog(migration).Error(err.Error())
 			m.Recorder.Event(migration,
 				v1.EventTypeWarning,
 				string(stork_api.MigrationStatusFailed),
@@ -161,7 +203,7 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 		// except for the namespace designated by the admin
 		if !m.namespaceMigrationAllowed(migration) {
 			err := fmt.Errorf("Spec.Namespaces should only contain the current namespace")
-			log.MigrationLog(migration).Errorf(err.Error())
+			log.MigrationLog(migration).Error(err.Error())
 			m.Recorder.Event(migration,
 				v1.EventTypeWarning,
 				string(stork_api.MigrationStatusFailed),
@@ -169,6 +211,14 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 			return nil
 		}
 
+		if _, ok := migration.Annotations[stork.RetryAnnotation]; ok {
+			return m.retryMigration(migration)
+		}
+
+		if migration.Status.Stage == stork_api.MigrationStageFinal {
+			queue.Instance().Release(migration.UID)
+		}
+
 		var terminationChannels []chan bool
 		var err error
 		var clusterDomains *stork_api.ClusterDomains
@@ -201,6 +251,18 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 
 		switch migration.Status.Stage {
 		case stork_api.MigrationStageInitial:
+			if err := validateNamespaceMapping(&migration.Spec); err != nil {
+				migration.Status.Status = stork_api.MigrationStatusFailed
+				migration.Status.Stage = stork_api.MigrationStageFinal
+				migration.Status.FinishTimestamp = metav1.Now()
+				err = fmt.Errorf("invalid namespaceMapping: %v", err)
+				log.MigrationLog(migration).Error(err.Error())
+				m.Recorder.Event(migration,
+					v1.EventTypeWarning,
+					string(stork_api.MigrationStatusFailed),
+					err.Error())
+				return sdk.Update(migration)
+			}
 			// Make sure the namespaces exist
 			for _, ns := range migration.Spec.Namespaces {
 				_, err := core.Instance().GetNamespace(ns)
@@ -209,7 +271,7 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 					migration.Status.Stage = stork_api.MigrationStageFinal
 					migration.Status.FinishTimestamp = metav1.Now()
 					err = fmt.Errorf("error getting namespace %v: %v", ns, err)
-					log.MigrationLog(migration).Errorf(err.Error())
+					log.MigrationLog(migration).Error(err.Error())
 					m.Recorder.Event(migration,
 						v1.EventTypeWarning,
 						string(stork_api.MigrationStatusFailed),
@@ -226,7 +288,7 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 				_, err := storkops.Instance().GetRule(migration.Spec.PreExecRule, migration.Namespace)
 				if err != nil {
 					message := fmt.Sprintf("Error getting PreExecRule %v: %v", migration.Spec.PreExecRule, err)
-					log.MigrationLog(migration).Errorf(message)
+					log.MigrationLog(migration).Error(message)
 					m.Recorder.Event(migration,
 						v1.EventTypeWarning,
 						string(stork_api.MigrationStatusFailed),
@@ -238,7 +300,7 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 				_, err := storkops.Instance().GetRule(migration.Spec.PostExecRule, migration.Namespace)
 				if err != nil {
 					message := fmt.Sprintf("Error getting PostExecRule %v: %v", migration.Spec.PreExecRule, err)
-					log.MigrationLog(migration).Errorf(message)
+					log.MigrationLog(migration).Error(message)
 					m.Recorder.Event(migration,
 						v1.EventTypeWarning,
 						string(stork_api.MigrationStatusFailed),
@@ -246,12 +308,27 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 					return nil
 				}
 			}
+			// Wait for other operations to complete if the limits for
+			// concurrent operations have been reached
+			queued, err := m.queueMigration(migration)
+			if err != nil {
+				message := fmt.Sprintf("Error queueing migration: %v", err)
+				log.MigrationLog(migration).Error(message)
+				m.Recorder.Event(migration,
+					v1.EventTypeWarning,
+					string(stork_api.MigrationStatusFailed),
+					message)
+				return nil
+			}
+			if queued {
+				return nil
+			}
 			fallthrough
 		case stork_api.MigrationStagePreExecRule:
 			terminationChannels, err = m.runPreExecRule(migration)
 			if err != nil {
 				message := fmt.Sprintf("Error running PreExecRule: %v", err)
-				log.MigrationLog(migration).Errorf(message)
+				log.MigrationLog(migration).Error(message)
 				m.Recorder.Event(migration,
 					v1.EventTypeWarning,
 					string(stork_api.MigrationStatusFailed),
@@ -270,7 +347,7 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 				err := m.migrateVolumes(migration, terminationChannels)
 				if err != nil {
 					message := fmt.Sprintf("Error migrating volumes: %v", err)
-					log.MigrationLog(migration).Errorf(message)
+					log.MigrationLog(migration).Error(message)
 					m.Recorder.Event(migration,
 						v1.EventTypeWarning,
 						string(stork_api.MigrationStatusFailed),
@@ -289,7 +366,7 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 			err := m.migrateResources(migration)
 			if err != nil {
 				message := fmt.Sprintf("Error migrating resources: %v", err)
-				log.MigrationLog(migration).Errorf(message)
+				log.MigrationLog(migration).Error(message)
 				m.Recorder.Event(migration,
 					v1.EventTypeWarning,
 					string(stork_api.MigrationStatusFailed),
@@ -305,6 +382,188 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 	return nil
 }
 
+func (m *MigrationController) newQueueOperation(migration *stork_api.Migration) *queue.Operation {
+	return &queue.Operation{
+		Kind:              "Migration",
+		Namespace:         migration.Namespace,
+		Name:              migration.Name,
+		UID:               migration.UID,
+		Priority:          migration.Spec.Priority,
+		CreationTimestamp: migration.CreationTimestamp.Time,
+		Volumes:           make(map[string]int),
+	}
+}
+
+// getQueueOperation returns the operation used to limit the number of
+// concurrent migrations. The volumes are counted from the PVCs to be migrated
+// if the migration hasn't started yet
+func (m *MigrationController) getQueueOperation(migration *stork_api.Migration) (*queue.Operation, error) {
+	op := m.newQueueOperation(migration)
+	driverName := m.Driver.String()
+	if migration.Status.Volumes != nil {
+		if len(migration.Status.Volumes) != 0 {
+			op.Volumes[driverName] = len(migration.Status.Volumes)
+		}
+		return op, nil
+	}
+	if !*migration.Spec.IncludeVolumes {
+		return op, nil
+	}
+	volumes, err := queue.GetPVCVolumes(migration.Spec.Namespaces, migration.Spec.Selectors)
+	if err != nil {
+		return nil, err
+	}
+	if volumes[driverName] != 0 {
+		op.Volumes[driverName] = volumes[driverName]
+	}
+	return op, nil
+}
+
+// queueMigration checks whether the migration can start. Returns true if the
+// migration has to wait for other operations to complete, in which case its
+// status is set to Queued along with its position in the queue
+func (m *MigrationController) queueMigration(migration *stork_api.Migration) (bool, error) {
+	op, err := m.getQueueOperation(migration)
+	if err != nil {
+		return false, err
+	}
+	if ok, position := queue.Instance().Acquire(op); !ok {
+		if migration.Status.Status == stork_api.MigrationStatusQueued &&
+			migration.Status.QueuePosition == position {
+			return true, nil
+		}
+		if migration.Status.Status != stork_api.MigrationStatusQueued {
+			m.Recorder.Event(migration,
+				v1.EventTypeNormal,
+				string(stork_api.MigrationStatusQueued),
+				fmt.Sprintf("Migration queued at position %v since the limit for concurrent operations has been reached", position))
+		}
+		migration.Status.Status = stork_api.MigrationStatusQueued
+		migration.Status.QueuePosition = position
+		return true, sdk.Update(migration)
+	}
+	if migration.Status.Status == stork_api.MigrationStatusQueued {
+		migration.Status.Status = stork_api.MigrationStatusInitial
+		migration.Status.QueuePosition = 0
+	}
+	return false, nil
+}
+
+// retryMigration starts the migration again for the volumes that failed.
+// Volumes that were migrated successfully are kept, and only the resources
+// that weren't migrated are applied once all the volumes are done
+func (m *MigrationController) retryMigration(migration *stork_api.Migration) error {
+	if migration.Status.Status != stork_api.MigrationStatusFailed &&
+		migration.Status.Status != stork_api.MigrationStatusPartialSuccess {
+		delete(migration.Annotations, stork.RetryAnnotation)
+		message := fmt.Sprintf("Ignoring retry for migration with status %v", migration.Status.Status)
+		log.MigrationLog(migration).Warn(message)
+		m.Recorder.Event(migration,
+			v1.EventTypeWarning,
+			string(migration.Status.Status),
+			message)
+		return sdk.Update(migration)
+	}
+	// Wait for other operations to complete if the limits for concurrent
+	// operations have been reached
+	if queued, err := m.queueRetry(migration); err != nil || queued {
+		return err
+	}
+
+	delete(migration.Annotations, stork.RetryAnnotation)
+	migration.Status.Retries++
+	failed := make([]*stork_api.MigrationVolumeInfo, 0)
+	volumeInfos := make([]*stork_api.MigrationVolumeInfo, 0)
+	for _, vInfo := range migration.Status.Volumes {
+		if vInfo.Status == stork_api.MigrationStatusFailed {
+			failed = append(failed, vInfo)
+		} else {
+			volumeInfos = append(volumeInfos, vInfo)
+		}
+	}
+
+	if len(failed) != 0 {
+		infos, err := m.Driver.RetryMigration(migration, failed)
+		if err != nil {
+			message := fmt.Sprintf("Error retrying migration for volumes: %v", err)
+			log.MigrationLog(migration).Error(message)
+			m.Recorder.Event(migration,
+				v1.EventTypeWarning,
+				string(stork_api.MigrationStatusFailed),
+				message)
+			return sdk.Update(migration)
+		}
+		migration.Status.Volumes = append(volumeInfos, infos...)
+		migration.Status.Stage = stork_api.MigrationStageVolumes
+	} else {
+		migration.Status.Stage = stork_api.MigrationStageApplications
+	}
+	// Apply the stages again for the resources that weren't migrated
+	migration.Status.ApplyStages = nil
+	migration.Status.Status = stork_api.MigrationStatusInProgress
+	migration.Status.FinishTimestamp = metav1.Time{}
+	message := fmt.Sprintf("Retrying migration of %v failed volumes", len(failed))
+	log.MigrationLog(migration).Info(message)
+	m.Recorder.Event(migration,
+		v1.EventTypeNormal,
+		string(stork_api.MigrationStatusInProgress),
+		message)
+	return sdk.Update(migration)
+}
+
+// queueRetry checks whether the failed volumes of the migration can be
+// migrated again. Returns true if the retry has to wait for other operations
+// to complete, in which case its position in the queue is saved and the retry
+// annotation is kept so that it is checked again on the next update
+func (m *MigrationController) queueRetry(migration *stork_api.Migration) (bool, error) {
+	op := m.newQueueOperation(migration)
+	for _, vInfo := range migration.Status.Volumes {
+		if vInfo.Status == stork_api.MigrationStatusFailed {
+			op.Volumes[m.Driver.String()]++
+		}
+	}
+	ok, position := queue.Instance().Acquire(op)
+	if ok {
+		migration.Status.QueuePosition = 0
+		return false, nil
+	}
+	if migration.Status.QueuePosition == position {
+		return true, nil
+	}
+	if migration.Status.QueuePosition == 0 {
+		m.Recorder.Event(migration,
+			v1.EventTypeNormal,
+			string(stork_api.MigrationStatusQueued),
+			fmt.Sprintf("Retry queued at position %v since the limit for concurrent operations has been reached", position))
+	}
+	migration.Status.QueuePosition = position
+	return true, sdk.Update(migration)
+}
+
+// getResourcesToRetry returns the objects that failed to be migrated or
+// weren't applied at all in the previous attempts of the migration, along
+// with the info for the resources that were migrated successfully
+func (m *MigrationController) getResourcesToRetry(
+	migration *stork_api.Migration,
+	objects []runtime.Unstructured,
+) ([]runtime.Unstructured, []*stork_api.MigrationResourceInfo, error) {
+	retryObjects := make([]runtime.Unstructured, 0)
+	resourceInfos := make([]*stork_api.MigrationResourceInfo, 0)
+	for _, o := range objects {
+		metadata, err := meta.Accessor(o)
+		if err != nil {
+			return nil, nil, err
+		}
+		resource := getMigrationResourceInfo(migration, metadata, o.GetObjectKind().GroupVersionKind(), false)
+		if resource != nil && isResourceMigrated(resource.Status) {
+			resourceInfos = append(resourceInfos, resource)
+			continue
+		}
+		retryObjects = append(retryObjects, o)
+	}
+	return retryObjects, resourceInfos, nil
+}
+
 func (m *MigrationController) purgeMigratedResources(migration *stork_api.Migration) error {
 	remoteConfig, err := getClusterPairSchedulerConfig(migration.Spec.ClusterPair, migration.Namespace)
 	if err != nil {
@@ -315,14 +574,15 @@ func (m *MigrationController) purgeMigratedResources(migration *stork_api.Migrat
 	// use seperate resource collector for collecting resources
 	// from destination cluster
 	rc := resourcecollector.ResourceCollector{
-		Driver: m.Driver,
+		Driver:         m.Driver,
+		AdminNamespace: m.migrationAdminNamespace,
 	}
 	err = rc.Init(remoteConfig)
 	if err != nil {
 		log.MigrationLog(migration).Errorf("Error initializing resource collector: %v", err)
 		return err
 	}
-	destObjects, err := rc.GetResources(migration.Spec.Namespaces, migration.Spec.Selectors, false)
+	destObjects, err := rc.GetResources(getDestinationNamespaces(&migration.Spec), migration.Spec.Selectors, false)
 	if err != nil {
 		m.Recorder.Event(migration,
 			v1.EventTypeWarning,
@@ -340,6 +600,12 @@ func (m *MigrationController) purgeMigratedResources(migration *stork_api.Migrat
 		log.MigrationLog(migration).Errorf("Error getting resources: %v", err)
 		return err
 	}
+	// Compare the source objects with the namespaces they were migrated to
+	for _, o := range srcObjects {
+		if err := mapObjectNamespace(&migration.Spec, o); err != nil {
+			return err
+		}
+	}
 	obj, err := objectToCollect(destObjects)
 	if err != nil {
 		return err
@@ -499,7 +765,7 @@ func (m *MigrationController) migrateVolumes(migration *stork_api.Migration, ter
 			err = m.runPostExecRule(migration)
 			if err != nil {
 				message := fmt.Sprintf("Error running PostExecRule: %v", err)
-				log.MigrationLog(migration).Errorf(message)
+				log.MigrationLog(migration).Error(message)
 				m.Recorder.Event(migration,
 					v1.EventTypeWarning,
 					string(stork_api.MigrationStatusFailed),
@@ -682,6 +948,12 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration) e
 		}
 	}
 
+	// Check if the stage that was applied last is ready before collecting
+	// the resources again
+	if waiting, err := m.checkWaitingApplyStages(migration); err != nil || waiting {
+		return err
+	}
+
 	allObjects, err := m.ResourceCollector.GetResources(migration.Spec.Namespaces, migration.Spec.Selectors, false)
 	if err != nil {
 		m.Recorder.Event(migration,
@@ -692,32 +964,13 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration) e
 		return err
 	}
 
-	// Save the collected resources infos in the status
-	resourceInfos := make([]*stork_api.MigrationResourceInfo, 0)
-	for _, obj := range allObjects {
-		metadata, err := meta.Accessor(obj)
-		if err != nil {
-			return err
-		}
-
-		resourceInfo := &stork_api.MigrationResourceInfo{
-			Name:      metadata.GetName(),
-			Namespace: metadata.GetNamespace(),
-			Status:    stork_api.MigrationStatusInProgress,
-		}
-		gvk := obj.GetObjectKind().GroupVersionKind()
-		resourceInfo.Kind = gvk.Kind
-		resourceInfo.Group = gvk.Group
-		// core Group doesn't have a name, so override it
-		if resourceInfo.Group == "" {
-			resourceInfo.Group = "core"
-		}
-		resourceInfo.Version = gvk.Version
-
-		resourceInfos = append(resourceInfos, resourceInfo)
+	if migration.Status.ApplyStages != nil {
+		// The migration was waiting for resources to be ready, so continue
+		// with the resources that were collected when it was started
+		allObjects, err = m.getCollectedResources(migration, allObjects)
+	} else {
+		allObjects, err = m.saveCollectedResources(migration, allObjects)
 	}
-	migration.Status.Resources = resourceInfos
-	err = sdk.Update(migration)
 	if err != nil {
 		return err
 	}
@@ -731,7 +984,7 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration) e
 		log.MigrationLog(migration).Errorf("Error preparing resources: %v", err)
 		return err
 	}
-	err = m.applyResources(migration, allObjects)
+	waiting, err := m.applyResources(migration, allObjects)
 	if err != nil {
 		m.Recorder.Event(migration,
 			v1.EventTypeWarning,
@@ -740,12 +993,21 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration) e
 		log.MigrationLog(migration).Errorf("Error applying resources: %v", err)
 		return err
 	}
+	if waiting {
+		return nil
+	}
 
 	migration.Status.Stage = stork_api.MigrationStageFinal
 	migration.Status.FinishTimestamp = metav1.Now()
 	migration.Status.Status = stork_api.MigrationStatusSuccessful
 	for _, resource := range migration.Status.Resources {
-		if resource.Status != stork_api.MigrationStatusSuccessful {
+		if !isResourceMigrated(resource.Status) {
+			migration.Status.Status = stork_api.MigrationStatusPartialSuccess
+			break
+		}
+	}
+	for _, stage := range migration.Status.ApplyStages {
+		if stage.Status == stork_api.ApplyStageStatusFailed {
 			migration.Status.Status = stork_api.MigrationStatusPartialSuccess
 			break
 		}
@@ -753,7 +1015,7 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration) e
 	if *migration.Spec.PurgeDeletedResources {
 		if err := m.purgeMigratedResources(migration); err != nil {
 			message := fmt.Sprintf("Error cleaning up resources: %v", err)
-			log.MigrationLog(migration).Errorf(message)
+			log.MigrationLog(migration).Error(message)
 			m.Recorder.Event(migration,
 				v1.EventTypeWarning,
 				string(stork_api.MigrationStatusPartialSuccess),
@@ -761,6 +1023,9 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration) e
 			return nil
 		}
 	}
+	if migration.Spec.CheckDrift != nil && *migration.Spec.CheckDrift {
+		m.checkDrift(migration)
+	}
 
 	err = sdk.Update(migration)
 	if err != nil {
@@ -769,24 +1034,126 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration) e
 	return nil
 }
 
+// saveCollectedResources saves the info for the resources to be migrated in
+// the status. Only the objects that weren't migrated are returned when
+// retrying
+func (m *MigrationController) saveCollectedResources(
+	migration *stork_api.Migration,
+	objects []runtime.Unstructured,
+) ([]runtime.Unstructured, error) {
+	var err error
+	resourceInfos := make([]*stork_api.MigrationResourceInfo, 0)
+	if migration.Status.Retries > 0 {
+		objects, resourceInfos, err = m.getResourcesToRetry(migration, objects)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, obj := range objects {
+		metadata, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		resourceInfo := &stork_api.MigrationResourceInfo{
+			Name:      metadata.GetName(),
+			Namespace: metadata.GetNamespace(),
+			Status:    stork_api.MigrationStatusInProgress,
+		}
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		resourceInfo.Kind = gvk.Kind
+		resourceInfo.Group = gvk.Group
+		// core Group doesn't have a name, so override it
+		if resourceInfo.Group == "" {
+			resourceInfo.Group = "core"
+		}
+		resourceInfo.Version = gvk.Version
+
+		resourceInfos = append(resourceInfos, resourceInfo)
+	}
+	migration.Status.Resources = resourceInfos
+	if err := sdk.Update(migration); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// getCollectedResources returns the objects for which the info was saved in
+// the status when the migration was started. Objects that were created since
+// then are picked up by the next migration
+func (m *MigrationController) getCollectedResources(
+	migration *stork_api.Migration,
+	objects []runtime.Unstructured,
+) ([]runtime.Unstructured, error) {
+	collectedObjects := make([]runtime.Unstructured, 0)
+	for _, o := range objects {
+		metadata, err := meta.Accessor(o)
+		if err != nil {
+			return nil, err
+		}
+		if getMigrationResourceInfo(migration, metadata, o.GetObjectKind().GroupVersionKind(), false) != nil {
+			collectedObjects = append(collectedObjects, o)
+		}
+	}
+	return collectedObjects, nil
+}
+
+// checkDrift compares the resources on the source and destination clusters
+// and saves the differences in the status. Errors are saved in the status
+// instead of failing the migration
+func (m *MigrationController) checkDrift(migration *stork_api.Migration) {
+	drift := &stork_api.MigrationDriftStatus{
+		CheckTimestamp: metav1.Now(),
+	}
+	migration.Status.Drift = drift
+
+	resources, err := CompareMigration(&m.ResourceCollector, &migration.Spec, migration.Namespace, false)
+	if err != nil {
+		drift.Reason = fmt.Sprintf("Error comparing resources: %v", err)
+		log.MigrationLog(migration).Error(drift.Reason)
+		m.Recorder.Event(migration,
+			v1.EventTypeWarning,
+			string(migration.Status.Status),
+			drift.Reason)
+		return
+	}
+	drift.Resources = resources
+	if len(resources) != 0 {
+		message := fmt.Sprintf("Found %v resources that are missing, extra or different on the destination", len(resources))
+		log.MigrationLog(migration).Warn(message)
+		m.Recorder.Event(migration,
+			v1.EventTypeWarning,
+			"Drift",
+			message)
+	}
+}
+
 func (m *MigrationController) prepareResources(
 	migration *stork_api.Migration,
 	objects []runtime.Unstructured,
 ) error {
+	registry, err := resourcecollector.GetApplicationRegistry(core.Instance(), m.migrationAdminNamespace)
+	if err != nil {
+		return err
+	}
 	for _, o := range objects {
 		metadata, err := meta.Accessor(o)
 		if err != nil {
 			return err
 		}
+		if err := mapObjectNamespace(&migration.Spec, o); err != nil {
+			return fmt.Errorf("error mapping namespace for %v resource %v: %v",
+				o.GetObjectKind().GroupVersionKind().Kind, metadata.GetName(), err)
+		}
 
-		switch o.GetObjectKind().GroupVersionKind().Kind {
-		case "PersistentVolume":
+		if o.GetObjectKind().GroupVersionKind().Kind == "PersistentVolume" {
 			err := m.preparePVResource(migration, o)
 			if err != nil {
 				return fmt.Errorf("error preparing PV resource %v: %v", metadata.GetName(), err)
 			}
-		case "Deployment", "StatefulSet", "DeploymentConfig", "IBPPeer", "IBPCA", "IBPConsole", "IBPOrderer":
-			err := m.prepareApplicationResource(migration, o)
+		} else if options := resourcecollector.GetApplicationSuspendOptions(registry, o.GetObjectKind().GroupVersionKind()); options != nil {
+			err := m.prepareApplicationResource(migration, o, options)
 			if err != nil {
 				return fmt.Errorf("error preparing %v resource %v: %v", o.GetObjectKind().GroupVersionKind().Kind, metadata.GetName(), err)
 			}
@@ -801,32 +1168,58 @@ func (m *MigrationController) updateResourceStatus(
 	status stork_api.MigrationStatusType,
 	reason string,
 ) {
+	metadata, err := meta.Accessor(object)
+	if err != nil {
+		return
+	}
+	gkv := object.GetObjectKind().GroupVersionKind()
+	// The namespace of the object has already been mapped to the namespace
+	// on the destination
+	resource := getMigrationResourceInfo(migration, metadata, gkv, true)
+	if resource == nil {
+		return
+	}
+	resource.Status = status
+	resource.Reason = reason
+	// Don't flood the events when most of the resources haven't changed
+	if status == stork_api.MigrationStatusUnchanged {
+		return
+	}
+	eventType := v1.EventTypeNormal
+	if status == stork_api.MigrationStatusFailed {
+		eventType = v1.EventTypeWarning
+	}
+	eventMessage := fmt.Sprintf("%v %v/%v: %v",
+		gkv,
+		resource.Namespace,
+		resource.Name,
+		reason)
+	m.Recorder.Event(migration, eventType, string(status), eventMessage)
+}
+
+// getMigrationResourceInfo returns the status of the resource for an object.
+// If mapped is set the namespace of the object is expected to have been
+// mapped to the namespace on the destination
+func getMigrationResourceInfo(
+	migration *stork_api.Migration,
+	metadata metav1.Object,
+	gkv schema.GroupVersionKind,
+	mapped bool,
+) *stork_api.MigrationResourceInfo {
 	for _, resource := range migration.Status.Resources {
-		metadata, err := meta.Accessor(object)
-		if err != nil {
-			continue
+		namespace := resource.Namespace
+		if mapped && namespace != "" {
+			namespace = migration.Spec.GetDestinationNamespace(namespace)
 		}
-		gkv := object.GetObjectKind().GroupVersionKind()
 		if resource.Name == metadata.GetName() &&
-			resource.Namespace == metadata.GetNamespace() &&
+			namespace == metadata.GetNamespace() &&
 			(resource.Group == gkv.Group || (resource.Group == "core" && gkv.Group == "")) &&
 			resource.Version == gkv.Version &&
 			resource.Kind == gkv.Kind {
-			resource.Status = status
-			resource.Reason = reason
-			eventType := v1.EventTypeNormal
-			if status == stork_api.MigrationStatusFailed {
-				eventType = v1.EventTypeWarning
-			}
-			eventMessage := fmt.Sprintf("%v %v/%v: %v",
-				gkv,
-				resource.Namespace,
-				resource.Name,
-				reason)
-			m.Recorder.Event(migration, eventType, string(status), eventMessage)
-			return
+			return resource
 		}
 	}
+	return nil
 }
 
 func (m *MigrationController) getRemoteAdminConfig(migration *stork_api.Migration) (*kubernetes.Clientset, error) {
@@ -929,186 +1322,479 @@ func (m *MigrationController) preparePVResource(
 func (m *MigrationController) prepareApplicationResource(
 	migration *stork_api.Migration,
 	object runtime.Unstructured,
+	options *resourcecollector.ApplicationSuspendOptions,
 ) error {
 	if *migration.Spec.StartApplications {
 		return nil
 	}
 
-	content := object.UnstructuredContent()
-	// Reset the replicas to 0 and store the current replicas in an annotation
-	replicas, found, err := unstructured.NestedInt64(content, "spec", "replicas")
-	if err != nil {
-		return err
-	}
-	if !found {
-		replicas = 1
+	// Suspend the application and store the current value in an annotation
+	o, ok := object.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unable to cast object to unstructured: %v", object)
 	}
+	return SuspendApplication(options, o)
+}
 
-	err = unstructured.SetNestedField(content, int64(0), "spec", "replicas")
+// getRemoteConfigs returns the configs for the destination cluster. The admin
+// config is used for cluster scoped resources
+func (m *MigrationController) getRemoteConfigs(migration *stork_api.Migration) (*rest.Config, *rest.Config, error) {
+	remoteConfig, err := getClusterPairSchedulerConfig(migration.Spec.ClusterPair, migration.Namespace)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	remoteAdminConfig := remoteConfig
+	// Use the admin cluter pair for cluster scoped resources if it has been configured
+	if migration.Spec.AdminClusterPair != "" {
+		remoteAdminConfig, err = getClusterPairSchedulerConfig(migration.Spec.AdminClusterPair, m.migrationAdminNamespace)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
+	return remoteConfig, remoteAdminConfig, nil
+}
 
-	annotations, found, err := unstructured.NestedStringMap(content, "metadata", "annotations")
+// getRemoteInterfaces returns the dynamic interfaces for the destination
+// cluster. The admin interface is used for cluster scoped resources
+func getRemoteInterfaces(remoteConfig *rest.Config, remoteAdminConfig *rest.Config) (dynamic.Interface, dynamic.Interface, error) {
+	remoteInterface, err := dynamic.NewForConfig(remoteConfig)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	if !found {
-		annotations = make(map[string]string)
+	remoteAdminInterface := remoteInterface
+	if remoteAdminConfig != remoteConfig {
+		remoteAdminInterface, err = dynamic.NewForConfig(remoteAdminConfig)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
-	annotations[StorkMigrationReplicasAnnotation] = strconv.FormatInt(replicas, 10)
-	return unstructured.SetNestedStringMap(content, annotations, "metadata", "annotations")
+	return remoteInterface, remoteAdminInterface, nil
 }
 
+// applyResources applies the objects in stages on the destination cluster.
+// Returns true if a stage is waiting for its objects to be ready, in which
+// case it should be called again on the next update to continue with the
+// remaining stages
 func (m *MigrationController) applyResources(
 	migration *stork_api.Migration,
 	objects []runtime.Unstructured,
-) error {
-	remoteConfig, err := getClusterPairSchedulerConfig(migration.Spec.ClusterPair, migration.Namespace)
+) (bool, error) {
+	remoteConfig, remoteAdminConfig, err := m.getRemoteConfigs(migration)
 	if err != nil {
-		return err
-	}
-	remoteAdminConfig := remoteConfig
-	// Use the admin cluter pair for cluster scoped resources if it has been configured
-	if migration.Spec.AdminClusterPair != "" {
-		remoteAdminConfig, err = getClusterPairSchedulerConfig(migration.Spec.AdminClusterPair, m.migrationAdminNamespace)
-		if err != nil {
-			return err
-		}
+		return false, err
 	}
 
 	adminClient, err := kubernetes.NewForConfig(remoteAdminConfig)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// First make sure all the namespaces are created on the
 	// remote cluster
+	remoteCore := core.New(adminClient, adminClient.CoreV1(), adminClient.StorageV1())
 	for _, ns := range migration.Spec.Namespaces {
 		namespace, err := core.Instance().GetNamespace(ns)
 		if err != nil {
-			return err
+			return false, err
 		}
 
-		// Don't create if the namespace already exists on the remote cluster
-		_, err = adminClient.CoreV1().Namespaces().Get(namespace.Name, metav1.GetOptions{})
+		destNamespace := migration.Spec.GetDestinationNamespace(namespace.Name)
+		// The applications are suspended if they aren't started, so they
+		// need to be activated again before they can be scaled up
+		if !*migration.Spec.StartApplications {
+			if err := SetNamespaceActivated(remoteCore, destNamespace, false); err != nil {
+				return false, err
+			}
+		}
+
+		// Don't create if the namespace already exists on the remote cluster,
+		// only add the migration label
+		existing, err := adminClient.CoreV1().Namespaces().Get(destNamespace, metav1.GetOptions{})
 		if err == nil {
+			if existing.Labels[StorkMigrationLabel] != "true" {
+				if existing.Labels == nil {
+					existing.Labels = make(map[string]string)
+				}
+				existing.Labels[StorkMigrationLabel] = "true"
+				if _, err := adminClient.CoreV1().Namespaces().Update(existing); err != nil {
+					return false, err
+				}
+			}
 			continue
 		}
 
+		labels := make(map[string]string)
+		for k, v := range namespace.Labels {
+			labels[k] = v
+		}
+		labels[StorkMigrationLabel] = "true"
+		// Keep track of the source namespace so that the applications can be
+		// activated using its name
+		if destNamespace != namespace.Name {
+			labels[StorkMigrationSourceNamespaceLabel] = namespace.Name
+		}
 		_, err = adminClient.CoreV1().Namespaces().Create(&v1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:        namespace.Name,
-				Labels:      namespace.Labels,
+				Name:        destNamespace,
+				Labels:      labels,
 				Annotations: namespace.Annotations,
 			},
 		})
 		if err != nil && !errors.IsAlreadyExists(err) {
-			return err
+			return false, err
 		}
 	}
 
-	remoteInterface, err := dynamic.NewForConfig(remoteConfig)
+	remoteInterface, remoteAdminInterface, err := getRemoteInterfaces(remoteConfig, remoteAdminConfig)
 	if err != nil {
-		return err
+		return false, err
 	}
-	remoteAdminInterface := remoteInterface
-	if migration.Spec.AdminClusterPair != "" {
-		remoteAdminInterface, err = dynamic.NewForConfig(remoteAdminConfig)
+	registry, err := resourcecollector.GetApplicationRegistry(core.Instance(), m.migrationAdminNamespace)
+	if err != nil {
+		return false, err
+	}
+
+	stages, err := resourcecollector.GroupObjectsByApplyStage(objects)
+	if err != nil {
+		return false, err
+	}
+	if migration.Status.ApplyStages == nil {
+		migration.Status.ApplyStages = resourcecollector.NewApplyStageInfos(stages)
+	}
+
+	stageObjects := make(map[stork_api.ApplyStageType][]runtime.Unstructured)
+	for _, stage := range stages {
+		stageObjects[stage.Stage] = stage.Objects
+	}
+	// Continue with the stages that haven't completed in case the migration
+	// was waiting for resources to be ready
+	for _, stageInfo := range migration.Status.ApplyStages {
+		switch stageInfo.Status {
+		case stork_api.ApplyStageStatusSuccessful, stork_api.ApplyStageStatusFailed:
+			continue
+		case stork_api.ApplyStageStatusWaiting:
+			waiting, err := m.checkApplyStageReady(migration, stageInfo, remoteInterface, remoteAdminInterface)
+			if err != nil || waiting {
+				return waiting, err
+			}
+			continue
+		}
+		err := m.applyStage(
+			migration,
+			stageInfo,
+			stageObjects[stageInfo.Stage],
+			registry,
+			remoteInterface,
+			remoteAdminInterface)
 		if err != nil {
-			return err
+			return false, err
 		}
+		if stageInfo.Status == stork_api.ApplyStageStatusWaiting {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// applyStage applies the objects in a stage on the remote cluster. If a
+// readiness gate is configured for the stage it is left waiting for the
+// objects to be ready, which is checked on the following updates
+func (m *MigrationController) applyStage(
+	migration *stork_api.Migration,
+	stageInfo *stork_api.ApplyStageInfo,
+	objects []runtime.Unstructured,
+	registry []*resourcecollector.ApplicationSuspendOptions,
+	remoteInterface dynamic.Interface,
+	remoteAdminInterface dynamic.Interface,
+) error {
+	stageInfo.Status = stork_api.ApplyStageStatusInProgress
+	stageInfo.Reason = "Applying resources"
+	stageInfo.StartTimestamp = metav1.Now()
+	if err := sdk.Update(migration); err != nil {
+		return err
 	}
 
 	for _, o := range objects {
-		metadata, err := meta.Accessor(o)
-		if err != nil {
+		if err := m.applyResource(migration, o, registry, remoteInterface, remoteAdminInterface); err != nil {
 			return err
 		}
-		objectType, err := meta.TypeAccessor(o)
+		stageInfo.AppliedResources++
+	}
+
+	if _, ok := resourcecollector.GetReadinessTimeout(migration.Spec.ReadinessGates, stageInfo.Stage); ok {
+		stageInfo.Status = stork_api.ApplyStageStatusWaiting
+		stageInfo.Reason = "Waiting for resources to be ready"
+		return sdk.Update(migration)
+	}
+
+	stageInfo.Status = stork_api.ApplyStageStatusSuccessful
+	stageInfo.Reason = "Resources migrated successfully"
+	stageInfo.FinishTimestamp = metav1.Now()
+	return sdk.Update(migration)
+}
+
+// checkWaitingApplyStages checks the stages that are waiting for their
+// readiness gates. Returns true if a stage needs to keep waiting
+func (m *MigrationController) checkWaitingApplyStages(migration *stork_api.Migration) (bool, error) {
+	for _, stageInfo := range migration.Status.ApplyStages {
+		if stageInfo.Status != stork_api.ApplyStageStatusWaiting {
+			continue
+		}
+		remoteConfig, remoteAdminConfig, err := m.getRemoteConfigs(migration)
 		if err != nil {
-			return err
+			return false, err
 		}
-		resource := &metav1.APIResource{
-			Name:       inflect.Pluralize(strings.ToLower(objectType.GetKind())),
-			Namespaced: len(metadata.GetNamespace()) > 0,
+		remoteInterface, remoteAdminInterface, err := getRemoteInterfaces(remoteConfig, remoteAdminConfig)
+		if err != nil {
+			return false, err
 		}
-		var dynamicClient dynamic.ResourceInterface
-		if resource.Namespaced {
-			dynamicClient = remoteInterface.Resource(
-				o.GetObjectKind().GroupVersionKind().GroupVersion().WithResource(resource.Name)).Namespace(metadata.GetNamespace())
-		} else {
-			dynamicClient = remoteAdminInterface.Resource(
-				o.GetObjectKind().GroupVersionKind().GroupVersion().WithResource(resource.Name))
-		}
-
-		log.MigrationLog(migration).Infof("Applying %v %v", objectType.GetKind(), metadata.GetName())
-		unstructured, ok := o.(*unstructured.Unstructured)
-		if !ok {
-			return fmt.Errorf("unable to cast object to unstructured: %v", o)
-		}
-
-		// set migration annotations
-		migrAnnot := metadata.GetAnnotations()
-		if migrAnnot == nil {
-			migrAnnot = make(map[string]string)
-		}
-		migrAnnot[StorkMigrationAnnotation] = "true"
-		migrAnnot[StorkMigrationName] = migration.GetName()
-		migrAnnot[StorkMigrationTime] = time.Now().Format(nameTimeSuffixFormat)
-		unstructured.SetAnnotations(migrAnnot)
-		retries := 0
-		for {
-			_, err = dynamicClient.Create(unstructured, metav1.CreateOptions{})
-			if err != nil && (errors.IsAlreadyExists(err) || strings.Contains(err.Error(), portallocator.ErrAllocated.Error())) {
-				switch objectType.GetKind() {
-				// Don't want to delete the Volume resources
-				case "PersistentVolumeClaim":
-					err = nil
-				case "PersistentVolume":
-					if migration.Spec.IncludeVolumes == nil || *migration.Spec.IncludeVolumes {
-						err = nil
-					} else {
-						_, err = dynamicClient.Update(unstructured, metav1.UpdateOptions{})
-					}
-				case "ServiceAccount":
-					err = m.checkAndUpdateDefaultSA(migration, o)
-				default:
-					// Delete the resource if it already exists on the destination
-					// cluster and try creating again
-					err = dynamicClient.Delete(metadata.GetName(), &metav1.DeleteOptions{})
-					if err == nil {
-						_, err = dynamicClient.Create(unstructured, metav1.CreateOptions{})
-					} else {
-						log.MigrationLog(migration).Errorf("Error deleting %v %v during migrate: %v", objectType.GetKind(), metadata.GetName(), err)
-					}
+		return m.checkApplyStageReady(migration, stageInfo, remoteInterface, remoteAdminInterface)
+	}
+	return false, nil
+}
 
-				}
-			}
-			// Retry a few times for Unauthorized errors
-			if err != nil && errors.IsUnauthorized(err) && retries < maxApplyRetries {
-				retries++
-				continue
-			}
-			break
+// checkApplyStageReady checks if the resources migrated in a stage that is
+// waiting for its readiness gate are ready on the remote cluster. Returns true
+// if the stage needs to keep waiting. The stage is marked as failed if the
+// resources aren't ready before the gate times out, and the migration carries
+// on with the next stage
+func (m *MigrationController) checkApplyStageReady(
+	migration *stork_api.Migration,
+	stageInfo *stork_api.ApplyStageInfo,
+	remoteInterface dynamic.Interface,
+	remoteAdminInterface dynamic.Interface,
+) (bool, error) {
+	namespacedObjects := make([]runtime.Unstructured, 0)
+	clusterObjects := make([]runtime.Unstructured, 0)
+	for _, resource := range migration.Status.Resources {
+		if !isResourceMigrated(resource.Status) {
+			continue
 		}
-		if err != nil {
-			m.updateResourceStatus(
-				migration,
-				o,
-				stork_api.MigrationStatusFailed,
-				fmt.Sprintf("Error applying resource: %v", err))
+		gvk := schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: resource.Kind}
+		if gvk.Group == "core" {
+			gvk.Group = ""
+		}
+		namespace := resource.Namespace
+		if namespace != "" {
+			namespace = migration.Spec.GetDestinationNamespace(namespace)
+		}
+		object := resourcecollector.NewObjectReference(gvk, namespace, resource.Name)
+		if stage, err := resourcecollector.GetApplyStage(object); err != nil {
+			return false, err
+		} else if stage != stageInfo.Stage {
+			continue
+		}
+		if namespace != "" {
+			namespacedObjects = append(namespacedObjects, object)
 		} else {
+			clusterObjects = append(clusterObjects, object)
+		}
+	}
+
+	// Cluster scoped resources need to be checked with the admin cluster
+	// pair
+	err := m.ResourceCollector.CheckResourcesReady(remoteAdminInterface, clusterObjects)
+	if err == nil {
+		err = m.ResourceCollector.CheckResourcesReady(remoteInterface, namespacedObjects)
+	}
+	if err != nil {
+		timeout, _ := resourcecollector.GetReadinessTimeout(migration.Spec.ReadinessGates, stageInfo.Stage)
+		if !resourcecollector.ReadinessTimedOut(stageInfo, timeout) {
+			log.MigrationLog(migration).Infof("Waiting for resources in stage %v to be ready: %v", stageInfo.Stage, err)
+			return true, nil
+		}
+		message := fmt.Sprintf("Readiness gate for stage %v failed: timed out waiting for resources to be ready: %v", stageInfo.Stage, err)
+		log.MigrationLog(migration).Error(message)
+		m.Recorder.Event(migration,
+			v1.EventTypeWarning,
+			string(stork_api.ApplyStageStatusFailed),
+			message)
+		stageInfo.Status = stork_api.ApplyStageStatusFailed
+		stageInfo.Reason = err.Error()
+	} else {
+		stageInfo.Status = stork_api.ApplyStageStatusSuccessful
+		stageInfo.Reason = "Resources migrated successfully"
+	}
+	stageInfo.FinishTimestamp = metav1.Now()
+	return false, sdk.Update(migration)
+}
+
+func (m *MigrationController) applyResource(
+	migration *stork_api.Migration,
+	o runtime.Unstructured,
+	registry []*resourcecollector.ApplicationSuspendOptions,
+	remoteInterface dynamic.Interface,
+	remoteAdminInterface dynamic.Interface,
+) error {
+	metadata, err := meta.Accessor(o)
+	if err != nil {
+		return err
+	}
+	objectType, err := meta.TypeAccessor(o)
+	if err != nil {
+		return err
+	}
+	resource := &metav1.APIResource{
+		Name:       inflect.Pluralize(strings.ToLower(objectType.GetKind())),
+		Namespaced: len(metadata.GetNamespace()) > 0,
+	}
+	var dynamicClient dynamic.ResourceInterface
+	if resource.Namespaced {
+		dynamicClient = remoteInterface.Resource(
+			o.GetObjectKind().GroupVersionKind().GroupVersion().WithResource(resource.Name)).Namespace(metadata.GetNamespace())
+	} else {
+		dynamicClient = remoteAdminInterface.Resource(
+			o.GetObjectKind().GroupVersionKind().GroupVersion().WithResource(resource.Name))
+	}
+
+	unstructured, ok := o.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unable to cast object to unstructured: %v", o)
+	}
+
+	// The hash is calculated before the migration annotations are added
+	// since they change every time
+	hash, err := getObjectHash(unstructured)
+	if err != nil {
+		return err
+	}
+	if *migration.Spec.SkipUnchangedResources {
+		existing, err := dynamicClient.Get(metadata.GetName(), metav1.GetOptions{})
+		if err == nil && isResourceUnchanged(existing, unstructured, hash, registry) {
+			log.MigrationLog(migration).Debugf("Skipping unchanged %v %v", objectType.GetKind(), metadata.GetName())
 			m.updateResourceStatus(
 				migration,
 				o,
-				stork_api.MigrationStatusSuccessful,
-				"Resource migrated successfully")
+				stork_api.MigrationStatusUnchanged,
+				"Resource hasn't changed since it was last migrated")
+			return nil
 		}
 	}
+
+	log.MigrationLog(migration).Infof("Applying %v %v", objectType.GetKind(), metadata.GetName())
+	// set migration annotations
+	migrAnnot := metadata.GetAnnotations()
+	if migrAnnot == nil {
+		migrAnnot = make(map[string]string)
+	}
+	migrAnnot[StorkMigrationHashAnnotation] = hash
+	migrAnnot[StorkMigrationAnnotation] = "true"
+	migrAnnot[StorkMigrationName] = migration.GetName()
+	migrAnnot[StorkMigrationTime] = time.Now().Format(nameTimeSuffixFormat)
+	unstructured.SetAnnotations(migrAnnot)
+	// Label applications so that scaling them up can be validated
+	if resourcecollector.GetApplicationSuspendOptions(registry, unstructured.GroupVersionKind()) != nil {
+		migrLabels := unstructured.GetLabels()
+		if migrLabels == nil {
+			migrLabels = make(map[string]string)
+		}
+		migrLabels[StorkMigrationLabel] = "true"
+		unstructured.SetLabels(migrLabels)
+	}
+	retries := 0
+	for {
+		_, err = dynamicClient.Create(unstructured, metav1.CreateOptions{})
+		if err != nil && (errors.IsAlreadyExists(err) || strings.Contains(err.Error(), portallocator.ErrAllocated.Error())) {
+			switch objectType.GetKind() {
+			// Don't want to delete the Volume resources
+			case "PersistentVolumeClaim":
+				err = nil
+			case "PersistentVolume":
+				if migration.Spec.IncludeVolumes == nil || *migration.Spec.IncludeVolumes {
+					err = nil
+				} else {
+					_, err = dynamicClient.Update(unstructured, metav1.UpdateOptions{})
+				}
+			case "ServiceAccount":
+				err = m.checkAndUpdateDefaultSA(migration, o)
+			default:
+				// Delete the resource if it already exists on the destination
+				// cluster and try creating again
+				err = dynamicClient.Delete(metadata.GetName(), &metav1.DeleteOptions{})
+				if err == nil {
+					_, err = dynamicClient.Create(unstructured, metav1.CreateOptions{})
+				} else {
+					log.MigrationLog(migration).Errorf("Error deleting %v %v during migrate: %v", objectType.GetKind(), metadata.GetName(), err)
+				}
+
+			}
+		}
+		// Retry a few times for Unauthorized errors
+		if err != nil && errors.IsUnauthorized(err) && retries < maxApplyRetries {
+			retries++
+			continue
+		}
+		break
+	}
+	if err != nil {
+		m.updateResourceStatus(
+			migration,
+			o,
+			stork_api.MigrationStatusFailed,
+			fmt.Sprintf("Error applying resource: %v", err))
+	} else {
+		m.updateResourceStatus(
+			migration,
+			o,
+			stork_api.MigrationStatusSuccessful,
+			"Resource migrated successfully")
+	}
 	return nil
 }
 
+// getObjectHash returns a hash of the content of an object that has been
+// prepared to be applied on the destination
+func getObjectHash(o *unstructured.Unstructured) (string, error) {
+	content, err := json.Marshal(o.UnstructuredContent())
+	if err != nil {
+		return "", fmt.Errorf("error calculating hash for %v %v: %v", o.GetKind(), o.GetName(), err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(content)), nil
+}
+
+// isResourceUnchanged returns true if the object on the destination was
+// migrated with the same content. Since the hash doesn't change when an
+// application is activated or scaled on the destination, the suspend field of
+// applications in the registry also needs to match
+func isResourceUnchanged(
+	existing *unstructured.Unstructured,
+	object *unstructured.Unstructured,
+	hash string,
+	registry []*resourcecollector.ApplicationSuspendOptions,
+) bool {
+	if existing.GetAnnotations()[StorkMigrationHashAnnotation] != hash {
+		return false
+	}
+	options := resourcecollector.GetApplicationSuspendOptions(registry, object.GroupVersionKind())
+	if options == nil {
+		return true
+	}
+	// Applications migrated before they were labelled need to be updated
+	if existing.GetLabels()[StorkMigrationLabel] != "true" {
+		return false
+	}
+	// Compare the encoded values so that numbers parsed differently match
+	values := make([]string, 0, 2)
+	for _, o := range []*unstructured.Unstructured{existing, object} {
+		value, err := options.GetValue(o.UnstructuredContent())
+		if err != nil {
+			return false
+		}
+		encoded, err := resourcecollector.EncodeValue(value)
+		if err != nil {
+			return false
+		}
+		values = append(values, encoded)
+	}
+	return values[0] == values[1]
+}
+
+// isResourceMigrated returns true if the resource is up to date on the
+// destination
+func isResourceMigrated(status stork_api.MigrationStatusType) bool {
+	return status == stork_api.MigrationStatusSuccessful || status == stork_api.MigrationStatusUnchanged
+}
+
 func (m *MigrationController) createCRD() error {
 	resource := apiextensions.CustomResource{
 		Name:    stork_api.MigrationResourceName,
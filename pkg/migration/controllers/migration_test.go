@@ -0,0 +1,85 @@
+// +build unittest
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestIsResourceUnchanged(t *testing.T) {
+	fakeKube := kubernetes.NewSimpleClientset()
+	registry, err := resourcecollector.GetApplicationRegistry(core.New(fakeKube, fakeKube.CoreV1(), fakeKube.StorageV1()), "")
+	require.NoError(t, err, "Error getting application registry")
+
+	withHash := func(o *unstructured.Unstructured, hash string) *unstructured.Unstructured {
+		o.SetAnnotations(map[string]string{StorkMigrationHashAnnotation: hash})
+		return o
+	}
+	withLabel := func(o *unstructured.Unstructured) *unstructured.Unstructured {
+		o.SetLabels(map[string]string{StorkMigrationLabel: "true"})
+		return o
+	}
+	withoutReplicas := func(o *unstructured.Unstructured) *unstructured.Unstructured {
+		unstructured.RemoveNestedField(o.Object, "spec", "replicas")
+		return o
+	}
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetName("config")
+
+	tests := []struct {
+		name      string
+		existing  *unstructured.Unstructured
+		object    *unstructured.Unstructured
+		unchanged bool
+	}{
+		{
+			name:      "different hash",
+			existing:  withHash(configMap.DeepCopy(), "old"),
+			object:    configMap.DeepCopy(),
+			unchanged: false,
+		},
+		{
+			name:      "same hash",
+			existing:  withHash(configMap.DeepCopy(), "hash"),
+			object:    configMap.DeepCopy(),
+			unchanged: true,
+		},
+		{
+			name:      "application still suspended",
+			existing:  withLabel(withHash(newTestDeployment(0), "hash")),
+			object:    newTestDeployment(0),
+			unchanged: true,
+		},
+		{
+			name:      "application without migration label",
+			existing:  withHash(newTestDeployment(0), "hash"),
+			object:    newTestDeployment(0),
+			unchanged: false,
+		},
+		{
+			name:      "application activated on the destination",
+			existing:  withLabel(withHash(newTestDeployment(3), "hash")),
+			object:    newTestDeployment(0),
+			unchanged: false,
+		},
+		{
+			name:      "default value on the destination",
+			existing:  withLabel(withHash(withoutReplicas(newTestDeployment(0)), "hash")),
+			object:    newTestDeployment(1),
+			unchanged: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.unchanged, isResourceUnchanged(test.existing, test.object, "hash", registry))
+		})
+	}
+}
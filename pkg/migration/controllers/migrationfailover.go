@@ -0,0 +1,598 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/libopenstorage/stork/pkg/apis/stork"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/controller"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	"github.com/operator-framework/operator-sdk/pkg/sdk"
+	"github.com/portworx/sched-ops/k8s/apiextensions"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/portworx/sched-ops/k8s/dynamic"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// ReverseMigrationScheduleSuffix is added to the name of the migration
+	// schedule to get the name of the schedule created on failover to
+	// migrate the applications back
+	ReverseMigrationScheduleSuffix = "-reverse"
+
+	// Timeout for requests to the remote cluster, so that a failover isn't
+	// held up for long when it isn't reachable
+	failoverRemoteTimeout = 30 * time.Second
+	finalSyncSuffix       = "-final-sync"
+)
+
+// failoverSteps are the steps of a failover in the order they are run. The
+// applications on the remote cluster are scaled down before the final sync so
+// that it includes all the changes made on the remote cluster
+var failoverSteps = []stork_api.MigrationFailoverStepType{
+	stork_api.MigrationFailoverStepSuspendSchedule,
+	stork_api.MigrationFailoverStepDeactivateSource,
+	stork_api.MigrationFailoverStepFinalSync,
+	stork_api.MigrationFailoverStepActivateDestination,
+	stork_api.MigrationFailoverStepReverseMigration,
+}
+
+// MigrationFailoverController reconciles MigrationFailover objects
+type MigrationFailoverController struct {
+	Recorder       record.EventRecorder
+	adminNamespace string
+}
+
+// remoteCluster is used to access the cluster that applications are being
+// failed over from
+type remoteCluster struct {
+	core    core.Ops
+	stork   storkops.Ops
+	dynamic dynamic.Ops
+}
+
+// Init Initialize the migration failover controller
+func (f *MigrationFailoverController) Init(adminNamespace string) error {
+	f.adminNamespace = adminNamespace
+	err := f.createCRD()
+	if err != nil {
+		return err
+	}
+	return controller.Register(
+		&schema.GroupVersionKind{
+			Group:   stork.GroupName,
+			Version: stork_api.SchemeGroupVersion.Version,
+			Kind:    reflect.TypeOf(stork_api.MigrationFailover{}).Name(),
+		},
+		"",
+		30*time.Second,
+		f)
+}
+
+// Handle updates for MigrationFailover objects
+func (f *MigrationFailoverController) Handle(ctx context.Context, event sdk.Event) error {
+	switch o := event.Object.(type) {
+	case *stork_api.MigrationFailover:
+		failover := o
+		if event.Deleted {
+			return nil
+		}
+
+		if _, ok := failover.Annotations[stork.RetryAnnotation]; ok {
+			return f.retryFailover(failover)
+		}
+
+		switch failover.Status.Status {
+		case stork_api.MigrationFailoverStatusInitial:
+			return f.startFailover(failover)
+		case stork_api.MigrationFailoverStatusInProgress:
+			return f.runSteps(failover)
+		}
+	}
+	return nil
+}
+
+func getRemoteCluster(clusterPair string, namespace string) (*remoteCluster, error) {
+	remoteConfig, err := getClusterPairSchedulerConfig(clusterPair, namespace)
+	if err != nil {
+		return nil, err
+	}
+	remoteConfig.Timeout = failoverRemoteTimeout
+
+	coreOps, err := core.NewForConfig(remoteConfig)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := coreOps.GetVersion(); err != nil {
+		return nil, fmt.Errorf("remote cluster isn't reachable: %v", err)
+	}
+	storkOps, err := storkops.NewForConfig(remoteConfig)
+	if err != nil {
+		return nil, err
+	}
+	dynamicOps, err := dynamic.NewForConfig(remoteConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteCluster{
+		core:    coreOps,
+		stork:   storkOps,
+		dynamic: dynamicOps,
+	}, nil
+}
+
+// getReverseMigrationScheduleName returns the name of the schedule used to
+// migrate the applications back to the remote cluster
+func getReverseMigrationScheduleName(failover *stork_api.MigrationFailover) string {
+	if failover.Spec.ReverseMigrationSchedule != "" {
+		return failover.Spec.ReverseMigrationSchedule
+	}
+	if failover.Spec.Type == stork_api.MigrationFailoverTypeFailback &&
+		strings.HasSuffix(failover.Spec.MigrationSchedule, ReverseMigrationScheduleSuffix) {
+		return strings.TrimSuffix(failover.Spec.MigrationSchedule, ReverseMigrationScheduleSuffix)
+	}
+	return failover.Spec.MigrationSchedule + ReverseMigrationScheduleSuffix
+}
+
+func (f *MigrationFailoverController) failFailover(failover *stork_api.MigrationFailover, message string) error {
+	failover.Status.Status = stork_api.MigrationFailoverStatusFailed
+	failover.Status.FinishTimestamp = meta.Now()
+	f.Recorder.Event(failover,
+		v1.EventTypeWarning,
+		string(stork_api.MigrationFailoverStatusFailed),
+		message)
+	log.MigrationFailoverLog(failover).Error(message)
+	return sdk.Update(failover)
+}
+
+func (f *MigrationFailoverController) startFailover(failover *stork_api.MigrationFailover) error {
+	if failover.Spec.Type == "" {
+		failover.Spec.Type = stork_api.MigrationFailoverTypeFailover
+	} else if failover.Spec.Type != stork_api.MigrationFailoverTypeFailover &&
+		failover.Spec.Type != stork_api.MigrationFailoverTypeFailback {
+		return f.failFailover(failover, fmt.Sprintf("Invalid type %v", failover.Spec.Type))
+	}
+	if failover.Spec.ClusterPair == "" {
+		return f.failFailover(failover, "ClusterPair needs to be specified")
+	}
+	if failover.Spec.MigrationSchedule == "" {
+		return f.failFailover(failover, "MigrationSchedule needs to be specified")
+	}
+
+	// Get the namespaces from the schedule on the remote cluster if it is
+	// reachable, otherwise they need to be specified
+	namespaces := failover.Spec.Namespaces
+	namespaceMapping := failover.Spec.NamespaceMapping
+	remote, err := getRemoteCluster(failover.Spec.ClusterPair, failover.Namespace)
+	if err != nil {
+		log.MigrationFailoverLog(failover).Warnf("Continuing without the remote cluster: %v", err)
+	} else {
+		failover.Status.RemoteReachable = true
+		migrationSchedule, err := remote.stork.GetMigrationSchedule(failover.Spec.MigrationSchedule, failover.Namespace)
+		if err != nil {
+			return f.failFailover(failover, fmt.Sprintf("Error getting migration schedule %v from remote cluster: %v",
+				failover.Spec.MigrationSchedule, err))
+		}
+		if len(namespaces) == 0 {
+			namespaces = migrationSchedule.Spec.Template.Spec.Namespaces
+		}
+		if len(namespaceMapping) == 0 {
+			namespaceMapping = migrationSchedule.Spec.Template.Spec.NamespaceMapping
+		}
+	}
+	if len(namespaces) == 0 {
+		return f.failFailover(failover, "Namespaces need to be specified when the remote cluster isn't reachable")
+	}
+	failover.Status.Namespaces = namespaces
+	failover.Status.NamespaceMapping = namespaceMapping
+
+	failover.Status.Steps = make([]*stork_api.MigrationFailoverStepInfo, 0)
+	for _, step := range failoverSteps {
+		failover.Status.Steps = append(failover.Status.Steps, &stork_api.MigrationFailoverStepInfo{
+			Step:   step,
+			Status: stork_api.MigrationFailoverStatusPending,
+		})
+	}
+	failover.Status.Status = stork_api.MigrationFailoverStatusInProgress
+	message := fmt.Sprintf("Started %v for namespaces %v", strings.ToLower(string(failover.Spec.Type)), namespaces)
+	if failover.Spec.TestMode {
+		message = message + " in test mode"
+	}
+	if !failover.Status.RemoteReachable {
+		message = message + ", remote cluster isn't reachable"
+	}
+	f.Recorder.Event(failover,
+		v1.EventTypeNormal,
+		string(stork_api.MigrationFailoverStatusInProgress),
+		message)
+	log.MigrationFailoverLog(failover).Info(message)
+	return sdk.Update(failover)
+}
+
+// runSteps runs the steps of the failover that haven't completed and saves
+// the status
+func (f *MigrationFailoverController) runSteps(failover *stork_api.MigrationFailover) error {
+	getRemote := func() (*remoteCluster, error) {
+		return getRemoteCluster(failover.Spec.ClusterPair, failover.Namespace)
+	}
+	if err := f.updateSteps(failover, getRemote); err != nil {
+		return f.failFailover(failover, err.Error())
+	}
+	return sdk.Update(failover)
+}
+
+// updateSteps runs the steps of the failover that haven't completed in order,
+// until a step is still in progress or fails. The remote cluster is only
+// accessed for steps that need it
+func (f *MigrationFailoverController) updateSteps(
+	failover *stork_api.MigrationFailover,
+	getRemote func() (*remoteCluster, error),
+) error {
+	var remote *remoteCluster
+	for _, step := range failover.Status.Steps {
+		if step.Status == stork_api.MigrationFailoverStatusSuccessful ||
+			step.Status == stork_api.MigrationFailoverStatusSkipped {
+			continue
+		}
+		if step.Status == stork_api.MigrationFailoverStatusPending {
+			step.Status = stork_api.MigrationFailoverStatusInProgress
+			step.StartTimestamp = meta.Now()
+		}
+
+		var done bool
+		var err error
+		if failover.Status.RemoteReachable && remote == nil && step.Step != stork_api.MigrationFailoverStepActivateDestination {
+			remote, err = getRemote()
+		}
+		if err == nil {
+			done, err = f.runStep(failover, step, remote)
+		}
+		if err != nil {
+			step.Status = stork_api.MigrationFailoverStatusFailed
+			step.Reason = err.Error()
+			step.FinishTimestamp = meta.Now()
+			return fmt.Errorf("error running step %v: %v", step.Step, err)
+		}
+		if !done {
+			return nil
+		}
+
+		step.FinishTimestamp = meta.Now()
+		if step.Status == stork_api.MigrationFailoverStatusSkipped {
+			log.MigrationFailoverLog(failover).Infof("Skipped step %v: %v", step.Step, step.Reason)
+			continue
+		}
+		step.Status = stork_api.MigrationFailoverStatusSuccessful
+		message := fmt.Sprintf("Completed step %v", step.Step)
+		f.Recorder.Event(failover,
+			v1.EventTypeNormal,
+			string(stork_api.MigrationFailoverStatusSuccessful),
+			message)
+		log.MigrationFailoverLog(failover).Info(message)
+	}
+
+	failover.Status.Status = stork_api.MigrationFailoverStatusSuccessful
+	failover.Status.FinishTimestamp = meta.Now()
+	message := fmt.Sprintf("%v completed successfully", failover.Spec.Type)
+	f.Recorder.Event(failover,
+		v1.EventTypeNormal,
+		string(stork_api.MigrationFailoverStatusSuccessful),
+		message)
+	log.MigrationFailoverLog(failover).Info(message)
+	return nil
+}
+
+// runStep runs a step of the failover and returns true once it is done
+func (f *MigrationFailoverController) runStep(
+	failover *stork_api.MigrationFailover,
+	step *stork_api.MigrationFailoverStepInfo,
+	remote *remoteCluster,
+) (bool, error) {
+	if !failover.Status.RemoteReachable && step.Step != stork_api.MigrationFailoverStepActivateDestination &&
+		step.Step != stork_api.MigrationFailoverStepReverseMigration {
+		step.Status = stork_api.MigrationFailoverStatusSkipped
+		step.Reason = "Remote cluster isn't reachable"
+		return true, nil
+	}
+	if failover.Spec.TestMode && (step.Step == stork_api.MigrationFailoverStepSuspendSchedule ||
+		step.Step == stork_api.MigrationFailoverStepDeactivateSource ||
+		step.Step == stork_api.MigrationFailoverStepReverseMigration) {
+		step.Status = stork_api.MigrationFailoverStatusSkipped
+		step.Reason = "Skipped in test mode"
+		return true, nil
+	}
+
+	switch step.Step {
+	case stork_api.MigrationFailoverStepSuspendSchedule:
+		return true, f.suspendMigrationSchedule(failover, remote)
+	case stork_api.MigrationFailoverStepFinalSync:
+		if failover.Spec.SkipFinalSync {
+			step.Status = stork_api.MigrationFailoverStatusSkipped
+			step.Reason = "Final sync was disabled"
+			return true, nil
+		}
+		return f.runFinalSync(failover, step, remote)
+	case stork_api.MigrationFailoverStepDeactivateSource:
+		for _, ns := range failover.Status.Namespaces {
+			if err := updateApplications(remote.core, remote.dynamic, f.adminNamespace, ns, false); err != nil {
+				return false, err
+			}
+			if err := SetNamespaceActivated(remote.core, ns, false); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	case stork_api.MigrationFailoverStepActivateDestination:
+		for _, ns := range getFailoverDestinationNamespaces(failover) {
+			// Mark the namespace as activated first so that the webhook
+			// allows the applications to be scaled up
+			if err := SetNamespaceActivated(core.Instance(), ns, true); err != nil {
+				return false, err
+			}
+			if err := updateApplications(core.Instance(), dynamic.Instance(), f.adminNamespace, ns, true); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	case stork_api.MigrationFailoverStepReverseMigration:
+		return true, f.startReverseMigration(failover, step, remote)
+	}
+	return false, fmt.Errorf("unknown step %v", step.Step)
+}
+
+func (f *MigrationFailoverController) suspendMigrationSchedule(
+	failover *stork_api.MigrationFailover,
+	remote *remoteCluster,
+) error {
+	migrationSchedule, err := remote.stork.GetMigrationSchedule(failover.Spec.MigrationSchedule, failover.Namespace)
+	if err != nil {
+		return fmt.Errorf("error getting migration schedule: %v", err)
+	}
+	if migrationSchedule.Spec.Suspend != nil && *migrationSchedule.Spec.Suspend {
+		return nil
+	}
+	suspend := true
+	migrationSchedule.Spec.Suspend = &suspend
+	if _, err := remote.stork.UpdateMigrationSchedule(migrationSchedule); err != nil {
+		return fmt.Errorf("error suspending migration schedule: %v", err)
+	}
+	return nil
+}
+
+// runFinalSync starts a migration on the remote cluster from the template of
+// the migration schedule and waits for it to complete. The name of the
+// migration is saved in the status before it is created so that the same
+// migration is checked if the failover is interrupted
+func (f *MigrationFailoverController) runFinalSync(
+	failover *stork_api.MigrationFailover,
+	step *stork_api.MigrationFailoverStepInfo,
+	remote *remoteCluster,
+) (bool, error) {
+	if step.Name == "" {
+		step.Name = failover.Name + finalSyncSuffix + "-" + time.Now().Format(nameTimeSuffixFormat)
+		return false, nil
+	}
+
+	migration, err := remote.stork.GetMigration(step.Name, failover.Namespace)
+	if errors.IsNotFound(err) {
+		migrationSchedule, err := remote.stork.GetMigrationSchedule(failover.Spec.MigrationSchedule, failover.Namespace)
+		if err != nil {
+			return false, fmt.Errorf("error getting migration schedule: %v", err)
+		}
+		startApplications := false
+		migration = &stork_api.Migration{
+			ObjectMeta: meta.ObjectMeta{
+				Name:      step.Name,
+				Namespace: failover.Namespace,
+			},
+			Spec: migrationSchedule.Spec.Template.Spec,
+		}
+		migration.Spec.StartApplications = &startApplications
+		if _, err := remote.stork.CreateMigration(migration); err != nil {
+			return false, fmt.Errorf("error creating final migration %v: %v", step.Name, err)
+		}
+		log.MigrationFailoverLog(failover).Infof("Started final migration %v", step.Name)
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("error getting final migration %v: %v", step.Name, err)
+	}
+
+	switch migration.Status.Status {
+	case stork_api.MigrationStatusSuccessful:
+		return true, nil
+	case stork_api.MigrationStatusFailed, stork_api.MigrationStatusPartialSuccess:
+		return false, fmt.Errorf("final migration %v completed with status %v", step.Name, migration.Status.Status)
+	}
+	return false, nil
+}
+
+// startReverseMigration resumes the schedule to migrate the applications back
+// to the remote cluster, or creates it from the template of the migration
+// schedule on the remote cluster
+func (f *MigrationFailoverController) startReverseMigration(
+	failover *stork_api.MigrationFailover,
+	step *stork_api.MigrationFailoverStepInfo,
+	remote *remoteCluster,
+) error {
+	name := getReverseMigrationScheduleName(failover)
+	step.Name = name
+	reverseSchedule, err := storkops.Instance().GetMigrationSchedule(name, failover.Namespace)
+	if err == nil {
+		if reverseSchedule.Spec.Suspend != nil && *reverseSchedule.Spec.Suspend {
+			suspend := false
+			reverseSchedule.Spec.Suspend = &suspend
+			if _, err := storkops.Instance().UpdateMigrationSchedule(reverseSchedule); err != nil {
+				return fmt.Errorf("error resuming migration schedule %v: %v", name, err)
+			}
+		}
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("error getting migration schedule %v: %v", name, err)
+	}
+
+	startApplications := false
+	template := stork_api.MigrationSpec{
+		StartApplications: &startApplications,
+	}
+	schedulePolicyName := failover.Spec.SchedulePolicyName
+	if remote != nil {
+		migrationSchedule, err := remote.stork.GetMigrationSchedule(failover.Spec.MigrationSchedule, failover.Namespace)
+		if err != nil {
+			return fmt.Errorf("error getting migration schedule: %v", err)
+		}
+		template = migrationSchedule.Spec.Template.Spec
+		template.StartApplications = &startApplications
+		if schedulePolicyName == "" {
+			schedulePolicyName = migrationSchedule.Spec.SchedulePolicyName
+		}
+	}
+	if schedulePolicyName == "" {
+		return fmt.Errorf("schedulePolicyName needs to be specified to create migration schedule %v "+
+			"when the remote cluster isn't reachable", name)
+	}
+	template.ClusterPair = failover.Spec.ClusterPair
+	template.AdminClusterPair = ""
+	// Migrate the namespaces on this cluster back to the namespaces they were
+	// migrated from
+	template.Namespaces = getFailoverDestinationNamespaces(failover)
+	template.NamespaceMapping = invertNamespaceMapping(failover.Status.NamespaceMapping)
+
+	reverseSchedule = &stork_api.MigrationSchedule{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: failover.Namespace,
+		},
+		Spec: stork_api.MigrationScheduleSpec{
+			Template: stork_api.MigrationTemplateSpec{
+				Spec: template,
+			},
+			SchedulePolicyName: schedulePolicyName,
+		},
+	}
+	if _, err := storkops.Instance().CreateMigrationSchedule(reverseSchedule); err != nil {
+		return fmt.Errorf("error creating migration schedule %v: %v", name, err)
+	}
+	return nil
+}
+
+// getFailoverDestinationNamespaces returns the namespaces on this cluster that
+// are being failed over
+func getFailoverDestinationNamespaces(failover *stork_api.MigrationFailover) []string {
+	spec := stork_api.MigrationSpec{
+		Namespaces:       failover.Status.Namespaces,
+		NamespaceMapping: failover.Status.NamespaceMapping,
+	}
+	return getDestinationNamespaces(&spec)
+}
+
+// retryFailover continues a failed failover from the step that failed. The
+// remote cluster is checked again since it might have become reachable
+func (f *MigrationFailoverController) retryFailover(failover *stork_api.MigrationFailover) error {
+	delete(failover.Annotations, stork.RetryAnnotation)
+	if failover.Status.Status != stork_api.MigrationFailoverStatusFailed || len(failover.Status.Steps) == 0 {
+		message := fmt.Sprintf("Ignoring retry for %v with status %v",
+			strings.ToLower(string(failover.Spec.Type)), failover.Status.Status)
+		log.MigrationFailoverLog(failover).Warn(message)
+		f.Recorder.Event(failover,
+			v1.EventTypeWarning,
+			string(failover.Status.Status),
+			message)
+		return sdk.Update(failover)
+	}
+
+	for _, step := range failover.Status.Steps {
+		if step.Status == stork_api.MigrationFailoverStatusFailed {
+			step.Status = stork_api.MigrationFailoverStatusPending
+			step.Reason = ""
+			step.Name = ""
+			step.FinishTimestamp = meta.Time{}
+		}
+	}
+	_, err := getRemoteCluster(failover.Spec.ClusterPair, failover.Namespace)
+	failover.Status.RemoteReachable = err == nil
+	failover.Status.Status = stork_api.MigrationFailoverStatusInProgress
+	failover.Status.FinishTimestamp = meta.Time{}
+	message := fmt.Sprintf("Retrying %v from the failed step", strings.ToLower(string(failover.Spec.Type)))
+	log.MigrationFailoverLog(failover).Info(message)
+	f.Recorder.Event(failover,
+		v1.EventTypeNormal,
+		string(stork_api.MigrationFailoverStatusInProgress),
+		message)
+	return sdk.Update(failover)
+}
+
+// updateApplications suspends or resumes the applications in a namespace
+// using the application registry of the cluster they are in, which is
+// expected to be in the same admin namespace on both clusters. When
+// suspending the current values are saved in the same annotations used for
+// migrated applications so that they can be activated the same way
+func updateApplications(
+	coreOps core.Ops,
+	dynamicOps dynamic.Ops,
+	adminNamespace string,
+	namespace string,
+	activate bool,
+) error {
+	registry, err := resourcecollector.GetApplicationRegistry(coreOps, adminNamespace)
+	if err != nil {
+		return err
+	}
+	for _, options := range registry {
+		objects, err := dynamicOps.ListObjects(
+			&meta.ListOptions{
+				TypeMeta: options.TypeMeta(),
+			},
+			namespace)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("error listing %v in namespace %v: %v", options.Kind, namespace, err)
+		}
+		for _, o := range objects.Items {
+			if activate {
+				if !IsApplicationSuspended(options, o.GetAnnotations()) {
+					continue
+				}
+				if _, err := ResumeApplication(options, &o); err != nil {
+					return err
+				}
+			} else if err := SuspendApplication(options, &o); err != nil {
+				return fmt.Errorf("error suspending %v %v/%v: %v", options.Kind, o.GetNamespace(), o.GetName(), err)
+			}
+			if _, err := dynamicOps.UpdateObject(&o); err != nil {
+				return fmt.Errorf("error updating %v for %v %v/%v: %v",
+					options.Path, options.Kind, o.GetNamespace(), o.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *MigrationFailoverController) createCRD() error {
+	resource := apiextensions.CustomResource{
+		Name:    stork_api.MigrationFailoverResourceName,
+		Plural:  stork_api.MigrationFailoverResourcePlural,
+		Group:   stork.GroupName,
+		Version: stork_api.SchemeGroupVersion.Version,
+		Scope:   apiextensionsv1beta1.NamespaceScoped,
+		Kind:    reflect.TypeOf(stork_api.MigrationFailover{}).Name(),
+	}
+	err := apiextensions.Instance().CreateCRD(resource)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	return apiextensions.Instance().ValidateCRD(resource, validateCRDTimeout, validateCRDInterval)
+}
@@ -0,0 +1,328 @@
+// +build unittest
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	fakeclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned/fake"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/portworx/sched-ops/k8s/dynamic"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+const testAppNamespace = "app"
+
+func newTestDeployment(replicas int64) *unstructured.Unstructured {
+	deployment := &unstructured.Unstructured{}
+	deployment.SetAPIVersion("apps/v1")
+	deployment.SetKind("Deployment")
+	deployment.SetNamespace(testAppNamespace)
+	deployment.SetName("app")
+	_ = unstructured.SetNestedField(deployment.Object, replicas, "spec", "replicas")
+	return deployment
+}
+
+// newTestCluster returns the ops for a fake cluster with a deployment in the
+// application namespace, which is suspended like a migrated application if
+// requested
+func newTestCluster(t *testing.T, replicas int64, suspended bool) *remoteCluster {
+	fakeKube := kubernetes.NewSimpleClientset()
+	cluster := &remoteCluster{
+		core:  core.New(fakeKube, fakeKube.CoreV1(), fakeKube.StorageV1()),
+		stork: storkops.New(fakeKube, fakeclient.NewSimpleClientset(), nil),
+	}
+	deployment := newTestDeployment(replicas)
+	if suspended {
+		registry, err := resourcecollector.GetApplicationRegistry(cluster.core, "")
+		require.NoError(t, err, "Error getting application registry")
+		for _, options := range registry {
+			if options.Kind == "Deployment" {
+				require.NoError(t, SuspendApplication(options, deployment), "Error suspending deployment")
+			}
+		}
+	}
+	cluster.dynamic = dynamic.New(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), deployment))
+	return cluster
+}
+
+// setLocalCluster sets the instances used to access the local cluster
+func setLocalCluster(cluster *remoteCluster) {
+	core.SetInstance(cluster.core)
+	storkops.SetInstance(cluster.stork)
+	dynamic.SetInstance(cluster.dynamic)
+}
+
+func getTestReplicas(t *testing.T, cluster *remoteCluster) int64 {
+	object, err := cluster.dynamic.GetObject(newTestDeployment(0))
+	require.NoError(t, err, "Error getting deployment")
+	replicas, _, err := unstructured.NestedInt64(object.(*unstructured.Unstructured).Object, "spec", "replicas")
+	require.NoError(t, err)
+	return replicas
+}
+
+func createTestMigrationSchedule(t *testing.T, cluster *remoteCluster) {
+	_, err := cluster.stork.CreateMigrationSchedule(&stork_api.MigrationSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "schedule",
+			Namespace: "admin",
+		},
+		Spec: stork_api.MigrationScheduleSpec{
+			Template: stork_api.MigrationTemplateSpec{
+				Spec: stork_api.MigrationSpec{
+					ClusterPair: "local",
+					Namespaces:  []string{testAppNamespace},
+				},
+			},
+			SchedulePolicyName: "remote-policy",
+		},
+	})
+	require.NoError(t, err, "Error creating migration schedule")
+}
+
+func newTestFailover(remoteReachable bool, testMode bool) *stork_api.MigrationFailover {
+	failover := &stork_api.MigrationFailover{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "failover",
+			Namespace: "admin",
+		},
+		Spec: stork_api.MigrationFailoverSpec{
+			Type:              stork_api.MigrationFailoverTypeFailover,
+			ClusterPair:       "remote",
+			MigrationSchedule: "schedule",
+			TestMode:          testMode,
+		},
+		Status: stork_api.MigrationFailoverStatus{
+			Status:          stork_api.MigrationFailoverStatusInProgress,
+			RemoteReachable: remoteReachable,
+			Namespaces:      []string{testAppNamespace},
+		},
+	}
+	for _, step := range failoverSteps {
+		failover.Status.Steps = append(failover.Status.Steps, &stork_api.MigrationFailoverStepInfo{
+			Step:   step,
+			Status: stork_api.MigrationFailoverStatusPending,
+		})
+	}
+	return failover
+}
+
+func requireStepStatus(
+	t *testing.T,
+	failover *stork_api.MigrationFailover,
+	expected ...stork_api.MigrationFailoverStatusType,
+) {
+	require.Len(t, failover.Status.Steps, len(expected))
+	for i, step := range failover.Status.Steps {
+		require.Equal(t, expected[i], step.Status, "Unexpected status for step %v: %v", step.Step, step.Reason)
+	}
+}
+
+// completeFinalSync runs the steps until the final migration has been created
+// on the remote cluster and marks it as successful
+func completeFinalSync(t *testing.T, controller *MigrationFailoverController, failover *stork_api.MigrationFailover, remote *remoteCluster) {
+	var finalSync *stork_api.MigrationFailoverStepInfo
+	for _, step := range failover.Status.Steps {
+		if step.Step == stork_api.MigrationFailoverStepFinalSync {
+			finalSync = step
+		}
+	}
+	getRemote := func() (*remoteCluster, error) { return remote, nil }
+	// The name of the migration is saved before it is created
+	if finalSync.Name == "" {
+		require.NoError(t, controller.updateSteps(failover, getRemote), "Error running failover steps")
+		require.NotEmpty(t, finalSync.Name)
+	}
+	require.NoError(t, controller.updateSteps(failover, getRemote), "Error creating final migration")
+	require.Equal(t, stork_api.MigrationFailoverStatusInProgress, failover.Status.Status)
+
+	migration, err := remote.stork.GetMigration(finalSync.Name, failover.Namespace)
+	require.NoError(t, err, "Final migration should have been created")
+	require.False(t, *migration.Spec.StartApplications)
+	migration.Status.Status = stork_api.MigrationStatusSuccessful
+	_, err = remote.stork.UpdateMigration(migration)
+	require.NoError(t, err, "Error updating final migration")
+}
+
+func TestFailoverStepsRemoteUnreachable(t *testing.T) {
+	local := newTestCluster(t, 3, true)
+	setLocalCluster(local)
+	controller := &MigrationFailoverController{Recorder: record.NewFakeRecorder(100)}
+	getRemote := func() (*remoteCluster, error) {
+		return nil, fmt.Errorf("remote cluster shouldn't be accessed")
+	}
+
+	// The reverse schedule can't be created without a schedule policy
+	failover := newTestFailover(false, false)
+	err := controller.updateSteps(failover, getRemote)
+	require.Error(t, err, "Reverse migration should fail without a schedule policy")
+	require.Contains(t, err.Error(), "error running step ReverseMigration: schedulePolicyName needs to be specified")
+	requireStepStatus(t, failover,
+		stork_api.MigrationFailoverStatusSkipped,
+		stork_api.MigrationFailoverStatusSkipped,
+		stork_api.MigrationFailoverStatusSkipped,
+		stork_api.MigrationFailoverStatusSuccessful,
+		stork_api.MigrationFailoverStatusFailed,
+	)
+	for _, step := range failover.Status.Steps[:3] {
+		require.Equal(t, "Remote cluster isn't reachable", step.Reason)
+	}
+
+	failover = newTestFailover(false, false)
+	failover.Spec.SchedulePolicyName = "policy"
+	require.NoError(t, controller.updateSteps(failover, getRemote), "Error running failover steps")
+	require.Equal(t, stork_api.MigrationFailoverStatusSuccessful, failover.Status.Status)
+	requireStepStatus(t, failover,
+		stork_api.MigrationFailoverStatusSkipped,
+		stork_api.MigrationFailoverStatusSkipped,
+		stork_api.MigrationFailoverStatusSkipped,
+		stork_api.MigrationFailoverStatusSuccessful,
+		stork_api.MigrationFailoverStatusSuccessful,
+	)
+	require.Equal(t, int64(3), getTestReplicas(t, local), "Application should have been activated")
+	activated, err := IsNamespaceActivated(local.core, testAppNamespace)
+	require.NoError(t, err)
+	require.True(t, activated, "Namespace should have been activated")
+
+	reverseSchedule, err := local.stork.GetMigrationSchedule("schedule"+ReverseMigrationScheduleSuffix, "admin")
+	require.NoError(t, err, "Reverse migration schedule should have been created")
+	require.Equal(t, "policy", reverseSchedule.Spec.SchedulePolicyName)
+	require.Equal(t, "remote", reverseSchedule.Spec.Template.Spec.ClusterPair)
+	require.Equal(t, []string{testAppNamespace}, reverseSchedule.Spec.Template.Spec.Namespaces)
+}
+
+func TestFailoverSteps(t *testing.T) {
+	local := newTestCluster(t, 3, true)
+	setLocalCluster(local)
+	remote := newTestCluster(t, 2, false)
+	createTestMigrationSchedule(t, remote)
+	require.NoError(t, SetNamespaceActivated(remote.core, testAppNamespace, true))
+	controller := &MigrationFailoverController{Recorder: record.NewFakeRecorder(100)}
+	failover := newTestFailover(true, false)
+	getRemote := func() (*remoteCluster, error) { return remote, nil }
+
+	// The applications on the remote cluster should be scaled down before the
+	// final migration is created
+	require.NoError(t, controller.updateSteps(failover, getRemote), "Error running failover steps")
+	requireStepStatus(t, failover,
+		stork_api.MigrationFailoverStatusSuccessful,
+		stork_api.MigrationFailoverStatusSuccessful,
+		stork_api.MigrationFailoverStatusInProgress,
+		stork_api.MigrationFailoverStatusPending,
+		stork_api.MigrationFailoverStatusPending,
+	)
+	finalSync := failover.Status.Steps[2]
+	require.Equal(t, stork_api.MigrationFailoverStepFinalSync, finalSync.Step)
+	require.NotEmpty(t, finalSync.Name)
+	_, err := remote.stork.GetMigration(finalSync.Name, failover.Namespace)
+	require.True(t, errors.IsNotFound(err), "Final migration shouldn't have been created yet")
+	migrationSchedule, err := remote.stork.GetMigrationSchedule("schedule", "admin")
+	require.NoError(t, err)
+	require.True(t, *migrationSchedule.Spec.Suspend, "Migration schedule should have been suspended")
+	require.Equal(t, int64(0), getTestReplicas(t, remote), "Remote application should have been scaled down")
+	activated, err := IsNamespaceActivated(remote.core, testAppNamespace)
+	require.NoError(t, err)
+	require.False(t, activated, "Remote namespace should have been deactivated")
+
+	completeFinalSync(t, controller, failover, remote)
+	require.NoError(t, controller.updateSteps(failover, getRemote), "Error running failover steps")
+	require.Equal(t, stork_api.MigrationFailoverStatusSuccessful, failover.Status.Status)
+	requireStepStatus(t, failover,
+		stork_api.MigrationFailoverStatusSuccessful,
+		stork_api.MigrationFailoverStatusSuccessful,
+		stork_api.MigrationFailoverStatusSuccessful,
+		stork_api.MigrationFailoverStatusSuccessful,
+		stork_api.MigrationFailoverStatusSuccessful,
+	)
+	require.Equal(t, int64(3), getTestReplicas(t, local), "Application should have been activated")
+
+	// The reverse schedule is created from the template of the remote
+	// schedule
+	reverseSchedule, err := local.stork.GetMigrationSchedule("schedule"+ReverseMigrationScheduleSuffix, "admin")
+	require.NoError(t, err, "Reverse migration schedule should have been created")
+	require.Equal(t, "remote-policy", reverseSchedule.Spec.SchedulePolicyName)
+	require.Equal(t, "remote", reverseSchedule.Spec.Template.Spec.ClusterPair)
+}
+
+func TestFailoverStepsTestMode(t *testing.T) {
+	local := newTestCluster(t, 3, true)
+	setLocalCluster(local)
+	remote := newTestCluster(t, 2, false)
+	createTestMigrationSchedule(t, remote)
+	controller := &MigrationFailoverController{Recorder: record.NewFakeRecorder(100)}
+	failover := newTestFailover(true, true)
+	getRemote := func() (*remoteCluster, error) { return remote, nil }
+
+	completeFinalSync(t, controller, failover, remote)
+	require.NoError(t, controller.updateSteps(failover, getRemote), "Error running failover steps")
+	require.Equal(t, stork_api.MigrationFailoverStatusSuccessful, failover.Status.Status)
+	requireStepStatus(t, failover,
+		stork_api.MigrationFailoverStatusSkipped,
+		stork_api.MigrationFailoverStatusSkipped,
+		stork_api.MigrationFailoverStatusSuccessful,
+		stork_api.MigrationFailoverStatusSuccessful,
+		stork_api.MigrationFailoverStatusSkipped,
+	)
+	require.Equal(t, "Skipped in test mode", failover.Status.Steps[0].Reason)
+	require.Equal(t, "Skipped in test mode", failover.Status.Steps[1].Reason)
+	require.Equal(t, "Skipped in test mode", failover.Status.Steps[4].Reason)
+	require.Equal(t, int64(2), getTestReplicas(t, remote), "Remote application shouldn't be scaled down")
+	require.Equal(t, int64(3), getTestReplicas(t, local), "Application should have been activated")
+	schedule, err := remote.stork.GetMigrationSchedule("schedule", "admin")
+	require.NoError(t, err, "Error getting migration schedule")
+	require.Nil(t, schedule.Spec.Suspend, "Migration schedule shouldn't be suspended in test mode")
+	_, err = local.stork.GetMigrationSchedule("schedule"+ReverseMigrationScheduleSuffix, "admin")
+	require.True(t, errors.IsNotFound(err), "Reverse migration schedule shouldn't be created")
+}
+
+func TestFailoverStepsFailure(t *testing.T) {
+	local := newTestCluster(t, 3, true)
+	setLocalCluster(local)
+	controller := &MigrationFailoverController{Recorder: record.NewFakeRecorder(100)}
+
+	// The step fails if the remote cluster isn't reachable anymore
+	failover := newTestFailover(true, false)
+	err := controller.updateSteps(failover, func() (*remoteCluster, error) {
+		return nil, fmt.Errorf("connection refused")
+	})
+	require.Error(t, err, "Step should fail when the remote cluster isn't reachable")
+	require.Contains(t, err.Error(), "error running step SuspendSchedule: connection refused")
+	require.Equal(t, "connection refused", failover.Status.Steps[0].Reason)
+	require.False(t, failover.Status.Steps[0].FinishTimestamp.IsZero())
+
+	// Later steps aren't run if the final migration fails
+	remote := newTestCluster(t, 2, false)
+	createTestMigrationSchedule(t, remote)
+	getRemote := func() (*remoteCluster, error) { return remote, nil }
+	failover = newTestFailover(true, false)
+	completeFinalSync(t, controller, failover, remote)
+	migration, err := remote.stork.GetMigration(failover.Status.Steps[2].Name, failover.Namespace)
+	require.NoError(t, err)
+	migration.Status.Status = stork_api.MigrationStatusFailed
+	_, err = remote.stork.UpdateMigration(migration)
+	require.NoError(t, err)
+
+	err = controller.updateSteps(failover, getRemote)
+	require.Error(t, err, "Step should fail when the final migration fails")
+	require.Contains(t, err.Error(), "error running step FinalSync")
+	requireStepStatus(t, failover,
+		stork_api.MigrationFailoverStatusSuccessful,
+		stork_api.MigrationFailoverStatusSuccessful,
+		stork_api.MigrationFailoverStatusFailed,
+		stork_api.MigrationFailoverStatusPending,
+		stork_api.MigrationFailoverStatusPending,
+	)
+	require.Contains(t, failover.Status.Steps[2].Reason, "completed with status Failed")
+	require.Equal(t, int64(0), getTestReplicas(t, local), "Application shouldn't be activated")
+}
@@ -75,6 +75,10 @@ func (m *MigrationScheduleController) Handle(ctx context.Context, event sdk.Even
 			return err
 		}
 
+		if err := m.updateRPOStatus(migrationSchedule); err != nil {
+			return err
+		}
+
 		// Then check if any of the policies require a trigger if it is enabled
 		if migrationSchedule.Spec.Suspend == nil || !*migrationSchedule.Spec.Suspend {
 			var err error
@@ -104,6 +108,14 @@ func (m *MigrationScheduleController) Handle(ctx context.Context, event sdk.Even
 				}
 			}
 
+			paused, err := m.updatePausedStatus(migrationSchedule)
+			if err != nil {
+				return err
+			}
+			if paused {
+				return m.pruneMigrations(migrationSchedule)
+			}
+
 			policyType, start, err := m.shouldStartMigration(migrationSchedule)
 			if err != nil {
 				msg := fmt.Sprintf("Error checking if migration should be triggered: %v", err)
@@ -197,8 +209,93 @@ func (m *MigrationScheduleController) updateMigrationStatus(migrationSchedule *s
 	return nil
 }
 
+// updateRPOStatus updates the start and finish times of the last successful
+// migration and the RPOBreached condition, and raises an event when the RPO target is
+// breached or met again. The status is only updated when either of them
+// changes, the current lag is computed by clients
+func (m *MigrationScheduleController) updateRPOStatus(migrationSchedule *stork_api.MigrationSchedule) error {
+	lastSuccessful := migrationSchedule.Status.LastSuccessfulTimestamp
+	lastSuccessfulFinish := migrationSchedule.Status.LastSuccessfulFinishTimestamp
+	for _, policyMigration := range migrationSchedule.Status.Items {
+		for _, migration := range policyMigration {
+			if migration.Status == stork_api.MigrationStatusSuccessful && lastSuccessful.Before(&migration.CreationTimestamp) {
+				lastSuccessful = migration.CreationTimestamp
+				lastSuccessfulFinish = migration.FinishTimestamp
+			}
+		}
+	}
+	lag := schedule.GetRPOLag(lastSuccessful, migrationSchedule.CreationTimestamp)
+	conditions, changed := schedule.UpdateRPOCondition(migrationSchedule.Status.Conditions, migrationSchedule.Spec.RPOTarget, lag)
+	if changed {
+		if schedule.IsRPOBreached(conditions) {
+			msg := fmt.Sprintf("RPO target (%v) breached, last successful migration was started %v ago", migrationSchedule.Spec.RPOTarget.Duration, lag)
+			m.Recorder.Event(migrationSchedule,
+				v1.EventTypeWarning,
+				schedule.RPOBreachedReason,
+				msg)
+			log.MigrationScheduleLog(migrationSchedule).Warn(msg)
+		} else {
+			msg := "RPO target is being met again"
+			m.Recorder.Event(migrationSchedule,
+				v1.EventTypeNormal,
+				schedule.RPOMetReason,
+				msg)
+			log.MigrationScheduleLog(migrationSchedule).Info(msg)
+		}
+	}
+
+	if lastSuccessful.Equal(&migrationSchedule.Status.LastSuccessfulTimestamp) &&
+		lastSuccessfulFinish.Equal(&migrationSchedule.Status.LastSuccessfulFinishTimestamp) &&
+		reflect.DeepEqual(conditions, migrationSchedule.Status.Conditions) {
+		return nil
+	}
+	migrationSchedule.Status.LastSuccessfulTimestamp = lastSuccessful
+	migrationSchedule.Status.LastSuccessfulFinishTimestamp = lastSuccessfulFinish
+	migrationSchedule.Status.Conditions = conditions
+	return sdk.Update(migrationSchedule)
+}
+
+// updatePausedStatus pauses the schedule while the ClusterPair isn't healthy
+// if PauseOnUnhealthyClusterPair is set and resumes it once the pair is
+// healthy again. Returns true if the schedule is paused
+func (m *MigrationScheduleController) updatePausedStatus(migrationSchedule *stork_api.MigrationSchedule) (bool, error) {
+	reason := ""
+	if migrationSchedule.Spec.PauseOnUnhealthyClusterPair != nil && *migrationSchedule.Spec.PauseOnUnhealthyClusterPair {
+		clusterPair, err := storkops.Instance().GetClusterPair(migrationSchedule.Spec.Template.Spec.ClusterPair, migrationSchedule.Namespace)
+		if err != nil {
+			reason = fmt.Sprintf("error getting clusterpair: %v", err)
+		} else if err := isClusterPairHealthy(clusterPair); err != nil {
+			reason = err.Error()
+		}
+	}
+
+	paused := reason != ""
+	if paused == migrationSchedule.Status.Paused && reason == migrationSchedule.Status.PausedReason {
+		return paused, nil
+	}
+	if paused && !migrationSchedule.Status.Paused {
+		msg := fmt.Sprintf("Pausing migration schedule since clusterpair isn't healthy: %v", reason)
+		m.Recorder.Event(migrationSchedule,
+			v1.EventTypeWarning,
+			"Paused",
+			msg)
+		log.MigrationScheduleLog(migrationSchedule).Warn(msg)
+	} else if !paused {
+		msg := "Resuming migration schedule since clusterpair is healthy"
+		m.Recorder.Event(migrationSchedule,
+			v1.EventTypeNormal,
+			"Resumed",
+			msg)
+		log.MigrationScheduleLog(migrationSchedule).Info(msg)
+	}
+	migrationSchedule.Status.Paused = paused
+	migrationSchedule.Status.PausedReason = reason
+	return paused, sdk.Update(migrationSchedule)
+}
+
 func (m *MigrationScheduleController) isMigrationComplete(status stork_api.MigrationStatusType) bool {
 	if status == stork_api.MigrationStatusPending ||
+		status == stork_api.MigrationStatusQueued ||
 		status == stork_api.MigrationStatusInProgress {
 		return false
 	}
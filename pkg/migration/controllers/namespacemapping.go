@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"fmt"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// StorkMigrationSourceNamespaceLabel is added to namespaces created on
+	// the destination cluster for a mapped namespace with the name of the
+	// namespace on the source cluster
+	StorkMigrationSourceNamespaceLabel = "stork.libopenstorage.org/migrationSourceNamespace"
+)
+
+// validateNamespaceMapping checks that only namespaces being migrated are
+// mapped and that each namespace on the destination is only used once
+func validateNamespaceMapping(spec *stork_api.MigrationSpec) error {
+	namespaces := make(map[string]bool)
+	for _, ns := range spec.Namespaces {
+		namespaces[ns] = true
+	}
+	destNamespaces := make(map[string]string)
+	for _, ns := range spec.Namespaces {
+		destNamespace := spec.GetDestinationNamespace(ns)
+		if other, ok := destNamespaces[destNamespace]; ok {
+			return fmt.Errorf("namespaces %v and %v are both mapped to namespace %v", other, ns, destNamespace)
+		}
+		destNamespaces[destNamespace] = ns
+	}
+	for ns := range spec.NamespaceMapping {
+		if !namespaces[ns] {
+			return fmt.Errorf("namespace %v in namespaceMapping isn't being migrated", ns)
+		}
+	}
+	return nil
+}
+
+// getDestinationNamespaces returns the namespaces on the destination cluster
+// for the namespaces being migrated
+func getDestinationNamespaces(spec *stork_api.MigrationSpec) []string {
+	destNamespaces := make([]string, 0, len(spec.Namespaces))
+	for _, ns := range spec.Namespaces {
+		destNamespaces = append(destNamespaces, spec.GetDestinationNamespace(ns))
+	}
+	return destNamespaces
+}
+
+// invertNamespaceMapping returns the mapping to migrate the namespaces back
+// from the destination cluster
+func invertNamespaceMapping(mapping map[string]string) map[string]string {
+	if len(mapping) == 0 {
+		return nil
+	}
+	inverted := make(map[string]string)
+	for src, dest := range mapping {
+		inverted[dest] = src
+	}
+	return inverted
+}
+
+// mapObjectNamespace updates the namespace of an object collected from the
+// source cluster to the namespace it should be created in on the
+// destination. References to namespaces in PersistentVolume claims and in
+// the ServiceAccount subjects of bindings are updated too
+func mapObjectNamespace(spec *stork_api.MigrationSpec, o runtime.Unstructured) error {
+	if len(spec.NamespaceMapping) == 0 {
+		return nil
+	}
+	metadata, err := meta.Accessor(o)
+	if err != nil {
+		return err
+	}
+	if ns := metadata.GetNamespace(); ns != "" {
+		metadata.SetNamespace(spec.GetDestinationNamespace(ns))
+	}
+
+	content := o.UnstructuredContent()
+	switch o.GetObjectKind().GroupVersionKind().Kind {
+	case "PersistentVolume":
+		ns, found, err := unstructured.NestedString(content, "spec", "claimRef", "namespace")
+		if err != nil || !found {
+			return err
+		}
+		return unstructured.SetNestedField(content, spec.GetDestinationNamespace(ns), "spec", "claimRef", "namespace")
+	case "RoleBinding", "ClusterRoleBinding":
+		subjects, found, err := unstructured.NestedSlice(content, "subjects")
+		if err != nil || !found {
+			return err
+		}
+		for _, s := range subjects {
+			subject, ok := s.(map[string]interface{})
+			if !ok || subject["kind"] != "ServiceAccount" {
+				continue
+			}
+			if ns, ok := subject["namespace"].(string); ok {
+				subject["namespace"] = spec.GetDestinationNamespace(ns)
+			}
+		}
+		return unstructured.SetNestedSlice(content, subjects, "subjects")
+	}
+	return nil
+}
@@ -17,6 +17,8 @@ type Migration struct {
 	clusterPairController       *controllers.ClusterPairController
 	migrationController         *controllers.MigrationController
 	migrationScheduleController *controllers.MigrationScheduleController
+	migrationFailoverController *controllers.MigrationFailoverController
+	drPlanController            *controllers.DRPlanController
 }
 
 // Init init
@@ -47,5 +49,19 @@ func (m *Migration) Init(migrationAdminNamespace string) error {
 	if err != nil {
 		return fmt.Errorf("error initializing migration schedule controller: %v", err)
 	}
+	m.migrationFailoverController = &controllers.MigrationFailoverController{
+		Recorder: m.Recorder,
+	}
+	err = m.migrationFailoverController.Init(migrationAdminNamespace)
+	if err != nil {
+		return fmt.Errorf("error initializing migration failover controller: %v", err)
+	}
+	m.drPlanController = &controllers.DRPlanController{
+		Recorder: m.Recorder,
+	}
+	err = m.drPlanController.Init()
+	if err != nil {
+		return fmt.Errorf("error initializing drplan controller: %v", err)
+	}
 	return nil
 }
@@ -0,0 +1,220 @@
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+)
+
+// BackupMetadataObjectName is the name of the object in which the metadata for
+// an ApplicationBackup is stored. It is uploaded after all the other objects
+// for the backup, so a backup is complete once its metadata is present
+const BackupMetadataObjectName = "metadata.json"
+
+const (
+	// backupIndexDir is the directory under the namespace of a location with
+	// an empty object for each backup, grouped in a directory for the day the
+	// backup completed. It lets new backups be found by listing only the
+	// directories for the last few days. It can't conflict with the path of
+	// a backup since names of backups can't start with "."
+	backupIndexDir       = ".backup-index"
+	backupIndexDayFormat = "20060102"
+	backupIndexDay       = 24 * time.Hour
+)
+
+// GetBackupIndexKey returns the key of the index object for a backup
+func GetBackupIndexKey(namespace string, finishTime time.Time, backupPath string) string {
+	return filepath.Join(namespace, backupIndexDir, finishTime.UTC().Format(backupIndexDayFormat), url.PathEscape(backupPath))
+}
+
+// AddBackupToIndex adds a backup to the index of the namespace of the backup
+// location. It should be called after the metadata for the backup has been
+// uploaded
+func AddBackupToIndex(
+	backupLocation *stork_api.BackupLocation,
+	bucket *blob.Bucket,
+	backupPath string,
+	finishTime time.Time,
+) error {
+	key := GetBackupIndexKey(backupLocation.Namespace, finishTime, backupPath)
+	return bucket.WriteAll(context.TODO(), key, []byte{}, GetWriterOptions(backupLocation))
+}
+
+// RemoveBackupFromIndex removes a backup from the index of the namespace of
+// the backup location. No error is returned if it isn't in the index
+func RemoveBackupFromIndex(
+	backupLocation *stork_api.BackupLocation,
+	bucket *blob.Bucket,
+	backupPath string,
+	finishTime time.Time,
+) error {
+	key := GetBackupIndexKey(backupLocation.Namespace, finishTime, backupPath)
+	if err := bucket.Delete(context.TODO(), key); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+		return err
+	}
+	return nil
+}
+
+// ListIndexedBackups returns the paths of the backups that were added to the
+// index of a namespace since the given time. Only the index directories for
+// the days since then are listed. The whole index is listed if the time is
+// zero
+func ListIndexedBackups(bucket *blob.Bucket, namespace string, since time.Time) ([]string, error) {
+	if since.IsZero() {
+		return listBackupIndex(bucket, filepath.Join(namespace, backupIndexDir)+"/")
+	}
+	backupPaths := make([]string, 0)
+	now := time.Now().UTC()
+	for day := since.UTC().Truncate(backupIndexDay); !day.After(now); day = day.Add(backupIndexDay) {
+		paths, err := listBackupIndex(bucket, filepath.Join(namespace, backupIndexDir, day.Format(backupIndexDayFormat))+"/")
+		if err != nil {
+			return nil, err
+		}
+		backupPaths = append(backupPaths, paths...)
+	}
+	return backupPaths, nil
+}
+
+// listBackupIndex returns the paths of the backups in the index objects with
+// the given prefix
+func listBackupIndex(bucket *blob.Bucket, prefix string) ([]string, error) {
+	backupPaths := make([]string, 0)
+	iterator := bucket.List(&blob.ListOptions{
+		Prefix: prefix,
+	})
+	for {
+		object, err := iterator.Next(context.TODO())
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		backupPath, err := url.PathUnescape(path.Base(object.Key))
+		if err != nil {
+			continue
+		}
+		backupPaths = append(backupPaths, backupPath)
+	}
+	return backupPaths, nil
+}
+
+// ListBackups returns the backups stored in the backup location for the
+// namespace of the backup location, sorted by the time they were triggered.
+// The path of each backup in the backup location is returned in
+// Status.BackupPath. The backups are found from the index of the namespace,
+// unless it is empty, in which case all the objects for the namespace are
+// listed to find backups uploaded before the index was added. Backups whose
+// metadata can't be read are skipped
+func ListBackups(backupLocation *stork_api.BackupLocation) ([]*stork_api.ApplicationBackup, error) {
+	bucket, err := GetBucket(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	backupPaths, err := ListIndexedBackups(bucket, backupLocation.Namespace, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if len(backupPaths) == 0 {
+		iterator := bucket.List(&blob.ListOptions{
+			Prefix: backupLocation.Namespace + "/",
+		})
+		for {
+			object, err := iterator.Next(context.TODO())
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if filepath.Base(object.Key) == BackupMetadataObjectName {
+				backupPaths = append(backupPaths, filepath.Dir(object.Key))
+			}
+		}
+	}
+
+	backups := make([]*stork_api.ApplicationBackup, 0)
+	seen := make(map[string]bool)
+	for _, backupPath := range backupPaths {
+		if seen[backupPath] {
+			continue
+		}
+		seen[backupPath] = true
+		backup, err := ReadBackupMetadata(backupLocation, bucket, backupPath)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup)
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].Status.TriggerTimestamp.Before(&backups[j].Status.TriggerTimestamp)
+	})
+	return backups, nil
+}
+
+// ValidateBackupPath checks that a backup path given by a user is a clean
+// relative path under the given namespace, so that it can't be used to read
+// backups from other namespaces in the backup location
+func ValidateBackupPath(namespace string, backupPath string) error {
+	if backupPath != path.Clean(backupPath) || path.IsAbs(backupPath) {
+		return fmt.Errorf("backup path %v is not a clean relative path", backupPath)
+	}
+	for _, element := range strings.Split(backupPath, "/") {
+		if element == ".." {
+			return fmt.Errorf("backup path %v can't contain ..", backupPath)
+		}
+	}
+	if !strings.HasPrefix(backupPath, namespace+"/") {
+		return fmt.Errorf("backup path %v is not under namespace %v", backupPath, namespace)
+	}
+	return nil
+}
+
+// GetBackup returns the backup stored at the given path in the backup location
+func GetBackup(
+	backupLocation *stork_api.BackupLocation,
+	backupPath string,
+) (*stork_api.ApplicationBackup, error) {
+	bucket, err := GetBucket(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	return ReadBackupMetadata(backupLocation, bucket, backupPath)
+}
+
+// ReadBackupMetadata reads and decrypts the metadata for the backup stored at
+// the given path in the bucket for the backup location
+func ReadBackupMetadata(
+	backupLocation *stork_api.BackupLocation,
+	bucket *blob.Bucket,
+	backupPath string,
+) (*stork_api.ApplicationBackup, error) {
+	data, err := bucket.ReadAll(context.TODO(), filepath.Join(backupPath, BackupMetadataObjectName))
+	if err != nil {
+		return nil, fmt.Errorf("error reading metadata: %v", err)
+	}
+	if backupLocation.Location.EncryptionKey != "" {
+		if data, err = crypto.Decrypt(data, backupLocation.Location.EncryptionKey); err != nil {
+			return nil, fmt.Errorf("error decrypting metadata: %v", err)
+		}
+	}
+	backup := &stork_api.ApplicationBackup{}
+	if err = json.Unmarshal(data, backup); err != nil {
+		return nil, fmt.Errorf("error parsing metadata: %v", err)
+	}
+	backup.Status.BackupPath = backupPath
+	return backup, nil
+}
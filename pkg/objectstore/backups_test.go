@@ -0,0 +1,134 @@
+// +build unittest
+
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newFilesystemLocation(t *testing.T) *stork_api.BackupLocation {
+	return &stork_api.BackupLocation{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "location",
+			Namespace: "ns",
+		},
+		Location: stork_api.BackupLocationItem{
+			Type: stork_api.BackupLocationFilesystem,
+			Path: t.TempDir(),
+		},
+	}
+}
+
+func TestBackupIndex(t *testing.T) {
+	location := newFilesystemLocation(t)
+	bucket, err := GetBucket(location)
+	require.NoError(t, err, "Error getting bucket")
+	defer bucket.Close()
+
+	now := time.Now()
+	twoDaysAgo := now.Add(-48 * time.Hour)
+	err = AddBackupToIndex(location, bucket, "ns/backup1/uid1", twoDaysAgo)
+	require.NoError(t, err, "Error adding backup to index")
+	err = AddBackupToIndex(location, bucket, "ns/backup2/uid2", now)
+	require.NoError(t, err, "Error adding backup to index")
+
+	backupPaths, err := ListIndexedBackups(bucket, "ns", twoDaysAgo)
+	require.NoError(t, err, "Error listing index")
+	require.ElementsMatch(t, []string{"ns/backup1/uid1", "ns/backup2/uid2"}, backupPaths)
+
+	// Only the days since the given time should be listed
+	backupPaths, err = ListIndexedBackups(bucket, "ns", now)
+	require.NoError(t, err, "Error listing index")
+	require.Equal(t, []string{"ns/backup2/uid2"}, backupPaths)
+
+	// The whole index should be listed without a time
+	backupPaths, err = ListIndexedBackups(bucket, "ns", time.Time{})
+	require.NoError(t, err, "Error listing index")
+	require.ElementsMatch(t, []string{"ns/backup1/uid1", "ns/backup2/uid2"}, backupPaths)
+
+	// Index for other namespaces shouldn't be listed
+	backupPaths, err = ListIndexedBackups(bucket, "otherns", twoDaysAgo)
+	require.NoError(t, err, "Error listing index")
+	require.Empty(t, backupPaths)
+
+	err = RemoveBackupFromIndex(location, bucket, "ns/backup2/uid2", now)
+	require.NoError(t, err, "Error removing backup from index")
+	backupPaths, err = ListIndexedBackups(bucket, "ns", now)
+	require.NoError(t, err, "Error listing index")
+	require.Empty(t, backupPaths)
+
+	// Removing a backup that isn't in the index shouldn't fail
+	err = RemoveBackupFromIndex(location, bucket, "ns/backup2/uid2", now)
+	require.NoError(t, err, "Error removing missing backup from index")
+}
+
+func uploadTestBackup(t *testing.T, location *stork_api.BackupLocation, backupPath string, index bool) {
+	bucket, err := GetBucket(location)
+	require.NoError(t, err, "Error getting bucket")
+	defer bucket.Close()
+	backup := &stork_api.ApplicationBackup{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      filepath.Base(filepath.Dir(backupPath)),
+			Namespace: "ns",
+		},
+	}
+	data, err := json.Marshal(backup)
+	require.NoError(t, err, "Error encoding backup")
+	err = bucket.WriteAll(context.TODO(), filepath.Join(backupPath, BackupMetadataObjectName), data, nil)
+	require.NoError(t, err, "Error uploading metadata")
+	if index {
+		require.NoError(t, AddBackupToIndex(location, bucket, backupPath, time.Now()), "Error adding backup to index")
+	}
+}
+
+func TestListBackups(t *testing.T) {
+	location := newFilesystemLocation(t)
+
+	// Backups are found from their metadata if nothing has been indexed
+	uploadTestBackup(t, location, "ns/old/uid1", false)
+	backups, err := ListBackups(location)
+	require.NoError(t, err, "Error listing backups")
+	require.Len(t, backups, 1)
+	require.Equal(t, "ns/old/uid1", backups[0].Status.BackupPath)
+
+	// Only the indexed backups are listed once there is an index
+	uploadTestBackup(t, location, "ns/new/uid2", true)
+	backups, err = ListBackups(location)
+	require.NoError(t, err, "Error listing backups")
+	require.Len(t, backups, 1)
+	require.Equal(t, "ns/new/uid2", backups[0].Status.BackupPath)
+	require.Equal(t, "new", backups[0].Name)
+}
+
+func TestValidateBackupPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		valid bool
+	}{
+		{path: "ns/backup/uid", valid: true},
+		{path: "ns/backup/uid/", valid: false},
+		{path: "/ns/backup/uid", valid: false},
+		{path: "ns/../otherns/backup/uid", valid: false},
+		{path: "ns/backup/../../otherns/backup/uid", valid: false},
+		{path: "../ns/backup/uid", valid: false},
+		{path: "otherns/backup/uid", valid: false},
+		{path: "ns", valid: false},
+		{path: "nsother/backup/uid", valid: false},
+	}
+	for _, test := range tests {
+		err := ValidateBackupPath("ns", test.path)
+		if test.valid {
+			require.NoError(t, err, "Path %v should be valid", test.path)
+		} else {
+			require.Error(t, err, "Path %v should be invalid", test.path)
+		}
+	}
+}
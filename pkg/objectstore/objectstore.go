@@ -1,22 +1,37 @@
 package objectstore
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"os"
 
 	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-pipeline-go/pipeline"
 	"github.com/Azure/azure-storage-blob-go/azblob"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"gocloud.dev/blob"
 	"gocloud.dev/blob/azureblob"
+	"gocloud.dev/blob/fileblob"
 	"gocloud.dev/blob/gcsblob"
 	"gocloud.dev/blob/s3blob"
 	"gocloud.dev/gcp"
 	"golang.org/x/oauth2/google"
-	//	"google.golang.org/api/option"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+const (
+	webIdentityTokenFileEnv = "AWS_WEB_IDENTITY_TOKEN_FILE"
 )
 
 // GetBucket gets the bucket handle for the given backup location
@@ -32,50 +47,255 @@ func GetBucket(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error) {
 		return getAzureBucket(backupLocation)
 	case stork_api.BackupLocationS3:
 		return getS3Bucket(backupLocation)
+	case stork_api.BackupLocationFilesystem:
+		return getFilesystemBucket(backupLocation)
 	default:
 		return nil, fmt.Errorf("invalid backupLocation type: %v", backupLocation.Location.Type)
 	}
 }
 
+// GetWriterOptions returns the options that should be used when writing
+// objects to the given backup location
+func GetWriterOptions(backupLocation *stork_api.BackupLocation) *blob.WriterOptions {
+	if backupLocation == nil || backupLocation.Location.Type != stork_api.BackupLocationS3 ||
+		backupLocation.Location.S3Config == nil {
+		return nil
+	}
+
+	s3Config := backupLocation.Location.S3Config
+	if s3Config.ServerSideEncryption == stork_api.S3ServerSideEncryptionNone &&
+		s3Config.StorageClass == "" {
+		return nil
+	}
+	return &blob.WriterOptions{
+		BeforeWrite: func(asFunc func(interface{}) bool) error {
+			var input *s3manager.UploadInput
+			if !asFunc(&input) {
+				return fmt.Errorf("unable to set S3 options for upload")
+			}
+			if s3Config.ServerSideEncryption != stork_api.S3ServerSideEncryptionNone {
+				input.ServerSideEncryption = aws.String(string(s3Config.ServerSideEncryption))
+				if s3Config.SSEKMSKeyID != "" {
+					input.SSEKMSKeyId = aws.String(s3Config.SSEKMSKeyID)
+				}
+			}
+			if s3Config.StorageClass != "" {
+				input.StorageClass = aws.String(s3Config.StorageClass)
+			}
+			return nil
+		},
+	}
+}
+
+// CreateBucket creates the bucket for the given backup location. No error is
+// returned if the bucket already exists
+func CreateBucket(backupLocation *stork_api.BackupLocation) error {
+	if backupLocation == nil {
+		return fmt.Errorf("nil backupLocation")
+	}
+
+	switch backupLocation.Location.Type {
+	case stork_api.BackupLocationGoogle:
+		return createGoogleBucket(backupLocation)
+	case stork_api.BackupLocationAzure:
+		return createAzureBucket(backupLocation)
+	case stork_api.BackupLocationS3:
+		return createS3Bucket(backupLocation)
+	case stork_api.BackupLocationFilesystem:
+		return createFilesystemBucket(backupLocation)
+	default:
+		return fmt.Errorf("invalid backupLocation type: %v", backupLocation.Location.Type)
+	}
+}
+
+func getS3Session(backupLocation *stork_api.BackupLocation) (*session.Session, error) {
+	s3Config := backupLocation.Location.S3Config
+	config := &aws.Config{
+		Endpoint:         aws.String(s3Config.Endpoint),
+		Region:           aws.String(s3Config.Region),
+		DisableSSL:       aws.Bool(s3Config.DisableSSL),
+		S3ForcePathStyle: aws.Bool(!s3Config.VirtualHostedStyle),
+	}
+	if s3Config.AuthType == stork_api.S3AuthStatic {
+		config.Credentials = credentials.NewStaticCredentials(s3Config.AccessKeyID,
+			s3Config.SecretAccessKey, "")
+	}
+
+	options := session.Options{
+		Config: *config,
+	}
+	if s3Config.CACert != "" {
+		options.CustomCABundle = bytes.NewReader([]byte(s3Config.CACert))
+	}
+	sess, err := session.NewSessionWithOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	switch s3Config.AuthType {
+	case stork_api.S3AuthIAM:
+		// The session uses the default credential chain, only need to
+		// override it if a role needs to be assumed
+		if s3Config.RoleARN != "" {
+			sess = sess.Copy(&aws.Config{
+				Credentials: stscreds.NewCredentials(sess, s3Config.RoleARN),
+			})
+		}
+	case stork_api.S3AuthWebIdentity:
+		tokenFile := s3Config.WebIdentityTokenFile
+		if tokenFile == "" {
+			tokenFile = os.Getenv(webIdentityTokenFileEnv)
+		}
+		if tokenFile == "" {
+			return nil, fmt.Errorf("webIdentityTokenFile is required for authType %v", s3Config.AuthType)
+		}
+		sess = sess.Copy(&aws.Config{
+			Credentials: stscreds.NewWebIdentityCredentials(sess, s3Config.RoleARN, "", tokenFile),
+		})
+	}
+	return sess, nil
+}
+
 func getS3Bucket(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error) {
-	sess, err := session.NewSession(&aws.Config{
-		Endpoint: aws.String(backupLocation.Location.S3Config.Endpoint),
-		Credentials: credentials.NewStaticCredentials(backupLocation.Location.S3Config.AccessKeyID,
-			backupLocation.Location.S3Config.SecretAccessKey, ""),
-		Region:           aws.String(backupLocation.Location.S3Config.Region),
-		DisableSSL:       aws.Bool(backupLocation.Location.S3Config.DisableSSL),
-		S3ForcePathStyle: aws.Bool(true),
-	})
+	sess, err := getS3Session(backupLocation)
 	if err != nil {
 		return nil, err
 	}
 	return s3blob.OpenBucket(context.Background(), sess, backupLocation.Location.Path, nil)
 }
 
-func getAzureBucket(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error) {
+func createS3Bucket(backupLocation *stork_api.BackupLocation) error {
+	sess, err := getS3Session(backupLocation)
+	if err != nil {
+		return err
+	}
+	input := &s3.CreateBucketInput{
+		Bucket: aws.String(backupLocation.Location.Path),
+	}
+	// us-east-1 is the default and can't be specified as a location
+	// constraint
+	if region := backupLocation.Location.S3Config.Region; region != "" && region != "us-east-1" {
+		input.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+			LocationConstraint: aws.String(region),
+		}
+	}
+	_, err = s3.New(sess).CreateBucket(input)
+	if awsErr, ok := err.(awserr.Error); ok {
+		if awsErr.Code() == s3.ErrCodeBucketAlreadyOwnedByYou {
+			return nil
+		}
+	}
+	return err
+}
+
+func getAzurePipeline(backupLocation *stork_api.BackupLocation) (pipeline.Pipeline, error) {
 	accountName := azureblob.AccountName(backupLocation.Location.AzureConfig.StorageAccountName)
 	accountKey := azureblob.AccountKey(backupLocation.Location.AzureConfig.StorageAccountKey)
 	credential, err := azureblob.NewCredential(accountName, accountKey)
 	if err != nil {
 		return nil, err
 	}
-	pipeline := azureblob.NewPipeline(credential, azblob.PipelineOptions{})
+	return azureblob.NewPipeline(credential, azblob.PipelineOptions{}), nil
+}
+
+func getAzureBucket(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error) {
+	pipeline, err := getAzurePipeline(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	accountName := azureblob.AccountName(backupLocation.Location.AzureConfig.StorageAccountName)
 	return azureblob.OpenBucket(context.Background(), pipeline, accountName, backupLocation.Location.Path, nil)
 }
 
-func getGoogleBucket(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error) {
+func createAzureBucket(backupLocation *stork_api.BackupLocation) error {
+	pipeline, err := getAzurePipeline(backupLocation)
+	if err != nil {
+		return err
+	}
+	serviceURL, err := url.Parse(fmt.Sprintf("https://%s.blob.core.windows.net", backupLocation.Location.AzureConfig.StorageAccountName))
+	if err != nil {
+		return err
+	}
+	containerURL := azblob.NewServiceURL(*serviceURL, pipeline).NewContainerURL(backupLocation.Location.Path)
+	_, err = containerURL.Create(context.Background(), azblob.Metadata{}, azblob.PublicAccessNone)
+	if storageErr, ok := err.(azblob.StorageError); ok {
+		if storageErr.ServiceCode() == azblob.ServiceCodeContainerAlreadyExists {
+			return nil
+		}
+	}
+	return err
+}
+
+func getGoogleHTTPClient(backupLocation *stork_api.BackupLocation) (*gcp.HTTPClient, error) {
 	conf, err := google.JWTConfigFromJSON([]byte(backupLocation.Location.GoogleConfig.AccountKey), storage.ScopeFullControl)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
-	client, err := gcp.NewHTTPClient(
+	return gcp.NewHTTPClient(
 		gcp.DefaultTransport(),
-		conf.TokenSource(ctx))
+		conf.TokenSource(context.Background()))
+}
+
+func getGoogleBucket(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error) {
+	client, err := getGoogleHTTPClient(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	return gcsblob.OpenBucket(context.Background(), client, backupLocation.Location.Path, nil)
+}
+
+func createGoogleBucket(backupLocation *stork_api.BackupLocation) error {
+	httpClient, err := getGoogleHTTPClient(backupLocation)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx, option.WithHTTPClient(&httpClient.Client))
 	if err != nil {
+		return err
+	}
+	defer client.Close() // nolint: errcheck
+
+	err = client.Bucket(backupLocation.Location.Path).Create(ctx, backupLocation.Location.GoogleConfig.ProjectID, nil)
+	if apiErr, ok := err.(*googleapi.Error); ok {
+		if apiErr.Code == http.StatusConflict {
+			return nil
+		}
+	}
+	return err
+}
+
+func getFilesystemBucket(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error) {
+	if err := checkFilesystemPath(backupLocation); err != nil {
 		return nil, err
 	}
+	return fileblob.OpenBucket(backupLocation.Location.Path, nil)
+}
+
+// createFilesystemBucket only checks that the path exists since the directory
+// isn't created for filesystem backupLocations
+func createFilesystemBucket(backupLocation *stork_api.BackupLocation) error {
+	return checkFilesystemPath(backupLocation)
+}
 
-	return gcsblob.OpenBucket(ctx, client, backupLocation.Location.Path, nil)
+// checkFilesystemPath returns an error if the path for a filesystem
+// backupLocation isn't an existing directory. The directory isn't created if
+// it doesn't exist. It should either be a local path or a mount point that has
+// been set up for the stork pod, and creating it could end up writing backups
+// to the container filesystem
+func checkFilesystemPath(backupLocation *stork_api.BackupLocation) error {
+	if backupLocation.Location.Path == "" {
+		return fmt.Errorf("path is required for filesystem backupLocation")
+	}
+	info, err := os.Stat(backupLocation.Location.Path)
+	if err != nil {
+		return fmt.Errorf("error accessing path %v for backupLocation: %v", backupLocation.Location.Path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("path %v for backupLocation is not a directory", backupLocation.Location.Path)
+	}
+	return nil
 }
@@ -0,0 +1,172 @@
+// +build unittest
+
+package objectstore
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func newS3Location(s3Config *stork_api.S3Config) *stork_api.BackupLocation {
+	return &stork_api.BackupLocation{
+		Location: stork_api.BackupLocationItem{
+			Type:     stork_api.BackupLocationS3,
+			Path:     "bucket",
+			S3Config: s3Config,
+		},
+	}
+}
+
+func generateCACert(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Error generating key")
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "stork-test-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err, "Error creating certificate")
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestS3SessionStaticCredentials(t *testing.T) {
+	sess, err := getS3Session(newS3Location(&stork_api.S3Config{
+		Endpoint:        "s3.example.com",
+		Region:          "us-west-1",
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+	}))
+	require.NoError(t, err, "Error getting session")
+	value, err := sess.Config.Credentials.Get()
+	require.NoError(t, err, "Error getting credentials")
+	require.Equal(t, "access", value.AccessKeyID)
+	require.Equal(t, "secret", value.SecretAccessKey)
+	require.True(t, *sess.Config.S3ForcePathStyle, "Path style should be used by default")
+}
+
+func TestS3SessionCABundle(t *testing.T) {
+	sess, err := getS3Session(newS3Location(&stork_api.S3Config{
+		Endpoint: "s3.example.com",
+		CACert:   generateCACert(t),
+	}))
+	require.NoError(t, err, "Error getting session with CA bundle")
+	transport, ok := sess.Config.HTTPClient.Transport.(*http.Transport)
+	require.True(t, ok, "Transport should be set for the CA bundle")
+	require.NotNil(t, transport.TLSClientConfig.RootCAs, "CA bundle should be loaded")
+
+	_, err = getS3Session(newS3Location(&stork_api.S3Config{
+		Endpoint: "s3.example.com",
+		CACert:   "invalid",
+	}))
+	require.Error(t, err, "Invalid CA bundle should be rejected")
+}
+
+func TestS3SessionWebIdentity(t *testing.T) {
+	s3Config := &stork_api.S3Config{
+		Endpoint: "s3.example.com",
+		AuthType: stork_api.S3AuthWebIdentity,
+		RoleARN:  "arn:aws:iam::123456789012:role/stork",
+	}
+	// Make sure the token file isn't picked up from the environment
+	t.Setenv(webIdentityTokenFileEnv, "")
+	_, err := getS3Session(newS3Location(s3Config))
+	require.EqualError(t, err, "webIdentityTokenFile is required for authType webIdentity")
+
+	s3Config.WebIdentityTokenFile = "/var/run/token"
+	sess, err := getS3Session(newS3Location(s3Config))
+	require.NoError(t, err, "Error getting session with token file")
+	require.NotNil(t, sess.Config.Credentials, "Credentials should be set for web identity")
+}
+
+func TestWriterOptions(t *testing.T) {
+	require.Nil(t, GetWriterOptions(nil))
+	require.Nil(t, GetWriterOptions(&stork_api.BackupLocation{
+		Location: stork_api.BackupLocationItem{Type: stork_api.BackupLocationFilesystem},
+	}), "No options should be used for other locations")
+	require.Nil(t, GetWriterOptions(newS3Location(&stork_api.S3Config{})),
+		"No options should be used if encryption and storage class aren't set")
+
+	tests := []struct {
+		config       *stork_api.S3Config
+		sse          *string
+		kmsKeyID     *string
+		storageClass *string
+	}{
+		{
+			config: &stork_api.S3Config{ServerSideEncryption: stork_api.S3ServerSideEncryptionAES256},
+			sse:    strPtr("AES256"),
+		},
+		{
+			config: &stork_api.S3Config{
+				ServerSideEncryption: stork_api.S3ServerSideEncryptionKMS,
+				SSEKMSKeyID:          "key",
+			},
+			sse:      strPtr("aws:kms"),
+			kmsKeyID: strPtr("key"),
+		},
+		{
+			config:       &stork_api.S3Config{StorageClass: "STANDARD_IA"},
+			storageClass: strPtr("STANDARD_IA"),
+		},
+	}
+	for _, test := range tests {
+		options := GetWriterOptions(newS3Location(test.config))
+		require.NotNil(t, options, "Options should be set for %+v", test.config)
+		input := &s3manager.UploadInput{}
+		err := options.BeforeWrite(func(i interface{}) bool {
+			p, ok := i.(**s3manager.UploadInput)
+			if ok {
+				*p = input
+			}
+			return ok
+		})
+		require.NoError(t, err, "Error setting upload options")
+		require.Equal(t, test.sse, input.ServerSideEncryption)
+		require.Equal(t, test.kmsKeyID, input.SSEKMSKeyId)
+		require.Equal(t, test.storageClass, input.StorageClass)
+	}
+
+	options := GetWriterOptions(newS3Location(&stork_api.S3Config{StorageClass: "STANDARD_IA"}))
+	err := options.BeforeWrite(func(i interface{}) bool { return false })
+	require.Error(t, err, "Error should be returned if the upload input isn't available")
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestCreateFilesystemBucket(t *testing.T) {
+	location := newFilesystemLocation(t)
+	require.NoError(t, CreateBucket(location), "Error creating bucket for existing directory")
+
+	// Missing directories shouldn't be created since the mount for the
+	// location could be missing
+	path := location.Location.Path
+	location.Location.Path = filepath.Join(path, "missing")
+	require.Error(t, CreateBucket(location), "Bucket shouldn't be created for missing directory")
+	_, err := os.Stat(location.Location.Path)
+	require.True(t, os.IsNotExist(err), "Directory shouldn't have been created")
+
+	location.Location.Path = filepath.Join(path, "file")
+	require.NoError(t, ioutil.WriteFile(location.Location.Path, []byte("file"), 0600))
+	require.Error(t, CreateBucket(location), "Bucket shouldn't be created for a file")
+}
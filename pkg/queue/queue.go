@@ -0,0 +1,319 @@
+package queue
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// LimitsConfigMapName is the name of the config map in the admin
+	// namespace used to configure the limits for concurrent operations
+	LimitsConfigMapName = "stork-concurrency-limits"
+	// MaxOperationsPerDriverKey is the key in the config map for the max
+	// number of operations that can transfer volumes for a driver at a time
+	MaxOperationsPerDriverKey = "maxOperationsPerDriver"
+	// MaxOperationsPerNamespaceKey is the key in the config map for the max
+	// number of operations that can run in a namespace at a time
+	MaxOperationsPerNamespaceKey = "maxOperationsPerNamespace"
+	// MaxVolumesPerDriverKey is the key in the config map for the max number
+	// of volumes that can be transferred for a driver at a time
+	MaxVolumesPerDriverKey = "maxVolumesPerDriver"
+	// MaxVolumesPerNamespaceKey is the key in the config map for the max
+	// number of volumes that can be transferred in a namespace at a time
+	MaxVolumesPerNamespaceKey = "maxVolumesPerNamespace"
+
+	// Operations that are waiting are dropped from the queue if they haven't
+	// tried to acquire it for this long, so that objects that were deleted
+	// don't hold up the queue
+	staleTimeout = 5 * time.Minute
+)
+
+// Limits for the number of concurrent operations and volume transfers. A
+// limit of 0 means there is no limit
+type Limits struct {
+	MaxOperationsPerDriver    int
+	MaxOperationsPerNamespace int
+	MaxVolumesPerDriver       int
+	MaxVolumesPerNamespace    int
+}
+
+// Operation is a backup, restore, migration or clone that transfers data
+type Operation struct {
+	Kind              string
+	Namespace         string
+	Name              string
+	UID               types.UID
+	Priority          int
+	CreationTimestamp time.Time
+	// Volumes is the number of volumes transferred by the operation for each
+	// driver
+	Volumes map[string]int
+
+	lastSeen time.Time
+}
+
+func (o *Operation) totalVolumes() int {
+	total := 0
+	for _, count := range o.Volumes {
+		total += count
+	}
+	return total
+}
+
+// Queue limits the number of operations that can run at a time. Operations
+// that can't start are queued and started in order of their priority. Among
+// operations with the same priority, namespaces with fewer running operations
+// go first and then the oldest operations
+type Queue struct {
+	sync.Mutex
+	limits  Limits
+	running map[types.UID]*Operation
+	waiting map[types.UID]*Operation
+}
+
+var instance = New(Limits{})
+
+// Instance returns the queue shared by all the controllers
+func Instance() *Queue {
+	return instance
+}
+
+// New returns a queue with the given limits
+func New(limits Limits) *Queue {
+	return &Queue{
+		limits:  limits,
+		running: make(map[types.UID]*Operation),
+		waiting: make(map[types.UID]*Operation),
+	}
+}
+
+// Init loads the limits for the shared queue from the config map in the
+// admin namespace and watches it for updates
+func Init(adminNamespace string) error {
+	cm, err := core.Instance().GetConfigMap(LimitsConfigMapName, adminNamespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("error getting config map %v: %v", LimitsConfigMapName, err)
+		}
+		cm = &v1.ConfigMap{}
+		cm.Name = LimitsConfigMapName
+		cm.Namespace = adminNamespace
+	} else if err := updateLimits(cm); err != nil {
+		logrus.Errorf("Error loading concurrency limits: %v", err)
+	}
+
+	fn := func(object runtime.Object) error {
+		cm, ok := object.(*v1.ConfigMap)
+		if !ok {
+			return fmt.Errorf("invalid object type on configmap watch: %v", object)
+		}
+		return updateLimits(cm)
+	}
+	return core.Instance().WatchConfigMap(cm, fn)
+}
+
+func updateLimits(cm *v1.ConfigMap) error {
+	limits, err := ParseLimits(cm.Data)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Updating concurrency limits to %+v", limits)
+	instance.SetLimits(limits)
+	return nil
+}
+
+// ParseLimits parses the limits from the data in the config map
+func ParseLimits(data map[string]string) (Limits, error) {
+	limits := Limits{}
+	for key, limit := range map[string]*int{
+		MaxOperationsPerDriverKey:    &limits.MaxOperationsPerDriver,
+		MaxOperationsPerNamespaceKey: &limits.MaxOperationsPerNamespace,
+		MaxVolumesPerDriverKey:       &limits.MaxVolumesPerDriver,
+		MaxVolumesPerNamespaceKey:    &limits.MaxVolumesPerNamespace,
+	} {
+		value, ok := data[key]
+		if !ok || value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return Limits{}, fmt.Errorf("invalid value %v for %v", value, key)
+		}
+		*limit = parsed
+	}
+	return limits, nil
+}
+
+// GetPVCVolumes returns the number of PVCs in the namespaces for each driver
+func GetPVCVolumes(namespaces []string, selectors map[string]string) (map[string]int, error) {
+	volumes := make(map[string]int)
+	for _, namespace := range namespaces {
+		pvcList, err := core.Instance().GetPersistentVolumeClaims(namespace, selectors)
+		if err != nil {
+			return nil, fmt.Errorf("error getting list of volumes: %v", err)
+		}
+		for _, pvc := range pvcList.Items {
+			driverName, err := volume.GetPVCDriver(&pvc)
+			if err != nil {
+				continue
+			}
+			volumes[driverName]++
+		}
+	}
+	return volumes, nil
+}
+
+// SetLimits updates the limits for the queue. Operations that are already
+// running aren't affected
+func (q *Queue) SetLimits(limits Limits) {
+	q.Lock()
+	defer q.Unlock()
+	q.limits = limits
+}
+
+// Acquire checks whether the operation can start. If it can't the operation is
+// queued and its position in the queue, starting from 1, is returned. It
+// should be called periodically until the operation is allowed to start
+func (q *Queue) Acquire(op *Operation) (bool, int) {
+	q.Lock()
+	defer q.Unlock()
+	if _, ok := q.running[op.UID]; ok {
+		return true, 0
+	}
+
+	now := time.Now()
+	op.lastSeen = now
+	q.waiting[op.UID] = op
+	for uid, w := range q.waiting {
+		if now.Sub(w.lastSeen) > staleTimeout {
+			delete(q.waiting, uid)
+		}
+	}
+
+	// Go through the queue in order and reserve capacity for the operations
+	// that can start, so that a lower priority operation doesn't take the
+	// place of one ahead of it
+	used := q.getUsage()
+	for i, w := range q.sortedWaiting() {
+		if !used.fits(w, q.limits) {
+			if w.UID == op.UID {
+				return false, i + 1
+			}
+			continue
+		}
+		if w.UID == op.UID {
+			delete(q.waiting, op.UID)
+			q.running[op.UID] = op
+			logrus.Infof("Starting %v %v/%v", op.Kind, op.Namespace, op.Name)
+			return true, 0
+		}
+		used.add(w)
+	}
+	return false, len(q.waiting)
+}
+
+// Track marks an operation that has already started as running. Used for
+// operations that were started before the queue was initialized
+func (q *Queue) Track(op *Operation) {
+	q.Lock()
+	defer q.Unlock()
+	if _, ok := q.running[op.UID]; ok {
+		return
+	}
+	delete(q.waiting, op.UID)
+	q.running[op.UID] = op
+}
+
+// Release removes the operation from the queue once it is done so that queued
+// operations can start
+func (q *Queue) Release(uid types.UID) {
+	q.Lock()
+	defer q.Unlock()
+	delete(q.running, uid)
+	delete(q.waiting, uid)
+}
+
+func (q *Queue) sortedWaiting() []*Operation {
+	namespaceRunning := make(map[string]int)
+	for _, op := range q.running {
+		namespaceRunning[op.Namespace]++
+	}
+	waiting := make([]*Operation, 0, len(q.waiting))
+	for _, op := range q.waiting {
+		waiting = append(waiting, op)
+	}
+	sort.Slice(waiting, func(i, j int) bool {
+		if waiting[i].Priority != waiting[j].Priority {
+			return waiting[i].Priority > waiting[j].Priority
+		}
+		if namespaceRunning[waiting[i].Namespace] != namespaceRunning[waiting[j].Namespace] {
+			return namespaceRunning[waiting[i].Namespace] < namespaceRunning[waiting[j].Namespace]
+		}
+		if !waiting[i].CreationTimestamp.Equal(waiting[j].CreationTimestamp) {
+			return waiting[i].CreationTimestamp.Before(waiting[j].CreationTimestamp)
+		}
+		return waiting[i].UID < waiting[j].UID
+	})
+	return waiting
+}
+
+type usage struct {
+	driverOperations    map[string]int
+	namespaceOperations map[string]int
+	driverVolumes       map[string]int
+	namespaceVolumes    map[string]int
+}
+
+func (q *Queue) getUsage() *usage {
+	used := &usage{
+		driverOperations:    make(map[string]int),
+		namespaceOperations: make(map[string]int),
+		driverVolumes:       make(map[string]int),
+		namespaceVolumes:    make(map[string]int),
+	}
+	for _, op := range q.running {
+		used.add(op)
+	}
+	return used
+}
+
+func (u *usage) add(op *Operation) {
+	u.namespaceOperations[op.Namespace]++
+	u.namespaceVolumes[op.Namespace] += op.totalVolumes()
+	for driverName, count := range op.Volumes {
+		u.driverOperations[driverName]++
+		u.driverVolumes[driverName] += count
+	}
+}
+
+// fits checks if the operation can run without going over the limits. An
+// operation with more volumes than the volume limit is allowed to run once
+// nothing else is transferring volumes, otherwise it would never start
+func (u *usage) fits(op *Operation, limits Limits) bool {
+	if !withinLimit(u.namespaceOperations[op.Namespace], 1, limits.MaxOperationsPerNamespace) ||
+		!withinLimit(u.namespaceVolumes[op.Namespace], op.totalVolumes(), limits.MaxVolumesPerNamespace) {
+		return false
+	}
+	for driverName, count := range op.Volumes {
+		if !withinLimit(u.driverOperations[driverName], 1, limits.MaxOperationsPerDriver) ||
+			!withinLimit(u.driverVolumes[driverName], count, limits.MaxVolumesPerDriver) {
+			return false
+		}
+	}
+	return true
+}
+
+func withinLimit(current, requested, limit int) bool {
+	return limit == 0 || current == 0 || current+requested <= limit
+}
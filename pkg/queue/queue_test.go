@@ -0,0 +1,151 @@
+// +build unittest
+
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newOperation(uid string, namespace string, priority int, age time.Duration, volumes map[string]int) *Operation {
+	return &Operation{
+		Kind:              "Migration",
+		Namespace:         namespace,
+		Name:              uid,
+		UID:               types.UID(uid),
+		Priority:          priority,
+		CreationTimestamp: time.Now().Add(-age),
+		Volumes:           volumes,
+	}
+}
+
+func TestQueue(t *testing.T) {
+	t.Run("parseLimitsTest", parseLimitsTest)
+	t.Run("noLimitsTest", noLimitsTest)
+	t.Run("driverLimitsTest", driverLimitsTest)
+	t.Run("priorityTest", priorityTest)
+	t.Run("namespaceFairnessTest", namespaceFairnessTest)
+	t.Run("volumeLimitsTest", volumeLimitsTest)
+	t.Run("trackTest", trackTest)
+}
+
+func parseLimitsTest(t *testing.T) {
+	limits, err := ParseLimits(map[string]string{
+		MaxOperationsPerDriverKey:    "2",
+		MaxOperationsPerNamespaceKey: "1",
+		MaxVolumesPerDriverKey:       "10",
+	})
+	require.NoError(t, err, "Error parsing limits")
+	require.Equal(t, Limits{
+		MaxOperationsPerDriver:    2,
+		MaxOperationsPerNamespace: 1,
+		MaxVolumesPerDriver:       10,
+	}, limits)
+
+	_, err = ParseLimits(map[string]string{MaxVolumesPerNamespaceKey: "abc"})
+	require.Error(t, err, "Expected error for invalid limit")
+	_, err = ParseLimits(map[string]string{MaxVolumesPerNamespaceKey: "-1"})
+	require.Error(t, err, "Expected error for negative limit")
+}
+
+func noLimitsTest(t *testing.T) {
+	q := New(Limits{})
+	for _, uid := range []string{"a", "b", "c"} {
+		started, position := q.Acquire(newOperation(uid, "ns", 0, 0, map[string]int{"pxd": 5}))
+		require.True(t, started, "Operation should have started")
+		require.Equal(t, 0, position)
+	}
+}
+
+func driverLimitsTest(t *testing.T) {
+	q := New(Limits{MaxOperationsPerDriver: 1})
+	started, _ := q.Acquire(newOperation("a", "ns1", 0, 3*time.Minute, map[string]int{"pxd": 1}))
+	require.True(t, started, "First operation should have started")
+	started, position := q.Acquire(newOperation("b", "ns2", 0, 2*time.Minute, map[string]int{"pxd": 1}))
+	require.False(t, started, "Second operation should be queued")
+	require.Equal(t, 1, position)
+	started, position = q.Acquire(newOperation("c", "ns3", 0, time.Minute, map[string]int{"pxd": 1}))
+	require.False(t, started, "Third operation should be queued")
+	require.Equal(t, 2, position)
+
+	// Operations for other drivers aren't affected
+	started, _ = q.Acquire(newOperation("d", "ns1", 0, 0, map[string]int{"aws": 1}))
+	require.True(t, started, "Operation for another driver should have started")
+
+	q.Release("a")
+	started, _ = q.Acquire(newOperation("c", "ns3", 0, time.Minute, map[string]int{"pxd": 1}))
+	require.False(t, started, "Operation behind in the queue shouldn't start first")
+	started, _ = q.Acquire(newOperation("b", "ns2", 0, 2*time.Minute, map[string]int{"pxd": 1}))
+	require.True(t, started, "Operation at the head of the queue should have started")
+}
+
+func priorityTest(t *testing.T) {
+	q := New(Limits{MaxOperationsPerDriver: 1})
+	started, _ := q.Acquire(newOperation("a", "ns", 0, 0, map[string]int{"pxd": 1}))
+	require.True(t, started, "First operation should have started")
+	_, position := q.Acquire(newOperation("low", "ns", 0, 2*time.Minute, map[string]int{"pxd": 1}))
+	require.Equal(t, 1, position)
+	_, position = q.Acquire(newOperation("high", "ns", 10, time.Minute, map[string]int{"pxd": 1}))
+	require.Equal(t, 1, position, "Higher priority operation should be at the head of the queue")
+	_, position = q.Acquire(newOperation("low", "ns", 0, 2*time.Minute, map[string]int{"pxd": 1}))
+	require.Equal(t, 2, position)
+
+	q.Release("a")
+	started, _ = q.Acquire(newOperation("low", "ns", 0, 2*time.Minute, map[string]int{"pxd": 1}))
+	require.False(t, started, "Lower priority operation shouldn't start first")
+	started, _ = q.Acquire(newOperation("high", "ns", 10, time.Minute, map[string]int{"pxd": 1}))
+	require.True(t, started, "Higher priority operation should have started")
+}
+
+func namespaceFairnessTest(t *testing.T) {
+	q := New(Limits{MaxOperationsPerDriver: 2})
+	started, _ := q.Acquire(newOperation("a", "busy", 0, 0, map[string]int{"pxd": 1}))
+	require.True(t, started, "First operation should have started")
+	started, _ = q.Acquire(newOperation("b", "other", 0, 0, map[string]int{"pxd": 1}))
+	require.True(t, started, "Second operation should have started")
+
+	_, position := q.Acquire(newOperation("c", "busy", 0, 2*time.Minute, map[string]int{"pxd": 1}))
+	require.Equal(t, 1, position)
+	_, position = q.Acquire(newOperation("d", "idle", 0, time.Minute, map[string]int{"pxd": 1}))
+	require.Equal(t, 1, position, "Operation from namespace without running operations should go first")
+
+	q.Release("b")
+	started, _ = q.Acquire(newOperation("d", "idle", 0, time.Minute, map[string]int{"pxd": 1}))
+	require.True(t, started, "Operation from idle namespace should have started")
+}
+
+func volumeLimitsTest(t *testing.T) {
+	q := New(Limits{MaxVolumesPerNamespace: 4})
+	started, _ := q.Acquire(newOperation("a", "ns", 0, 0, map[string]int{"pxd": 3}))
+	require.True(t, started, "First operation should have started")
+	started, _ = q.Acquire(newOperation("b", "ns", 0, 0, map[string]int{"pxd": 2}))
+	require.False(t, started, "Operation over the volume limit should be queued")
+	started, _ = q.Acquire(newOperation("c", "other", 0, 0, map[string]int{"pxd": 2}))
+	require.True(t, started, "Operation in another namespace should have started")
+
+	// An operation larger than the limit runs once nothing else is running
+	q.Release("a")
+	started, _ = q.Acquire(newOperation("big", "ns", 0, time.Hour, map[string]int{"pxd": 10}))
+	require.True(t, started, "Operation larger than the limit should start when nothing else is running")
+	started, _ = q.Acquire(newOperation("b", "ns", 0, 0, map[string]int{"pxd": 2}))
+	require.False(t, started, "Operation should be queued behind the large operation")
+	q.Release("big")
+	started, _ = q.Acquire(newOperation("b", "ns", 0, 0, map[string]int{"pxd": 2}))
+	require.True(t, started, "Operation should have started after the large operation was released")
+}
+
+func trackTest(t *testing.T) {
+	q := New(Limits{MaxOperationsPerNamespace: 1})
+	q.Track(newOperation("a", "ns", 0, 0, map[string]int{"pxd": 1}))
+	started, position := q.Acquire(newOperation("b", "ns", 0, 0, map[string]int{"pxd": 1}))
+	require.False(t, started, "Operation should be queued behind tracked operation")
+	require.Equal(t, 1, position)
+	started, _ = q.Acquire(newOperation("a", "ns", 0, 0, map[string]int{"pxd": 1}))
+	require.True(t, started, "Tracked operation should be running")
+	q.Release("a")
+	started, _ = q.Acquire(newOperation("b", "ns", 0, 0, map[string]int{"pxd": 1}))
+	require.True(t, started, "Operation should have started after release")
+}
@@ -0,0 +1,234 @@
+package resourcecollector
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/portworx/sched-ops/k8s/core"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ApplicationRegistryConfigMapName is the name of the config map in the
+	// admin namespace with the kinds of applications that are suspended when
+	// they are migrated. Each key in the config map has an
+	// ApplicationSuspendOptions in YAML or JSON format. Entries override the
+	// defaults for the same group and kind
+	ApplicationRegistryConfigMapName = "stork-application-registry"
+)
+
+// ApplicationSuspendOptions describes how an application of a kind is
+// suspended on the destination cluster after it is migrated
+type ApplicationSuspendOptions struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// Path is the path of the field that suspends the application,
+	// separated by ".", for example spec.replicas
+	Path string `json:"path"`
+	// Value is the value the field is set to when the application is
+	// suspended
+	Value interface{} `json:"value"`
+	// DefaultValue is the value of the field when it isn't set. If it isn't
+	// set the field is removed when the application is resumed
+	DefaultValue interface{} `json:"defaultValue,omitempty"`
+}
+
+var defaultApplicationRegistry = []*ApplicationSuspendOptions{
+	newReplicasSuspendOptions("apps", "v1", "Deployment"),
+	newReplicasSuspendOptions("apps", "v1", "StatefulSet"),
+	newReplicasSuspendOptions("apps.openshift.io", "v1", "DeploymentConfig"),
+	newReplicasSuspendOptions("ibp.com", "v1alpha1", "IBPPeer"),
+	newReplicasSuspendOptions("ibp.com", "v1alpha1", "IBPCA"),
+	newReplicasSuspendOptions("ibp.com", "v1alpha1", "IBPOrderer"),
+	newReplicasSuspendOptions("ibp.com", "v1alpha1", "IBPConsole"),
+	{
+		Group:        "batch",
+		Version:      "v1beta1",
+		Kind:         "CronJob",
+		Path:         "spec.suspend",
+		Value:        true,
+		DefaultValue: false,
+	},
+}
+
+func newReplicasSuspendOptions(group, version, kind string) *ApplicationSuspendOptions {
+	return &ApplicationSuspendOptions{
+		Group:        group,
+		Version:      version,
+		Kind:         kind,
+		Path:         "spec.replicas",
+		Value:        int64(0),
+		DefaultValue: int64(1),
+	}
+}
+
+// GetApplicationRegistry returns the kinds of applications that are suspended
+// when they are migrated. The defaults are merged with the entries from the
+// registry config map in the admin namespace on the cluster, if it exists
+func GetApplicationRegistry(coreOps core.Ops, adminNamespace string) ([]*ApplicationSuspendOptions, error) {
+	registry := make(map[string]*ApplicationSuspendOptions)
+	for _, options := range defaultApplicationRegistry {
+		registry[options.key()] = options
+	}
+
+	configMap, err := coreOps.GetConfigMap(ApplicationRegistryConfigMapName, adminNamespace)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("error getting application registry: %v", err)
+	} else if err == nil {
+		// Sort the keys so that duplicate entries are resolved the same way
+		// every time
+		keys := make([]string, 0, len(configMap.Data))
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			options := &ApplicationSuspendOptions{}
+			if err := yaml.Unmarshal([]byte(configMap.Data[key]), options, func(d *json.Decoder) *json.Decoder {
+				d.UseNumber()
+				return d
+			}); err != nil {
+				return nil, fmt.Errorf("error parsing application registry entry %v: %v", key, err)
+			}
+			if err := options.validate(); err != nil {
+				return nil, fmt.Errorf("invalid application registry entry %v: %v", key, err)
+			}
+			options.Value = normalizeValue(options.Value)
+			options.DefaultValue = normalizeValue(options.DefaultValue)
+			registry[options.key()] = options
+		}
+	}
+
+	applications := make([]*ApplicationSuspendOptions, 0, len(registry))
+	for _, options := range registry {
+		applications = append(applications, options)
+	}
+	sort.Slice(applications, func(i, j int) bool {
+		return applications[i].key() < applications[j].key()
+	})
+	return applications, nil
+}
+
+// GetApplicationSuspendOptions returns the options from the registry for the
+// kind of an object, or nil if objects of the kind don't need to be suspended
+func GetApplicationSuspendOptions(
+	registry []*ApplicationSuspendOptions,
+	gvk schema.GroupVersionKind,
+) *ApplicationSuspendOptions {
+	for _, options := range registry {
+		if options.Group == gvk.Group && options.Kind == gvk.Kind {
+			return options
+		}
+	}
+	return nil
+}
+
+// TypeMeta returns the type of the applications, which can be used to list
+// them
+func (a *ApplicationSuspendOptions) TypeMeta() metav1.TypeMeta {
+	return metav1.TypeMeta{
+		Kind:       a.Kind,
+		APIVersion: schema.GroupVersion{Group: a.Group, Version: a.Version}.String(),
+	}
+}
+
+// GetValue returns the current value of the suspend field of an object, or
+// the default value if it isn't set
+func (a *ApplicationSuspendOptions) GetValue(content map[string]interface{}) (interface{}, error) {
+	value, found, err := unstructured.NestedFieldCopy(content, a.fields()...)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return a.DefaultValue, nil
+	}
+	return value, nil
+}
+
+// SetValue updates the suspend field of an object. The field is removed if
+// the value is nil
+func (a *ApplicationSuspendOptions) SetValue(content map[string]interface{}, value interface{}) error {
+	if value == nil {
+		a.RemoveValue(content)
+		return nil
+	}
+	return unstructured.SetNestedField(content, value, a.fields()...)
+}
+
+// RemoveValue removes the suspend field from an object
+func (a *ApplicationSuspendOptions) RemoveValue(content map[string]interface{}) {
+	unstructured.RemoveNestedField(content, a.fields()...)
+}
+
+// EncodeValue returns the value of a field in the format it is saved in
+// annotations
+func EncodeValue(value interface{}) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// DecodeValue parses a value that was saved in an annotation with
+// EncodeValue
+func DecodeValue(encoded string) (interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return normalizeValue(value), nil
+}
+
+func (a *ApplicationSuspendOptions) key() string {
+	return a.Group + "/" + a.Kind
+}
+
+func (a *ApplicationSuspendOptions) fields() []string {
+	return strings.Split(a.Path, ".")
+}
+
+func (a *ApplicationSuspendOptions) validate() error {
+	if a.Kind == "" || a.Version == "" {
+		return fmt.Errorf("kind and version need to be specified")
+	}
+	if a.Path == "" {
+		return fmt.Errorf("path needs to be specified")
+	}
+	if a.Value == nil {
+		return fmt.Errorf("value needs to be specified")
+	}
+	return nil
+}
+
+// normalizeValue converts numbers to the types used in unstructured objects
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]interface{}:
+		for key, nested := range v {
+			v[key] = normalizeValue(nested)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = normalizeValue(nested)
+		}
+	}
+	return value
+}
@@ -0,0 +1,85 @@
+// +build unittest
+
+package resourcecollector
+
+import (
+	"testing"
+
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestApplicationRegistry(t *testing.T) {
+	fakeKubeClient := kubernetes.NewSimpleClientset()
+	coreOps := core.New(fakeKubeClient, fakeKubeClient.CoreV1(), fakeKubeClient.StorageV1())
+
+	// Only the defaults without the config map
+	registry, err := GetApplicationRegistry(coreOps, "admin")
+	require.NoError(t, err, "Error getting application registry")
+	require.Len(t, registry, len(defaultApplicationRegistry), "Registry should only have the defaults")
+	options := GetApplicationSuspendOptions(registry, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	require.NotNil(t, options, "Deployments should be in the registry")
+	require.Equal(t, "spec.replicas", options.Path)
+	require.Nil(t, GetApplicationSuspendOptions(registry, schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}))
+
+	_, err = coreOps.CreateConfigMap(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ApplicationRegistryConfigMapName,
+			Namespace: "admin",
+		},
+		Data: map[string]string{
+			"database": "group: example.com\nversion: v1\nkind: Database\npath: spec.instances\nvalue: 0\ndefaultValue: 3\n",
+			"deployment": `{"group": "apps", "version": "v1", "kind": "Deployment", "path": "spec.paused", "value": true}`,
+		},
+	})
+	require.NoError(t, err, "Error creating application registry config map")
+
+	registry, err = GetApplicationRegistry(coreOps, "admin")
+	require.NoError(t, err, "Error getting application registry")
+	require.Len(t, registry, len(defaultApplicationRegistry)+1, "Registry should have the new kind")
+
+	options = GetApplicationSuspendOptions(registry, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	require.NotNil(t, options, "Deployments should be in the registry")
+	require.Equal(t, "spec.paused", options.Path, "Default should be overridden")
+	require.Equal(t, true, options.Value)
+
+	options = GetApplicationSuspendOptions(registry, schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Database"})
+	require.NotNil(t, options, "Database should be in the registry")
+	require.Equal(t, int64(0), options.Value, "Numbers should be parsed as integers")
+	require.Equal(t, "example.com/v1", options.TypeMeta().APIVersion)
+
+	content := map[string]interface{}{"spec": map[string]interface{}{}}
+	value, err := options.GetValue(content)
+	require.NoError(t, err, "Error getting value")
+	require.Equal(t, int64(3), value, "Default value should be returned when the field isn't set")
+	encoded, err := EncodeValue(value)
+	require.NoError(t, err, "Error encoding value")
+	require.Equal(t, "3", encoded)
+
+	err = options.SetValue(content, options.Value)
+	require.NoError(t, err, "Error setting value")
+	value, err = options.GetValue(content)
+	require.NoError(t, err, "Error getting value")
+	require.Equal(t, int64(0), value)
+
+	decoded, err := DecodeValue(encoded)
+	require.NoError(t, err, "Error decoding value")
+	require.Equal(t, int64(3), decoded)
+
+	_, err = coreOps.UpdateConfigMap(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ApplicationRegistryConfigMapName,
+			Namespace: "admin",
+		},
+		Data: map[string]string{
+			"invalid": "kind: Database\nversion: v1\n",
+		},
+	})
+	require.NoError(t, err, "Error updating application registry config map")
+	_, err = GetApplicationRegistry(coreOps, "admin")
+	require.Error(t, err, "Entries without a path should be rejected")
+}
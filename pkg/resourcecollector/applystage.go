@@ -0,0 +1,290 @@
+package resourcecollector
+
+import (
+	"fmt"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	defaultReadinessTimeout = 5 * time.Minute
+	storageClassAnnotation  = "volume.beta.kubernetes.io/storage-class"
+)
+
+var storageClassResource = schema.GroupVersionResource{
+	Group:    "storage.k8s.io",
+	Version:  "v1",
+	Resource: "storageclasses",
+}
+
+// applyStageOrder is the order in which the stages are applied
+var applyStageOrder = []stork_api.ApplyStageType{
+	stork_api.ApplyStageNamespaces,
+	stork_api.ApplyStageCRDs,
+	stork_api.ApplyStageRBAC,
+	stork_api.ApplyStageConfig,
+	stork_api.ApplyStageStorage,
+	stork_api.ApplyStageWorkloads,
+}
+
+// ApplyStageObjects are the objects to be applied in a stage
+type ApplyStageObjects struct {
+	Stage   stork_api.ApplyStageType
+	Objects []runtime.Unstructured
+}
+
+// GetApplyStage returns the stage in which the object should be applied
+func GetApplyStage(object runtime.Unstructured) (stork_api.ApplyStageType, error) {
+	objectType, err := meta.TypeAccessor(object)
+	if err != nil {
+		return "", err
+	}
+	switch objectType.GetKind() {
+	case "Namespace":
+		return stork_api.ApplyStageNamespaces, nil
+	case "CustomResourceDefinition":
+		return stork_api.ApplyStageCRDs, nil
+	case "ServiceAccount",
+		"Role",
+		"RoleBinding",
+		"ClusterRole",
+		"ClusterRoleBinding":
+		return stork_api.ApplyStageRBAC, nil
+	case "ConfigMap",
+		"Secret",
+		"ImageStream",
+		"Template":
+		return stork_api.ApplyStageConfig, nil
+	case "PersistentVolume",
+		"PersistentVolumeClaim":
+		return stork_api.ApplyStageStorage, nil
+	}
+	return stork_api.ApplyStageWorkloads, nil
+}
+
+// GroupObjectsByApplyStage groups the objects by the stage in which they
+// should be applied. The stages are returned in the order they should be
+// applied and stages without any objects are skipped. The order of objects
+// within a stage is preserved
+func GroupObjectsByApplyStage(objects []runtime.Unstructured) ([]*ApplyStageObjects, error) {
+	stageObjects := make(map[stork_api.ApplyStageType][]runtime.Unstructured)
+	for _, o := range objects {
+		stage, err := GetApplyStage(o)
+		if err != nil {
+			return nil, err
+		}
+		stageObjects[stage] = append(stageObjects[stage], o)
+	}
+
+	stages := make([]*ApplyStageObjects, 0)
+	for _, stage := range applyStageOrder {
+		if len(stageObjects[stage]) == 0 {
+			continue
+		}
+		stages = append(stages, &ApplyStageObjects{
+			Stage:   stage,
+			Objects: stageObjects[stage],
+		})
+	}
+	return stages, nil
+}
+
+// GetReadinessTimeout returns the timeout of the readiness gate for the
+// stage. Returns false if no readiness gate is configured for the stage
+func GetReadinessTimeout(
+	readinessGates []stork_api.ReadinessGate,
+	stage stork_api.ApplyStageType,
+) (time.Duration, bool) {
+	for _, gate := range readinessGates {
+		if gate.Stage != stage {
+			continue
+		}
+		if gate.TimeoutSeconds <= 0 {
+			return defaultReadinessTimeout, true
+		}
+		return time.Duration(gate.TimeoutSeconds) * time.Second, true
+	}
+	return 0, false
+}
+
+// NewApplyStageInfos returns the status for each of the stages in which
+// objects are applied, with all of them pending
+func NewApplyStageInfos(stages []*ApplyStageObjects) []*stork_api.ApplyStageInfo {
+	stageInfos := make([]*stork_api.ApplyStageInfo, 0)
+	for _, stage := range stages {
+		stageInfos = append(stageInfos, &stork_api.ApplyStageInfo{
+			Stage:     stage.Stage,
+			Status:    stork_api.ApplyStageStatusPending,
+			Resources: len(stage.Objects),
+		})
+	}
+	return stageInfos
+}
+
+// ReadinessTimedOut returns true if the objects in a stage haven't been ready
+// within the timeout, which starts when the stage is started
+func ReadinessTimedOut(stageInfo *stork_api.ApplyStageInfo, timeout time.Duration) bool {
+	return time.Since(stageInfo.StartTimestamp.Time) > timeout
+}
+
+// NewObjectReference returns an object with only its type, name and namespace
+// set. It can be used to check whether a resource that has already been
+// applied is ready
+func NewObjectReference(gvk schema.GroupVersionKind, namespace string, name string) runtime.Unstructured {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(gvk)
+	object.SetNamespace(namespace)
+	object.SetName(name)
+	return object
+}
+
+// CheckResourcesReady checks once if the objects are ready using the provided
+// client interface. An error is returned for the first object that isn't
+// ready. Objects which don't have a readiness check are considered ready once
+// they have been created
+func (r *ResourceCollector) CheckResourcesReady(
+	dynamicInterface dynamic.Interface,
+	objects []runtime.Unstructured,
+) error {
+	for _, o := range objects {
+		ready, err := r.resourceReady(dynamicInterface, o)
+		if err != nil {
+			return err
+		}
+		if !ready {
+			metadata, err := meta.Accessor(o)
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%v %v/%v is not ready",
+				o.GetObjectKind().GroupVersionKind().Kind, metadata.GetNamespace(), metadata.GetName())
+		}
+	}
+	return nil
+}
+
+func (r *ResourceCollector) resourceReady(
+	dynamicInterface dynamic.Interface,
+	object runtime.Unstructured,
+) (bool, error) {
+	kind := object.GetObjectKind().GroupVersionKind().Kind
+	switch kind {
+	case "PersistentVolumeClaim",
+		"Deployment",
+		"StatefulSet",
+		"DaemonSet",
+		"CustomResourceDefinition":
+	default:
+		return true, nil
+	}
+
+	metadata, err := meta.Accessor(object)
+	if err != nil {
+		return false, err
+	}
+	dynamicClient, err := r.getDynamicClient(dynamicInterface, object)
+	if err != nil {
+		return false, err
+	}
+	current, err := dynamicClient.Get(metadata.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	content := current.UnstructuredContent()
+
+	switch kind {
+	case "PersistentVolumeClaim":
+		phase, _, err := unstructured.NestedString(content, "status", "phase")
+		if err != nil || phase == "Bound" {
+			return phase == "Bound", err
+		}
+		// PVCs from classes that wait for the first consumer won't be bound
+		// until the pods using them are created in a later stage
+		if phase == "Pending" {
+			return waitingForFirstConsumer(dynamicInterface, content)
+		}
+		return false, nil
+	case "Deployment":
+		replicas, err := getSpecReplicas(content)
+		if err != nil {
+			return false, err
+		}
+		available, _, err := unstructured.NestedInt64(content, "status", "availableReplicas")
+		return available >= replicas, err
+	case "StatefulSet":
+		replicas, err := getSpecReplicas(content)
+		if err != nil {
+			return false, err
+		}
+		ready, _, err := unstructured.NestedInt64(content, "status", "readyReplicas")
+		return ready >= replicas, err
+	case "DaemonSet":
+		desired, _, err := unstructured.NestedInt64(content, "status", "desiredNumberScheduled")
+		if err != nil {
+			return false, err
+		}
+		ready, _, err := unstructured.NestedInt64(content, "status", "numberReady")
+		return ready >= desired, err
+	case "CustomResourceDefinition":
+		conditions, _, err := unstructured.NestedSlice(content, "status", "conditions")
+		if err != nil {
+			return false, err
+		}
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if condition["type"] == "Established" && condition["status"] == "True" {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// waitingForFirstConsumer returns true if the storage class of the PVC has the
+// WaitForFirstConsumer volume binding mode
+func waitingForFirstConsumer(
+	dynamicInterface dynamic.Interface,
+	content map[string]interface{},
+) (bool, error) {
+	storageClassName, _, err := unstructured.NestedString(content, "spec", "storageClassName")
+	if err != nil {
+		return false, err
+	}
+	if storageClassName == "" {
+		storageClassName, _, err = unstructured.NestedString(content, "metadata", "annotations", storageClassAnnotation)
+		if err != nil || storageClassName == "" {
+			return false, err
+		}
+	}
+	storageClass, err := dynamicInterface.Resource(storageClassResource).Get(storageClassName, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	mode, _, err := unstructured.NestedString(storageClass.UnstructuredContent(), "volumeBindingMode")
+	return mode == string(storagev1.VolumeBindingWaitForFirstConsumer), err
+}
+
+// getSpecReplicas returns the number of replicas from the spec, which defaults
+// to 1 if it isn't set
+func getSpecReplicas(content map[string]interface{}) (int64, error) {
+	replicas, found, err := unstructured.NestedInt64(content, "spec", "replicas")
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 1, nil
+	}
+	return replicas, nil
+}
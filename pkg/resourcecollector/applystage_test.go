@@ -0,0 +1,199 @@
+// +build unittest
+
+package resourcecollector
+
+import (
+	"testing"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newTestObject(apiVersion string, kind string, namespace string, name string) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion(apiVersion)
+	object.SetKind(kind)
+	object.SetNamespace(namespace)
+	object.SetName(name)
+	return object
+}
+
+func TestGroupObjectsByApplyStage(t *testing.T) {
+	tests := []struct {
+		name     string
+		objects  []runtime.Unstructured
+		expected map[stork_api.ApplyStageType][]string
+		order    []stork_api.ApplyStageType
+	}{
+		{
+			name:     "no objects",
+			objects:  nil,
+			expected: map[stork_api.ApplyStageType][]string{},
+			order:    []stork_api.ApplyStageType{},
+		},
+		{
+			name: "all stages in order",
+			objects: []runtime.Unstructured{
+				newTestObject("apps/v1", "Deployment", "ns", "deploy"),
+				newTestObject("v1", "PersistentVolumeClaim", "ns", "pvc"),
+				newTestObject("v1", "ConfigMap", "ns", "config"),
+				newTestObject("rbac.authorization.k8s.io/v1", "Role", "ns", "role"),
+				newTestObject("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "", "crd"),
+				newTestObject("v1", "Namespace", "", "ns"),
+				newTestObject("v1", "PersistentVolume", "", "pv"),
+				newTestObject("v1", "Secret", "ns", "secret"),
+				newTestObject("v1", "Service", "ns", "svc"),
+			},
+			expected: map[stork_api.ApplyStageType][]string{
+				stork_api.ApplyStageNamespaces: {"ns"},
+				stork_api.ApplyStageCRDs:       {"crd"},
+				stork_api.ApplyStageRBAC:       {"role"},
+				stork_api.ApplyStageConfig:     {"config", "secret"},
+				stork_api.ApplyStageStorage:    {"pvc", "pv"},
+				stork_api.ApplyStageWorkloads:  {"deploy", "svc"},
+			},
+			order: []stork_api.ApplyStageType{
+				stork_api.ApplyStageNamespaces,
+				stork_api.ApplyStageCRDs,
+				stork_api.ApplyStageRBAC,
+				stork_api.ApplyStageConfig,
+				stork_api.ApplyStageStorage,
+				stork_api.ApplyStageWorkloads,
+			},
+		},
+		{
+			name: "empty stages are skipped",
+			objects: []runtime.Unstructured{
+				newTestObject("example.com/v1", "Database", "ns", "db"),
+				newTestObject("v1", "ServiceAccount", "ns", "sa"),
+			},
+			expected: map[stork_api.ApplyStageType][]string{
+				stork_api.ApplyStageRBAC:      {"sa"},
+				stork_api.ApplyStageWorkloads: {"db"},
+			},
+			order: []stork_api.ApplyStageType{
+				stork_api.ApplyStageRBAC,
+				stork_api.ApplyStageWorkloads,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stages, err := GroupObjectsByApplyStage(test.objects)
+			require.NoError(t, err, "Error grouping objects")
+			order := make([]stork_api.ApplyStageType, 0)
+			for _, stage := range stages {
+				order = append(order, stage.Stage)
+				names := make([]string, 0)
+				for _, o := range stage.Objects {
+					names = append(names, o.(*unstructured.Unstructured).GetName())
+				}
+				require.Equal(t, test.expected[stage.Stage], names, "Unexpected objects for stage %v", stage.Stage)
+			}
+			require.Equal(t, test.order, order)
+
+			stageInfos := NewApplyStageInfos(stages)
+			require.Len(t, stageInfos, len(stages))
+			for i, stageInfo := range stageInfos {
+				require.Equal(t, stages[i].Stage, stageInfo.Stage)
+				require.Equal(t, stork_api.ApplyStageStatusPending, stageInfo.Status)
+				require.Equal(t, len(stages[i].Objects), stageInfo.Resources)
+			}
+		})
+	}
+}
+
+func TestGetReadinessTimeout(t *testing.T) {
+	gates := []stork_api.ReadinessGate{
+		{Stage: stork_api.ApplyStageCRDs},
+		{Stage: stork_api.ApplyStageStorage, TimeoutSeconds: 60},
+		{Stage: stork_api.ApplyStageWorkloads, TimeoutSeconds: -1},
+	}
+	tests := []struct {
+		stage   stork_api.ApplyStageType
+		timeout time.Duration
+		ok      bool
+	}{
+		{stork_api.ApplyStageCRDs, defaultReadinessTimeout, true},
+		{stork_api.ApplyStageStorage, time.Minute, true},
+		{stork_api.ApplyStageWorkloads, defaultReadinessTimeout, true},
+		{stork_api.ApplyStageRBAC, 0, false},
+	}
+	for _, test := range tests {
+		t.Run(string(test.stage), func(t *testing.T) {
+			timeout, ok := GetReadinessTimeout(gates, test.stage)
+			require.Equal(t, test.ok, ok)
+			require.Equal(t, test.timeout, timeout)
+		})
+	}
+
+	_, ok := GetReadinessTimeout(nil, stork_api.ApplyStageCRDs)
+	require.False(t, ok, "No gate should be found without readiness gates")
+}
+
+func TestReadinessTimedOut(t *testing.T) {
+	stageInfo := &stork_api.ApplyStageInfo{
+		StartTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+	}
+	require.True(t, ReadinessTimedOut(stageInfo, time.Minute))
+	require.False(t, ReadinessTimedOut(stageInfo, 5*time.Minute))
+}
+
+func TestCheckResourcesReady(t *testing.T) {
+	deployment := newTestObject("apps/v1", "Deployment", "ns", "deploy")
+	err := unstructured.SetNestedField(deployment.Object, int64(2), "spec", "replicas")
+	require.NoError(t, err)
+	err = unstructured.SetNestedField(deployment.Object, int64(1), "status", "availableReplicas")
+	require.NoError(t, err)
+	pvc := newTestObject("v1", "PersistentVolumeClaim", "ns", "pvc")
+	err = unstructured.SetNestedField(pvc.Object, "Bound", "status", "phase")
+	require.NoError(t, err)
+	configMap := newTestObject("v1", "ConfigMap", "ns", "config")
+	// PVCs from classes that wait for the first consumer are ready while
+	// they are pending
+	waitingPVC := newTestObject("v1", "PersistentVolumeClaim", "ns", "waiting")
+	err = unstructured.SetNestedField(waitingPVC.Object, "Pending", "status", "phase")
+	require.NoError(t, err)
+	err = unstructured.SetNestedField(waitingPVC.Object, "wffc", "spec", "storageClassName")
+	require.NoError(t, err)
+	waitingClass := newTestObject("storage.k8s.io/v1", "StorageClass", "", "wffc")
+	err = unstructured.SetNestedField(waitingClass.Object, "WaitForFirstConsumer", "volumeBindingMode")
+	require.NoError(t, err)
+	pendingPVC := newTestObject("v1", "PersistentVolumeClaim", "ns", "pending")
+	err = unstructured.SetNestedField(pendingPVC.Object, "Pending", "status", "phase")
+	require.NoError(t, err)
+	err = unstructured.SetNestedField(pendingPVC.Object, "immediate", "spec", "storageClassName")
+	require.NoError(t, err)
+	immediateClass := newTestObject("storage.k8s.io/v1", "StorageClass", "", "immediate")
+	err = unstructured.SetNestedField(immediateClass.Object, "Immediate", "volumeBindingMode")
+	require.NoError(t, err)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		deployment, pvc, waitingPVC, waitingClass, pendingPVC, immediateClass)
+	r := &ResourceCollector{}
+
+	// Objects without a readiness check don't need to exist
+	pvcReference := NewObjectReference(schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"}, "ns", "pvc")
+	require.NoError(t, r.CheckResourcesReady(dynamicClient, []runtime.Unstructured{configMap, pvcReference}))
+
+	deploymentReference := NewObjectReference(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, "ns", "deploy")
+	err = r.CheckResourcesReady(dynamicClient, []runtime.Unstructured{pvcReference, deploymentReference})
+	require.Error(t, err, "Deployment shouldn't be ready")
+	require.Contains(t, err.Error(), "Deployment ns/deploy is not ready")
+
+	waitingReference := NewObjectReference(schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"}, "ns", "waiting")
+	require.NoError(t, r.CheckResourcesReady(dynamicClient, []runtime.Unstructured{waitingReference}))
+	pendingReference := NewObjectReference(schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"}, "ns", "pending")
+	err = r.CheckResourcesReady(dynamicClient, []runtime.Unstructured{pendingReference})
+	require.Error(t, err, "Pending PVC shouldn't be ready")
+	require.Contains(t, err.Error(), "PersistentVolumeClaim ns/pending is not ready")
+
+	missing := NewObjectReference(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}, "ns", "missing")
+	require.Error(t, r.CheckResourcesReady(dynamicClient, []runtime.Unstructured{missing}), "Missing object shouldn't be ready")
+}
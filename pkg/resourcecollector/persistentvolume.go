@@ -115,3 +115,16 @@ func (r *ResourceCollector) preparePVResourceForApply(
 
 	return err
 }
+
+// mergePVForUpdate keeps the claimRef of the current PV so that it stays bound
+// to the same PVC
+func (r *ResourceCollector) mergePVForUpdate(
+	currentObject *unstructured.Unstructured,
+	object *unstructured.Unstructured,
+) error {
+	claimRef, found, err := unstructured.NestedMap(currentObject.UnstructuredContent(), "spec", "claimRef")
+	if err != nil || !found {
+		return err
+	}
+	return unstructured.SetNestedMap(object.UnstructuredContent(), claimRef, "spec", "claimRef")
+}
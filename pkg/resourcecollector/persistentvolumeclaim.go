@@ -4,11 +4,19 @@ import (
 	"fmt"
 
 	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 )
 
+var podResource = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
 func (r *ResourceCollector) pvcToBeCollected(
 	object runtime.Unstructured,
 	namespace string,
@@ -73,3 +81,108 @@ func (r *ResourceCollector) preparePVCResourceForApply(
 	object.SetUnstructuredContent(o)
 	return nil
 }
+
+// mergePVCForUpdate leaves the spec out of the update since it can't be
+// changed once the PVC has been bound. PVCs that are bound to a different
+// volume can't be updated and need to be replaced, see GetPVCsToRebind
+func (r *ResourceCollector) mergePVCForUpdate(
+	currentObject *unstructured.Unstructured,
+	object *unstructured.Unstructured,
+) error {
+	currentVolumeName, _, err := unstructured.NestedString(currentObject.UnstructuredContent(), "spec", "volumeName")
+	if err != nil {
+		return err
+	}
+	volumeName, _, err := unstructured.NestedString(object.UnstructuredContent(), "spec", "volumeName")
+	if err != nil {
+		return err
+	}
+	if volumeName != "" && currentVolumeName != volumeName {
+		return fmt.Errorf("PVC %v is bound to volume %v and can't be bound to volume %v",
+			object.GetName(), currentVolumeName, volumeName)
+	}
+	unstructured.RemoveNestedField(object.UnstructuredContent(), "spec")
+	return nil
+}
+
+// GetPVCsToRebind returns the PVCs from the objects that already exist but
+// are bound to a different volume than the one they are being applied with.
+// The volume of a bound PVC can't be changed, so these need to be deleted and
+// created again to be bound to the new volumes. PVCs that are being used by
+// pods are skipped so that they aren't deleted from under the applications,
+// and fail to be applied instead
+func (r *ResourceCollector) GetPVCsToRebind(
+	dynamicInterface dynamic.Interface,
+	objects []runtime.Unstructured,
+) ([]runtime.Unstructured, error) {
+	pvcs := make([]runtime.Unstructured, 0)
+	for _, o := range objects {
+		object, ok := o.(*unstructured.Unstructured)
+		if !ok || object.GetKind() != "PersistentVolumeClaim" {
+			continue
+		}
+		volumeName, _, err := unstructured.NestedString(object.UnstructuredContent(), "spec", "volumeName")
+		if err != nil {
+			return nil, err
+		}
+		if volumeName == "" {
+			continue
+		}
+		dynamicClient, err := r.getDynamicClient(dynamicInterface, object)
+		if err != nil {
+			return nil, err
+		}
+		currentObject, err := dynamicClient.Get(object.GetName(), metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		currentVolumeName, _, err := unstructured.NestedString(currentObject.UnstructuredContent(), "spec", "volumeName")
+		if err != nil {
+			return nil, err
+		}
+		if currentVolumeName == volumeName {
+			continue
+		}
+		inUse, err := r.pvcInUse(dynamicInterface, object)
+		if err != nil {
+			return nil, err
+		}
+		if inUse {
+			logrus.Warnf("PVC %v/%v is being used by pods, not replacing it to bind it to volume %v",
+				object.GetNamespace(), object.GetName(), volumeName)
+			continue
+		}
+		pvcs = append(pvcs, object)
+	}
+	return pvcs, nil
+}
+
+// pvcInUse returns true if the PVC is used by any pods in its namespace that
+// haven't completed
+func (r *ResourceCollector) pvcInUse(
+	dynamicInterface dynamic.Interface,
+	object *unstructured.Unstructured,
+) (bool, error) {
+	podList, err := dynamicInterface.Resource(podResource).Namespace(object.GetNamespace()).List(metav1.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	for _, o := range podList.Items {
+		var pod v1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.UnstructuredContent(), &pod); err != nil {
+			return false, err
+		}
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		for _, podVolume := range pod.Spec.Volumes {
+			if podVolume.PersistentVolumeClaim != nil && podVolume.PersistentVolumeClaim.ClaimName == object.GetName() {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
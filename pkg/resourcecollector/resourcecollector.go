@@ -1,6 +1,7 @@
 package resourcecollector
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -22,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/kubernetes/pkg/registry/core/service/portallocator"
 )
@@ -36,6 +38,7 @@ const (
 // ResourceCollector is used to collect and process unstructured objects in namespaces and using label selectors
 type ResourceCollector struct {
 	Driver           volume.Driver
+	AdminNamespace   string
 	discoveryHelper  discovery.Helper
 	dynamicInterface dynamic.Interface
 	coreOps          core.Ops
@@ -126,6 +129,13 @@ func (r *ResourceCollector) GetResources(namespaces []string, labelSelectors map
 		return nil, err
 	}
 
+	// Applications in the registry are collected too so that they can be
+	// suspended on the destination
+	registry, err := GetApplicationRegistry(r.coreOps, r.AdminNamespace)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, group := range r.discoveryHelper.Resources() {
 		groupVersion, err := schema.ParseGroupVersion(group.GroupVersion)
 		if err != nil {
@@ -133,7 +143,8 @@ func (r *ResourceCollector) GetResources(namespaces []string, labelSelectors map
 		}
 
 		for _, resource := range group.APIResources {
-			if !resourceToBeCollected(resource) {
+			if !resourceToBeCollected(resource) &&
+				GetApplicationSuspendOptions(registry, groupVersion.WithKind(resource.Kind)) == nil {
 				continue
 			}
 			for _, ns := range namespaces {
@@ -409,6 +420,7 @@ func (r *ResourceCollector) mergeSupportedForResource(
 }
 
 func (r *ResourceCollector) mergeAndUpdateResource(
+	dynamicInterface dynamic.Interface,
 	object runtime.Unstructured,
 ) error {
 	objectType, err := meta.TypeAccessor(object)
@@ -420,7 +432,93 @@ func (r *ResourceCollector) mergeAndUpdateResource(
 	case "ClusterRoleBinding":
 		return r.mergeAndUpdateClusterRoleBinding(object)
 	}
-	return nil
+
+	return r.patchResource(dynamicInterface, object)
+}
+
+// patchResource patches the current resource with the fields that are set in
+// the object. A strategic merge patch is used for the built-in types so that
+// lists like the ports of a service are merged by key, and a JSON merge patch
+// is used for custom resources. Fields that are only set on the current
+// resource, like the labels and annotations added by other controllers, are
+// kept
+func (r *ResourceCollector) patchResource(
+	dynamicInterface dynamic.Interface,
+	object runtime.Unstructured,
+) error {
+	metadata, err := meta.Accessor(object)
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := r.getDynamicClient(dynamicInterface, object)
+	if err != nil {
+		return err
+	}
+	updatedObject, ok := object.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unable to cast object to unstructured: %v", object)
+	}
+	currentObject, err := dynamicClient.Get(metadata.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			_, err = dynamicClient.Create(updatedObject, metav1.CreateOptions{})
+		}
+		return err
+	}
+
+	patch, patchType, err := r.getUpdatePatch(currentObject, updatedObject)
+	if err != nil {
+		return err
+	}
+	_, err = dynamicClient.Patch(metadata.GetName(), patchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// getUpdatePatch returns the patch to update the current resource with the
+// object. Fields that are assigned by the cluster or that can't be changed on
+// the current resource are left out of the patch
+func (r *ResourceCollector) getUpdatePatch(
+	currentObject *unstructured.Unstructured,
+	object *unstructured.Unstructured,
+) ([]byte, types.PatchType, error) {
+	updatedObject := object.DeepCopy()
+	switch updatedObject.GetKind() {
+	case "Service":
+		if err := r.mergeServiceForUpdate(currentObject, updatedObject); err != nil {
+			return nil, "", err
+		}
+	case "PersistentVolumeClaim":
+		if err := r.mergePVCForUpdate(currentObject, updatedObject); err != nil {
+			return nil, "", err
+		}
+	case "PersistentVolume":
+		if err := r.mergePVForUpdate(currentObject, updatedObject); err != nil {
+			return nil, "", err
+		}
+	}
+
+	content := updatedObject.UnstructuredContent()
+	delete(content, "status")
+	for _, field := range []string{"resourceVersion", "uid", "selfLink", "creationTimestamp", "generation", "managedFields"} {
+		unstructured.RemoveNestedField(content, "metadata", field)
+	}
+	// JSON merge patches replace lists, so don't clear the finalizers and
+	// owners of the current resource
+	if len(updatedObject.GetFinalizers()) == 0 {
+		unstructured.RemoveNestedField(content, "metadata", "finalizers")
+	}
+	if len(updatedObject.GetOwnerReferences()) == 0 {
+		unstructured.RemoveNestedField(content, "metadata", "ownerReferences")
+	}
+
+	patch, err := json.Marshal(content)
+	if err != nil {
+		return nil, "", err
+	}
+	if scheme.Scheme.Recognizes(updatedObject.GroupVersionKind()) {
+		return patch, types.StrategicMergePatchType, nil
+	}
+	return patch, types.MergePatchType, nil
 }
 
 // ApplyResource applies a given resource using the provided client interface
@@ -436,7 +534,7 @@ func (r *ResourceCollector) ApplyResource(
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) || strings.Contains(err.Error(), portallocator.ErrAllocated.Error()) {
 			if r.mergeSupportedForResource(object) {
-				return r.mergeAndUpdateResource(object)
+				return r.mergeAndUpdateResource(dynamicInterface, object)
 			} else if strings.Contains(err.Error(), portallocator.ErrAllocated.Error()) {
 				err = r.updateService(object)
 				if err != nil {
@@ -453,6 +551,18 @@ func (r *ResourceCollector) ApplyResource(
 	return err
 }
 
+// UpdateResource updates an existing resource with the given resource using
+// the provided client interface. The resource is patched so that fields set
+// only on the existing resource, like labels and annotations, are kept, as
+// well as fields assigned by the cluster, like the clusterIP and nodePorts for
+// services. The resource is created if it doesn't exist
+func (r *ResourceCollector) UpdateResource(
+	dynamicInterface dynamic.Interface,
+	object runtime.Unstructured,
+) error {
+	return r.mergeAndUpdateResource(dynamicInterface, object)
+}
+
 // DeleteResources deletes given resources using the provided client interface
 func (r *ResourceCollector) DeleteResources(
 	dynamicInterface dynamic.Interface,
@@ -0,0 +1,212 @@
+// +build unittest
+
+package resourcecollector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func toUnstructured(t *testing.T, object runtime.Object) *unstructured.Unstructured {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	require.NoError(t, err, "Error converting object")
+	return &unstructured.Unstructured{Object: content}
+}
+
+func newTestPVC(name string, volumeName string) *v1.PersistentVolumeClaim {
+	pvc := &v1.PersistentVolumeClaim{
+		Spec: v1.PersistentVolumeClaimSpec{
+			VolumeName: volumeName,
+		},
+	}
+	pvc.APIVersion = "v1"
+	pvc.Kind = "PersistentVolumeClaim"
+	pvc.Namespace = "ns"
+	pvc.Name = name
+	return pvc
+}
+
+func TestGetUpdatePatchService(t *testing.T) {
+	r := &ResourceCollector{}
+	current := &v1.Service{
+		Spec: v1.ServiceSpec{
+			Type:      v1.ServiceTypeNodePort,
+			ClusterIP: "10.0.0.10",
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: 80, Protocol: v1.ProtocolTCP, NodePort: 30080},
+				{Name: "https", Port: 443, Protocol: v1.ProtocolTCP, NodePort: 30443},
+			},
+			Selector: map[string]string{"app": "old"},
+		},
+	}
+	current.APIVersion = "v1"
+	current.Kind = "Service"
+	current.Namespace = "ns"
+	current.Name = "svc"
+	current.ResourceVersion = "10"
+	current.Labels = map[string]string{"cluster": "label"}
+	current.Finalizers = []string{"cluster/finalizer"}
+
+	service := &v1.Service{
+		Spec: v1.ServiceSpec{
+			Type:      v1.ServiceTypeNodePort,
+			ClusterIP: "10.1.0.20",
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: 80, Protocol: v1.ProtocolTCP, NodePort: 31080},
+				{Name: "https", Port: 443, Protocol: v1.ProtocolTCP},
+			},
+			Selector: map[string]string{"app": "new"},
+		},
+		Status: v1.ServiceStatus{
+			LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "1.2.3.4"}}},
+		},
+	}
+	service.APIVersion = "v1"
+	service.Kind = "Service"
+	service.Namespace = "ns"
+	service.Name = "svc"
+	service.ResourceVersion = "5"
+	service.UID = "backup-uid"
+	service.Labels = map[string]string{"app": "label"}
+
+	currentObject := toUnstructured(t, current)
+	object := toUnstructured(t, service)
+	patch, patchType, err := r.getUpdatePatch(currentObject, object)
+	require.NoError(t, err, "Error getting patch")
+	require.Equal(t, types.StrategicMergePatchType, patchType)
+	require.Equal(t, "10.1.0.20", service.Spec.ClusterIP, "Object shouldn't be modified")
+	require.Equal(t, "5", object.GetResourceVersion(), "Object shouldn't be modified")
+
+	currentData, err := json.Marshal(current)
+	require.NoError(t, err)
+	patched, err := strategicpatch.StrategicMergePatch(currentData, patch, v1.Service{})
+	require.NoError(t, err, "Error applying patch")
+	updated := &v1.Service{}
+	require.NoError(t, json.Unmarshal(patched, updated))
+
+	require.Equal(t, "10.0.0.10", updated.Spec.ClusterIP, "ClusterIP shouldn't change")
+	require.Equal(t, "10", updated.ResourceVersion)
+	require.Empty(t, updated.UID)
+	require.Empty(t, updated.Status.LoadBalancer.Ingress, "Status shouldn't be patched")
+	require.Equal(t, map[string]string{"app": "new"}, updated.Spec.Selector)
+	require.Equal(t, map[string]string{"cluster": "label", "app": "label"}, updated.Labels)
+	require.Equal(t, []string{"cluster/finalizer"}, updated.Finalizers)
+	require.Len(t, updated.Spec.Ports, 2)
+	for _, port := range updated.Spec.Ports {
+		switch port.Port {
+		case 80:
+			require.Equal(t, int32(30080), port.NodePort)
+		case 443:
+			require.Equal(t, int32(30443), port.NodePort)
+		}
+	}
+}
+
+func TestGetUpdatePatchPVC(t *testing.T) {
+	r := &ResourceCollector{}
+	current := toUnstructured(t, newTestPVC("pvc", "pv1"))
+
+	// The spec of a PVC bound to the same volume is left out of the patch
+	pvc := newTestPVC("pvc", "pv1")
+	pvc.Labels = map[string]string{"app": "db"}
+	pvc.Spec.StorageClassName = new(string)
+	patch, _, err := r.getUpdatePatch(current, toUnstructured(t, pvc))
+	require.NoError(t, err, "Error getting patch")
+	content := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(patch, &content))
+	require.NotContains(t, content, "spec")
+	labels, _, err := unstructured.NestedStringMap(content, "metadata", "labels")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"app": "db"}, labels)
+
+	// PVCs bound to other volumes can't be updated
+	_, _, err = r.getUpdatePatch(current, toUnstructured(t, newTestPVC("pvc", "pv2")))
+	require.Error(t, err, "PVC bound to another volume shouldn't be updated")
+	require.Contains(t, err.Error(), "PVC pvc is bound to volume pv1 and can't be bound to volume pv2")
+}
+
+func TestGetPVCsToRebind(t *testing.T) {
+	r := &ResourceCollector{}
+	newTestPod := func(name string, claimName string, phase v1.PodPhase) *v1.Pod {
+		pod := &v1.Pod{
+			Spec: v1.PodSpec{
+				Volumes: []v1.Volume{
+					{
+						Name: "data",
+						VolumeSource: v1.VolumeSource{
+							PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+						},
+					},
+				},
+			},
+			Status: v1.PodStatus{Phase: phase},
+		}
+		pod.APIVersion = "v1"
+		pod.Kind = "Pod"
+		pod.Namespace = "ns"
+		pod.Name = name
+		return pod
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		toUnstructured(t, newTestPVC("same", "pv1")),
+		toUnstructured(t, newTestPVC("other", "pv2")),
+		toUnstructured(t, newTestPVC("mounted", "pv4")),
+		toUnstructured(t, newTestPod("running", "mounted", v1.PodRunning)),
+		toUnstructured(t, newTestPod("completed", "other", v1.PodSucceeded)),
+	)
+	objects := []runtime.Unstructured{
+		toUnstructured(t, newTestPVC("same", "pv1")),
+		toUnstructured(t, newTestPVC("other", "restored-pv2")),
+		toUnstructured(t, newTestPVC("missing", "pv3")),
+		toUnstructured(t, newTestPVC("mounted", "restored-pv4")),
+		newTestObject("v1", "ConfigMap", "ns", "other"),
+	}
+	pvcs, err := r.GetPVCsToRebind(dynamicClient, objects)
+	require.NoError(t, err, "Error getting PVCs to rebind")
+	require.Len(t, pvcs, 1)
+	require.Equal(t, "other", pvcs[0].(*unstructured.Unstructured).GetName())
+}
+
+func TestUpdateCustomResource(t *testing.T) {
+	r := &ResourceCollector{}
+	current := newTestObject("example.com/v1", "Database", "ns", "db")
+	current.SetLabels(map[string]string{"cluster": "label"})
+	current.SetFinalizers([]string{"example.com/finalizer"})
+	require.NoError(t, unstructured.SetNestedField(current.Object, "1", "spec", "version"))
+	require.NoError(t, unstructured.SetNestedField(current.Object, int64(3), "spec", "replicas"))
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), current)
+
+	object := newTestObject("example.com/v1", "Database", "ns", "db")
+	object.SetLabels(map[string]string{"app": "label"})
+	require.NoError(t, unstructured.SetNestedField(object.Object, "2", "spec", "version"))
+	_, patchType, err := r.getUpdatePatch(current, object)
+	require.NoError(t, err, "Error getting patch")
+	require.Equal(t, types.MergePatchType, patchType)
+
+	require.NoError(t, r.UpdateResource(dynamicClient, object), "Error updating resource")
+	dbClient, err := r.getDynamicClient(dynamicClient, object)
+	require.NoError(t, err)
+	db, err := dbClient.Get("db", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting resource")
+	require.Equal(t, map[string]string{"cluster": "label", "app": "label"}, db.GetLabels())
+	require.Equal(t, []string{"example.com/finalizer"}, db.GetFinalizers())
+	version, _, _ := unstructured.NestedString(db.Object, "spec", "version")
+	require.Equal(t, "2", version)
+	replicas, _, _ := unstructured.NestedInt64(db.Object, "spec", "replicas")
+	require.Equal(t, int64(3), replicas)
+
+	// Resources that don't exist are created
+	created := newTestObject("example.com/v1", "Database", "ns", "created")
+	require.NoError(t, r.UpdateResource(dynamicClient, created), "Error creating resource")
+	_, err = dbClient.Get("created", metav1.GetOptions{})
+	require.NoError(t, err, "Resource should have been created")
+}
@@ -63,3 +63,42 @@ func (r *ResourceCollector) updateService(
 	}
 	return nil
 }
+
+// mergeServiceForUpdate keeps the clusterIP and nodePorts that were assigned
+// to the current service since they can't be changed or would need to be
+// allocated again
+func (r *ResourceCollector) mergeServiceForUpdate(
+	currentObject *unstructured.Unstructured,
+	object *unstructured.Unstructured,
+) error {
+	var currentService, service v1.Service
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(currentObject.UnstructuredContent(), &currentService); err != nil {
+		return fmt.Errorf("error converting to service: %v", err)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.UnstructuredContent(), &service); err != nil {
+		return fmt.Errorf("error converting to service: %v", err)
+	}
+
+	service.Spec.ClusterIP = currentService.Spec.ClusterIP
+	if service.Spec.Type == v1.ServiceTypeNodePort || service.Spec.Type == v1.ServiceTypeLoadBalancer {
+		for i := range service.Spec.Ports {
+			for _, currentPort := range currentService.Spec.Ports {
+				if currentPort.Port == service.Spec.Ports[i].Port &&
+					currentPort.Protocol == service.Spec.Ports[i].Protocol {
+					service.Spec.Ports[i].NodePort = currentPort.NodePort
+					break
+				}
+			}
+		}
+		if service.Spec.HealthCheckNodePort == 0 {
+			service.Spec.HealthCheckNodePort = currentService.Spec.HealthCheckNodePort
+		}
+	}
+
+	o, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&service)
+	if err != nil {
+		return err
+	}
+	object.SetUnstructuredContent(o)
+	return nil
+}
@@ -170,12 +170,12 @@ func PerformRuleRecovery(
 
 				metadata, err := meta.Accessor(owner)
 				if err != nil {
-					log.RuleLog(nil, owner).Warnf(err.Error())
+					log.RuleLog(nil, owner).Warn(err.Error())
 					continue
 				}
 
 				err = fmt.Errorf("failed to get pod with uid: %s due to: %v", pod.UID, err)
-				log.RuleLog(nil, owner).Warnf(err.Error())
+				log.RuleLog(nil, owner).Warn(err.Error())
 
 				ev := &v1.Event{
 					ObjectMeta: metav1.ObjectMeta{
@@ -646,9 +646,9 @@ func waitForExecPodCompletion(pod *v1.Pod) error {
 		}
 
 		if p.Status.Phase == v1.PodFailed {
-			errMsg := fmt.Sprintf("Pod: [%s] %s failed", p.GetNamespace(), p.GetName())
-			logrus.Errorf(errMsg)
-			return true, fmt.Errorf(errMsg)
+			err := fmt.Errorf("Pod: [%s] %s failed", p.GetNamespace(), p.GetName())
+			logrus.Error(err)
+			return true, err
 		}
 
 		if p.Status.Phase == v1.PodSucceeded {
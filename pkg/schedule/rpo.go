@@ -0,0 +1,97 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RPOBreachedReason is the reason used for events and conditions when
+	// the RPO target of a schedule is breached
+	RPOBreachedReason = "RPOBreached"
+	// RPOMetReason is the reason used for events and conditions when the
+	// RPO target of a schedule is met
+	RPOMetReason = "RPOMet"
+)
+
+// GetRPOLag returns the time since the last successful run of a schedule was
+// started. If there hasn't been a successful run yet the time since the
+// schedule was created is used
+func GetRPOLag(lastSuccessful meta.Time, created meta.Time) time.Duration {
+	since := lastSuccessful
+	if since.IsZero() {
+		since = created
+	}
+	// Round to seconds since the lag is only displayed and compared with
+	// targets
+	return GetCurrentTime().Sub(since.Time).Round(time.Second)
+}
+
+// UpdateRPOCondition updates the RPOBreached condition in the list for the
+// lag of a schedule. The condition is removed if no target is set. The
+// message of the condition includes the lag when its status changed, and the
+// condition is kept as is while the status doesn't change so that it isn't
+// updated as the lag grows. Returns the updated conditions and true if the
+// status of the condition changed
+func UpdateRPOCondition(
+	conditions []stork_api.ScheduleCondition,
+	target *meta.Duration,
+	lag time.Duration,
+) ([]stork_api.ScheduleCondition, bool) {
+	var updated []stork_api.ScheduleCondition
+	var existing *stork_api.ScheduleCondition
+	for i, c := range conditions {
+		if c.Type == stork_api.ScheduleConditionRPOBreached {
+			existing = &conditions[i]
+			continue
+		}
+		updated = append(updated, c)
+	}
+	if target == nil {
+		return updated, false
+	}
+
+	condition := stork_api.ScheduleCondition{
+		Type:               stork_api.ScheduleConditionRPOBreached,
+		Status:             v1.ConditionFalse,
+		LastTransitionTime: meta.NewTime(GetCurrentTime()),
+		Reason:             RPOMetReason,
+		Message:            fmt.Sprintf("Last successful run was started %v ago, within the RPO target (%v)", lag, target.Duration),
+	}
+	if IsRPOLagBreached(target, lag) {
+		condition.Status = v1.ConditionTrue
+		condition.Reason = RPOBreachedReason
+		condition.Message = fmt.Sprintf("Last successful run was started %v ago, more than the RPO target (%v)", lag, target.Duration)
+	}
+
+	changed := true
+	if existing != nil && existing.Status == condition.Status {
+		condition = *existing
+		changed = false
+	} else if existing == nil && condition.Status == v1.ConditionFalse {
+		// Nothing to report if the target was met the first time it was
+		// checked
+		changed = false
+	}
+	return append(updated, condition), changed
+}
+
+// IsRPOLagBreached returns true if the lag of a schedule is more than its
+// target
+func IsRPOLagBreached(target *meta.Duration, lag time.Duration) bool {
+	return target != nil && lag > target.Duration
+}
+
+// IsRPOBreached returns true if the RPOBreached condition is set in the list
+func IsRPOBreached(conditions []stork_api.ScheduleCondition) bool {
+	for _, c := range conditions {
+		if c.Type == stork_api.ScheduleConditionRPOBreached {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -35,6 +35,7 @@ func TestSchedule(t *testing.T) {
 	t.Run("triggerMonthlyRequiredTest", triggerMonthlyRequiredTest)
 	t.Run("validateSchedulePolicyTest", validateSchedulePolicyTest)
 	t.Run("policyRetainTest", policyRetainTest)
+	t.Run("rpoTest", rpoTest)
 }
 
 func createDefaultPoliciesTest(t *testing.T) {
@@ -423,3 +424,49 @@ func policyRetainTest(t *testing.T) {
 	require.NoError(t, err, "Error getting retain")
 	require.Equal(t, policy.Policy.Monthly.Retain, retain, "Wrong default retain for monthly policy")
 }
+
+func rpoTest(t *testing.T) {
+	defer setMockTime(nil)
+	mockNow := time.Date(2019, time.February, 7, 12, 0, 0, 0, time.Local)
+	setMockTime(&mockNow)
+
+	created := meta.NewTime(mockNow.Add(-3 * time.Hour))
+	require.Equal(t, 3*time.Hour, GetRPOLag(meta.Time{}, created), "Lag should be from creation without a successful run")
+	lastSuccessful := meta.NewTime(mockNow.Add(-30 * time.Minute))
+	require.Equal(t, 30*time.Minute, GetRPOLag(lastSuccessful, created), "Lag should be from the last successful run")
+
+	// No condition without a target
+	conditions, changed := UpdateRPOCondition(nil, nil, time.Hour)
+	require.Empty(t, conditions, "No condition expected without target")
+	require.False(t, changed, "Condition shouldn't have changed")
+
+	// Target met the first time isn't reported
+	target := &meta.Duration{Duration: time.Hour}
+	conditions, changed = UpdateRPOCondition(conditions, target, 30*time.Minute)
+	require.Len(t, conditions, 1, "Expected RPO condition")
+	require.False(t, changed, "Condition shouldn't have changed")
+	require.False(t, IsRPOBreached(conditions), "RPO shouldn't be breached")
+
+	conditions, changed = UpdateRPOCondition(conditions, target, 2*time.Hour)
+	require.True(t, changed, "Condition should have changed")
+	require.True(t, IsRPOBreached(conditions), "RPO should be breached")
+	require.Equal(t, RPOBreachedReason, conditions[0].Reason, "Condition reason mismatch")
+	require.Contains(t, conditions[0].Message, "started 2h0m0s ago", "Condition should include the lag")
+
+	// Condition isn't modified while the status doesn't change so that the
+	// status of schedules isn't updated as the lag grows
+	previous := conditions
+	mockNow = mockNow.Add(time.Hour)
+	conditions, changed = UpdateRPOCondition(conditions, target, 3*time.Hour)
+	require.False(t, changed, "Condition shouldn't have changed")
+	require.Equal(t, previous, conditions, "Condition shouldn't have been modified")
+
+	conditions, changed = UpdateRPOCondition(conditions, target, time.Minute)
+	require.True(t, changed, "Condition should have changed")
+	require.False(t, IsRPOBreached(conditions), "RPO shouldn't be breached")
+	require.Equal(t, RPOMetReason, conditions[0].Reason, "Condition reason mismatch")
+
+	// Condition is removed when the target is removed
+	conditions, _ = UpdateRPOCondition(conditions, nil, time.Minute)
+	require.Nil(t, conditions, "No condition expected without target")
+}
@@ -4,9 +4,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/libopenstorage/stork/pkg/apis/stork"
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/backuparchive"
+	"github.com/libopenstorage/stork/pkg/objectstore"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/portworx/sched-ops/task"
 	"github.com/spf13/cobra"
@@ -16,12 +21,17 @@ import (
 	"k8s.io/kubernetes/pkg/printers"
 )
 
+// archiveEncryptionKeyEnv is the environment variable with the key for backup
+// archives, used if a file with the key isn't given
+const archiveEncryptionKeyEnv = "STORKCTL_ARCHIVE_ENCRYPTION_KEY"
+
 var (
 	backupStatusRetryInterval = 30 * time.Second
 	backupStatusRetryTimeout  = 6 * time.Hour
 )
 
 var applicationBackupColumns = []string{"NAME", "STAGE", "STATUS", "VOLUMES", "RESOURCES", "CREATED", "ELAPSED"}
+var locationBackupColumns = []string{"NAME", "PATH", "NAMESPACES", "STATUS", "VOLUMES", "RESOURCES", "CREATED"}
 var applicationBackupSubcommand = "applicationbackups"
 var applicationBackupAliases = []string{"applicationbackup", "backup", "backups"}
 
@@ -90,6 +100,7 @@ func newCreateApplicationBackupCommand(cmdFactory Factory, ioStreams genericclio
 }
 
 func newGetApplicationBackupCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var backupLocation string
 	getApplicationBackupCommand := &cobra.Command{
 		Use:     applicationBackupSubcommand,
 		Aliases: applicationBackupAliases,
@@ -98,6 +109,11 @@ func newGetApplicationBackupCommand(cmdFactory Factory, ioStreams genericcliopti
 			var applicationBackups *storkv1.ApplicationBackupList
 			var err error
 
+			if backupLocation != "" {
+				getLocationBackups(c, cmdFactory, ioStreams, backupLocation, args)
+				return
+			}
+
 			namespaces, err := cmdFactory.GetAllNamespaces()
 			if err != nil {
 				util.CheckErr(err)
@@ -139,11 +155,58 @@ func newGetApplicationBackupCommand(cmdFactory Factory, ioStreams genericcliopti
 			}
 		},
 	}
+	getApplicationBackupCommand.Flags().StringVarP(&backupLocation, "backupLocation", "l", "", "List the backups stored in the BackupLocation instead of the applicationbackups in the cluster")
 	cmdFactory.BindGetFlags(getApplicationBackupCommand.Flags())
 
 	return getApplicationBackupCommand
 }
 
+// getLocationBackups prints the backups stored in the backup location. These
+// can be restored by specifying their path when creating an applicationrestore
+func getLocationBackups(
+	c *cobra.Command,
+	cmdFactory Factory,
+	ioStreams genericclioptions.IOStreams,
+	backupLocationName string,
+	names []string,
+) {
+	if cmdFactory.AllNamespaces() {
+		util.CheckErr(fmt.Errorf("backups can't be listed from a BackupLocation across all namespaces"))
+		return
+	}
+	backupLocation, err := storkops.Instance().GetBackupLocation(backupLocationName, cmdFactory.GetNamespace())
+	if err != nil {
+		util.CheckErr(err)
+		return
+	}
+	backups, err := objectstore.ListBackups(backupLocation)
+	if err != nil {
+		util.CheckErr(fmt.Errorf("error listing backups in BackupLocation %v: %v", backupLocationName, err))
+		return
+	}
+
+	selectedNames := make(map[string]bool)
+	for _, name := range names {
+		selectedNames[name] = true
+	}
+	applicationBackups := new(storkv1.ApplicationBackupList)
+	for _, backup := range backups {
+		if len(selectedNames) > 0 && !selectedNames[backup.Name] {
+			continue
+		}
+		applicationBackups.Items = append(applicationBackups.Items, *backup)
+	}
+	if len(applicationBackups.Items) == 0 {
+		handleEmptyList(ioStreams.Out)
+		return
+	}
+
+	if err := printObjects(c, applicationBackups, cmdFactory, locationBackupColumns, locationBackupPrinter, ioStreams.Out); err != nil {
+		util.CheckErr(err)
+		return
+	}
+}
+
 func newDeleteApplicationBackupCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	deleteApplicationBackupCommand := &cobra.Command{
 		Use:     applicationBackupSubcommand,
@@ -165,6 +228,48 @@ func newDeleteApplicationBackupCommand(cmdFactory Factory, ioStreams genericclio
 	return deleteApplicationBackupCommand
 }
 
+func newRetryApplicationBackupCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	retryApplicationBackupCommand := &cobra.Command{
+		Use:     applicationBackupSubcommand,
+		Aliases: applicationBackupAliases,
+		Short:   "Retry the failed volumes of applicationbackups",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) == 0 {
+				util.CheckErr(fmt.Errorf("at least one argument needs to be provided for applicationbackup name"))
+				return
+			}
+			retryApplicationBackups(args, cmdFactory.GetNamespace(), ioStreams)
+		},
+	}
+
+	return retryApplicationBackupCommand
+}
+
+func retryApplicationBackups(applicationBackups []string, namespace string, ioStreams genericclioptions.IOStreams) {
+	for _, name := range applicationBackups {
+		applicationBackup, err := storkops.Instance().GetApplicationBackup(name, namespace)
+		if err != nil {
+			util.CheckErr(err)
+			return
+		}
+		if applicationBackup.Status.Status != storkv1.ApplicationBackupStatusFailed &&
+			applicationBackup.Status.Status != storkv1.ApplicationBackupStatusPartialSuccess {
+			util.CheckErr(fmt.Errorf("applicationbackup %v can't be retried since its status is %v", name, applicationBackup.Status.Status))
+			return
+		}
+		if applicationBackup.Annotations == nil {
+			applicationBackup.Annotations = make(map[string]string)
+		}
+		applicationBackup.Annotations[stork.RetryAnnotation] = "true"
+		if _, err := storkops.Instance().UpdateApplicationBackup(applicationBackup); err != nil {
+			util.CheckErr(err)
+			return
+		}
+		msg := fmt.Sprintf("ApplicationBackup %v will be retried", name)
+		printMsg(msg, ioStreams.Out)
+	}
+}
+
 func deleteApplicationBackups(applicationBackups []string, namespace string, ioStreams genericclioptions.IOStreams) {
 	for _, applicationBackup := range applicationBackups {
 		err := storkops.Instance().DeleteApplicationBackup(applicationBackup, namespace)
@@ -224,6 +329,213 @@ func applicationBackupPrinter(
 	return rows, nil
 }
 
+func newExportApplicationBackupCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var backupLocationName string
+	var backupPath string
+	var archiveFile string
+	var encryptionKeyFile string
+
+	exportApplicationBackupCommand := &cobra.Command{
+		Use:     applicationBackupSubcommand,
+		Aliases: applicationBackupAliases,
+		Short:   "Export an applicationbackup to an encrypted archive",
+		Run: func(c *cobra.Command, args []string) {
+			if archiveFile == "" {
+				util.CheckErr(fmt.Errorf("need to provide the file to export the applicationbackup to"))
+				return
+			}
+			encryptionKey, err := getArchiveEncryptionKey(encryptionKeyFile)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+
+			namespace := cmdFactory.GetNamespace()
+			name := backupPath
+			if backupPath == "" {
+				if len(args) != 1 {
+					util.CheckErr(fmt.Errorf("exactly one name needs to be provided for applicationbackup name"))
+					return
+				}
+				name = args[0]
+				backup, err := storkops.Instance().GetApplicationBackup(name, namespace)
+				if err != nil {
+					util.CheckErr(err)
+					return
+				}
+				if backup.Status.Stage != storkv1.ApplicationBackupStageFinal ||
+					(backup.Status.Status != storkv1.ApplicationBackupStatusSuccessful &&
+						backup.Status.Status != storkv1.ApplicationBackupStatusPartialSuccess) {
+					util.CheckErr(fmt.Errorf("applicationbackup %v hasn't completed successfully", name))
+					return
+				}
+				backupLocationName = backup.Spec.BackupLocation
+				backupPath = backup.Status.BackupPath
+			} else if backupLocationName == "" {
+				util.CheckErr(fmt.Errorf("need to provide BackupLocation when exporting a backup using its path"))
+				return
+			}
+
+			backupLocation, err := storkops.Instance().GetBackupLocation(backupLocationName, namespace)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			file, err := os.OpenFile(archiveFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+			if err != nil {
+				util.CheckErr(fmt.Errorf("error writing archive: %v", err))
+				return
+			}
+			manifest, err := backuparchive.Export(backupLocation, backupPath, encryptionKey, file)
+			if closeErr := file.Close(); err == nil && closeErr != nil {
+				err = fmt.Errorf("error writing archive: %v", closeErr)
+			}
+			if err != nil {
+				_ = os.Remove(archiveFile)
+				util.CheckErr(fmt.Errorf("error exporting applicationbackup %v: %v", name, err))
+				return
+			}
+
+			printMsg(fmt.Sprintf("ApplicationBackup %v exported to %v", name, archiveFile), ioStreams.Out)
+			notExported := 0
+			for _, vInfo := range manifest.Volumes {
+				if len(vInfo.DataObjects) == 0 {
+					notExported++
+				}
+			}
+			if notExported > 0 {
+				printMsg(fmt.Sprintf("%v volume backups are stored by their volume drivers and can only be restored where the drivers can access them",
+					notExported), ioStreams.Out)
+			}
+		},
+	}
+	exportApplicationBackupCommand.Flags().StringVarP(&archiveFile, "file", "f", "", "File to write the archive to")
+	exportApplicationBackupCommand.Flags().StringVarP(&encryptionKeyFile, "encryptionKeyFile", "", "",
+		"File with the key used to encrypt the archive. The key is read from the "+archiveEncryptionKeyEnv+" environment variable if not set")
+	exportApplicationBackupCommand.Flags().StringVarP(&backupLocationName, "backupLocation", "l", "", "BackupLocation to export the backup from, when using the path of the backup")
+	exportApplicationBackupCommand.Flags().StringVarP(&backupPath, "backupPath", "", "", "Path of the backup in the BackupLocation to export, without an applicationbackup in the cluster")
+
+	return exportApplicationBackupCommand
+}
+
+func newImportApplicationBackupCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var backupLocationName string
+	var archiveFile string
+	var encryptionKeyFile string
+
+	importApplicationBackupCommand := &cobra.Command{
+		Use:     applicationBackupSubcommand,
+		Aliases: applicationBackupAliases,
+		Short:   "Import an applicationbackup from an archive into a BackupLocation",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) > 1 {
+				util.CheckErr(fmt.Errorf("at most one name can be provided for the imported applicationbackup"))
+				return
+			}
+			if archiveFile == "" {
+				util.CheckErr(fmt.Errorf("need to provide the archive to import"))
+				return
+			}
+			encryptionKey, err := getArchiveEncryptionKey(encryptionKeyFile)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			if backupLocationName == "" {
+				util.CheckErr(fmt.Errorf("need to provide BackupLocation to import the applicationbackup into"))
+				return
+			}
+			name := ""
+			if len(args) == 1 {
+				name = args[0]
+			}
+
+			backupLocation, err := storkops.Instance().GetBackupLocation(backupLocationName, cmdFactory.GetNamespace())
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			file, err := os.Open(archiveFile)
+			if err != nil {
+				util.CheckErr(fmt.Errorf("error reading archive: %v", err))
+				return
+			}
+			defer file.Close() // nolint: errcheck
+			backup, _, err := backuparchive.Import(backupLocation, file, encryptionKey, name)
+			if err != nil {
+				util.CheckErr(fmt.Errorf("error importing applicationbackup: %v", err))
+				return
+			}
+
+			// Register the backup so that it can be restored from the
+			// cluster. The objects were copied into the location for this
+			// backup, so its ReclaimPolicy is kept to delete them along
+			// with it
+			backup.UID = ""
+			backup.ResourceVersion = ""
+			backup.SelfLink = ""
+			backup.OwnerReferences = nil
+			if _, err := storkops.Instance().CreateApplicationBackup(backup); err != nil {
+				util.CheckErr(err)
+				return
+			}
+			printMsg(fmt.Sprintf("ApplicationBackup %v imported into BackupLocation %v", backup.Name, backupLocationName), ioStreams.Out)
+		},
+	}
+	importApplicationBackupCommand.Flags().StringVarP(&archiveFile, "file", "f", "", "Archive to import")
+	importApplicationBackupCommand.Flags().StringVarP(&encryptionKeyFile, "encryptionKeyFile", "", "",
+		"File with the key used to encrypt the archive. The key is read from the "+archiveEncryptionKeyEnv+" environment variable if not set")
+	importApplicationBackupCommand.Flags().StringVarP(&backupLocationName, "backupLocation", "l", "", "BackupLocation to import the backup into")
+
+	return importApplicationBackupCommand
+}
+
+// getArchiveEncryptionKey reads the key for an archive from the file, or from
+// the environment if no file is given, so that it doesn't show up in the
+// command line of the process or the shell history
+func getArchiveEncryptionKey(encryptionKeyFile string) (string, error) {
+	if encryptionKeyFile == "" {
+		if encryptionKey := os.Getenv(archiveEncryptionKeyEnv); encryptionKey != "" {
+			return encryptionKey, nil
+		}
+		return "", fmt.Errorf("need to provide the encryption key for the archive in a file or in the %v environment variable",
+			archiveEncryptionKeyEnv)
+	}
+	data, err := ioutil.ReadFile(encryptionKeyFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading encryption key: %v", err)
+	}
+	encryptionKey := strings.TrimRight(string(data), "\r\n")
+	if encryptionKey == "" {
+		return "", fmt.Errorf("encryption key file %v is empty", encryptionKeyFile)
+	}
+	return encryptionKey, nil
+}
+
+func locationBackupPrinter(
+	applicationBackupList *storkv1.ApplicationBackupList,
+	options printers.GenerateOptions,
+) ([]metav1beta1.TableRow, error) {
+	if applicationBackupList == nil {
+		return nil, nil
+	}
+
+	rows := make([]metav1beta1.TableRow, 0)
+	for _, applicationBackup := range applicationBackupList.Items {
+		row := getRow(&applicationBackup,
+			[]interface{}{applicationBackup.Name,
+				applicationBackup.Status.BackupPath,
+				strings.Join(applicationBackup.Spec.Namespaces, ","),
+				applicationBackup.Status.Status,
+				len(applicationBackup.Status.Volumes),
+				len(applicationBackup.Status.Resources),
+				toTimeString(applicationBackup.Status.TriggerTimestamp.Time)},
+		)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
 func waitForApplicationBackup(name, namespace string, ioStreams genericclioptions.IOStreams) (string, error) {
 	var msg string
 	var err error
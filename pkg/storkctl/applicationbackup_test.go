@@ -3,11 +3,18 @@
 package storkctl
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/libopenstorage/stork/pkg/apis/stork"
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/libopenstorage/stork/pkg/objectstore"
 	"github.com/portworx/sched-ops/k8s/core"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/stretchr/testify/require"
@@ -231,3 +238,192 @@ func setApplicationBackupStatus(name, namespace string, isFail bool, t *testing.
 	_, err = storkops.Instance().UpdateApplicationBackup(backup)
 	require.NoError(t, err, "Error updating ApplicationBackups")
 }
+
+// createLocationBackups creates a filesystem backup location with two backups
+// stored in it. Returns the directory used for the backup location and the
+// time the first backup was triggered, the second one was triggered a minute
+// later
+func createLocationBackups(t *testing.T, namespace string, name string) (string, time.Time) {
+	dir, err := ioutil.TempDir("", "storkctl")
+	require.NoError(t, err, "Error creating directory for backuplocation")
+
+	backupLocation := &storkv1.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Location: storkv1.BackupLocationItem{
+			Type: storkv1.BackupLocationFilesystem,
+			Path: dir,
+		},
+	}
+	_, err = storkops.Instance().CreateBackupLocation(backupLocation)
+	require.NoError(t, err, "Error creating backuplocation")
+
+	triggerTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i, backupID := range []string{"1", "2"} {
+		backup := &storkv1.ApplicationBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "backup" + backupID,
+				Namespace: namespace,
+			},
+			Spec: storkv1.ApplicationBackupSpec{
+				BackupLocation: name,
+				Namespaces:     []string{"namespace1", "namespace2"},
+				ReclaimPolicy:  storkv1.ApplicationBackupReclaimPolicyDelete,
+			},
+			Status: storkv1.ApplicationBackupStatus{
+				Status:           storkv1.ApplicationBackupStatusSuccessful,
+				TriggerTimestamp: metav1.NewTime(triggerTime.Add(time.Duration(i) * time.Minute)),
+				Volumes:          []*storkv1.ApplicationBackupVolumeInfo{{}},
+			},
+		}
+		data, err := json.Marshal(backup)
+		require.NoError(t, err, "Error marshalling backup")
+		backupDir := filepath.Join(dir, namespace, "backup"+backupID, "uid"+backupID)
+		err = os.MkdirAll(backupDir, 0755)
+		require.NoError(t, err, "Error creating backup directory")
+		err = ioutil.WriteFile(filepath.Join(backupDir, "metadata.json"), data, 0644)
+		require.NoError(t, err, "Error writing backup metadata")
+	}
+	return dir, triggerTime
+}
+
+func TestGetApplicationBackupsFromLocation(t *testing.T) {
+	defer resetTest()
+	dir, triggerTime := createLocationBackups(t, "default", "filesystemlocation")
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	expected := "NAME      PATH                   NAMESPACES              STATUS       VOLUMES   RESOURCES   CREATED\n" +
+		"backup1   default/backup1/uid1   namespace1,namespace2   Successful   1         0           " + toTimeString(triggerTime) + "\n" +
+		"backup2   default/backup2/uid2   namespace1,namespace2   Successful   1         0           " + toTimeString(triggerTime.Add(time.Minute)) + "\n"
+	cmdArgs := []string{"get", "applicationbackups", "-n", "default", "--backupLocation", "filesystemlocation"}
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	expected = "NAME      PATH                   NAMESPACES              STATUS       VOLUMES   RESOURCES   CREATED\n" +
+		"backup2   default/backup2/uid2   namespace1,namespace2   Successful   1         0           " + toTimeString(triggerTime.Add(time.Minute)) + "\n"
+	cmdArgs = []string{"get", "applicationbackups", "-n", "default", "--backupLocation", "filesystemlocation", "backup2"}
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	cmdArgs = []string{"get", "applicationbackups", "-n", "default", "--backupLocation", "missinglocation"}
+	expected = `Error from server (NotFound): backuplocations.stork.libopenstorage.org "missinglocation" not found`
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestExportImportApplicationBackup(t *testing.T) {
+	defer resetTest()
+	sourceDir, _ := createLocationBackups(t, "default", "sourcelocation")
+	defer os.RemoveAll(sourceDir) // nolint: errcheck
+	backupDir := filepath.Join(sourceDir, "default", "backup1", "uid1")
+	err := ioutil.WriteFile(filepath.Join(backupDir, "resources.json"), []byte("[]"), 0644)
+	require.NoError(t, err, "Error writing backup resources")
+	err = ioutil.WriteFile(filepath.Join(backupDir, "secrets.json"), []byte("secrets"), 0644)
+	require.NoError(t, err, "Error writing backup secrets")
+	err = ioutil.WriteFile(filepath.Join(backupDir, "volumedata"), []byte("data"), 0644)
+	require.NoError(t, err, "Error writing volume data")
+
+	destDir, err := ioutil.TempDir("", "storkctl")
+	require.NoError(t, err, "Error creating directory for backuplocation")
+	defer os.RemoveAll(destDir) // nolint: errcheck
+	destLocation := &storkv1.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "destlocation",
+			Namespace: "default",
+		},
+		Location: storkv1.BackupLocationItem{
+			Type:          storkv1.BackupLocationFilesystem,
+			Path:          destDir,
+			EncryptionKey: "locationkey",
+		},
+	}
+	_, err = storkops.Instance().CreateBackupLocation(destLocation)
+	require.NoError(t, err, "Error creating backuplocation")
+
+	archive := filepath.Join(destDir, "archive")
+	keyFile := filepath.Join(destDir, "key")
+	err = ioutil.WriteFile(keyFile, []byte("archivekey\n"), 0600)
+	require.NoError(t, err, "Error writing key file")
+	wrongKeyFile := filepath.Join(destDir, "wrongkey")
+	err = ioutil.WriteFile(wrongKeyFile, []byte("wrongkey"), 0600)
+	require.NoError(t, err, "Error writing key file")
+
+	cmdArgs := []string{"export", "applicationbackups", "-n", "default", "--backupLocation", "sourcelocation",
+		"--backupPath", "default/backup1/uid1", "-f", archive}
+	expected := "error: need to provide the encryption key for the archive in a file or in the " + archiveEncryptionKeyEnv + " environment variable"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"export", "applicationbackups", "-n", "default", "--backupLocation", "sourcelocation",
+		"--backupPath", "default/backup1/uid1", "-f", archive, "--encryptionKeyFile", keyFile}
+	expected = "ApplicationBackup default/backup1/uid1 exported to " + archive + "\n" +
+		"1 volume backups are stored by their volume drivers and can only be restored where the drivers can access them\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	cmdArgs = []string{"import", "applicationbackups", "-n", "default", "importedbackup", "--backupLocation", "destlocation",
+		"-f", archive, "--encryptionKeyFile", wrongKeyFile}
+	expected = "error: error importing applicationbackup: error decrypting archive: cipher: message authentication failed"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	// The key is read from the environment without a file
+	require.NoError(t, os.Setenv(archiveEncryptionKeyEnv, "archivekey"))
+	defer os.Unsetenv(archiveEncryptionKeyEnv) // nolint: errcheck
+	cmdArgs = []string{"import", "applicationbackups", "-n", "default", "importedbackup", "--backupLocation", "destlocation",
+		"-f", archive}
+	expected = "ApplicationBackup importedbackup imported into BackupLocation destlocation\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	backup, err := storkops.Instance().GetApplicationBackup("importedbackup", "default")
+	require.NoError(t, err, "Error getting imported backup")
+	require.Equal(t, "destlocation", backup.Spec.BackupLocation, "BackupLocation mismatch")
+	require.Equal(t, "default/importedbackup", backup.Status.BackupPath[:len("default/importedbackup")], "BackupPath mismatch")
+	require.Equal(t, storkv1.ApplicationBackupReclaimPolicyDelete, backup.Spec.ReclaimPolicy, "ReclaimPolicy mismatch")
+
+	data, err := ioutil.ReadFile(filepath.Join(destDir, backup.Status.BackupPath, "volumedata"))
+	require.NoError(t, err, "Error reading imported volume data")
+	require.Equal(t, "data", string(data), "Volume data mismatch")
+	data, err = ioutil.ReadFile(filepath.Join(destDir, backup.Status.BackupPath, "secrets.json"))
+	require.NoError(t, err, "Error reading imported secrets")
+	data, err = crypto.Decrypt(data, "locationkey")
+	require.NoError(t, err, "Error decrypting imported secrets")
+	require.Equal(t, "secrets", string(data), "Secrets mismatch")
+	location, err := storkops.Instance().GetBackupLocation("destlocation", "default")
+	require.NoError(t, err, "Error getting backuplocation")
+	importedBackup, err := objectstore.GetBackup(location, backup.Status.BackupPath)
+	require.NoError(t, err, "Error reading imported metadata")
+	require.Equal(t, "importedbackup", importedBackup.Name, "Imported backup name mismatch")
+
+	// Importing the same backup again should fail
+	expected = "error: error importing applicationbackup: backup already exists at " + backup.Status.BackupPath + " in BackupLocation destlocation"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestRetryApplicationBackups(t *testing.T) {
+	defer resetTest()
+	createApplicationBackupAndVerify(t, "retrybackup", "default", []string{"namespace1"}, "backuplocation", "", "")
+
+	cmdArgs := []string{"retry", "backups"}
+	expected := "error: at least one argument needs to be provided for applicationbackup name"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"retry", "backups", "retrybackup"}
+	expected = "error: applicationbackup retrybackup can't be retried since its status is "
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	backup, err := storkops.Instance().GetApplicationBackup("retrybackup", "default")
+	require.NoError(t, err, "Error getting backup")
+	backup.Status.Stage = storkv1.ApplicationBackupStageFinal
+	backup.Status.Status = storkv1.ApplicationBackupStatusPartialSuccess
+	_, err = storkops.Instance().UpdateApplicationBackup(backup)
+	require.NoError(t, err, "Error updating backup")
+
+	cmdArgs = []string{"retry", "backups", "retrybackup"}
+	expected = "ApplicationBackup retrybackup will be retried\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	backup, err = storkops.Instance().GetApplicationBackup("retrybackup", "default")
+	require.NoError(t, err, "Error getting backup")
+	require.Contains(t, backup.Annotations, stork.RetryAnnotation, "Retry annotation missing")
+
+	cmdArgs = []string{"retry", "backups", "missingbackup"}
+	expected = "Error from server (NotFound): applicationbackups.stork.libopenstorage.org \"missingbackup\" not found"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
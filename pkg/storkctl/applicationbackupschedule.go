@@ -25,6 +25,7 @@ func newCreateApplicationBackupScheduleCommand(cmdFactory Factory, ioStreams gen
 	var postExecRule string
 	var schedulePolicyName string
 	var suspend bool
+	var rpoTarget time.Duration
 
 	createApplicationBackupScheduleCommand := &cobra.Command{
 		Use:     applicationBackupScheduleSubcommand,
@@ -67,6 +68,7 @@ func newCreateApplicationBackupScheduleCommand(cmdFactory Factory, ioStreams gen
 					},
 					SchedulePolicyName: schedulePolicyName,
 					Suspend:            &suspend,
+					RPOTarget:          getRPOTarget(rpoTarget),
 				},
 			}
 			applicationBackupSchedule.Name = applicationBackupScheduleName
@@ -86,6 +88,7 @@ func newCreateApplicationBackupScheduleCommand(cmdFactory Factory, ioStreams gen
 	createApplicationBackupScheduleCommand.Flags().StringVarP(&postExecRule, "postExecRule", "", "", "Rule to run after executing applicationBackup")
 	createApplicationBackupScheduleCommand.Flags().StringVarP(&schedulePolicyName, "schedulePolicyName", "s", "default-applicationbackup-policy", "Name of the schedule policy to use")
 	createApplicationBackupScheduleCommand.Flags().BoolVar(&suspend, "suspend", false, "Flag to denote whether schedule should be suspended on creation")
+	createApplicationBackupScheduleCommand.Flags().DurationVar(&rpoTarget, "rpoTarget", 0, "Maximum time allowed since the last successful backup before the RPO is considered breached")
 
 	return createApplicationBackupScheduleCommand
 }
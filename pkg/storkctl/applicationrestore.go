@@ -6,7 +6,9 @@ import (
 	"log"
 	"time"
 
+	"github.com/libopenstorage/stork/pkg/apis/stork"
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/objectstore"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/portworx/sched-ops/task"
 	"github.com/spf13/cobra"
@@ -30,7 +32,9 @@ func newCreateApplicationRestoreCommand(cmdFactory Factory, ioStreams genericcli
 	var backupLocation string
 	var waitForCompletion bool
 	var backupName string
+	var backupPath string
 	var replacePolicy string
+	var postExecRule string
 
 	createApplicationRestoreCommand := &cobra.Command{
 		Use:     applicationRestoreSubcommand,
@@ -45,8 +49,28 @@ func newCreateApplicationRestoreCommand(cmdFactory Factory, ioStreams genericcli
 				util.CheckErr(fmt.Errorf("need to provide BackupLocation to use for restore"))
 				return
 			}
-			if backupName == "" {
-				util.CheckErr(fmt.Errorf("need to provide BackupName to restore"))
+			if backupPath != "" {
+				// Make sure the backup exists in the location and use its
+				// name if one wasn't provided
+				location, err := storkops.Instance().GetBackupLocation(backupLocation, cmdFactory.GetNamespace())
+				if err != nil {
+					util.CheckErr(err)
+					return
+				}
+				if err := objectstore.ValidateBackupPath(location.Namespace, backupPath); err != nil {
+					util.CheckErr(err)
+					return
+				}
+				backup, err := objectstore.GetBackup(location, backupPath)
+				if err != nil {
+					util.CheckErr(fmt.Errorf("error getting backup %v from BackupLocation %v: %v", backupPath, backupLocation, err))
+					return
+				}
+				if backupName == "" {
+					backupName = backup.Name
+				}
+			} else if backupName == "" {
+				util.CheckErr(fmt.Errorf("need to provide BackupName or BackupPath to restore"))
 				return
 			}
 
@@ -55,7 +79,9 @@ func newCreateApplicationRestoreCommand(cmdFactory Factory, ioStreams genericcli
 				Spec: storkv1.ApplicationRestoreSpec{
 					BackupLocation: backupLocation,
 					BackupName:     backupName,
+					BackupPath:     backupPath,
 					ReplacePolicy:  storkv1.ApplicationRestoreReplacePolicyType(replacePolicy),
+					PostExecRule:   postExecRule,
 				},
 			}
 			applicationRestore.Name = applicationRestoreName
@@ -82,7 +108,9 @@ func newCreateApplicationRestoreCommand(cmdFactory Factory, ioStreams genericcli
 	createApplicationRestoreCommand.Flags().BoolVarP(&waitForCompletion, "wait", "w", false, "Wait for applicationrestore to complete")
 	createApplicationRestoreCommand.Flags().StringVarP(&backupLocation, "backupLocation", "l", "", "BackupLocation to use for the restore")
 	createApplicationRestoreCommand.Flags().StringVarP(&backupName, "backupName", "b", "", "Backup to restore from")
-	createApplicationRestoreCommand.Flags().StringVarP(&replacePolicy, "replacePolicy", "r", "Retain", "Policy to use if resources being restored already exist (Retain or Delete).")
+	createApplicationRestoreCommand.Flags().StringVarP(&backupPath, "backupPath", "", "", "Path of the backup in the BackupLocation to restore from, without an applicationbackup in the cluster")
+	createApplicationRestoreCommand.Flags().StringVarP(&replacePolicy, "replacePolicy", "r", "Retain", "Policy to use if resources being restored already exist (Retain, Delete or Update).")
+	createApplicationRestoreCommand.Flags().StringVarP(&postExecRule, "postExecRule", "", "", "Rule to run after the applicationrestore completes")
 
 	return createApplicationRestoreCommand
 }
@@ -163,6 +191,48 @@ func newDeleteApplicationRestoreCommand(cmdFactory Factory, ioStreams genericcli
 	return deleteApplicationRestoreCommand
 }
 
+func newRetryApplicationRestoreCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	retryApplicationRestoreCommand := &cobra.Command{
+		Use:     applicationRestoreSubcommand,
+		Aliases: applicationRestoreAliases,
+		Short:   "Retry the failed volumes and resources of applicationrestores",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) == 0 {
+				util.CheckErr(fmt.Errorf("at least one argument needs to be provided for applicationrestore name"))
+				return
+			}
+			retryApplicationRestores(args, cmdFactory.GetNamespace(), ioStreams)
+		},
+	}
+
+	return retryApplicationRestoreCommand
+}
+
+func retryApplicationRestores(applicationRestores []string, namespace string, ioStreams genericclioptions.IOStreams) {
+	for _, name := range applicationRestores {
+		applicationRestore, err := storkops.Instance().GetApplicationRestore(name, namespace)
+		if err != nil {
+			util.CheckErr(err)
+			return
+		}
+		if applicationRestore.Status.Status != storkv1.ApplicationRestoreStatusFailed &&
+			applicationRestore.Status.Status != storkv1.ApplicationRestoreStatusPartialSuccess {
+			util.CheckErr(fmt.Errorf("applicationrestore %v can't be retried since its status is %v", name, applicationRestore.Status.Status))
+			return
+		}
+		if applicationRestore.Annotations == nil {
+			applicationRestore.Annotations = make(map[string]string)
+		}
+		applicationRestore.Annotations[stork.RetryAnnotation] = "true"
+		if _, err := storkops.Instance().UpdateApplicationRestore(applicationRestore); err != nil {
+			util.CheckErr(err)
+			return
+		}
+		msg := fmt.Sprintf("ApplicationRestore %v will be retried", name)
+		printMsg(msg, ioStreams.Out)
+	}
+}
+
 func deleteApplicationRestores(applicationRestores []string, namespace string, ioStreams genericclioptions.IOStreams) {
 	for _, applicationRestore := range applicationRestores {
 		err := storkops.Instance().DeleteApplicationRestore(applicationRestore, namespace)
@@ -3,9 +3,11 @@
 package storkctl
 
 import (
+	"os"
 	"testing"
 	"time"
 
+	"github.com/libopenstorage/stork/pkg/apis/stork"
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	core "github.com/portworx/sched-ops/k8s/core"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
@@ -123,6 +125,34 @@ func TestCreateApplicationRestores(t *testing.T) {
 	createApplicationRestoreAndVerify(t, "createrestore", "default", []string{"namespace1"}, "backuplocation", "backupname")
 }
 
+func TestCreateApplicationRestoresWithPostExecRule(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"create", "apprestores", "-n", "default", "restorewithrule", "--backupLocation", "backuplocation",
+		"--backupName", "backupname", "--postExecRule", "postrule"}
+	expected := "ApplicationRestore restorewithrule started successfully\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	restore, err := storkops.Instance().GetApplicationRestore("restorewithrule", "default")
+	require.NoError(t, err, "Error getting restore")
+	require.Equal(t, "postrule", restore.Spec.PostExecRule, "ApplicationRestore postExecRule mismatch")
+}
+
+func TestCreateApplicationRestoresFromBackupPath(t *testing.T) {
+	defer resetTest()
+	dir, _ := createLocationBackups(t, "default", "filesystemlocation")
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	cmdArgs := []string{"create", "apprestores", "-n", "default", "restorefrompath", "--backupLocation", "filesystemlocation",
+		"--backupPath", "default/backup1/uid1"}
+	expected := "ApplicationRestore restorefrompath started successfully\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	restore, err := storkops.Instance().GetApplicationRestore("restorefrompath", "default")
+	require.NoError(t, err, "Error getting restore")
+	require.Equal(t, "backup1", restore.Spec.BackupName, "ApplicationRestore backupName mismatch")
+	require.Equal(t, "default/backup1/uid1", restore.Spec.BackupPath, "ApplicationRestore backupPath mismatch")
+}
+
 func TestCreateApplicationRestoresMissingParameters(t *testing.T) {
 	defer resetTest()
 	cmdArgs := []string{"create", "apprestores", "createrestore", "--backupName", "backupname"}
@@ -130,7 +160,7 @@ func TestCreateApplicationRestoresMissingParameters(t *testing.T) {
 	testCommon(t, cmdArgs, nil, expected, true)
 
 	cmdArgs = []string{"create", "apprestores", "createrestore", "--backupLocation", "backuplocation"}
-	expected = "error: need to provide BackupName to restore"
+	expected = "error: need to provide BackupName or BackupPath to restore"
 	testCommon(t, cmdArgs, nil, expected, true)
 }
 
@@ -224,3 +254,35 @@ func setApplicationRestoreStatus(name, namespace string, isFail bool, t *testing
 	_, err = storkops.Instance().UpdateApplicationRestore(restore)
 	require.NoError(t, err, "Error updating ApplicationRestores")
 }
+
+func TestRetryApplicationRestores(t *testing.T) {
+	defer resetTest()
+	createApplicationRestoreAndVerify(t, "retryrestore", "default", []string{"namespace1"}, "backuplocation", "backupname")
+
+	cmdArgs := []string{"retry", "apprestores"}
+	expected := "error: at least one argument needs to be provided for applicationrestore name"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	restore, err := storkops.Instance().GetApplicationRestore("retryrestore", "default")
+	require.NoError(t, err, "Error getting restore")
+	restore.Status.Stage = storkv1.ApplicationRestoreStageFinal
+	restore.Status.Status = storkv1.ApplicationRestoreStatusSuccessful
+	_, err = storkops.Instance().UpdateApplicationRestore(restore)
+	require.NoError(t, err, "Error updating restore")
+
+	cmdArgs = []string{"retry", "apprestores", "retryrestore"}
+	expected = "error: applicationrestore retryrestore can't be retried since its status is Successful"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	restore.Status.Status = storkv1.ApplicationRestoreStatusFailed
+	_, err = storkops.Instance().UpdateApplicationRestore(restore)
+	require.NoError(t, err, "Error updating restore")
+
+	cmdArgs = []string{"retry", "apprestores", "retryrestore"}
+	expected = "ApplicationRestore retryrestore will be retried\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	restore, err = storkops.Instance().GetApplicationRestore("retryrestore", "default")
+	require.NoError(t, err, "Error getting restore")
+	require.Contains(t, restore.Annotations, stork.RetryAnnotation, "Retry annotation missing")
+}
@@ -17,9 +17,10 @@ const (
 	hiddenString             = "<HIDDEN>"
 )
 
-var s3BackupLocationColumns = []string{"NAME", "PATH", "ACCESS-KEY-ID", "SECRET-ACCESS-KEY", "REGION", "ENDPOINT", "SSL-DISABLED"}
-var azureBackupLocationColumns = []string{"NAME", "PATH", "STORAGE-ACCOUNT-NAME", "STORAGE-ACCOUNT-KEY"}
-var googleBackupLocationColumns = []string{"NAME", "PATH", "PROJECT-ID"}
+var s3BackupLocationColumns = []string{"NAME", "PATH", "ACCESS-KEY-ID", "SECRET-ACCESS-KEY", "REGION", "ENDPOINT", "SSL-DISABLED", "STATUS"}
+var azureBackupLocationColumns = []string{"NAME", "PATH", "STORAGE-ACCOUNT-NAME", "STORAGE-ACCOUNT-KEY", "STATUS"}
+var googleBackupLocationColumns = []string{"NAME", "PATH", "PROJECT-ID", "STATUS"}
+var filesystemBackupLocationColumns = []string{"NAME", "PATH", "STATUS"}
 
 func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var showSecrets bool
@@ -73,6 +74,7 @@ func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions
 			s3BackupLocations := &storkv1.BackupLocationList{}
 			azureBackupLocations := &storkv1.BackupLocationList{}
 			googleBackupLocations := &storkv1.BackupLocationList{}
+			filesystemBackupLocations := &storkv1.BackupLocationList{}
 			unknownBackupLocations := &storkv1.BackupLocationList{}
 			for _, bl := range backupLocations.Items {
 				switch bl.Location.Type {
@@ -91,6 +93,8 @@ func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions
 						bl.Location.GoogleConfig.AccountKey = hiddenString
 					}
 					googleBackupLocations.Items = append(googleBackupLocations.Items, bl)
+				case storkv1.BackupLocationFilesystem:
+					filesystemBackupLocations.Items = append(filesystemBackupLocations.Items, bl)
 				default:
 					unknownBackupLocations.Items = append(unknownBackupLocations.Items, bl)
 				}
@@ -132,6 +136,16 @@ func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions
 						return
 					}
 				}
+				if len(filesystemBackupLocations.Items) != 0 {
+					if _, err := fmt.Fprintf(ioStreams.Out, "\nFilesystem:\n-----------\n"); err != nil {
+						util.CheckErr(err)
+						return
+					}
+					if err := printObjects(c, filesystemBackupLocations, cmdFactory, filesystemBackupLocationColumns, filesystemBackupLocationPrinter, ioStreams.Out); err != nil {
+						util.CheckErr(err)
+						return
+					}
+				}
 			} else {
 				if err := printObjects(c, backupLocations, cmdFactory, nil, nil, ioStreams.Out); err != nil {
 					util.CheckErr(err)
@@ -164,7 +178,8 @@ func s3BackupLocationPrinter(
 				backupLocation.Location.S3Config.SecretAccessKey,
 				backupLocation.Location.S3Config.Region,
 				backupLocation.Location.S3Config.Endpoint,
-				backupLocation.Location.S3Config.DisableSSL},
+				backupLocation.Location.S3Config.DisableSSL,
+				backupLocation.Status.Status},
 		)
 		rows = append(rows, row)
 
@@ -185,7 +200,8 @@ func azureBackupLocationPrinter(
 			[]interface{}{backupLocation.Name,
 				backupLocation.Location.Path,
 				backupLocation.Location.AzureConfig.StorageAccountName,
-				backupLocation.Location.AzureConfig.StorageAccountKey},
+				backupLocation.Location.AzureConfig.StorageAccountKey,
+				backupLocation.Status.Status},
 		)
 		rows = append(rows, row)
 	}
@@ -205,7 +221,28 @@ func googleBackupLocationPrinter(
 		row := getRow(&backupLocation,
 			[]interface{}{backupLocation.Name,
 				backupLocation.Location.Path,
-				backupLocation.Location.GoogleConfig.ProjectID},
+				backupLocation.Location.GoogleConfig.ProjectID,
+				backupLocation.Status.Status},
+		)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func filesystemBackupLocationPrinter(
+	backupLocationList *storkv1.BackupLocationList,
+	options printers.GenerateOptions,
+) ([]metav1beta1.TableRow, error) {
+	if backupLocationList == nil {
+		return nil, nil
+	}
+
+	rows := make([]metav1beta1.TableRow, 0)
+	for _, backupLocation := range backupLocationList.Items {
+		row := getRow(&backupLocation,
+			[]interface{}{backupLocation.Name,
+				backupLocation.Location.Path,
+				backupLocation.Status.Status},
 		)
 		rows = append(rows, row)
 	}
@@ -41,8 +41,8 @@ func TestBackupLocationNotFound(t *testing.T) {
 	testCommon(t, cmdArgs, nil, expected, true)
 
 	expected = "\nS3:\n---\n" +
-		"NAME            PATH   ACCESS-KEY-ID   SECRET-ACCESS-KEY   REGION      ENDPOINT           SSL-DISABLED\n" +
-		"testlocation1                          <HIDDEN>            us-east-1   s3.amazonaws.com   false\n"
+		"NAME            PATH   ACCESS-KEY-ID   SECRET-ACCESS-KEY   REGION      ENDPOINT           SSL-DISABLED   STATUS\n" +
+		"testlocation1                          <HIDDEN>            us-east-1   s3.amazonaws.com   false          \n"
 	cmdArgs = []string{"get", "backuplocation", "testlocation1"}
 	testCommon(t, cmdArgs, nil, expected, false)
 }
@@ -63,8 +63,8 @@ func TestS3BackupLocation(t *testing.T) {
 	require.NoError(t, err, "Error creating backuplocation")
 
 	expected := "\nS3:\n---\n" +
-		"NAME         PATH   ACCESS-KEY-ID   SECRET-ACCESS-KEY   REGION      ENDPOINT           SSL-DISABLED\n" +
-		"s3location                          <HIDDEN>            us-east-1   s3.amazonaws.com   false\n"
+		"NAME         PATH   ACCESS-KEY-ID   SECRET-ACCESS-KEY   REGION      ENDPOINT           SSL-DISABLED   STATUS\n" +
+		"s3location                          <HIDDEN>            us-east-1   s3.amazonaws.com   false          \n"
 	cmdArgs := []string{"get", "backuplocation", "s3location"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -80,13 +80,13 @@ func TestS3BackupLocation(t *testing.T) {
 	require.NoError(t, err, "Error updating backuplocation")
 
 	expected = "\nS3:\n---\n" +
-		"NAME         PATH       ACCESS-KEY-ID   SECRET-ACCESS-KEY   REGION      ENDPOINT    SSL-DISABLED\n" +
-		"s3location   testpath   accesskey       <HIDDEN>            us-west-1   127.0.0.1   true\n"
+		"NAME         PATH       ACCESS-KEY-ID   SECRET-ACCESS-KEY   REGION      ENDPOINT    SSL-DISABLED   STATUS\n" +
+		"s3location   testpath   accesskey       <HIDDEN>            us-west-1   127.0.0.1   true           \n"
 	testCommon(t, cmdArgs, nil, expected, false)
 
 	expected = "\nS3:\n---\n" +
-		"NAME         PATH       ACCESS-KEY-ID   SECRET-ACCESS-KEY   REGION      ENDPOINT    SSL-DISABLED\n" +
-		"s3location   testpath   accesskey       secretKey           us-west-1   127.0.0.1   true\n"
+		"NAME         PATH       ACCESS-KEY-ID   SECRET-ACCESS-KEY   REGION      ENDPOINT    SSL-DISABLED   STATUS\n" +
+		"s3location   testpath   accesskey       secretKey           us-west-1   127.0.0.1   true           \n"
 	cmdArgs = []string{"get", "backuplocation", "s3location", "-s"}
 	testCommon(t, cmdArgs, nil, expected, false)
 }
@@ -107,8 +107,8 @@ func TestAzureBackupLocation(t *testing.T) {
 	require.NoError(t, err, "Error creating backuplocation")
 
 	expected := "\nAzureBlob:\n----------\n" +
-		"NAME            PATH   STORAGE-ACCOUNT-NAME   STORAGE-ACCOUNT-KEY\n" +
-		"azurelocation                                 <HIDDEN>\n"
+		"NAME            PATH   STORAGE-ACCOUNT-NAME   STORAGE-ACCOUNT-KEY   STATUS\n" +
+		"azurelocation                                 <HIDDEN>              \n"
 	cmdArgs := []string{"get", "backuplocation", "azurelocation"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -121,13 +121,13 @@ func TestAzureBackupLocation(t *testing.T) {
 	require.NoError(t, err, "Error updating backuplocation")
 
 	expected = "\nAzureBlob:\n----------\n" +
-		"NAME            PATH       STORAGE-ACCOUNT-NAME   STORAGE-ACCOUNT-KEY\n" +
-		"azurelocation   testpath   accountname            <HIDDEN>\n"
+		"NAME            PATH       STORAGE-ACCOUNT-NAME   STORAGE-ACCOUNT-KEY   STATUS\n" +
+		"azurelocation   testpath   accountname            <HIDDEN>              \n"
 	testCommon(t, cmdArgs, nil, expected, false)
 
 	expected = "\nAzureBlob:\n----------\n" +
-		"NAME            PATH       STORAGE-ACCOUNT-NAME   STORAGE-ACCOUNT-KEY\n" +
-		"azurelocation   testpath   accountname            accountkey\n"
+		"NAME            PATH       STORAGE-ACCOUNT-NAME   STORAGE-ACCOUNT-KEY   STATUS\n" +
+		"azurelocation   testpath   accountname            accountkey            \n"
 	cmdArgs = []string{"get", "backuplocation", "azurelocation", "-s"}
 	testCommon(t, cmdArgs, nil, expected, false)
 }
@@ -148,8 +148,8 @@ func TestGoogleBackupLocation(t *testing.T) {
 	require.NoError(t, err, "Error creating backuplocation")
 
 	expected := "\nGoogleCloudStorage:\n-------------------\n" +
-		"NAME             PATH   PROJECT-ID\n" +
-		"googlelocation          \n"
+		"NAME             PATH   PROJECT-ID   STATUS\n" +
+		"googlelocation                       \n"
 	cmdArgs := []string{"get", "backuplocation", "googlelocation"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -161,8 +161,34 @@ func TestGoogleBackupLocation(t *testing.T) {
 	require.NoError(t, err, "Error updating backuplocation")
 
 	expected = "\nGoogleCloudStorage:\n-------------------\n" +
-		"NAME             PATH       PROJECT-ID\n" +
-		"googlelocation   testpath   testproject\n"
+		"NAME             PATH       PROJECT-ID    STATUS\n" +
+		"googlelocation   testpath   testproject   \n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestFilesystemBackupLocation(t *testing.T) {
+	defer resetTest()
+
+	backupLocation := &storkv1.BackupLocation{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "fslocation",
+			Namespace: "default",
+		},
+		Location: storkv1.BackupLocationItem{
+			Type: storkv1.BackupLocationFilesystem,
+			Path: "/mnt/backups",
+		},
+		Status: storkv1.BackupLocationStatus{
+			Status: storkv1.BackupLocationStatusReady,
+		},
+	}
+	_, err := storkops.Instance().CreateBackupLocation(backupLocation)
+	require.NoError(t, err, "Error creating backuplocation")
+
+	expected := "\nFilesystem:\n-----------\n" +
+		"NAME         PATH           STATUS\n" +
+		"fslocation   /mnt/backups   Ready\n"
+	cmdArgs := []string{"get", "backuplocation", "fslocation"}
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
@@ -230,32 +256,32 @@ func TestAllBackupLocation(t *testing.T) {
 	require.NoError(t, err, "Error creating backuplocation")
 
 	expected := "\nAzureBlob:\n----------\n" +
-		"NAME            PATH        STORAGE-ACCOUNT-NAME   STORAGE-ACCOUNT-KEY\n" +
-		"azurelocation   azurepath   accountname            <HIDDEN>\n"
+		"NAME            PATH        STORAGE-ACCOUNT-NAME   STORAGE-ACCOUNT-KEY   STATUS\n" +
+		"azurelocation   azurepath   accountname            <HIDDEN>              \n"
 	cmdArgs := []string{"get", "backuplocation", "azurelocation", "-n", "azure"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
 	expected = "\nS3:\n---\n" +
-		"NAME         PATH     ACCESS-KEY-ID   SECRET-ACCESS-KEY   REGION      ENDPOINT    SSL-DISABLED\n" +
-		"s3location   s3path   accesskey       <HIDDEN>            us-west-1   127.0.0.1   true\n"
+		"NAME         PATH     ACCESS-KEY-ID   SECRET-ACCESS-KEY   REGION      ENDPOINT    SSL-DISABLED   STATUS\n" +
+		"s3location   s3path   accesskey       <HIDDEN>            us-west-1   127.0.0.1   true           \n"
 	cmdArgs = []string{"get", "backuplocation", "s3location", "-n", "s3"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
 	expected = "\nGoogleCloudStorage:\n-------------------\n" +
-		"NAME             PATH       PROJECT-ID\n" +
-		"googlelocation   testpath   testproject\n"
+		"NAME             PATH       PROJECT-ID    STATUS\n" +
+		"googlelocation   testpath   testproject   \n"
 	cmdArgs = []string{"get", "backuplocation", "googlelocation", "-n", "google"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
 	expected = "\nS3:\n---\n" +
-		"NAMESPACE   NAME         PATH     ACCESS-KEY-ID   SECRET-ACCESS-KEY   REGION      ENDPOINT    SSL-DISABLED\n" +
-		"s3          s3location   s3path   accesskey       <HIDDEN>            us-west-1   127.0.0.1   true\n\n" +
+		"NAMESPACE   NAME         PATH     ACCESS-KEY-ID   SECRET-ACCESS-KEY   REGION      ENDPOINT    SSL-DISABLED   STATUS\n" +
+		"s3          s3location   s3path   accesskey       <HIDDEN>            us-west-1   127.0.0.1   true           \n\n" +
 		"GoogleCloudStorage:\n-------------------\n" +
-		"NAMESPACE   NAME             PATH       PROJECT-ID\n" +
-		"google      googlelocation   testpath   testproject\n\n" +
+		"NAMESPACE   NAME             PATH       PROJECT-ID    STATUS\n" +
+		"google      googlelocation   testpath   testproject   \n\n" +
 		"AzureBlob:\n----------\n" +
-		"NAMESPACE   NAME            PATH        STORAGE-ACCOUNT-NAME   STORAGE-ACCOUNT-KEY\n" +
-		"azure       azurelocation   azurepath   accountname            <HIDDEN>\n"
+		"NAMESPACE   NAME            PATH        STORAGE-ACCOUNT-NAME   STORAGE-ACCOUNT-KEY   STATUS\n" +
+		"azure       azurelocation   azurepath   accountname            <HIDDEN>              \n"
 	cmdArgs = []string{"get", "backuplocation", "--all-namespaces"}
 	testCommon(t, cmdArgs, nil, expected, false)
 }
@@ -6,15 +6,28 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/libopenstorage/stork/pkg/apis/stork"
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/portworx/sched-ops/k8s/rbac"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/portworx/sched-ops/task"
 	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/validation"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubernetes/pkg/printers"
 )
@@ -24,8 +37,76 @@ const (
 	cmdPathKey            = "cmd-path"
 	gcloudPath            = "./google-cloud-sdk/bin/gcloud"
 	gcloudBinaryName      = "gcloud"
+
+	clusterPairCredentialsSuffix     = "-credentials"
+	serviceAccountTokenSuffix        = "-token"
+	defaultServiceAccountNamespace   = "kube-system"
+	serviceAccountTokenTimeout       = 1 * time.Minute
+	serviceAccountTokenRetryInterval = 2 * time.Second
 )
 
+// Permissions required on the destination cluster to migrate resources and
+// to manage migrations for failover. They are granted in all namespaces.
+// Access to Namespaces and Secrets is only granted for the namespaces being
+// migrated, and rules for the kinds in the application registry are added to
+// these
+var clusterPairServiceAccountRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"persistentvolumes", "persistentvolumeclaims", "configmaps",
+			"services", "serviceaccounts"},
+		Verbs: clusterPairServiceAccountVerbs,
+	},
+	{
+		APIGroups: []string{"apps"},
+		Resources: []string{"deployments", "statefulsets", "daemonsets"},
+		Verbs:     clusterPairServiceAccountVerbs,
+	},
+	{
+		APIGroups: []string{"extensions", "networking.k8s.io"},
+		Resources: []string{"ingresses"},
+		Verbs:     clusterPairServiceAccountVerbs,
+	},
+	{
+		APIGroups: []string{"image.openshift.io", "route.openshift.io", "template.openshift.io"},
+		Resources: []string{"imagestreams", "routes", "templates"},
+		Verbs:     clusterPairServiceAccountVerbs,
+	},
+	{
+		APIGroups: []string{stork.GroupName},
+		Resources: []string{storkv1.MigrationResourcePlural, storkv1.MigrationScheduleResourcePlural,
+			storkv1.SchedulePolicyResourcePlural},
+		Verbs: clusterPairServiceAccountVerbs,
+	},
+}
+
+// Permissions required to migrate roles and their bindings. The roles being
+// migrated can grant any permission, so the escalate and bind verbs are
+// needed, which makes the service account equivalent to cluster-admin. They
+// are only granted when requested
+var clusterPairServiceAccountRBACRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{"rbac.authorization.k8s.io"},
+		Resources: []string{"roles", "rolebindings", "clusterroles", "clusterrolebindings"},
+		Verbs:     append([]string{"bind", "escalate"}, clusterPairServiceAccountVerbs...),
+	},
+}
+
+// Permissions granted through a Role in each of the namespaces being migrated
+var clusterPairServiceAccountNamespaceRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"secrets"},
+		Verbs:     clusterPairServiceAccountVerbs,
+	},
+}
+
+// Verbs allowed on the namespaces being migrated. They are created by storkctl
+// since creating namespaces can't be limited to specific names
+var clusterPairServiceAccountNamespaceVerbs = []string{"get", "update", "patch"}
+
+var clusterPairServiceAccountVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+
 var clusterPairColumns = []string{"NAME", "STORAGE-STATUS", "SCHEDULER-STATUS", "CREATED"}
 
 func newGetClusterPairCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
@@ -127,6 +208,12 @@ func getByteData(fileName string) ([]byte, error) {
 }
 
 func newGenerateClusterPairCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var serviceAccount string
+	var serviceAccountNamespace string
+	var rotateToken bool
+	var migrateRBAC bool
+	var namespaces []string
+	var adminNamespace string
 	generateClusterPairCommand := &cobra.Command{
 		Use:   clusterPairSubcommand,
 		Short: "Generate a spec to be used for cluster pairing from a remote cluster",
@@ -152,6 +239,20 @@ func newGenerateClusterPairCommand(cmdFactory Factory, ioStreams genericclioptio
 				util.CheckErr(err)
 				return
 			}
+			if rotateToken && serviceAccount == "" {
+				util.CheckErr(fmt.Errorf("serviceAccount needs to be provided to rotate the token"))
+				return
+			}
+			if serviceAccount != "" && !rotateToken && len(namespaces) == 0 {
+				util.CheckErr(fmt.Errorf("namespaces that will be migrated need to be provided to create the serviceAccount"))
+				return
+			}
+			for _, ns := range namespaces {
+				if errors := validation.ValidateNamespaceName(ns, false); len(errors) != 0 {
+					util.CheckErr(fmt.Errorf("the Namespace \"%v\" is not valid: %v", ns, errors))
+					return
+				}
+			}
 
 			// Prune out all but the current-context and related
 			// info
@@ -224,6 +325,56 @@ func newGenerateClusterPairCommand(cmdFactory Factory, ioStreams genericclioptio
 					config.Clusters[currentCluster].CertificateAuthority = ""
 				}
 
+				var credentials *v1.Secret
+				if serviceAccount != "" {
+					// Only the token is replaced when rotating
+					if !rotateToken {
+						rules, err := getClusterPairServiceAccountRules(migrateRBAC, namespaces, adminNamespace)
+						if err != nil {
+							util.CheckErr(err)
+							return
+						}
+						if err := createClusterPairServiceAccount(serviceAccount, serviceAccountNamespace, rules, namespaces); err != nil {
+							util.CheckErr(err)
+							return
+						}
+					}
+					token, err := getClusterPairServiceAccountToken(serviceAccount, serviceAccountNamespace, rotateToken)
+					if err != nil {
+						util.CheckErr(err)
+						return
+					}
+					credentials = &v1.Secret{
+						TypeMeta: meta.TypeMeta{
+							Kind:       reflect.TypeOf(v1.Secret{}).Name(),
+							APIVersion: v1.SchemeGroupVersion.String(),
+						},
+						ObjectMeta: meta.ObjectMeta{
+							Name:      clusterPairName + clusterPairCredentialsSuffix,
+							Namespace: cmdFactory.GetNamespace(),
+						},
+						Data: map[string][]byte{
+							storkv1.ClusterPairSecretTokenKey: []byte(token),
+						},
+					}
+					if err = printEncoded(c, credentials, "yaml", ioStreams.Out); err != nil {
+						util.CheckErr(err)
+						return
+					}
+					// The pair reads the Secret every time it is used, so
+					// only the Secret needs to be updated when rotating
+					if rotateToken {
+						return
+					}
+					printMsg("---", ioStreams.Out)
+
+					// Don't include the credentials of the current user
+					currentAuthInfo := config.Contexts[currentContext].AuthInfo
+					config.AuthInfos = map[string]*clientcmdapi.AuthInfo{
+						currentAuthInfo: {},
+					}
+				}
+
 				clusterPair := &storkv1.ClusterPair{
 					TypeMeta: meta.TypeMeta{
 						Kind:       reflect.TypeOf(storkv1.ClusterPair{}).Name(),
@@ -241,6 +392,9 @@ func newGenerateClusterPairCommand(cmdFactory Factory, ioStreams genericclioptio
 						},
 					},
 				}
+				if credentials != nil {
+					clusterPair.Spec.CredentialsSecret = credentials.Name
+				}
 				if err = printEncoded(c, clusterPair, "yaml", ioStreams.Out); err != nil {
 					util.CheckErr(err)
 					return
@@ -249,5 +403,251 @@ func newGenerateClusterPairCommand(cmdFactory Factory, ioStreams genericclioptio
 		},
 	}
 
+	generateClusterPairCommand.Flags().StringVar(&serviceAccount, "serviceAccount", "",
+		"Create a ServiceAccount with this name with the permissions required for migration and use its token for the pair")
+	generateClusterPairCommand.Flags().StringVar(&serviceAccountNamespace, "serviceAccountNamespace", defaultServiceAccountNamespace,
+		"Namespace in which to create the ServiceAccount")
+	generateClusterPairCommand.Flags().BoolVar(&rotateToken, "rotateToken", false,
+		"Create a new token for the ServiceAccount and only generate the Secret with the new token")
+	generateClusterPairCommand.Flags().StringSliceVar(&namespaces, "namespaces", nil,
+		"Comma separated list of namespaces on this cluster that applications will be migrated into. The ServiceAccount "+
+			"is only given access to Namespaces and Secrets in these namespaces, which are created if they don't exist")
+	generateClusterPairCommand.Flags().BoolVar(&migrateRBAC, "migrateRBAC", false,
+		"Make the ServiceAccount equivalent to cluster-admin so that it can migrate Roles, ClusterRoles and their "+
+			"bindings, since these can grant any permission")
+	generateClusterPairCommand.Flags().StringVar(&adminNamespace, "adminNamespace", defaultAdminNamespace,
+		"Admin namespace of stork, which has the application registry used to add permissions for the ServiceAccount")
+
 	return generateClusterPairCommand
 }
+
+// getClusterPairServiceAccountRules returns the rules for the ClusterRole of
+// the ServiceAccount used by ClusterPairs, including the kinds in the
+// application registry in the admin namespace that aren't already covered.
+// Access to Namespaces is limited to the given namespaces
+func getClusterPairServiceAccountRules(
+	migrateRBAC bool,
+	namespaces []string,
+	adminNamespace string,
+) ([]rbacv1.PolicyRule, error) {
+	rules := append([]rbacv1.PolicyRule{}, clusterPairServiceAccountRules...)
+	resourceNames := append([]string{}, namespaces...)
+	sort.Strings(resourceNames)
+	rules = append(rules, rbacv1.PolicyRule{
+		APIGroups:     []string{""},
+		Resources:     []string{"namespaces"},
+		ResourceNames: resourceNames,
+		Verbs:         clusterPairServiceAccountNamespaceVerbs,
+	})
+	if migrateRBAC {
+		rules = append(rules, clusterPairServiceAccountRBACRules...)
+	}
+	covered := make(map[schema.GroupResource]bool)
+	for _, rule := range rules {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				covered[schema.GroupResource{Group: group, Resource: resource}] = true
+			}
+		}
+	}
+
+	registry, err := resourcecollector.GetApplicationRegistry(core.Instance(), adminNamespace)
+	if err != nil {
+		return nil, err
+	}
+	registryResources := make(map[string][]string)
+	for _, options := range registry {
+		gvk := schema.GroupVersionKind{Group: options.Group, Version: options.Version, Kind: options.Kind}
+		resource, _ := apimeta.UnsafeGuessKindToResource(gvk)
+		if covered[resource.GroupResource()] {
+			continue
+		}
+		covered[resource.GroupResource()] = true
+		registryResources[options.Group] = append(registryResources[options.Group], resource.Resource)
+	}
+	groups := make([]string, 0, len(registryResources))
+	for group := range registryResources {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		resources := registryResources[group]
+		sort.Strings(resources)
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: resources,
+			Verbs:     clusterPairServiceAccountVerbs,
+		})
+	}
+	return rules, nil
+}
+
+// createClusterPairServiceAccount creates the ServiceAccount used by
+// ClusterPairs along with its ClusterRole and ClusterRoleBinding if they don't
+// exist, and a Role and RoleBinding in each of the namespaces being migrated.
+// The namespaces are created if they don't exist. The rules of existing roles
+// are updated
+func createClusterPairServiceAccount(
+	name string,
+	namespace string,
+	rules []rbacv1.PolicyRule,
+	namespaces []string,
+) error {
+	_, err := core.Instance().CreateServiceAccount(&v1.ServiceAccount{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("error creating service account %v/%v: %v", namespace, name, err)
+	}
+	_, err = rbac.Instance().CreateClusterRole(&rbacv1.ClusterRole{
+		ObjectMeta: meta.ObjectMeta{
+			Name: name,
+		},
+		Rules: rules,
+	})
+	if errors.IsAlreadyExists(err) {
+		err = updateClusterPairServiceAccountRules(name, rules)
+	}
+	if err != nil {
+		return fmt.Errorf("error creating cluster role %v: %v", name, err)
+	}
+	_, err = rbac.Instance().CreateClusterRoleBinding(&rbacv1.ClusterRoleBinding{
+		ObjectMeta: meta.ObjectMeta{
+			Name: name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      name,
+				Namespace: namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     reflect.TypeOf(rbacv1.ClusterRole{}).Name(),
+			Name:     name,
+		},
+	})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("error creating cluster role binding %v: %v", name, err)
+	}
+	for _, ns := range namespaces {
+		if err := createClusterPairNamespaceRole(name, namespace, ns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createClusterPairNamespaceRole creates the namespace if it doesn't exist
+// along with the Role and RoleBinding that give the ServiceAccount access to
+// the Secrets in it
+func createClusterPairNamespaceRole(name string, serviceAccountNamespace string, namespace string) error {
+	_, err := core.Instance().CreateNamespace(namespace, nil)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("error creating namespace %v: %v", namespace, err)
+	}
+	_, err = rbac.Instance().CreateRole(&rbacv1.Role{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Rules: clusterPairServiceAccountNamespaceRules,
+	})
+	if errors.IsAlreadyExists(err) {
+		var role *rbacv1.Role
+		if role, err = rbac.Instance().GetRole(name, namespace); err == nil && !reflect.DeepEqual(role.Rules, clusterPairServiceAccountNamespaceRules) {
+			role.Rules = clusterPairServiceAccountNamespaceRules
+			_, err = rbac.Instance().UpdateRole(role)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error creating role %v/%v: %v", namespace, name, err)
+	}
+	_, err = rbac.Instance().CreateRoleBinding(&rbacv1.RoleBinding{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      name,
+				Namespace: serviceAccountNamespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     reflect.TypeOf(rbacv1.Role{}).Name(),
+			Name:     name,
+		},
+	})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("error creating role binding %v/%v: %v", namespace, name, err)
+	}
+	return nil
+}
+
+// getClusterPairServiceAccountToken creates the token Secret for the
+// ServiceAccount used by ClusterPairs if it doesn't exist and returns the
+// token. If rotate is set the token Secret is recreated, which also
+// invalidates the previous token
+func getClusterPairServiceAccountToken(
+	name string,
+	namespace string,
+	rotate bool,
+) (string, error) {
+	secretName := name + serviceAccountTokenSuffix
+	if rotate {
+		if err := core.Instance().DeleteSecret(secretName, namespace); err != nil && !errors.IsNotFound(err) {
+			return "", fmt.Errorf("error deleting token secret %v/%v: %v", namespace, secretName, err)
+		}
+	}
+	_, err := core.Instance().CreateSecret(&v1.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      secretName,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				v1.ServiceAccountNameKey: name,
+			},
+		},
+		Type: v1.SecretTypeServiceAccountToken,
+	})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("error creating token secret %v/%v: %v", namespace, secretName, err)
+	}
+
+	// The token is populated by the token controller
+	t := func() (interface{}, bool, error) {
+		secret, err := core.Instance().GetSecret(secretName, namespace)
+		if err != nil {
+			return "", true, err
+		}
+		token, ok := secret.Data[v1.ServiceAccountTokenKey]
+		if !ok || len(token) == 0 {
+			return "", true, fmt.Errorf("token not populated in secret %v/%v", namespace, secretName)
+		}
+		return string(token), false, nil
+	}
+	token, err := task.DoRetryWithTimeout(t, serviceAccountTokenTimeout, serviceAccountTokenRetryInterval)
+	if err != nil {
+		return "", err
+	}
+	return token.(string), nil
+}
+
+func updateClusterPairServiceAccountRules(name string, rules []rbacv1.PolicyRule) error {
+	role, err := rbac.Instance().GetClusterRole(name)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(role.Rules, rules) {
+		return nil
+	}
+	role.Rules = rules
+	_, err = rbac.Instance().UpdateClusterRole(role)
+	return err
+}
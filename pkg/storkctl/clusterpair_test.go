@@ -6,9 +6,13 @@ import (
 	"testing"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
 	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/portworx/sched-ops/k8s/rbac"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -124,3 +128,136 @@ func TestGenerateClusterPairInvalidNamespace(t *testing.T) {
 	expected := "error: the Namespace \"test_namespace\" is not valid: [a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')]"
 	testCommon(t, cmdArgs, nil, expected, true)
 }
+
+func TestGenerateClusterPairRotateTokenNoServiceAccount(t *testing.T) {
+	cmdArgs := []string{"generate", "clusterpair", "pair1", "-n", "test", "--rotateToken"}
+
+	expected := "error: serviceAccount needs to be provided to rotate the token"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestGenerateClusterPairServiceAccountNoNamespaces(t *testing.T) {
+	cmdArgs := []string{"generate", "clusterpair", "pair1", "-n", "test", "--serviceAccount", "pair-sa"}
+
+	expected := "error: namespaces that will be migrated need to be provided to create the serviceAccount"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestClusterPairServiceAccountToken(t *testing.T) {
+	defer resetTest()
+	// The token controller doesn't run with the fake client, so create the
+	// token secret with the token already populated
+	_, err := core.Instance().CreateSecret(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pair-sa" + serviceAccountTokenSuffix,
+			Namespace: "kube-system",
+		},
+		Data: map[string][]byte{
+			v1.ServiceAccountTokenKey: []byte("token1"),
+		},
+	})
+	require.NoError(t, err, "Error creating token secret")
+
+	_, err = core.Instance().CreateNamespace("existing", nil)
+	require.NoError(t, err, "Error creating namespace")
+	namespaces := []string{"existing", "new"}
+	rules, err := getClusterPairServiceAccountRules(false, namespaces, defaultAdminNamespace)
+	require.NoError(t, err, "Error getting service account rules")
+	err = createClusterPairServiceAccount("pair-sa", "kube-system", rules, namespaces)
+	require.NoError(t, err, "Error creating service account")
+	token, err := getClusterPairServiceAccountToken("pair-sa", "kube-system", false)
+	require.NoError(t, err, "Error getting service account token")
+	require.Equal(t, "token1", token, "Token mismatch")
+
+	_, err = core.Instance().GetServiceAccount("pair-sa", "kube-system")
+	require.NoError(t, err, "Error getting service account")
+	role, err := rbac.Instance().GetClusterRole("pair-sa")
+	require.NoError(t, err, "Error getting cluster role")
+	require.Equal(t, rules, role.Rules, "Cluster role rules mismatch")
+	binding, err := rbac.Instance().GetClusterRoleBinding("pair-sa")
+	require.NoError(t, err, "Error getting cluster role binding")
+	require.Equal(t, "pair-sa", binding.RoleRef.Name, "Cluster role binding role mismatch")
+	require.Equal(t, "kube-system", binding.Subjects[0].Namespace, "Cluster role binding subject mismatch")
+	// Secrets are only accessible in the namespaces being migrated, which
+	// are created if they don't exist
+	for _, ns := range namespaces {
+		_, err = core.Instance().GetNamespace(ns)
+		require.NoError(t, err, "Namespace %v should exist", ns)
+		namespaceRole, err := rbac.Instance().GetRole("pair-sa", ns)
+		require.NoError(t, err, "Error getting role in namespace %v", ns)
+		require.Equal(t, clusterPairServiceAccountNamespaceRules, namespaceRole.Rules, "Role rules mismatch")
+		roleBinding, err := rbac.Instance().GetRoleBinding("pair-sa", ns)
+		require.NoError(t, err, "Error getting role binding in namespace %v", ns)
+		require.Equal(t, "pair-sa", roleBinding.RoleRef.Name, "Role binding role mismatch")
+		require.Equal(t, "kube-system", roleBinding.Subjects[0].Namespace, "Role binding subject mismatch")
+	}
+
+	// Generating again should reuse the existing objects and update the
+	// rules of the cluster role
+	rules, err = getClusterPairServiceAccountRules(true, namespaces, defaultAdminNamespace)
+	require.NoError(t, err, "Error getting service account rules")
+	err = createClusterPairServiceAccount("pair-sa", "kube-system", rules, namespaces)
+	require.NoError(t, err, "Error creating service account")
+	token, err = getClusterPairServiceAccountToken("pair-sa", "kube-system", false)
+	require.NoError(t, err, "Error getting service account token")
+	require.Equal(t, "token1", token, "Token mismatch")
+	role, err = rbac.Instance().GetClusterRole("pair-sa")
+	require.NoError(t, err, "Error getting cluster role")
+	require.Equal(t, rules, role.Rules, "Cluster role rules should have been updated")
+}
+
+func TestClusterPairServiceAccountRules(t *testing.T) {
+	defer resetTest()
+	hasRule := func(rules []rbacv1.PolicyRule, group string, resource string, verb string) bool {
+		for _, rule := range rules {
+			for _, g := range rule.APIGroups {
+				for _, r := range rule.Resources {
+					for _, v := range rule.Verbs {
+						if g == group && r == resource && v == verb {
+							return true
+						}
+					}
+				}
+			}
+		}
+		return false
+	}
+
+	rules, err := getClusterPairServiceAccountRules(false, []string{"ns2", "ns1"}, "admin")
+	require.NoError(t, err, "Error getting service account rules")
+	require.False(t, hasRule(rules, "", "secrets", "get"), "Secrets shouldn't be accessible in all namespaces")
+	require.False(t, hasRule(rules, "", "namespaces", "create"), "Namespaces shouldn't be created")
+	for _, rule := range rules {
+		if len(rule.Resources) == 1 && rule.Resources[0] == "namespaces" {
+			require.Equal(t, []string{"ns1", "ns2"}, rule.ResourceNames, "Namespaces should be limited to the migrated ones")
+		} else {
+			require.Empty(t, rule.ResourceNames)
+		}
+	}
+	require.True(t, hasRule(rules, "batch", "cronjobs", "update"), "Kinds in the registry should be added")
+	require.True(t, hasRule(rules, "apps.openshift.io", "deploymentconfigs", "update"))
+	require.True(t, hasRule(rules, "ibp.com", "ibppeers", "update"))
+	require.False(t, hasRule(rules, "rbac.authorization.k8s.io", "clusterroles", "create"),
+		"RBAC resources shouldn't be included by default")
+	require.False(t, hasRule(rules, "rbac.authorization.k8s.io", "clusterroles", "escalate"))
+	for _, rule := range rules {
+		require.NotContains(t, rule.Resources, "*", "Rules shouldn't use wildcards")
+		require.NotContains(t, rule.APIGroups, "*", "Rules shouldn't use wildcards")
+	}
+
+	_, err = core.Instance().CreateConfigMap(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resourcecollector.ApplicationRegistryConfigMapName,
+			Namespace: "admin",
+		},
+		Data: map[string]string{
+			"database": "group: example.com\nversion: v1\nkind: Database\npath: spec.instances\nvalue: 0\n",
+		},
+	})
+	require.NoError(t, err, "Error creating application registry config map")
+	registryRules, err := getClusterPairServiceAccountRules(true, []string{"ns2", "ns1"}, "admin")
+	require.NoError(t, err, "Error getting service account rules")
+	require.True(t, hasRule(registryRules, "example.com", "databases", "update"), "Custom kinds should be added")
+	require.True(t, hasRule(registryRules, "rbac.authorization.k8s.io", "clusterroles", "escalate"))
+	require.Len(t, registryRules, len(rules)+2)
+}
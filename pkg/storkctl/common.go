@@ -4,8 +4,33 @@ import (
 	"fmt"
 	"io"
 	"time"
+
+	storkclientset "github.com/libopenstorage/stork/pkg/client/clientset/versioned"
 )
 
+// defaultAdminNamespace is the admin namespace stork uses by default
+const defaultAdminNamespace = "kube-system"
+
+// storkClient is used to access the stork resources that aren't supported by
+// storkops
+var storkClient storkclientset.Interface
+
+func getStorkClient(cmdFactory Factory) (storkclientset.Interface, error) {
+	if storkClient != nil {
+		return storkClient, nil
+	}
+	config, err := cmdFactory.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := storkclientset.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	storkClient = client
+	return storkClient, nil
+}
+
 func toTimeString(t time.Time) string {
 	if t.IsZero() {
 		return ""
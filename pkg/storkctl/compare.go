@@ -0,0 +1,19 @@
+package storkctl
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newCompareCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	compareCommands := &cobra.Command{
+		Use:   "compare",
+		Short: "Compare resources between paired clusters",
+	}
+
+	compareCommands.AddCommand(
+		newCompareMigrationCommand(cmdFactory, ioStreams),
+	)
+
+	return compareCommands
+}
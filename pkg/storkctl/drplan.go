@@ -0,0 +1,326 @@
+package storkctl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/libopenstorage/stork/pkg/apis/stork"
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkclientset "github.com/libopenstorage/stork/pkg/client/clientset/versioned"
+	migration "github.com/libopenstorage/stork/pkg/migration/controllers"
+	"github.com/portworx/sched-ops/task"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubernetes/pkg/printers"
+)
+
+const drPlanWaveColumnTemplate = "%-22s\t%-12s\t%-14s\t%s"
+
+var drPlanColumns = []string{"NAME", "ACTION", "STATUS", "WAVE", "CREATED", "ELAPSED"}
+var drPlanSubcommand = "drplans"
+var drPlanAliases = []string{"drplan"}
+
+var drPlanActions = []storkv1.DRPlanActionType{
+	storkv1.DRPlanActionFailover,
+	storkv1.DRPlanActionFailback,
+	storkv1.DRPlanActionTest,
+}
+
+func newGetDRPlanCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	getDRPlanCommand := &cobra.Command{
+		Use:     drPlanSubcommand,
+		Aliases: drPlanAliases,
+		Short:   "Get DR plans",
+		Run: func(c *cobra.Command, args []string) {
+			client, err := getStorkClient(cmdFactory)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			namespaces, err := cmdFactory.GetAllNamespaces()
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+
+			plans := new(storkv1.DRPlanList)
+			for _, ns := range namespaces {
+				if len(args) > 0 {
+					for _, name := range args {
+						plan, err := client.StorkV1alpha1().DRPlans(ns).Get(name, metav1.GetOptions{})
+						if err != nil {
+							util.CheckErr(err)
+							return
+						}
+						plans.Items = append(plans.Items, *plan)
+					}
+				} else {
+					nsPlans, err := client.StorkV1alpha1().DRPlans(ns).List(metav1.ListOptions{})
+					if err != nil {
+						util.CheckErr(err)
+						return
+					}
+					plans.Items = append(plans.Items, nsPlans.Items...)
+				}
+			}
+
+			if len(plans.Items) == 0 {
+				handleEmptyList(ioStreams.Out)
+				return
+			}
+			if err := printObjects(c, plans, cmdFactory, drPlanColumns, drPlanPrinter, ioStreams.Out); err != nil {
+				util.CheckErr(err)
+				return
+			}
+		},
+	}
+	cmdFactory.BindGetFlags(getDRPlanCommand.Flags())
+
+	return getDRPlanCommand
+}
+
+func newExecuteDRPlanCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var actionName string
+	var waitForCompletion bool
+
+	executeDRPlanCommand := &cobra.Command{
+		Use:     drPlanSubcommand,
+		Aliases: drPlanAliases,
+		Short:   "Execute a DR plan to fail over, fail back or test the applications in its waves",
+		Long: "Execute a DR plan to fail over, fail back or test the applications in its waves. The waves are " +
+			"run in order and each wave is started once the applications of the previous one are ready. " +
+			"A test brings up a copy of the applications on this cluster without deactivating them on the other cluster",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				util.CheckErr(fmt.Errorf("exactly one name needs to be provided for the DR plan"))
+				return
+			}
+			action, err := getDRPlanAction(actionName)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			client, err := getStorkClient(cmdFactory)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+
+			plan, err := client.StorkV1alpha1().DRPlans(cmdFactory.GetNamespace()).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			if plan.Status.Status == storkv1.DRPlanStatusInProgress {
+				util.CheckErr(fmt.Errorf("DR plan %v is already being executed for %v",
+					plan.Name, strings.ToLower(string(plan.Status.Action))))
+				return
+			}
+			if plan.Annotations == nil {
+				plan.Annotations = make(map[string]string)
+			}
+			plan.Annotations[migration.DRPlanActionAnnotation] = string(action)
+			if _, err := client.StorkV1alpha1().DRPlans(plan.Namespace).Update(plan); err != nil {
+				util.CheckErr(err)
+				return
+			}
+
+			if waitForCompletion {
+				msg, err := waitForDRPlan(client, plan.Name, plan.Namespace, ioStreams)
+				if err != nil {
+					util.CheckErr(err)
+					return
+				}
+				printMsg(msg, ioStreams.Out)
+			} else {
+				msg := fmt.Sprintf("DRPlan %v will be executed for %v", plan.Name, strings.ToLower(string(action)))
+				printMsg(msg, ioStreams.Out)
+			}
+		},
+	}
+	executeDRPlanCommand.Flags().StringVarP(&actionName, "action", "a", "", "Action to execute the plan for. Valid values: failover, failback, test")
+	executeDRPlanCommand.Flags().BoolVarP(&waitForCompletion, "wait", "w", false, "Wait for the plan to complete")
+
+	return executeDRPlanCommand
+}
+
+func newRetryDRPlanCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	retryDRPlanCommand := &cobra.Command{
+		Use:     drPlanSubcommand,
+		Aliases: drPlanAliases,
+		Short:   "Continue failed executions of DR plans from the wave that failed",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) == 0 {
+				util.CheckErr(fmt.Errorf("at least one argument needs to be provided for DR plan name"))
+				return
+			}
+			client, err := getStorkClient(cmdFactory)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			for _, name := range args {
+				plan, err := client.StorkV1alpha1().DRPlans(cmdFactory.GetNamespace()).Get(name, metav1.GetOptions{})
+				if err != nil {
+					util.CheckErr(err)
+					return
+				}
+				if plan.Status.Status != storkv1.DRPlanStatusFailed {
+					util.CheckErr(fmt.Errorf("DR plan %v can't be retried since its status is %v", name, plan.Status.Status))
+					return
+				}
+				if plan.Annotations == nil {
+					plan.Annotations = make(map[string]string)
+				}
+				plan.Annotations[stork.RetryAnnotation] = "true"
+				if _, err := client.StorkV1alpha1().DRPlans(plan.Namespace).Update(plan); err != nil {
+					util.CheckErr(err)
+					return
+				}
+				msg := fmt.Sprintf("DRPlan %v will be retried", name)
+				printMsg(msg, ioStreams.Out)
+			}
+		},
+	}
+
+	return retryDRPlanCommand
+}
+
+// getDRPlanAction returns the action for a name, ignoring case
+func getDRPlanAction(name string) (storkv1.DRPlanActionType, error) {
+	validActions := make([]string, 0)
+	for _, action := range drPlanActions {
+		if strings.EqualFold(name, string(action)) {
+			return action, nil
+		}
+		validActions = append(validActions, strings.ToLower(string(action)))
+	}
+	return "", fmt.Errorf("action needs to be one of: %v", strings.Join(validActions, ", "))
+}
+
+// getCurrentDRPlanWave returns the wave that is running or failed, or the
+// last wave once all of them are done
+func getCurrentDRPlanWave(plan *storkv1.DRPlan) *storkv1.DRPlanWaveInfo {
+	for _, wave := range plan.Status.Waves {
+		if wave.Status != storkv1.DRPlanStatusSuccessful {
+			return wave
+		}
+	}
+	if len(plan.Status.Waves) == 0 {
+		return nil
+	}
+	return plan.Status.Waves[len(plan.Status.Waves)-1]
+}
+
+// getCurrentDRPlanStage returns the stage of a wave that is running or
+// failed, or the last stage once all of them are done
+func getCurrentDRPlanStage(wave *storkv1.DRPlanWaveInfo) *storkv1.DRPlanWaveStageInfo {
+	for _, stage := range wave.Stages {
+		if stage.Status != storkv1.DRPlanStatusSuccessful &&
+			stage.Status != storkv1.DRPlanStatusSkipped {
+			return stage
+		}
+	}
+	if len(wave.Stages) == 0 {
+		return nil
+	}
+	return wave.Stages[len(wave.Stages)-1]
+}
+
+// waitForDRPlan prints the status of each wave as it changes until the plan
+// completes
+func waitForDRPlan(
+	client storkclientset.Interface,
+	name string,
+	namespace string,
+	ioStreams genericclioptions.IOStreams,
+) (string, error) {
+	var msg string
+	printed := make(map[string]string)
+	printMsg(fmt.Sprintf(drPlanWaveColumnTemplate, "WAVE", "STATUS", "STAGE", "REASON"), ioStreams.Out)
+	t := func() (interface{}, bool, error) {
+		plan, err := client.StorkV1alpha1().DRPlans(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return "", true, err
+		}
+		// Wait for the controller to start the execution
+		if _, ok := plan.Annotations[migration.DRPlanActionAnnotation]; ok {
+			return "", true, fmt.Errorf("waiting for the plan to be started")
+		}
+		for _, wave := range plan.Status.Waves {
+			if wave.Status == storkv1.DRPlanStatusPending {
+				continue
+			}
+			stageName := ""
+			reason := wave.Reason
+			if stage := getCurrentDRPlanStage(wave); stage != nil {
+				stageName = string(stage.Stage)
+				if reason == "" {
+					reason = stage.Reason
+				}
+			}
+			line := fmt.Sprintf(drPlanWaveColumnTemplate, wave.Name, wave.Status, stageName, reason)
+			if printed[wave.Name] == line {
+				continue
+			}
+			printed[wave.Name] = line
+			printMsg(line, ioStreams.Out)
+		}
+		switch plan.Status.Status {
+		case storkv1.DRPlanStatusSuccessful:
+			msg = fmt.Sprintf("%v of DRPlan %v completed successfully", plan.Status.Action, name)
+			return "", false, nil
+		case storkv1.DRPlanStatusFailed:
+			msg = fmt.Sprintf("%v of DRPlan %v failed, it can be continued with \"storkctl retry %v %v\"",
+				plan.Status.Action, name, drPlanSubcommand, name)
+			return "", false, nil
+		}
+		return "", true, fmt.Errorf("%v", plan.Status.Status)
+	}
+	if _, err := task.DoRetryWithTimeout(t, failoverTimeout, failoverRetryInterval); err != nil {
+		return "Timed out performing task", err
+	}
+	return msg, nil
+}
+
+func drPlanPrinter(
+	planList *storkv1.DRPlanList,
+	options printers.GenerateOptions,
+) ([]metav1beta1.TableRow, error) {
+	if planList == nil {
+		return nil, nil
+	}
+
+	rows := make([]metav1beta1.TableRow, 0)
+	for _, plan := range planList.Items {
+		currentWave := ""
+		if wave := getCurrentDRPlanWave(&plan); wave != nil {
+			currentWave = wave.Name
+		}
+
+		elapsed := ""
+		if !plan.Status.StartTimestamp.IsZero() {
+			if !plan.Status.FinishTimestamp.IsZero() {
+				elapsed = plan.Status.FinishTimestamp.Sub(plan.Status.StartTimestamp.Time).String()
+			} else {
+				elapsed = time.Since(plan.Status.StartTimestamp.Time).String()
+			}
+		}
+
+		creationTime := toTimeString(plan.CreationTimestamp.Time)
+		row := getRow(&plan,
+			[]interface{}{plan.Name,
+				plan.Status.Action,
+				plan.Status.Status,
+				currentWave,
+				creationTime,
+				elapsed},
+		)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
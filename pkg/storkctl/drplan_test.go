@@ -0,0 +1,121 @@
+// +build unittest
+
+package storkctl
+
+import (
+	"testing"
+
+	"github.com/libopenstorage/stork/pkg/apis/stork"
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	migration "github.com/libopenstorage/stork/pkg/migration/controllers"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func createDRPlan(t *testing.T, name string, namespace string) *storkv1.DRPlan {
+	plan := &storkv1.DRPlan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: storkv1.DRPlanSpec{
+			ClusterPair: "clusterpair1",
+			Waves: []storkv1.DRPlanWave{
+				{
+					Name:               "databases",
+					MigrationSchedules: []string{"schedule1"},
+				},
+				{
+					Name:               "frontend",
+					MigrationSchedules: []string{"schedule2"},
+				},
+			},
+		},
+	}
+	_, err := storkClient.StorkV1alpha1().DRPlans(namespace).Create(plan)
+	require.NoError(t, err, "Error creating DR plan")
+	return plan
+}
+
+func TestGetDRPlansNoDRPlan(t *testing.T) {
+	cmdArgs := []string{"get", "drplans"}
+
+	expected := "No resources found.\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestGetDRPlans(t *testing.T) {
+	defer resetTest()
+	plan := createDRPlan(t, "drplantest", "test")
+
+	expected := "NAME         ACTION   STATUS   WAVE   CREATED   ELAPSED\n" +
+		"drplantest                                      \n"
+	cmdArgs := []string{"get", "drplans", "-n", "test"}
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	plan.Status = storkv1.DRPlanStatus{
+		Status: storkv1.DRPlanStatusInProgress,
+		Action: storkv1.DRPlanActionTest,
+		Waves: []*storkv1.DRPlanWaveInfo{
+			{
+				Name:   "databases",
+				Status: storkv1.DRPlanStatusSuccessful,
+			},
+			{
+				Name:   "frontend",
+				Status: storkv1.DRPlanStatusInProgress,
+			},
+		},
+	}
+	_, err := storkClient.StorkV1alpha1().DRPlans("test").Update(plan)
+	require.NoError(t, err, "Error updating DR plan")
+
+	expected = "NAME         ACTION   STATUS       WAVE       CREATED   ELAPSED\n" +
+		"drplantest   Test     InProgress   frontend             \n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestExecuteDRPlan(t *testing.T) {
+	defer resetTest()
+	createDRPlan(t, "executetest", "test")
+
+	cmdArgs := []string{"execute", "drplan", "executetest", "-n", "test"}
+	expected := "error: action needs to be one of: failover, failback, test"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"execute", "drplan", "executetest", "-n", "test", "--action", "test"}
+	expected = "DRPlan executetest will be executed for test\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	plan, err := storkClient.StorkV1alpha1().DRPlans("test").Get("executetest", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting DR plan")
+	require.Equal(t, string(storkv1.DRPlanActionTest), plan.Annotations[migration.DRPlanActionAnnotation])
+
+	plan.Status.Status = storkv1.DRPlanStatusInProgress
+	plan.Status.Action = storkv1.DRPlanActionTest
+	_, err = storkClient.StorkV1alpha1().DRPlans("test").Update(plan)
+	require.NoError(t, err, "Error updating DR plan")
+
+	cmdArgs = []string{"execute", "drplan", "executetest", "-n", "test", "--action", "Failover"}
+	expected = "error: DR plan executetest is already being executed for test"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestRetryDRPlan(t *testing.T) {
+	defer resetTest()
+	plan := createDRPlan(t, "retrytest", "test")
+
+	cmdArgs := []string{"retry", "drplans", "retrytest", "-n", "test"}
+	expected := "error: DR plan retrytest can't be retried since its status is "
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	plan.Status.Status = storkv1.DRPlanStatusFailed
+	_, err := storkClient.StorkV1alpha1().DRPlans("test").Update(plan)
+	require.NoError(t, err, "Error updating DR plan")
+
+	expected = "DRPlan retrytest will be retried\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+	plan, err = storkClient.StorkV1alpha1().DRPlans("test").Get("retrytest", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting DR plan")
+	require.Contains(t, plan.Annotations, stork.RetryAnnotation)
+}
@@ -0,0 +1,19 @@
+package storkctl
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newExecuteCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	executeCommands := &cobra.Command{
+		Use:   "execute",
+		Short: "Execute plans",
+	}
+
+	executeCommands.AddCommand(
+		newExecuteDRPlanCommand(cmdFactory, ioStreams),
+	)
+
+	return executeCommands
+}
@@ -0,0 +1,19 @@
+package storkctl
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newExportCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	exportCommands := &cobra.Command{
+		Use:   "export",
+		Short: "Export resources to an archive",
+	}
+
+	exportCommands.AddCommand(
+		newExportApplicationBackupCommand(cmdFactory, ioStreams),
+	)
+
+	return exportCommands
+}
@@ -23,6 +23,8 @@ func newGetCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *c
 		newGetClusterPairCommand(cmdFactory, ioStreams),
 		newGetSchedulePolicyCommand(cmdFactory, ioStreams),
 		newGetMigrationScheduleCommand(cmdFactory, ioStreams),
+		newGetMigrationFailoverCommand(cmdFactory, ioStreams),
+		newGetDRPlanCommand(cmdFactory, ioStreams),
 		newGetSnapshotScheduleCommand(cmdFactory, ioStreams),
 		newGetGroupVolumeSnapshotCommand(cmdFactory, ioStreams),
 		newGetClusterDomainsStatusCommand(cmdFactory, ioStreams),
@@ -0,0 +1,19 @@
+package storkctl
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newImportCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	importCommands := &cobra.Command{
+		Use:   "import",
+		Short: "Import resources from an archive",
+	}
+
+	importCommands.AddCommand(
+		newImportApplicationBackupCommand(cmdFactory, ioStreams),
+	)
+
+	return importCommands
+}
@@ -8,8 +8,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/libopenstorage/stork/pkg/apis/stork"
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	migration "github.com/libopenstorage/stork/pkg/migration/controllers"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
 	"github.com/portworx/sched-ops/k8s/apps"
 	"github.com/portworx/sched-ops/k8s/core"
 	"github.com/portworx/sched-ops/k8s/dynamic"
@@ -19,7 +21,6 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/kubectl/pkg/cmd/util"
@@ -48,6 +49,8 @@ func newCreateMigrationCommand(cmdFactory Factory, ioStreams genericclioptions.I
 	var startApplications bool
 	var preExecRule string
 	var postExecRule string
+	var checkDrift bool
+	var namespaceMapping map[string]string
 	var includeVolumes bool
 	var waitForCompletion bool
 
@@ -78,6 +81,8 @@ func newCreateMigrationCommand(cmdFactory Factory, ioStreams genericclioptions.I
 					StartApplications: &startApplications,
 					PreExecRule:       preExecRule,
 					PostExecRule:      postExecRule,
+					CheckDrift:        &checkDrift,
+					NamespaceMapping:  namespaceMapping,
 				},
 			}
 			migration.Name = migrationName
@@ -109,12 +114,16 @@ func newCreateMigrationCommand(cmdFactory Factory, ioStreams genericclioptions.I
 	createMigrationCommand.Flags().BoolVarP(&startApplications, "startApplications", "a", true, "Start applications on the destination cluster after migration")
 	createMigrationCommand.Flags().StringVarP(&preExecRule, "preExecRule", "", "", "Rule to run before executing migration")
 	createMigrationCommand.Flags().StringVarP(&postExecRule, "postExecRule", "", "", "Rule to run after executing migration")
+	createMigrationCommand.Flags().BoolVarP(&checkDrift, "checkDrift", "", false, "Compare the resources on the source and destination clusters after migration")
+	createMigrationCommand.Flags().StringToStringVar(&namespaceMapping, "namespaceMapping", nil, "Comma separated list of source=destination namespaces to migrate namespaces to a different namespace")
 
 	return createMigrationCommand
 }
 
 func newActivateMigrationsCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var allNamespaces bool
+	var sourceNamespace string
+	var adminNamespace string
 
 	activateMigrationCommand := &cobra.Command{
 		Use:     migrationSubcommand,
@@ -132,29 +141,42 @@ func newActivateMigrationsCommand(cmdFactory Factory, ioStreams genericclioption
 					activationNamespaces = append(activationNamespaces, ns.Name)
 				}
 
+			} else if sourceNamespace != "" {
+				namespaces, err := getMigratedNamespaces(sourceNamespace)
+				if err != nil {
+					util.CheckErr(err)
+					return
+				}
+				activationNamespaces = append(activationNamespaces, namespaces...)
 			} else {
 				activationNamespaces = append(activationNamespaces, cmdFactory.GetNamespace())
 			}
 
 			for _, ns := range activationNamespaces {
-				updateStatefulSets(ns, true, ioStreams)
-				updateDeployments(ns, true, ioStreams)
-				updateDeploymentConfigs(ns, true, ioStreams)
-				updateObjects("IBPPeer", ns, true, ioStreams)
-				updateObjects("IBPCA", ns, true, ioStreams)
-				updateObjects("IBPOrderer", ns, true, ioStreams)
-				updateObjects("IBPConsole", ns, true, ioStreams)
+				// Mark the namespace as activated first so that the webhook
+				// allows the applications to be scaled up
+				if err := migration.SetNamespaceActivated(core.Instance(), ns, true); err != nil {
+					util.CheckErr(err)
+					return
+				}
+				updateApplications(ns, adminNamespace, true, ioStreams)
 			}
 
 		},
 	}
 	activateMigrationCommand.Flags().BoolVarP(&allNamespaces, "all-namespaces", "a", false, "Activate applications in all namespaces")
+	activateMigrationCommand.Flags().StringVar(&sourceNamespace, "sourceNamespace", "",
+		"Activate applications in the namespaces that were migrated from this namespace on the source cluster")
+	activateMigrationCommand.Flags().StringVar(&adminNamespace, "adminNamespace", defaultAdminNamespace,
+		"Admin namespace of stork, which has the application registry")
 
 	return activateMigrationCommand
 }
 
 func newDeactivateMigrationsCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var allNamespaces bool
+	var sourceNamespace string
+	var adminNamespace string
 
 	deactivateMigrationCommand := &cobra.Command{
 		Use:     migrationSubcommand,
@@ -172,27 +194,125 @@ func newDeactivateMigrationsCommand(cmdFactory Factory, ioStreams genericcliopti
 					deactivationNamespaces = append(deactivationNamespaces, ns.Name)
 				}
 
+			} else if sourceNamespace != "" {
+				namespaces, err := getMigratedNamespaces(sourceNamespace)
+				if err != nil {
+					util.CheckErr(err)
+					return
+				}
+				deactivationNamespaces = append(deactivationNamespaces, namespaces...)
 			} else {
 				deactivationNamespaces = append(deactivationNamespaces, cmdFactory.GetNamespace())
 			}
 
 			for _, ns := range deactivationNamespaces {
-				updateStatefulSets(ns, false, ioStreams)
-				updateDeployments(ns, false, ioStreams)
-				updateDeploymentConfigs(ns, false, ioStreams)
-				updateObjects("IBPPeer", ns, false, ioStreams)
-				updateObjects("IBPCA", ns, false, ioStreams)
-				updateObjects("IBPOrderer", ns, false, ioStreams)
-				updateObjects("IBPConsole", ns, false, ioStreams)
+				updateApplications(ns, adminNamespace, false, ioStreams)
+				if err := migration.SetNamespaceActivated(core.Instance(), ns, false); err != nil {
+					util.CheckErr(err)
+					return
+				}
 			}
 
 		},
 	}
 	deactivateMigrationCommand.Flags().BoolVarP(&allNamespaces, "all-namespaces", "a", false, "Deactivate applications in all namespaces")
+	deactivateMigrationCommand.Flags().StringVar(&sourceNamespace, "sourceNamespace", "",
+		"Deactivate applications in the namespaces that were migrated from this namespace on the source cluster")
+	deactivateMigrationCommand.Flags().StringVar(&adminNamespace, "adminNamespace", defaultAdminNamespace,
+		"Admin namespace of stork, which has the application registry")
 
 	return deactivateMigrationCommand
 }
 
+func newCompareMigrationCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var adminNamespace string
+	compareMigrationCommand := &cobra.Command{
+		Use:     migrationSubcommand,
+		Aliases: migrationAliases,
+		Short:   "Compare the resources of a migration on the source and destination clusters",
+		Long: "Compare the resources selected by a migration on this cluster with the resources on the cluster " +
+			"its ClusterPair points to, and list the resources that are missing, extra or different on the destination. " +
+			"Fields that are changed during migration, like the replicas of applications, are ignored",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				util.CheckErr(fmt.Errorf("exactly one name needs to be provided for migration name"))
+				return
+			}
+			migr, err := storkops.Instance().GetMigration(args[0], cmdFactory.GetNamespace())
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			config, err := cmdFactory.GetConfig()
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			srcCollector := resourcecollector.ResourceCollector{
+				AdminNamespace: adminNamespace,
+			}
+			if err := srcCollector.Init(config); err != nil {
+				util.CheckErr(err)
+				return
+			}
+			drift, err := migration.CompareMigration(&srcCollector, &migr.Spec, migr.Namespace, true)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			if len(drift) == 0 {
+				printMsg("No drift found between the source and destination clusters", ioStreams.Out)
+				return
+			}
+			printMigrationDrift(drift, ioStreams)
+		},
+	}
+	compareMigrationCommand.Flags().StringVar(&adminNamespace, "adminNamespace", defaultAdminNamespace,
+		"Admin namespace of stork, which has the application registry")
+
+	return compareMigrationCommand
+}
+
+func printMigrationDrift(drift []*storkv1.MigrationDriftInfo, ioStreams genericclioptions.IOStreams) {
+	w := printers.GetNewTabWriter(ioStreams.Out)
+	printMsg("KIND\tNAMESPACE\tNAME\tDRIFT\tFIELDS", w)
+	for _, info := range drift {
+		printMsg(fmt.Sprintf("%v\t%v\t%v\t%v\t%v",
+			info.Kind, info.Namespace, info.Name, info.Type, strings.Join(info.Fields, ",")), w)
+	}
+	if err := w.Flush(); err != nil {
+		util.CheckErr(err)
+	}
+}
+
+// getMigratedNamespaces returns the namespaces on this cluster that were
+// migrated from a namespace on the source cluster. Namespaces are migrated to
+// a namespace with the same name unless a namespace mapping was used
+func getMigratedNamespaces(sourceNamespace string) ([]string, error) {
+	migratedNamespaces := make([]string, 0)
+	namespaces, err := core.Instance().ListNamespaces(map[string]string{
+		migration.StorkMigrationSourceNamespaceLabel: sourceNamespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, ns := range namespaces.Items {
+		migratedNamespaces = append(migratedNamespaces, ns.Name)
+	}
+	// Namespaces that weren't mapped have the same name
+	if ns, err := core.Instance().GetNamespace(sourceNamespace); err == nil {
+		if _, ok := ns.Labels[migration.StorkMigrationSourceNamespaceLabel]; !ok {
+			migratedNamespaces = append(migratedNamespaces, ns.Name)
+		}
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if len(migratedNamespaces) == 0 {
+		return nil, fmt.Errorf("no namespaces found that were migrated from namespace %v", sourceNamespace)
+	}
+	return migratedNamespaces, nil
+}
+
 func updateStatefulSets(namespace string, activate bool, ioStreams genericclioptions.IOStreams) {
 	statefulSets, err := apps.Instance().ListStatefulSets(namespace)
 	if err != nil {
@@ -253,12 +373,41 @@ func updateDeploymentConfigs(namespace string, activate bool, ioStreams genericc
 	}
 }
 
-func updateObjects(kind string, namespace string, activate bool, ioStreams genericclioptions.IOStreams) {
+// updateApplications activates or deactivates the migrated applications in a
+// namespace for all the kinds in the application registry
+func updateApplications(namespace string, adminNamespace string, activate bool, ioStreams genericclioptions.IOStreams) {
+	registry, err := resourcecollector.GetApplicationRegistry(core.Instance(), adminNamespace)
+	if err != nil {
+		util.CheckErr(err)
+		return
+	}
+	for _, options := range registry {
+		switch {
+		case isReplicasSuspendOptions(options, "apps", "StatefulSet"):
+			updateStatefulSets(namespace, activate, ioStreams)
+		case isReplicasSuspendOptions(options, "apps", "Deployment"):
+			updateDeployments(namespace, activate, ioStreams)
+		case isReplicasSuspendOptions(options, "apps.openshift.io", "DeploymentConfig"):
+			updateDeploymentConfigs(namespace, activate, ioStreams)
+		default:
+			updateObjects(options, namespace, activate, ioStreams)
+		}
+	}
+}
+
+func isReplicasSuspendOptions(options *resourcecollector.ApplicationSuspendOptions, group string, kind string) bool {
+	return options.Group == group && options.Kind == kind && options.Path == "spec.replicas"
+}
+
+func updateObjects(
+	options *resourcecollector.ApplicationSuspendOptions,
+	namespace string,
+	activate bool,
+	ioStreams genericclioptions.IOStreams,
+) {
 	objects, err := dynamic.Instance().ListObjects(
 		&metav1.ListOptions{
-			TypeMeta: metav1.TypeMeta{
-				Kind:       kind,
-				APIVersion: "ibp.com/v1alpha1"},
+			TypeMeta: options.TypeMeta(),
 		},
 		namespace)
 	if err != nil {
@@ -267,20 +416,29 @@ func updateObjects(kind string, namespace string, activate bool, ioStreams gener
 		}
 		return
 	}
+	kind := strings.ToLower(options.Kind)
+	field := options.Path[strings.LastIndex(options.Path, ".")+1:]
 	for _, o := range objects.Items {
-		if replicas, update := getUpdatedReplicaCount(o.GetAnnotations(), activate, ioStreams); update {
-			err := unstructured.SetNestedField(o.Object, int64(replicas), "spec", "replicas")
-			if err != nil {
-				printMsg(fmt.Sprintf("Error updating replicas for %v %v/%v : %v", strings.ToLower(kind), o.GetNamespace(), o.GetName(), err), ioStreams.ErrOut)
-				continue
-			}
-			_, err = dynamic.Instance().UpdateObject(&o)
-			if err != nil {
-				printMsg(fmt.Sprintf("Error updating replicas for %v %v/%v : %v", strings.ToLower(kind), o.GetNamespace(), o.GetName(), err), ioStreams.ErrOut)
-				continue
-			}
-			printMsg(fmt.Sprintf("Updated replicas for %v %v/%v to %v", strings.ToLower(kind), o.GetNamespace(), o.GetName(), replicas), ioStreams.Out)
+		if !migration.IsApplicationSuspended(options, o.GetAnnotations()) {
+			continue
+		}
+		var value interface{}
+		if activate {
+			value, err = migration.ResumeApplication(options, &o)
+		} else {
+			value = options.Value
+			err = migration.SuspendApplication(options, &o)
+		}
+		if err != nil {
+			printMsg(fmt.Sprintf("Error updating %v for %v %v/%v : %v", field, kind, o.GetNamespace(), o.GetName(), err), ioStreams.ErrOut)
+			continue
 		}
+		_, err = dynamic.Instance().UpdateObject(&o)
+		if err != nil {
+			printMsg(fmt.Sprintf("Error updating %v for %v %v/%v : %v", field, kind, o.GetNamespace(), o.GetName(), err), ioStreams.ErrOut)
+			continue
+		}
+		printMsg(fmt.Sprintf("Updated %v for %v %v/%v to %v", field, kind, o.GetNamespace(), o.GetName(), value), ioStreams.Out)
 	}
 }
 
@@ -413,6 +571,48 @@ func newDeleteMigrationCommand(cmdFactory Factory, ioStreams genericclioptions.I
 	return deleteMigrationCommand
 }
 
+func newRetryMigrationCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	retryMigrationCommand := &cobra.Command{
+		Use:     migrationSubcommand,
+		Aliases: migrationAliases,
+		Short:   "Retry the failed volumes and resources of migrations",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) == 0 {
+				util.CheckErr(fmt.Errorf("at least one argument needs to be provided for migration name"))
+				return
+			}
+			retryMigrations(args, cmdFactory.GetNamespace(), ioStreams)
+		},
+	}
+
+	return retryMigrationCommand
+}
+
+func retryMigrations(migrations []string, namespace string, ioStreams genericclioptions.IOStreams) {
+	for _, name := range migrations {
+		migr, err := storkops.Instance().GetMigration(name, namespace)
+		if err != nil {
+			util.CheckErr(err)
+			return
+		}
+		if migr.Status.Status != storkv1.MigrationStatusFailed &&
+			migr.Status.Status != storkv1.MigrationStatusPartialSuccess {
+			util.CheckErr(fmt.Errorf("migration %v can't be retried since its status is %v", name, migr.Status.Status))
+			return
+		}
+		if migr.Annotations == nil {
+			migr.Annotations = make(map[string]string)
+		}
+		migr.Annotations[stork.RetryAnnotation] = "true"
+		if _, err := storkops.Instance().UpdateMigration(migr); err != nil {
+			util.CheckErr(err)
+			return
+		}
+		msg := fmt.Sprintf("Migration %v will be retried", name)
+		printMsg(msg, ioStreams.Out)
+	}
+}
+
 func deleteMigrations(migrations []string, namespace string, ioStreams genericclioptions.IOStreams) {
 	for _, migration := range migrations {
 		err := storkops.Instance().DeleteMigration(migration, namespace)
@@ -452,7 +652,8 @@ func migrationPrinter(
 			totalResources := len(migration.Status.Resources)
 			doneResources := 0
 			for _, resource := range migration.Status.Resources {
-				if resource.Status == storkv1.MigrationStatusSuccessful {
+				if resource.Status == storkv1.MigrationStatusSuccessful ||
+					resource.Status == storkv1.MigrationStatusUnchanged {
 					doneResources++
 				}
 			}
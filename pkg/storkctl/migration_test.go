@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/libopenstorage/stork/pkg/apis/stork"
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	migration "github.com/libopenstorage/stork/pkg/migration/controllers"
 	ocpv1 "github.com/openshift/api/apps/v1"
@@ -136,6 +137,30 @@ func TestGetMigrationsWithStatusAndProgress(t *testing.T) {
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
+func TestGetMigrationsWithUnchangedResources(t *testing.T) {
+	defer resetTest()
+	createMigrationAndVerify(t, "unchangedresourcestest", "default", "clusterpair1", []string{"namespace1"}, "", "")
+	migration, err := storkops.Instance().GetMigration("unchangedresourcestest", "default")
+	require.NoError(t, err, "Error getting migration")
+
+	migration.Status.FinishTimestamp = metav1.Now()
+	migration.CreationTimestamp = metav1.NewTime(migration.Status.FinishTimestamp.Add(-5 * time.Minute))
+	migration.Status.Stage = storkv1.MigrationStageFinal
+	migration.Status.Status = storkv1.MigrationStatusPartialSuccess
+	migration.Status.Resources = []*storkv1.MigrationResourceInfo{
+		{Name: "unchanged", Status: storkv1.MigrationStatusUnchanged},
+		{Name: "successful", Status: storkv1.MigrationStatusSuccessful},
+		{Name: "failed", Status: storkv1.MigrationStatusFailed},
+	}
+	_, err = storkops.Instance().UpdateMigration(migration)
+	require.NoError(t, err, "Error updating migration")
+
+	expected := "NAME                     CLUSTERPAIR    STAGE   STATUS           VOLUMES   RESOURCES   CREATED               ELAPSED\n" +
+		"unchangedresourcestest   clusterpair1   Final   PartialSuccess   0/0       2/3         " + toTimeString(migration.CreationTimestamp.Time) + "   5m0s\n"
+	cmdArgs := []string{"get", "migrations", "unchangedresourcestest"}
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
 func TestCreateMigrationsNoNamespace(t *testing.T) {
 	cmdArgs := []string{"create", "migrations", "-c", "clusterPair1", "migration1"}
 
@@ -327,6 +352,9 @@ func TestActivateDeactivateMigrations(t *testing.T) {
 	cmdArgs := []string{"activate", "migrations", "-n", "dep"}
 	expected := "Updated replicas for deployment dep/migratedDeployment to 1\n"
 	testCommon(t, cmdArgs, nil, expected, false)
+	activated, err := migration.IsNamespaceActivated(core.Instance(), "dep")
+	require.NoError(t, err, "Error checking if namespace is activated")
+	require.True(t, activated, "Namespace should be marked as activated")
 
 	cmdArgs = []string{"activate", "migrations", "-n", "depconf"}
 	expected = "Updated replicas for deploymentconfig depconf/migratedDeploymentConfig to 1\n"
@@ -339,6 +367,9 @@ func TestActivateDeactivateMigrations(t *testing.T) {
 	cmdArgs = []string{"deactivate", "migrations", "-n", "dep"}
 	expected = "Updated replicas for deployment dep/migratedDeployment to 0\n"
 	testCommon(t, cmdArgs, nil, expected, false)
+	activated, err = migration.IsNamespaceActivated(core.Instance(), "dep")
+	require.NoError(t, err, "Error checking if namespace is activated")
+	require.False(t, activated, "Namespace shouldn't be marked as activated")
 
 	cmdArgs = []string{"deactivate", "migrations", "-n", "depconf"}
 	expected = "Updated replicas for deploymentconfig depconf/migratedDeploymentConfig to 0\n"
@@ -361,6 +392,54 @@ func TestActivateDeactivateMigrations(t *testing.T) {
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
+func TestActivateDeactivateMappedMigrations(t *testing.T) {
+	defer resetTest()
+	replicas := int32(0)
+	_, err := core.Instance().CreateNamespace("app-dr", map[string]string{
+		migration.StorkMigrationSourceNamespaceLabel: "app",
+	})
+	require.NoError(t, err, "Error creating app-dr namespace")
+	_, err = apps.Instance().CreateDeployment(&appv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mappedDeployment",
+			Namespace: "app-dr",
+			Annotations: map[string]string{
+				migration.StorkMigrationReplicasAnnotation: "2",
+			},
+		},
+		Spec: appv1.DeploymentSpec{
+			Replicas: &replicas,
+		},
+	})
+	require.NoError(t, err, "Error creating deployment")
+
+	cmdArgs := []string{"activate", "migrations", "--sourceNamespace", "app"}
+	expected := "Updated replicas for deployment app-dr/mappedDeployment to 2\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	cmdArgs = []string{"deactivate", "migrations", "--sourceNamespace", "app"}
+	expected = "Updated replicas for deployment app-dr/mappedDeployment to 0\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	cmdArgs = []string{"activate", "migrations", "--sourceNamespace", "other"}
+	expected = "error: no namespaces found that were migrated from namespace other"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestCreateMigrationNamespaceMapping(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"create", "migrations", "-c", "clusterpair1", "--namespaces", "app,other",
+		"--namespaceMapping", "app=app-dr", "-n", "test", "mappedmigration"}
+	expected := "Migration mappedmigration created successfully\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	migr, err := storkops.Instance().GetMigration("mappedmigration", "test")
+	require.NoError(t, err, "Error getting migration")
+	require.Equal(t, map[string]string{"app": "app-dr"}, migr.Spec.NamespaceMapping, "Migration namespaceMapping mismatch")
+	require.Equal(t, "app-dr", migr.Spec.GetDestinationNamespace("app"), "Destination namespace mismatch")
+	require.Equal(t, "other", migr.Spec.GetDestinationNamespace("other"), "Destination namespace mismatch")
+}
+
 func TestCreateMigrationWaitSuccess(t *testing.T) {
 	migrRetryTimeout = 10 * time.Second
 	defer resetTest()
@@ -403,3 +482,42 @@ func setMigrationStatus(name, namespace string, isFail bool, t *testing.T) {
 	_, err = storkops.Instance().UpdateMigration(migrResp)
 	require.NoError(t, err, "Error updating Migrations")
 }
+
+func TestRetryMigrations(t *testing.T) {
+	defer resetTest()
+	createMigrationAndVerify(t, "retrymigration", "default", "clusterpair1", []string{"namespace1"}, "", "")
+
+	cmdArgs := []string{"retry", "migrations"}
+	expected := "error: at least one argument needs to be provided for migration name"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"retry", "migrations", "retrymigration"}
+	expected = "error: migration retrymigration can't be retried since its status is "
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	migr, err := storkops.Instance().GetMigration("retrymigration", "default")
+	require.NoError(t, err, "Error getting migration")
+	migr.Status.Stage = storkv1.MigrationStageFinal
+	migr.Status.Status = storkv1.MigrationStatusFailed
+	_, err = storkops.Instance().UpdateMigration(migr)
+	require.NoError(t, err, "Error updating migration")
+
+	cmdArgs = []string{"retry", "migrations", "retrymigration"}
+	expected = "Migration retrymigration will be retried\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	migr, err = storkops.Instance().GetMigration("retrymigration", "default")
+	require.NoError(t, err, "Error getting migration")
+	require.Contains(t, migr.Annotations, stork.RetryAnnotation, "Retry annotation missing")
+}
+
+func TestCompareMigrations(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"compare", "migrations"}
+	expected := "error: exactly one name needs to be provided for migration name"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"compare", "migrations", "comparemigration"}
+	expected = "Error from server (NotFound): migrations.stork.libopenstorage.org \"comparemigration\" not found"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
@@ -0,0 +1,316 @@
+package storkctl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/libopenstorage/stork/pkg/apis/stork"
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkclientset "github.com/libopenstorage/stork/pkg/client/clientset/versioned"
+	"github.com/portworx/sched-ops/task"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubernetes/pkg/printers"
+)
+
+const (
+	failoverTimeout            = 6 * time.Hour
+	failoverNameTimeFormat     = "2006-01-02-150405"
+	failoverStepColumnTemplate = "%-22s\t%-12s\t%s"
+)
+
+var failoverRetryInterval = 10 * time.Second
+
+var migrationFailoverColumns = []string{"NAME", "TYPE", "MIGRATIONSCHEDULE", "STATUS", "STEP", "CREATED", "ELAPSED"}
+var migrationFailoverSubcommand = "migrationfailovers"
+var migrationFailoverAliases = []string{"migrationfailover", "failover", "failovers"}
+
+func newFailoverCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return newMigrationFailoverCommand(cmdFactory, ioStreams, storkv1.MigrationFailoverTypeFailover)
+}
+
+func newFailbackCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return newMigrationFailoverCommand(cmdFactory, ioStreams, storkv1.MigrationFailoverTypeFailback)
+}
+
+func newMigrationFailoverCommand(
+	cmdFactory Factory,
+	ioStreams genericclioptions.IOStreams,
+	failoverType storkv1.MigrationFailoverType,
+) *cobra.Command {
+	var failoverName string
+	var clusterPair string
+	var reverseMigrationSchedule string
+	var schedulePolicyName string
+	var namespaceList []string
+	var namespaceMapping map[string]string
+	var skipFinalSync bool
+	var testMode bool
+	var waitForCompletion bool
+
+	operation := strings.ToLower(string(failoverType))
+	failoverCommand := &cobra.Command{
+		Use:   operation + " <migrationSchedule>",
+		Short: fmt.Sprintf("Start a %v of the applications migrated to this cluster by a migration schedule", operation),
+		Long: fmt.Sprintf("Start a %v of the applications migrated to this cluster by a migration schedule "+
+			"on the cluster the ClusterPair points to. The migration schedule is suspended, a final migration is run and "+
+			"the applications are scaled down on the other cluster if it is reachable. Then the applications are "+
+			"activated on this cluster and a migration schedule is started to migrate them back", operation),
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				util.CheckErr(fmt.Errorf("exactly one name needs to be provided for the migration schedule"))
+				return
+			}
+			if len(clusterPair) == 0 {
+				util.CheckErr(fmt.Errorf("ClusterPair name needs to be provided for %v", operation))
+				return
+			}
+			client, err := getStorkClient(cmdFactory)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+
+			if len(failoverName) == 0 {
+				failoverName = fmt.Sprintf("%v-%v-%v", args[0], operation, time.Now().Format(failoverNameTimeFormat))
+			}
+			failover := &storkv1.MigrationFailover{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      failoverName,
+					Namespace: cmdFactory.GetNamespace(),
+				},
+				Spec: storkv1.MigrationFailoverSpec{
+					Type:                     failoverType,
+					ClusterPair:              clusterPair,
+					MigrationSchedule:        args[0],
+					ReverseMigrationSchedule: reverseMigrationSchedule,
+					SchedulePolicyName:       schedulePolicyName,
+					Namespaces:               namespaceList,
+					NamespaceMapping:         namespaceMapping,
+					SkipFinalSync:            skipFinalSync,
+					TestMode:                 testMode,
+				},
+			}
+			if _, err := client.StorkV1alpha1().MigrationFailovers(failover.Namespace).Create(failover); err != nil {
+				util.CheckErr(err)
+				return
+			}
+
+			if waitForCompletion {
+				msg, err := waitForMigrationFailover(client, failover.Name, failover.Namespace, ioStreams)
+				if err != nil {
+					util.CheckErr(err)
+					return
+				}
+				printMsg(msg, ioStreams.Out)
+			} else {
+				msg := fmt.Sprintf("MigrationFailover %v created successfully", failover.Name)
+				printMsg(msg, ioStreams.Out)
+			}
+		},
+	}
+	failoverCommand.Flags().StringVarP(&failoverName, "name", "", "", "Name of the MigrationFailover, generated from the migration schedule if not specified")
+	failoverCommand.Flags().StringVarP(&clusterPair, "clusterPair", "c", "", "ClusterPair on this cluster that points to the cluster the applications are moved from")
+	failoverCommand.Flags().StringVarP(&reverseMigrationSchedule, "reverseMigrationSchedule", "", "", "Migration schedule to resume or create to migrate the applications back")
+	failoverCommand.Flags().StringVarP(&schedulePolicyName, "schedulePolicyName", "s", "", "Schedule policy for the reverse migration schedule, defaults to the policy of the migration schedule")
+	failoverCommand.Flags().StringSliceVarP(&namespaceList, "namespaces", "", nil, "Comma separated list of namespaces, required if the other cluster isn't reachable")
+	failoverCommand.Flags().StringToStringVar(&namespaceMapping, "namespaceMapping", nil, "Comma separated list of namespaces on the other cluster mapped to namespaces on this cluster, required if they were mapped and the other cluster isn't reachable")
+	failoverCommand.Flags().BoolVarP(&skipFinalSync, "skipFinalSync", "", false, "Skip the final migration from the other cluster")
+	failoverCommand.Flags().BoolVarP(&testMode, "testMode", "", false, "Activate a copy of the applications without suspending the migration schedule, deactivating them on the other cluster or starting the reverse migration")
+	failoverCommand.Flags().BoolVarP(&waitForCompletion, "wait", "w", false, fmt.Sprintf("Wait for the %v to complete", operation))
+
+	return failoverCommand
+}
+
+func newGetMigrationFailoverCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	getMigrationFailoverCommand := &cobra.Command{
+		Use:     migrationFailoverSubcommand,
+		Aliases: migrationFailoverAliases,
+		Short:   "Get migration failovers and failbacks",
+		Run: func(c *cobra.Command, args []string) {
+			client, err := getStorkClient(cmdFactory)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			namespaces, err := cmdFactory.GetAllNamespaces()
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+
+			failovers := new(storkv1.MigrationFailoverList)
+			for _, ns := range namespaces {
+				if len(args) > 0 {
+					for _, name := range args {
+						failover, err := client.StorkV1alpha1().MigrationFailovers(ns).Get(name, metav1.GetOptions{})
+						if err != nil {
+							util.CheckErr(err)
+							return
+						}
+						failovers.Items = append(failovers.Items, *failover)
+					}
+				} else {
+					nsFailovers, err := client.StorkV1alpha1().MigrationFailovers(ns).List(metav1.ListOptions{})
+					if err != nil {
+						util.CheckErr(err)
+						return
+					}
+					failovers.Items = append(failovers.Items, nsFailovers.Items...)
+				}
+			}
+
+			if len(failovers.Items) == 0 {
+				handleEmptyList(ioStreams.Out)
+				return
+			}
+			if err := printObjects(c, failovers, cmdFactory, migrationFailoverColumns, migrationFailoverPrinter, ioStreams.Out); err != nil {
+				util.CheckErr(err)
+				return
+			}
+		},
+	}
+	cmdFactory.BindGetFlags(getMigrationFailoverCommand.Flags())
+
+	return getMigrationFailoverCommand
+}
+
+func newRetryMigrationFailoverCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	retryMigrationFailoverCommand := &cobra.Command{
+		Use:     migrationFailoverSubcommand,
+		Aliases: migrationFailoverAliases,
+		Short:   "Continue failed migration failovers and failbacks from the step that failed",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) == 0 {
+				util.CheckErr(fmt.Errorf("at least one argument needs to be provided for migration failover name"))
+				return
+			}
+			client, err := getStorkClient(cmdFactory)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			for _, name := range args {
+				failover, err := client.StorkV1alpha1().MigrationFailovers(cmdFactory.GetNamespace()).Get(name, metav1.GetOptions{})
+				if err != nil {
+					util.CheckErr(err)
+					return
+				}
+				if failover.Status.Status != storkv1.MigrationFailoverStatusFailed {
+					util.CheckErr(fmt.Errorf("migration failover %v can't be retried since its status is %v", name, failover.Status.Status))
+					return
+				}
+				if failover.Annotations == nil {
+					failover.Annotations = make(map[string]string)
+				}
+				failover.Annotations[stork.RetryAnnotation] = "true"
+				if _, err := client.StorkV1alpha1().MigrationFailovers(failover.Namespace).Update(failover); err != nil {
+					util.CheckErr(err)
+					return
+				}
+				msg := fmt.Sprintf("MigrationFailover %v will be retried", name)
+				printMsg(msg, ioStreams.Out)
+			}
+		},
+	}
+
+	return retryMigrationFailoverCommand
+}
+
+// getCurrentFailoverStep returns the step that is running or failed, or the
+// last step once all of them are done
+func getCurrentFailoverStep(failover *storkv1.MigrationFailover) *storkv1.MigrationFailoverStepInfo {
+	for _, step := range failover.Status.Steps {
+		if step.Status != storkv1.MigrationFailoverStatusSuccessful &&
+			step.Status != storkv1.MigrationFailoverStatusSkipped {
+			return step
+		}
+	}
+	if len(failover.Status.Steps) == 0 {
+		return nil
+	}
+	return failover.Status.Steps[len(failover.Status.Steps)-1]
+}
+
+// waitForMigrationFailover prints the status of each step as it changes until
+// the failover completes
+func waitForMigrationFailover(
+	client storkclientset.Interface,
+	name string,
+	namespace string,
+	ioStreams genericclioptions.IOStreams,
+) (string, error) {
+	var msg string
+	printed := make(map[storkv1.MigrationFailoverStepType]storkv1.MigrationFailoverStatusType)
+	printMsg(fmt.Sprintf(failoverStepColumnTemplate, "STEP", "STATUS", "REASON"), ioStreams.Out)
+	t := func() (interface{}, bool, error) {
+		failover, err := client.StorkV1alpha1().MigrationFailovers(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return "", true, err
+		}
+		for _, step := range failover.Status.Steps {
+			if step.Status == storkv1.MigrationFailoverStatusPending || printed[step.Step] == step.Status {
+				continue
+			}
+			printed[step.Step] = step.Status
+			printMsg(fmt.Sprintf(failoverStepColumnTemplate, step.Step, step.Status, step.Reason), ioStreams.Out)
+		}
+		switch failover.Status.Status {
+		case storkv1.MigrationFailoverStatusSuccessful:
+			msg = fmt.Sprintf("%v %v completed successfully", failover.Spec.Type, name)
+			return "", false, nil
+		case storkv1.MigrationFailoverStatusFailed:
+			msg = fmt.Sprintf("%v %v failed, it can be continued with \"storkctl retry %v %v\"",
+				failover.Spec.Type, name, migrationFailoverSubcommand, name)
+			return "", false, nil
+		}
+		return "", true, fmt.Errorf("%v", failover.Status.Status)
+	}
+	if _, err := task.DoRetryWithTimeout(t, failoverTimeout, failoverRetryInterval); err != nil {
+		return "Timed out performing task", err
+	}
+	return msg, nil
+}
+
+func migrationFailoverPrinter(
+	failoverList *storkv1.MigrationFailoverList,
+	options printers.GenerateOptions,
+) ([]metav1beta1.TableRow, error) {
+	if failoverList == nil {
+		return nil, nil
+	}
+
+	rows := make([]metav1beta1.TableRow, 0)
+	for _, failover := range failoverList.Items {
+		currentStep := ""
+		if step := getCurrentFailoverStep(&failover); step != nil {
+			currentStep = string(step.Step)
+		}
+
+		elapsed := ""
+		if !failover.CreationTimestamp.IsZero() {
+			if !failover.Status.FinishTimestamp.IsZero() {
+				elapsed = failover.Status.FinishTimestamp.Sub(failover.CreationTimestamp.Time).String()
+			} else {
+				elapsed = time.Since(failover.CreationTimestamp.Time).String()
+			}
+		}
+
+		creationTime := toTimeString(failover.CreationTimestamp.Time)
+		row := getRow(&failover,
+			[]interface{}{failover.Name,
+				failover.Spec.Type,
+				failover.Spec.MigrationSchedule,
+				failover.Status.Status,
+				currentStep,
+				creationTime,
+				elapsed},
+		)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,100 @@
+// +build unittest
+
+package storkctl
+
+import (
+	"testing"
+
+	"github.com/libopenstorage/stork/pkg/apis/stork"
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetMigrationFailoversNoMigrationFailover(t *testing.T) {
+	cmdArgs := []string{"get", "migrationfailovers"}
+
+	expected := "No resources found.\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestFailoverNoMigrationSchedule(t *testing.T) {
+	cmdArgs := []string{"failover", "-c", "clusterpair1"}
+
+	expected := "error: exactly one name needs to be provided for the migration schedule"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestFailoverNoClusterPair(t *testing.T) {
+	cmdArgs := []string{"failover", "schedule1"}
+
+	expected := "error: ClusterPair name needs to be provided for failover"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func createMigrationFailoverAndVerify(
+	t *testing.T,
+	command string,
+	name string,
+	namespace string,
+	migrationSchedule string,
+	clusterPair string,
+) *storkv1.MigrationFailover {
+	cmdArgs := []string{command, migrationSchedule, "--name", name, "-n", namespace, "-c", clusterPair, "--skipFinalSync"}
+
+	expected := "MigrationFailover " + name + " created successfully\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	failover, err := storkClient.StorkV1alpha1().MigrationFailovers(namespace).Get(name, metav1.GetOptions{})
+	require.NoError(t, err, "Error getting migration failover")
+	require.Equal(t, migrationSchedule, failover.Spec.MigrationSchedule, "MigrationFailover schedule mismatch")
+	require.Equal(t, clusterPair, failover.Spec.ClusterPair, "MigrationFailover clusterpair mismatch")
+	require.True(t, failover.Spec.SkipFinalSync, "MigrationFailover skipFinalSync mismatch")
+	return failover
+}
+
+func TestFailover(t *testing.T) {
+	defer resetTest()
+	failover := createMigrationFailoverAndVerify(t, "failover", "failovertest", "test", "schedule1", "clusterpair1")
+	require.Equal(t, storkv1.MigrationFailoverTypeFailover, failover.Spec.Type, "MigrationFailover type mismatch")
+
+	expected := "NAME           TYPE       MIGRATIONSCHEDULE   STATUS   STEP   CREATED   ELAPSED\n" +
+		"failovertest   Failover   schedule1                                     \n"
+	cmdArgs := []string{"get", "migrationfailovers", "-n", "test"}
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestFailback(t *testing.T) {
+	defer resetTest()
+	failover := createMigrationFailoverAndVerify(t, "failback", "failbacktest", "test", "schedule1-reverse", "clusterpair1")
+	require.Equal(t, storkv1.MigrationFailoverTypeFailback, failover.Spec.Type, "MigrationFailover type mismatch")
+}
+
+func TestRetryMigrationFailover(t *testing.T) {
+	defer resetTest()
+	failover := createMigrationFailoverAndVerify(t, "failover", "retrytest", "test", "schedule1", "clusterpair1")
+
+	cmdArgs := []string{"retry", "migrationfailovers", "retrytest", "-n", "test"}
+	expected := "error: migration failover retrytest can't be retried since its status is "
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	failover.Status.Status = storkv1.MigrationFailoverStatusFailed
+	failover.Status.Steps = []*storkv1.MigrationFailoverStepInfo{
+		{
+			Step:   storkv1.MigrationFailoverStepSuspendSchedule,
+			Status: storkv1.MigrationFailoverStatusFailed,
+		},
+	}
+	_, err := storkClient.StorkV1alpha1().MigrationFailovers("test").Update(failover)
+	require.NoError(t, err, "Error updating migration failover")
+
+	expected = "NAME        TYPE       MIGRATIONSCHEDULE   STATUS   STEP              CREATED   ELAPSED\n" +
+		"retrytest   Failover   schedule1           Failed   SuspendSchedule             \n"
+	testCommon(t, []string{"get", "migrationfailovers", "-n", "test"}, nil, expected, false)
+
+	expected = "MigrationFailover retrytest will be retried\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+	failover, err = storkClient.StorkV1alpha1().MigrationFailovers("test").Get("retrytest", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting migration failover")
+	require.Contains(t, failover.Annotations, stork.RetryAnnotation)
+}
@@ -5,15 +5,17 @@ import (
 	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/schedule"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubernetes/pkg/printers"
 )
 
-var migrationScheduleColumns = []string{"NAME", "POLICYNAME", "CLUSTERPAIR", "SUSPEND", "LAST-SUCCESS-TIME", "LAST-SUCCESS-DURATION"}
+var migrationScheduleColumns = []string{"NAME", "POLICYNAME", "CLUSTERPAIR", "SUSPEND", "LAST-SUCCESS-TIME", "LAST-SUCCESS-DURATION", "RPO"}
 var migrationScheduleSubcommand = "migrationschedules"
 var migrationScheduleAliases = []string{"migrationschedule"}
 
@@ -26,8 +28,12 @@ func newCreateMigrationScheduleCommand(cmdFactory Factory, ioStreams genericclio
 	var startApplications bool
 	var preExecRule string
 	var postExecRule string
+	var checkDrift bool
 	var schedulePolicyName string
 	var suspend bool
+	var pauseOnUnhealthyClusterPair bool
+	var namespaceMapping map[string]string
+	var rpoTarget time.Duration
 
 	createMigrationScheduleCommand := &cobra.Command{
 		Use:     migrationScheduleSubcommand,
@@ -69,10 +75,14 @@ func newCreateMigrationScheduleCommand(cmdFactory Factory, ioStreams genericclio
 							StartApplications: &startApplications,
 							PreExecRule:       preExecRule,
 							PostExecRule:      postExecRule,
+							CheckDrift:        &checkDrift,
+							NamespaceMapping:  namespaceMapping,
 						},
 					},
-					SchedulePolicyName: schedulePolicyName,
-					Suspend:            &suspend,
+					SchedulePolicyName:          schedulePolicyName,
+					Suspend:                     &suspend,
+					PauseOnUnhealthyClusterPair: &pauseOnUnhealthyClusterPair,
+					RPOTarget:                   getRPOTarget(rpoTarget),
 				},
 			}
 			migrationSchedule.Name = migrationScheduleName
@@ -93,8 +103,12 @@ func newCreateMigrationScheduleCommand(cmdFactory Factory, ioStreams genericclio
 	createMigrationScheduleCommand.Flags().BoolVarP(&startApplications, "startApplications", "a", false, "Start applications on the destination cluster after migration")
 	createMigrationScheduleCommand.Flags().StringVarP(&preExecRule, "preExecRule", "", "", "Rule to run before executing migration")
 	createMigrationScheduleCommand.Flags().StringVarP(&postExecRule, "postExecRule", "", "", "Rule to run after executing migration")
+	createMigrationScheduleCommand.Flags().BoolVarP(&checkDrift, "checkDrift", "", false, "Compare the resources on the source and destination clusters after migration")
+	createMigrationScheduleCommand.Flags().StringToStringVar(&namespaceMapping, "namespaceMapping", nil, "Comma separated list of source=destination namespaces to migrate namespaces to a different namespace")
 	createMigrationScheduleCommand.Flags().StringVarP(&schedulePolicyName, "schedulePolicyName", "s", "default-migration-policy", "Name of the schedule policy to use")
 	createMigrationScheduleCommand.Flags().BoolVar(&suspend, "suspend", false, "Flag to denote whether schedule should be suspended on creation")
+	createMigrationScheduleCommand.Flags().BoolVar(&pauseOnUnhealthyClusterPair, "pauseOnUnhealthyClusterPair", false, "Don't trigger migrations while the ClusterPair isn't healthy")
+	createMigrationScheduleCommand.Flags().DurationVar(&rpoTarget, "rpoTarget", 0, "Maximum time allowed since the last successful migration before the RPO is considered breached")
 
 	return createMigrationScheduleCommand
 }
@@ -338,6 +352,11 @@ func migrationSchedulePrinter(
 				}
 			}
 		}
+		// The migration could have been pruned from the status
+		if lastSuccessFinish := migrationSchedule.Status.LastSuccessfulFinishTimestamp; lastSuccessFinish.Time.After(lastSuccessTime) {
+			lastSuccessTime = lastSuccessFinish.Time
+			lastSuccessDuration = lastSuccessFinish.Time.Sub(migrationSchedule.Status.LastSuccessfulTimestamp.Time).String()
+		}
 
 		var suspend bool
 		if migrationSchedule.Spec.Suspend == nil {
@@ -352,9 +371,34 @@ func migrationSchedulePrinter(
 				migrationSchedule.Spec.Template.Spec.ClusterPair,
 				suspend,
 				toTimeString(lastSuccessTime),
-				lastSuccessDuration},
+				lastSuccessDuration,
+				getRPOString(migrationSchedule.Spec.RPOTarget, migrationSchedule.Status.LastSuccessfulTimestamp, migrationSchedule.CreationTimestamp)},
 		)
 		rows = append(rows, row)
 	}
 	return rows, nil
 }
+
+// getRPOTarget returns the RPO target for a schedule, or nil if one wasn't
+// specified
+func getRPOTarget(rpoTarget time.Duration) *metav1.Duration {
+	if rpoTarget == 0 {
+		return nil
+	}
+	return &metav1.Duration{Duration: rpoTarget}
+}
+
+// getRPOString returns the time since the last successful migration was
+// started and the target of a schedule in the form lag/target, prefixed with
+// Breached if the target isn't being met
+func getRPOString(rpoTarget *metav1.Duration, lastSuccessful metav1.Time, created metav1.Time) string {
+	if rpoTarget == nil {
+		return ""
+	}
+	lag := schedule.GetRPOLag(lastSuccessful, created)
+	rpo := fmt.Sprintf("%v/%v", lag, rpoTarget.Duration)
+	if schedule.IsRPOLagBreached(rpoTarget, lag) {
+		return "Breached " + rpo
+	}
+	return rpo
+}
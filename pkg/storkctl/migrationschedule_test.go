@@ -71,8 +71,8 @@ func TestGetMigrationSchedulesOneMigrationSchedule(t *testing.T) {
 	defer resetTest()
 	createMigrationScheduleAndVerify(t, "getmigrationscheduletest", "testpolicy", "test", "clusterpair1", []string{"namespace1"}, "preExec", "postExec", true)
 
-	expected := "NAME                       POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION\n" +
-		"getmigrationscheduletest   testpolicy   clusterpair1   true                          \n"
+	expected := "NAME                       POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION   RPO\n" +
+		"getmigrationscheduletest   testpolicy   clusterpair1   true                                                  \n"
 
 	cmdArgs := []string{"get", "migrationschedules", "-n", "test"}
 	testCommon(t, cmdArgs, nil, expected, false)
@@ -83,9 +83,9 @@ func TestGetMigrationSchedulesMultiple(t *testing.T) {
 	createMigrationScheduleAndVerify(t, "getmigrationscheduletest1", "testpolicy", "default", "clusterpair1", []string{"namespace1"}, "", "", true)
 	createMigrationScheduleAndVerify(t, "getmigrationscheduletest2", "testpolicy", "default", "clusterpair2", []string{"namespace1"}, "", "", true)
 
-	expected := "NAME                        POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION\n" +
-		"getmigrationscheduletest1   testpolicy   clusterpair1   true                          \n" +
-		"getmigrationscheduletest2   testpolicy   clusterpair2   true                          \n"
+	expected := "NAME                        POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION   RPO\n" +
+		"getmigrationscheduletest1   testpolicy   clusterpair1   true                                                  \n" +
+		"getmigrationscheduletest2   testpolicy   clusterpair2   true                                                  \n"
 
 	cmdArgs := []string{"get", "migrationschedules", "getmigrationscheduletest1", "getmigrationscheduletest2"}
 	testCommon(t, cmdArgs, nil, expected, false)
@@ -94,8 +94,8 @@ func TestGetMigrationSchedulesMultiple(t *testing.T) {
 	cmdArgs = []string{"get", "migrationschedules"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
-	expected = "NAME                        POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION\n" +
-		"getmigrationscheduletest1   testpolicy   clusterpair1   true                          \n"
+	expected = "NAME                        POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION   RPO\n" +
+		"getmigrationscheduletest1   testpolicy   clusterpair1   true                                                  \n"
 	// Should get only one migration if name given
 	cmdArgs = []string{"get", "migrationschedules", "getmigrationscheduletest1"}
 	testCommon(t, cmdArgs, nil, expected, false)
@@ -111,17 +111,17 @@ func TestGetMigrationSchedulesMultipleNamespaces(t *testing.T) {
 	createMigrationScheduleAndVerify(t, "getmigrationscheduletest1", "testpolicy", "test1", "clusterpair1", []string{"namespace1"}, "", "", true)
 	createMigrationScheduleAndVerify(t, "getmigrationscheduletest2", "testpolicy", "test2", "clusterpair2", []string{"namespace1"}, "", "", true)
 
-	expected := "NAME                        POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION\n" +
-		"getmigrationscheduletest1   testpolicy   clusterpair1   true                          \n"
+	expected := "NAME                        POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION   RPO\n" +
+		"getmigrationscheduletest1   testpolicy   clusterpair1   true                                                  \n"
 
 	cmdArgs := []string{"get", "migrationschedules", "-n", "test1"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
 	// Should get all migrationschedules
 	cmdArgs = []string{"get", "migrationschedules", "--all-namespaces"}
-	expected = "NAMESPACE   NAME                        POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION\n" +
-		"test1       getmigrationscheduletest1   testpolicy   clusterpair1   true                          \n" +
-		"test2       getmigrationscheduletest2   testpolicy   clusterpair2   true                          \n"
+	expected = "NAMESPACE   NAME                        POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION   RPO\n" +
+		"test1       getmigrationscheduletest1   testpolicy   clusterpair1   true                                                  \n" +
+		"test2       getmigrationscheduletest2   testpolicy   clusterpair2   true                                                  \n"
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
@@ -130,8 +130,8 @@ func TestGetMigrationSchedulesWithClusterPair(t *testing.T) {
 	createMigrationScheduleAndVerify(t, "getmigrationscheduletest1", "testpolicy", "default", "clusterpair1", []string{"namespace1"}, "", "", true)
 	createMigrationScheduleAndVerify(t, "getmigrationscheduletest2", "testpolicy", "default", "clusterpair2", []string{"namespace1"}, "", "", true)
 
-	expected := "NAME                        POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION\n" +
-		"getmigrationscheduletest1   testpolicy   clusterpair1   true                          \n"
+	expected := "NAME                        POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION   RPO\n" +
+		"getmigrationscheduletest1   testpolicy   clusterpair1   true                                                  \n"
 
 	cmdArgs := []string{"get", "migrationschedules", "-c", "clusterpair1"}
 	testCommon(t, cmdArgs, nil, expected, false)
@@ -159,8 +159,8 @@ func TestGetMigrationSchedulesWithStatus(t *testing.T) {
 	migrationSchedule, err = storkops.Instance().UpdateMigrationSchedule(migrationSchedule)
 	require.NoError(t, err, "Error updating migration schedule")
 
-	expected := "NAME                             POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME     LAST-SUCCESS-DURATION\n" +
-		"getmigrationschedulestatustest   testpolicy   clusterpair1   true      " + toTimeString(finishTimestamp.Time) + "   5m0s\n"
+	expected := "NAME                             POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME     LAST-SUCCESS-DURATION   RPO\n" +
+		"getmigrationschedulestatustest   testpolicy   clusterpair1   true      " + toTimeString(finishTimestamp.Time) + "   5m0s                    \n"
 	cmdArgs := []string{"get", "migrationschedules", "getmigrationschedulestatustest"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -177,8 +177,8 @@ func TestGetMigrationSchedulesWithStatus(t *testing.T) {
 	migrationSchedule, err = storkops.Instance().UpdateMigrationSchedule(migrationSchedule)
 	require.NoError(t, err, "Error updating migration schedule")
 
-	expected = "NAME                             POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME     LAST-SUCCESS-DURATION\n" +
-		"getmigrationschedulestatustest   testpolicy   clusterpair1   true      " + toTimeString(finishTimestamp.Time) + "   5m0s\n"
+	expected = "NAME                             POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME     LAST-SUCCESS-DURATION   RPO\n" +
+		"getmigrationschedulestatustest   testpolicy   clusterpair1   true      " + toTimeString(finishTimestamp.Time) + "   5m0s                    \n"
 	cmdArgs = []string{"get", "migrationschedules", "getmigrationschedulestatustest"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -195,12 +195,35 @@ func TestGetMigrationSchedulesWithStatus(t *testing.T) {
 	_, err = storkops.Instance().UpdateMigrationSchedule(migrationSchedule)
 	require.NoError(t, err, "Error updating migration schedule")
 
-	expected = "NAME                             POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME     LAST-SUCCESS-DURATION\n" +
-		"getmigrationschedulestatustest   testpolicy   clusterpair1   true      " + toTimeString(finishTimestamp.Time) + "   5m0s\n"
+	expected = "NAME                             POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME     LAST-SUCCESS-DURATION   RPO\n" +
+		"getmigrationschedulestatustest   testpolicy   clusterpair1   true      " + toTimeString(finishTimestamp.Time) + "   5m0s                    \n"
 	cmdArgs = []string{"get", "migrationschedules", "getmigrationschedulestatustest"}
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
+func TestGetMigrationSchedulesWithRPO(t *testing.T) {
+	defer resetTest()
+	createMigrationScheduleAndVerify(t, "rpomigrationscheduletest", "testpolicy", "default", "clusterpair1", []string{"namespace1"}, "", "", true)
+
+	cmdArgs := []string{"create", "migrationschedules", "-s", "testpolicy", "-c", "clusterpair1", "--namespaces", "namespace1", "--rpoTarget", "1h", "rpomigrationscheduletest2"}
+	expected := "MigrationSchedule rpomigrationscheduletest2 created successfully\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+	migrationSchedule, err := storkops.Instance().GetMigrationSchedule("rpomigrationscheduletest2", "default")
+	require.NoError(t, err, "Error getting migration schedule")
+	require.NotNil(t, migrationSchedule.Spec.RPOTarget, "RPO target should be set")
+	require.Equal(t, time.Hour, migrationSchedule.Spec.RPOTarget.Duration, "RPO target mismatch")
+
+	migrationSchedule.Status.LastSuccessfulTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	_, err = storkops.Instance().UpdateMigrationSchedule(migrationSchedule)
+	require.NoError(t, err, "Error updating migration schedule")
+
+	expected = "NAME                        POLICYNAME   CLUSTERPAIR    SUSPEND   LAST-SUCCESS-TIME   LAST-SUCCESS-DURATION   RPO\n" +
+		"rpomigrationscheduletest    testpolicy   clusterpair1   true                                                  \n" +
+		"rpomigrationscheduletest2   testpolicy   clusterpair1   false                                                 Breached 2h0m0s/1h0m0s\n"
+	cmdArgs = []string{"get", "migrationschedules"}
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
 func TestCreateMigrationSchedulesNoNamespace(t *testing.T) {
 	cmdArgs := []string{"create", "migrationschedules", "-c", "clusterPair1", "migration1"}
 
@@ -227,6 +250,21 @@ func TestCreateMigrationSchedules(t *testing.T) {
 	createMigrationScheduleAndVerify(t, "createmigration", "testpolicy", "default", "clusterpair1", []string{"namespace1"}, "", "", true)
 }
 
+func TestCreateMigrationSchedulePauseOnUnhealthyClusterPair(t *testing.T) {
+	defer resetTest()
+	createMigrationScheduleAndVerify(t, "pausemigrationschedule", "testpolicy", "default", "clusterpair1", []string{"namespace1"}, "", "", false)
+	migrationSchedule, err := storkops.Instance().GetMigrationSchedule("pausemigrationschedule", "default")
+	require.NoError(t, err, "Error getting migration schedule")
+	require.False(t, *migrationSchedule.Spec.PauseOnUnhealthyClusterPair, "MigrationSchedule pauseOnUnhealthyClusterPair mismatch")
+
+	cmdArgs := []string{"create", "migrationschedules", "-s", "testpolicy", "-c", "clusterpair1", "--namespaces", "namespace1", "--pauseOnUnhealthyClusterPair", "pausemigrationschedule2"}
+	expected := "MigrationSchedule pausemigrationschedule2 created successfully\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+	migrationSchedule, err = storkops.Instance().GetMigrationSchedule("pausemigrationschedule2", "default")
+	require.NoError(t, err, "Error getting migration schedule")
+	require.True(t, *migrationSchedule.Spec.PauseOnUnhealthyClusterPair, "MigrationSchedule pauseOnUnhealthyClusterPair mismatch")
+}
+
 func TestCreateDuplicateMigrationSchedules(t *testing.T) {
 	defer resetTest()
 	createMigrationScheduleAndVerify(t, "createmigrationschedule", "testpolicy", "default", "clusterpair1", []string{"namespace1"}, "", "", true)
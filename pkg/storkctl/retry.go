@@ -0,0 +1,23 @@
+package storkctl
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newRetryCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	retryCommands := &cobra.Command{
+		Use:   "retry",
+		Short: "Retry the failed volumes and resources of an operation",
+	}
+
+	retryCommands.AddCommand(
+		newRetryApplicationBackupCommand(cmdFactory, ioStreams),
+		newRetryApplicationRestoreCommand(cmdFactory, ioStreams),
+		newRetryMigrationCommand(cmdFactory, ioStreams),
+		newRetryMigrationFailoverCommand(cmdFactory, ioStreams),
+		newRetryDRPlanCommand(cmdFactory, ioStreams),
+	)
+
+	return retryCommands
+}
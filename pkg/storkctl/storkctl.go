@@ -33,9 +33,16 @@ func NewCommand(cmdFactory Factory, in io.Reader, out io.Writer, errOut io.Write
 		newGetCommand(cmdFactory, ioStreams),
 		newActivateCommand(cmdFactory, ioStreams),
 		newDeactivateCommand(cmdFactory, ioStreams),
+		newCompareCommand(cmdFactory, ioStreams),
 		newGenerateCommand(cmdFactory, ioStreams),
 		newSuspendCommand(cmdFactory, ioStreams),
 		newResumeCommand(cmdFactory, ioStreams),
+		newRetryCommand(cmdFactory, ioStreams),
+		newFailoverCommand(cmdFactory, ioStreams),
+		newFailbackCommand(cmdFactory, ioStreams),
+		newExecuteCommand(cmdFactory, ioStreams),
+		newExportCommand(cmdFactory, ioStreams),
+		newImportCommand(cmdFactory, ioStreams),
 		newVersionCommand(cmdFactory, ioStreams),
 	)
 
@@ -12,6 +12,7 @@ import (
 	"math/big"
 	"time"
 
+	migration "github.com/libopenstorage/stork/pkg/migration/controllers"
 	"github.com/portworx/sched-ops/k8s/admissionregistration"
 	"github.com/portworx/sched-ops/k8s/core"
 	log "github.com/sirupsen/logrus"
@@ -22,10 +23,17 @@ import (
 )
 
 const (
-	webhookName       = "webhook.stork.libopenstorage.org"
-	storkService      = "stork-service"
-	storkNamespaceEnv = "STORK-NAMESPACE"
-	defaultNamespace  = "kube-system"
+	webhookName         = "webhook.stork.libopenstorage.org"
+	validateWebhookName = "validate.webhook.stork.libopenstorage.org"
+	storkService        = "stork-service"
+	storkNamespaceEnv   = "STORK-NAMESPACE"
+	defaultNamespace    = "kube-system"
+
+	// validateScaleWebhookName is the webhook for the scale subresource of
+	// migrated applications
+	validateScaleWebhookName = "validate-scale.webhook.stork.libopenstorage.org"
+	// namespaceNameLabel is set on every namespace to its name
+	namespaceNameLabel = "kubernetes.io/metadata.name"
 )
 
 // CreateMutateWebhook create new webhookconfig for stork if not exist already
@@ -52,14 +60,92 @@ func CreateMutateWebhook(caBundle []byte, ns string) error {
 			},
 		},
 	}
+	// Requests that scale up migrated applications are rejected from these
+	// webhooks since only mutating webhook configurations can be managed.
+	// Requests are rejected if the webhook can't be reached so that
+	// applications can't be started while the volumes are being migrated, so
+	// they only match applications labelled by migrations and never stork's
+	// own namespace. Scale requests don't have the labels of the application,
+	// so they are matched in namespaces labelled by migrations instead
+	validatePath := validateWebHook
+	failurePolicy := admissionv1beta1.Fail
+	excludeStorkNamespace := metav1.LabelSelectorRequirement{
+		Key:      namespaceNameLabel,
+		Operator: metav1.LabelSelectorOpNotIn,
+		Values:   []string{ns},
+	}
+	validateWebhook := admissionv1beta1.MutatingWebhook{
+		Name:          validateWebhookName,
+		FailurePolicy: &failurePolicy,
+		ClientConfig: admissionv1beta1.WebhookClientConfig{
+			Service: &admissionv1beta1.ServiceReference{
+				Name:      storkService,
+				Namespace: ns,
+				Path:      &validatePath,
+			},
+			CABundle: caBundle,
+		},
+		Rules: []admissionv1beta1.RuleWithOperations{
+			{
+				Operations: []admissionv1beta1.OperationType{admissionv1beta1.Update},
+				Rule: admissionv1beta1.Rule{
+					APIGroups:   []string{"*"},
+					APIVersions: []string{"*"},
+					Resources:   []string{"*"},
+				},
+			},
+		},
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{excludeStorkNamespace},
+		},
+		ObjectSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{migration.StorkMigrationLabel: "true"},
+		},
+	}
+	validateScaleWebhook := admissionv1beta1.MutatingWebhook{
+		Name:          validateScaleWebhookName,
+		FailurePolicy: &failurePolicy,
+		ClientConfig: admissionv1beta1.WebhookClientConfig{
+			Service: &admissionv1beta1.ServiceReference{
+				Name:      storkService,
+				Namespace: ns,
+				Path:      &validatePath,
+			},
+			CABundle: caBundle,
+		},
+		Rules: []admissionv1beta1.RuleWithOperations{
+			{
+				Operations: []admissionv1beta1.OperationType{admissionv1beta1.Update},
+				Rule: admissionv1beta1.Rule{
+					APIGroups:   []string{"*"},
+					APIVersions: []string{"*"},
+					Resources:   []string{"*/scale"},
+				},
+			},
+		},
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels:      map[string]string{migration.StorkMigrationLabel: "true"},
+			MatchExpressions: []metav1.LabelSelectorRequirement{excludeStorkNamespace},
+		},
+	}
 	req := &admissionv1beta1.MutatingWebhookConfiguration{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: storkAdmissionController,
 		},
-		Webhooks: []admissionv1beta1.MutatingWebhook{webhook},
+		Webhooks: []admissionv1beta1.MutatingWebhook{webhook, validateWebhook, validateScaleWebhook},
 	}
 	_, err := admissionregistration.Instance().CreateMutatingWebhookConfiguration(req)
-	if !k8serr.IsAlreadyExists(err) && err != nil {
+	if k8serr.IsAlreadyExists(err) {
+		// Update the existing configuration in case it was created by an
+		// older version without all the webhooks
+		var current *admissionv1beta1.MutatingWebhookConfiguration
+		current, err = admissionregistration.Instance().GetMutatingWebhookConfiguration(storkAdmissionController)
+		if err == nil {
+			current.Webhooks = req.Webhooks
+			_, err = admissionregistration.Instance().UpdateMutatingWebhookConfiguration(current)
+		}
+	}
+	if err != nil {
 		log.Errorf("unable to create mutate webhook: %v", err)
 		return err
 	}
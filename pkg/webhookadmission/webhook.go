@@ -11,15 +11,23 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-openapi/inflect"
 	"github.com/libopenstorage/stork/drivers/volume"
+	migration "github.com/libopenstorage/stork/pkg/migration/controllers"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
 	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/portworx/sched-ops/k8s/dynamic"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/api/admission/v1beta1"
 	admissionv1beta1 "k8s.io/api/admissionregistration/v1beta1"
 	appv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	v1 "k8s.io/api/core/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/record"
 )
 
@@ -39,17 +47,20 @@ const (
 // with stork as scheduler, if given resources are using driver supported
 // by stork
 type Controller struct {
-	Recorder record.EventRecorder
-	Driver   volume.Driver
-	server   *http.Server
-	lock     sync.Mutex
-	started  bool
+	Recorder       record.EventRecorder
+	Driver         volume.Driver
+	AdminNamespace string
+	server         *http.Server
+	lock           sync.Mutex
+	started        bool
 }
 
 // Serve method for webhook server
 func (c *Controller) serveHTTP(w http.ResponseWriter, req *http.Request) {
 	if strings.Contains(req.URL.Path, mutateWebHook) {
 		c.processMutateRequest(w, req)
+	} else if strings.Contains(req.URL.Path, validateWebHook) {
+		c.processValidateRequest(w, req)
 	} else {
 		http.Error(w, "Unsupported request", http.StatusNotFound)
 	}
@@ -154,6 +165,180 @@ func (c *Controller) processMutateRequest(w http.ResponseWriter, req *http.Reque
 	}
 }
 
+// processValidateRequest rejects requests that scale up migrated
+// applications that haven't been activated. Applications are migrated with 0
+// replicas and need to be activated through stork, which marks the namespace
+// as activated, so that they aren't started while the volumes are still being
+// migrated
+func (c *Controller) processValidateRequest(w http.ResponseWriter, req *http.Request) {
+	admissionReview := v1beta1.AdmissionReview{}
+	decoder := json.NewDecoder(req.Body)
+	defer func() {
+		if err := req.Body.Close(); err != nil {
+			log.Warnf("Error closing decoder")
+		}
+	}()
+	if err := decoder.Decode(&admissionReview); err != nil {
+		log.Errorf("Error decoding admission review request: %v", err)
+		http.Error(w, "Decode error", http.StatusBadRequest)
+		return
+	}
+
+	arReq := admissionReview.Request
+	admissionResponse := &v1beta1.AdmissionResponse{
+		Allowed: true,
+	}
+	if err := validateScaleUp(arReq, c.AdminNamespace); err != nil {
+		admissionResponse = &v1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+				Reason:  metav1.StatusReasonForbidden,
+				Code:    http.StatusForbidden,
+			},
+			Allowed: false,
+		}
+	}
+
+	admissionResponse.UID = arReq.UID
+	admissionReview.Response = admissionResponse
+	resp, err := json.Marshal(admissionReview)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not marshal response: %v", err), http.StatusInternalServerError)
+	}
+	if _, err := w.Write(resp); err != nil {
+		http.Error(w, fmt.Sprintf("could not write http response: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// validateScaleUp returns an error if the request resumes a migrated
+// application that was suspended and the namespace hasn't been activated. The
+// kinds of applications and how they are suspended are read from the
+// application registry in the admin namespace. Requests that can't be
+// validated are rejected too
+func validateScaleUp(arReq *v1beta1.AdmissionRequest, adminNamespace string) error {
+	if arReq.Operation != v1beta1.Update {
+		return nil
+	}
+
+	registry, err := resourcecollector.GetApplicationRegistry(core.Instance(), adminNamespace)
+	if err != nil {
+		return fmt.Errorf("could not get application registry: %v", err)
+	}
+
+	// The migration annotation is checked on the object before the update so
+	// that it can't be removed in the same update that resumes the application
+	var oldAnnotations, newAnnotations map[string]string
+	if arReq.SubResource == "scale" {
+		var oldScale, newScale autoscalingv1.Scale
+		if err := json.Unmarshal(arReq.OldObject.Raw, &oldScale); err != nil {
+			return fmt.Errorf("could not unmarshal admission review object: %v", err)
+		}
+		if err := json.Unmarshal(arReq.Object.Raw, &newScale); err != nil {
+			return fmt.Errorf("could not unmarshal admission review object: %v", err)
+		}
+		if oldScale.Spec.Replicas != 0 || newScale.Spec.Replicas == 0 {
+			return nil
+		}
+		options := getScaleSuspendOptions(registry, arReq.Resource)
+		if options == nil {
+			return nil
+		}
+		// The annotations are on the application being scaled
+		application := &unstructured.Unstructured{}
+		typeMeta := options.TypeMeta()
+		application.SetAPIVersion(typeMeta.APIVersion)
+		application.SetKind(typeMeta.Kind)
+		application.SetName(arReq.Name)
+		application.SetNamespace(arReq.Namespace)
+		object, err := dynamic.Instance().GetObject(application)
+		if err != nil {
+			return fmt.Errorf("could not get %v %v/%v: %v", arReq.Resource.Resource, arReq.Namespace, arReq.Name, err)
+		}
+		metadata, err := meta.Accessor(object)
+		if err != nil {
+			return err
+		}
+		oldAnnotations = metadata.GetAnnotations()
+		newAnnotations = oldAnnotations
+	} else {
+		options := resourcecollector.GetApplicationSuspendOptions(registry, schema.GroupVersionKind(arReq.Kind))
+		if options == nil {
+			return nil
+		}
+		var oldObject, newObject unstructured.Unstructured
+		if err := json.Unmarshal(arReq.OldObject.Raw, &oldObject.Object); err != nil {
+			return fmt.Errorf("could not unmarshal admission review object: %v", err)
+		}
+		if err := json.Unmarshal(arReq.Object.Raw, &newObject.Object); err != nil {
+			return fmt.Errorf("could not unmarshal admission review object: %v", err)
+		}
+		wasSuspended, err := isSuspended(options, &oldObject)
+		if err != nil {
+			return err
+		}
+		suspended, err := isSuspended(options, &newObject)
+		if err != nil {
+			return err
+		}
+		if !wasSuspended || suspended {
+			return nil
+		}
+		oldAnnotations = oldObject.GetAnnotations()
+		newAnnotations = newObject.GetAnnotations()
+	}
+
+	if oldAnnotations[migration.StorkMigrationAnnotation] != "true" ||
+		newAnnotations[migration.StorkMigrationAllowScaleAnnotation] == "true" {
+		return nil
+	}
+	activated, err := migration.IsNamespaceActivated(core.Instance(), arReq.Namespace)
+	if err != nil {
+		return fmt.Errorf("could not check if namespace %v is activated: %v", arReq.Namespace, err)
+	}
+	if activated {
+		return nil
+	}
+	return fmt.Errorf("%v %v/%v was migrated and can't be scaled up until the migrations in the namespace "+
+		"are activated with storkctl or the %v annotation is set to true",
+		arReq.Resource.Resource, arReq.Namespace, arReq.Name, migration.StorkMigrationAllowScaleAnnotation)
+}
+
+// getScaleSuspendOptions returns the options from the registry for the
+// resource of a scale request, or nil if the application isn't suspended
+// through its replicas
+func getScaleSuspendOptions(
+	registry []*resourcecollector.ApplicationSuspendOptions,
+	resource metav1.GroupVersionResource,
+) *resourcecollector.ApplicationSuspendOptions {
+	for _, options := range registry {
+		if options.Group == resource.Group &&
+			inflect.Pluralize(strings.ToLower(options.Kind)) == resource.Resource &&
+			options.Path == "spec.replicas" {
+			return options
+		}
+	}
+	return nil
+}
+
+// isSuspended returns true if the field that suspends an application is set
+// to the suspended value. The encoded values are compared so that numbers
+// parsed differently match
+func isSuspended(options *resourcecollector.ApplicationSuspendOptions, o *unstructured.Unstructured) (bool, error) {
+	value, err := options.GetValue(o.UnstructuredContent())
+	if err != nil {
+		return false, fmt.Errorf("could not get %v: %v", options.Path, err)
+	}
+	encoded, err := resourcecollector.EncodeValue(value)
+	if err != nil {
+		return false, err
+	}
+	suspended, err := resourcecollector.EncodeValue(options.Value)
+	if err != nil {
+		return false, err
+	}
+	return encoded == suspended, nil
+}
+
 func (c *Controller) checkVolumeOwner(volumes []v1.Volume, namespace string) (bool, error) {
 	// check whether pod spec use stork driver volume claims
 	for _, v := range volumes {
@@ -227,7 +412,8 @@ func (c *Controller) Start() error {
 	c.server = &http.Server{Addr: ":443",
 		TLSConfig: &tls.Config{Certificates: []tls.Certificate{tlsCert}}}
 
-	http.HandleFunc("/mutate", c.serveHTTP)
+	http.HandleFunc(mutateWebHook, c.serveHTTP)
+	http.HandleFunc(validateWebHook, c.serveHTTP)
 	go func() {
 		if err := c.server.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
 			log.Errorf("Error starting webhook server: %v", err)
@@ -0,0 +1,251 @@
+// +build unittest
+
+package webhookadmission
+
+import (
+	"encoding/json"
+	"testing"
+
+	migration "github.com/libopenstorage/stork/pkg/migration/controllers"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/portworx/sched-ops/k8s/dynamic"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/admission/v1beta1"
+	appv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	activatedNamespace   = "activated"
+	deactivatedNamespace = "deactivated"
+)
+
+func newTestDeployment(namespace string, replicas int32, annotations map[string]string) *appv1.Deployment {
+	return &appv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Namespace:   namespace,
+			Annotations: annotations,
+		},
+		Spec: appv1.DeploymentSpec{
+			Replicas: &replicas,
+		},
+	}
+}
+
+func newTestStatefulSet(namespace string, replicas int32, annotations map[string]string) *appv1.StatefulSet {
+	return &appv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apps/v1",
+			Kind:       "StatefulSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Namespace:   namespace,
+			Annotations: annotations,
+		},
+		Spec: appv1.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+	}
+}
+
+func newTestCronJob(namespace string, suspend bool, annotations map[string]string) *batchv1beta1.CronJob {
+	return &batchv1beta1.CronJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1beta1",
+			Kind:       "CronJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Namespace:   namespace,
+			Annotations: annotations,
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Suspend: &suspend,
+		},
+	}
+}
+
+func newTestScale(namespace string, replicas int32) *autoscalingv1.Scale {
+	return &autoscalingv1.Scale{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app",
+			Namespace: namespace,
+		},
+		Spec: autoscalingv1.ScaleSpec{
+			Replicas: replicas,
+		},
+	}
+}
+
+func newTestRequest(
+	t *testing.T,
+	operation v1beta1.Operation,
+	resource string,
+	subResource string,
+	kind string,
+	namespace string,
+	oldObject interface{},
+	object interface{},
+) *v1beta1.AdmissionRequest {
+	encode := func(o interface{}) runtime.RawExtension {
+		if raw, ok := o.([]byte); ok {
+			return runtime.RawExtension{Raw: raw}
+		}
+		raw, err := json.Marshal(o)
+		require.NoError(t, err, "Error encoding object")
+		return runtime.RawExtension{Raw: raw}
+	}
+	return &v1beta1.AdmissionRequest{
+		Operation:   operation,
+		Name:        "app",
+		Namespace:   namespace,
+		Resource:    metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: resource},
+		SubResource: subResource,
+		Kind:        metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: kind},
+		OldObject:   encode(oldObject),
+		Object:      encode(object),
+	}
+}
+
+func TestValidateScaleUp(t *testing.T) {
+	migrated := map[string]string{migration.StorkMigrationAnnotation: "true"}
+	allowed := map[string]string{
+		migration.StorkMigrationAnnotation:           "true",
+		migration.StorkMigrationAllowScaleAnnotation: "true",
+	}
+	fakeKube := kubernetes.NewSimpleClientset()
+	core.SetInstance(core.New(fakeKube, fakeKube.CoreV1(), fakeKube.StorageV1()))
+	require.NoError(t, migration.SetNamespaceActivated(core.Instance(), activatedNamespace, true))
+	var objects []runtime.Object
+	for _, namespace := range []string{activatedNamespace, deactivatedNamespace} {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(newTestDeployment(namespace, 0, migrated))
+		require.NoError(t, err, "Error converting deployment")
+		objects = append(objects, &unstructured.Unstructured{Object: content})
+	}
+	dynamic.SetInstance(dynamic.New(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...)))
+
+	newCronJobRequest := func(oldObject, object *batchv1beta1.CronJob) *v1beta1.AdmissionRequest {
+		request := newTestRequest(t, v1beta1.Update, "cronjobs", "", "CronJob", object.Namespace, oldObject, object)
+		request.Resource = metav1.GroupVersionResource{Group: "batch", Version: "v1beta1", Resource: "cronjobs"}
+		request.Kind = metav1.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"}
+		return request
+	}
+
+	tests := []struct {
+		name     string
+		request  *v1beta1.AdmissionRequest
+		rejected bool
+	}{
+		{
+			name: "create migrated deployment",
+			request: newTestRequest(t, v1beta1.Create, "deployments", "", "Deployment", deactivatedNamespace,
+				nil, newTestDeployment(deactivatedNamespace, 1, migrated)),
+		},
+		{
+			name: "scale up deployment that wasn't migrated",
+			request: newTestRequest(t, v1beta1.Update, "deployments", "", "Deployment", deactivatedNamespace,
+				newTestDeployment(deactivatedNamespace, 0, nil), newTestDeployment(deactivatedNamespace, 1, nil)),
+		},
+		{
+			name: "scale up migrated deployment",
+			request: newTestRequest(t, v1beta1.Update, "deployments", "", "Deployment", deactivatedNamespace,
+				newTestDeployment(deactivatedNamespace, 0, migrated), newTestDeployment(deactivatedNamespace, 1, migrated)),
+			rejected: true,
+		},
+		{
+			name: "scale up migrated deployment in activated namespace",
+			request: newTestRequest(t, v1beta1.Update, "deployments", "", "Deployment", activatedNamespace,
+				newTestDeployment(activatedNamespace, 0, migrated), newTestDeployment(activatedNamespace, 1, migrated)),
+		},
+		{
+			name: "scale up migrated deployment allowed by annotation",
+			request: newTestRequest(t, v1beta1.Update, "deployments", "", "Deployment", deactivatedNamespace,
+				newTestDeployment(deactivatedNamespace, 0, migrated), newTestDeployment(deactivatedNamespace, 1, allowed)),
+		},
+		{
+			name: "remove migration annotation while scaling up",
+			request: newTestRequest(t, v1beta1.Update, "deployments", "", "Deployment", deactivatedNamespace,
+				newTestDeployment(deactivatedNamespace, 0, migrated), newTestDeployment(deactivatedNamespace, 1, nil)),
+			rejected: true,
+		},
+		{
+			name: "scale down migrated deployment",
+			request: newTestRequest(t, v1beta1.Update, "deployments", "", "Deployment", deactivatedNamespace,
+				newTestDeployment(deactivatedNamespace, 2, migrated), newTestDeployment(deactivatedNamespace, 1, migrated)),
+		},
+		{
+			name: "update suspended migrated deployment",
+			request: newTestRequest(t, v1beta1.Update, "deployments", "", "Deployment", deactivatedNamespace,
+				newTestDeployment(deactivatedNamespace, 0, migrated), newTestDeployment(deactivatedNamespace, 0, migrated)),
+		},
+		{
+			name: "scale up migrated statefulset",
+			request: newTestRequest(t, v1beta1.Update, "statefulsets", "", "StatefulSet", deactivatedNamespace,
+				newTestStatefulSet(deactivatedNamespace, 0, migrated), newTestStatefulSet(deactivatedNamespace, 3, migrated)),
+			rejected: true,
+		},
+		{
+			name: "scale up migrated statefulset in activated namespace",
+			request: newTestRequest(t, v1beta1.Update, "statefulsets", "", "StatefulSet", activatedNamespace,
+				newTestStatefulSet(activatedNamespace, 0, migrated), newTestStatefulSet(activatedNamespace, 3, migrated)),
+		},
+		{
+			name:     "resume migrated cronjob",
+			request:  newCronJobRequest(newTestCronJob(deactivatedNamespace, true, migrated), newTestCronJob(deactivatedNamespace, false, migrated)),
+			rejected: true,
+		},
+		{
+			name:    "resume migrated cronjob in activated namespace",
+			request: newCronJobRequest(newTestCronJob(activatedNamespace, true, migrated), newTestCronJob(activatedNamespace, false, migrated)),
+		},
+		{
+			name:    "suspend migrated cronjob",
+			request: newCronJobRequest(newTestCronJob(deactivatedNamespace, false, migrated), newTestCronJob(deactivatedNamespace, true, migrated)),
+		},
+		{
+			name: "scale subresource of migrated deployment",
+			request: newTestRequest(t, v1beta1.Update, "deployments", "scale", "Scale", deactivatedNamespace,
+				newTestScale(deactivatedNamespace, 0), newTestScale(deactivatedNamespace, 1)),
+			rejected: true,
+		},
+		{
+			name: "scale subresource of migrated deployment in activated namespace",
+			request: newTestRequest(t, v1beta1.Update, "deployments", "scale", "Scale", activatedNamespace,
+				newTestScale(activatedNamespace, 0), newTestScale(activatedNamespace, 1)),
+		},
+		{
+			name: "scale subresource of missing deployment",
+			request: newTestRequest(t, v1beta1.Update, "deployments", "scale", "Scale", "missing",
+				newTestScale("missing", 0), newTestScale("missing", 1)),
+			rejected: true,
+		},
+		{
+			name: "invalid object",
+			request: newTestRequest(t, v1beta1.Update, "deployments", "", "Deployment", deactivatedNamespace,
+				newTestDeployment(deactivatedNamespace, 0, migrated), []byte("invalid")),
+			rejected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateScaleUp(test.request, "kube-system")
+			if test.rejected {
+				require.Error(t, err, "Request should have been rejected")
+			} else {
+				require.NoError(t, err, "Request should have been allowed")
+			}
+		})
+	}
+}
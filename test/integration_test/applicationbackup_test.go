@@ -1025,6 +1025,8 @@ func getBackupLocationType(locationName storkv1.BackupLocationType) (storkv1.Bac
 		return storkv1.BackupLocationAzure, nil
 	case storkv1.BackupLocationGoogle:
 		return storkv1.BackupLocationGoogle, nil
+	case storkv1.BackupLocationFilesystem:
+		return storkv1.BackupLocationFilesystem, nil
 	default:
 		return storkv1.BackupLocationType(""), fmt.Errorf("Invalid backuplocation type: %s", locationName)
 	}